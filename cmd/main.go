@@ -1,55 +1,278 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	log "github.com/sirupsen/logrus"
-	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 	_ "traive-engineering-challenge/docs"
 	"traive-engineering-challenge/internal/api"
 	"traive-engineering-challenge/internal/api/handlers"
+	"traive-engineering-challenge/internal/auth"
 	"traive-engineering-challenge/internal/config"
+	"traive-engineering-challenge/internal/health"
+	"traive-engineering-challenge/internal/ratelimit"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/memory"
 	"traive-engineering-challenge/internal/repository/postgres"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/scheduler"
+	"traive-engineering-challenge/internal/server"
+	"traive-engineering-challenge/internal/support"
+	"traive-engineering-challenge/internal/telemetry"
 )
 
+// version is the version of the build, set with -ldflags "-X main.version=..."
+var version = "dev"
+
 // @title Transaction API
 // @description API for creating and retrieving transactions
 // @version 1.0
 // @BasePath /v1
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 
 	log.SetFormatter(&log.JSONFormatter{})
 
+	configFile := flag.String("config", os.Getenv(support.ConfigFile), "path of the YAML or TOML configuration file")
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		if err := runCommand(flag.Args(), *configFile); err != nil {
+			log.WithError(err).Fatal("Command failed")
+		}
+		return
+	}
+
 	fmt.Println("Starting application ...")
 
-	cfg, err := config.LoadConfig()
+	cfg, err := config.LoadConfig(*configFile)
 	if err != nil {
 		log.WithError(err).Fatal("Failed to load configuration")
 	}
 
-	dbConn, err := postgres.NewConnection(cfg.DatabaseURL)
+	tel, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:    cfg.Telemetry.ServiceName,
+		ServiceVersion: version,
+		Environment:    cfg.Environment,
+		Exporter:       cfg.Telemetry.Exporter,
+		OTLPEndpoint:   cfg.Telemetry.OTLPEndpoint,
+		OTLPInsecure:   cfg.Telemetry.OTLPInsecure,
+		SampleRatio:    cfg.Telemetry.SampleRatio,
+		MetricInterval: cfg.Telemetry.MetricInterval,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Failed to set up telemetry")
+	}
+
+	workers := server.NewWorkers()
+
+	var (
+		repo         repository.Repository
+		postgresRepo *postgres.Repository
+		dependencies []health.Dependency
+		closeRepo    = func(context.Context) error { return nil }
+	)
+	if cfg.Database.InMemory() {
+		log.Warn("Keeping the data in memory: it is lost on restart and not shared with other instances")
+		repo = memory.New()
+	} else {
+		postgresRepo = openPostgres(cfg.Database)
+		workers.Go(postgresRepo.MonitorReplica)
+		dependencies = append(dependencies, health.Dependency{Name: "postgres", Check: postgresRepo.CheckSchema})
+		closeRepo = func(context.Context) error { return postgresRepo.Close() }
+		repo = postgresRepo
+	}
+
+	screener := risk.NewEngine()
+	if cfg.Features.RiskScreening {
+		screener, err = risk.LoadEngine(cfg.Risk.RulesFile, repo)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to load risk rules")
+		}
+	}
+
+	tokenVerifier, err := auth.LoadJWTVerifier(cfg.Auth.JWKSFile, cfg.Auth.JWKSURL, cfg.Auth.JWTIssuer, cfg.Auth.JWTAudience)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load JWT verification keys")
+	}
+
+	var rateLimiter, ipRateLimiter *ratelimit.Limiter
+	if cfg.Features.RateLimiting {
+		rateLimiter, ipRateLimiter, err = newRateLimiters(cfg.RateLimit, postgresRepo)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to configure rate limiting")
+		}
+	}
+	trustedProxies, err := ratelimit.ParseTrustedProxies(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to configure rate limiting")
+	}
+
+	app := api.NewApplication(repo, screener, cfg.Auth.BootstrapAPIKey, tokenVerifier, rateLimiter, ipRateLimiter, trustedProxies, cfg.FX.QuoteTTL)
+	router := handlers.NewRouter(app)
+
+	if cfg.Features.Scheduling {
+		workers.Go(scheduler.New(repo, app.TransactionService, scheduler.Config{
+			Interval:  cfg.Scheduler.Interval,
+			Lease:     cfg.Scheduler.Lease,
+			BatchSize: cfg.Scheduler.BatchSize,
+		}).Run)
+	}
+
+	serverConfig := server.Config{
+		Addr:              cfg.Server.Addr,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	apiServer := server.New(serverConfig, router)
+
+	checker := health.NewChecker(health.NewBuildInfo(version), cfg.Health.CheckTimeout, dependencies...)
+	adminConfig := serverConfig
+	adminConfig.Addr = cfg.Admin.Addr
+	adminServer := server.New(adminConfig, handlers.NewAdminRouter(tel.MetricsHandler(), checker))
+
+	serverErrs := make(chan error, 2)
+	log.Printf("Starting server on %s", apiServer.Addr)
+	server.Serve(apiServer, serverErrs)
+	log.Printf("Starting admin server on %s", adminServer.Addr)
+	server.Serve(adminServer, serverErrs)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case <-ctx.Done():
+		log.Info("Shutting down")
+	case err := <-serverErrs:
+		log.WithError(err).Error("Server failed, shutting down")
+	}
+	// a second signal kills the process without waiting for the shutdown to complete
+	stop()
+
+	// readiness fails from now on; the delay lets load balancers notice it before the server stops accepting requests
+	checker.SetShuttingDown()
+	time.Sleep(cfg.Shutdown.Delay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	defer cancel()
+	err = server.Shutdown(shutdownCtx,
+		server.Step{Name: "drain requests", Run: apiServer.Shutdown},
+		server.Step{Name: "stop workers", Run: workers.Stop},
+		server.Step{Name: "stop admin server", Run: adminServer.Shutdown},
+		server.Step{Name: "flush telemetry", Run: tel.Shutdown},
+		server.Step{Name: "close database", Run: closeRepo},
+	)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to shut down gracefully")
+	}
+	log.Info("Shut down gracefully")
+}
+
+// openPostgres connects to the database, and to its read replica when one is configured
+func openPostgres(cfg config.DatabaseConfig) *postgres.Repository {
+	databaseConfig := postgres.Config{
+		URL:               cfg.URL,
+		MaxOpenConns:      cfg.MaxOpenConns,
+		MaxIdleConns:      cfg.MaxIdleConns,
+		ConnMaxLifetime:   cfg.ConnMaxLifetime,
+		ConnMaxIdleTime:   cfg.ConnMaxIdleTime,
+		StatementTimeout:  cfg.StatementTimeout,
+		ConnectAttempts:   cfg.ConnectAttempts,
+		ConnectBackoff:    cfg.ConnectBackoff,
+		ConnectMaxBackoff: cfg.ConnectMaxBackoff,
+	}
+	dbConn, err := postgres.Connect(context.Background(), databaseConfig)
 	if err != nil {
 		log.WithError(err).Fatal("Failed to establish database connection")
 	}
 
-	defer func(dbConn *sql.DB) {
-		err := dbConn.Close()
+	repoOptions := []postgres.Option{
+		postgres.WithStatementTimeout(cfg.StatementTimeout),
+		postgres.WithReadAttempts(cfg.ReadAttempts),
+	}
+	if cfg.ReplicaURL != "" {
+		// the replica is not waited for: reads go to the primary until it is found healthy
+		replicaConfig := databaseConfig
+		replicaConfig.URL = cfg.ReplicaURL
+		replicaConn, err := postgres.NewConnection(replicaConfig)
 		if err != nil {
-			log.WithError(err).Fatal("Failed to close database connection")
+			log.WithError(err).Fatal("Failed to open read replica connection")
 		}
-	}(dbConn)
+		repoOptions = append(repoOptions, postgres.WithReplica(replicaConn, postgres.ReplicaConfig{
+			MaxLag:        cfg.ReplicaMaxLag,
+			CheckInterval: cfg.ReplicaCheckInterval,
+			StickyWindow:  cfg.ReplicaStickyWindow,
+		}))
+	}
 
-	repo, err := postgres.NewRepository(dbConn)
+	repo, err := postgres.NewRepository(dbConn, repoOptions...)
 	if err != nil {
 		log.WithError(err).Fatal("Failed to initialize repository")
 	}
+	return repo
+}
+
+// newRateLimiters builds the rate limiter of the callers and the one of the IP addresses, applied before the requests
+// are authenticated, whose buckets are kept in memory or, to share them between instances, in Postgres.
+// repo is nil when the data is kept in memory.
+func newRateLimiters(cfg config.RateLimitConfig, repo *postgres.Repository) (*ratelimit.Limiter, *ratelimit.Limiter, error) {
+	keyBy, err := ratelimit.ParseKeyBy(cfg.KeyBy)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	app := api.NewApplication(repo)
-	router := handlers.NewRouter(app)
+	var store ratelimit.Store
+	switch cfg.Store {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		if repo == nil {
+			return nil, nil, errors.New("the postgres rate limit store needs a postgres database")
+		}
+		store = postgres.NewRateLimitStore(repo)
+	default:
+		return nil, nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+	}
+
+	read := ratelimit.PerMinute(cfg.ReadPerMinute, cfg.ReadBurst)
+	write := ratelimit.PerMinute(cfg.WritePerMinute, cfg.WriteBurst)
+	ip := ratelimit.PerMinute(cfg.IPPerMinute, cfg.IPBurst)
+	return ratelimit.NewLimiter(store, read, write, keyBy), ratelimit.NewLimiter(store, ip, ip, ratelimit.KeyByIP), nil
+}
+
+// runCommand runs the command of the arguments instead of the API:
+//
+//	config print [--redacted]	prints the configuration that the API would run with
+func runCommand(args []string, configFile string) error {
+	if len(args) < 2 || args[0] != "config" || args[1] != "print" {
+		return fmt.Errorf("unknown command %q, expected: config print [--redacted]", strings.Join(args, " "))
+	}
 
-	const addr = ":8080"
-	log.Printf("Starting server on %s", addr)
-	if err := http.ListenAndServe(addr, router); err != nil {
-		log.WithError(err).Fatal("Failed to start server")
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := flags.Bool("redacted", false, "mask the secrets")
+	if err := flags.Parse(args[2:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	if *redacted {
+		*cfg = cfg.Redacted()
 	}
+	return cfg.WriteYAML(os.Stdout)
 }
@@ -2,18 +2,50 @@
 package api
 
 import (
+	"time"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/ratelimit"
 	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/risk"
 	"traive-engineering-challenge/internal/service"
 )
 
 type Application struct {
 	repository.Repository
 	TransactionService service.TransactionService
+	StatementService   service.StatementService
+	LimitService       service.LimitService
+	SavedSearchService service.SavedSearchService
+	CategoryService    service.CategoryService
+	ScheduleService    service.ScheduleService
+	HoldService        service.HoldService
+	FXService          service.FXService
+	APIKeyService      service.APIKeyService
+	// TokenVerifier validates bearer tokens; bearer tokens are rejected when it is nil
+	TokenVerifier auth.TokenVerifier
+	// RateLimiter limits the requests of each caller; requests are not limited when it is nil
+	RateLimiter *ratelimit.Limiter
+	// IPRateLimiter limits the requests of each IP address before they are authenticated; they are not when it is nil
+	IPRateLimiter *ratelimit.Limiter
+	// TrustedProxies forward the IP address of the clients that the rate limiters key buckets by
+	TrustedProxies ratelimit.TrustedProxies
 }
 
-func NewApplication(repo repository.Repository) Application {
+func NewApplication(repo repository.Repository, screener risk.Screener, bootstrapAPIKey string, tokenVerifier auth.TokenVerifier, rateLimiter, ipRateLimiter *ratelimit.Limiter, trustedProxies ratelimit.TrustedProxies, quoteTTL time.Duration) Application {
 	return Application{
 		Repository:         repo,
-		TransactionService: service.NewTransactionService(repo),
+		TransactionService: service.NewTransactionService(repo, screener),
+		StatementService:   service.NewStatementService(repo),
+		LimitService:       service.NewLimitService(repo),
+		SavedSearchService: service.NewSavedSearchService(repo),
+		CategoryService:    service.NewCategoryService(repo),
+		ScheduleService:    service.NewScheduleService(repo),
+		HoldService:        service.NewHoldService(repo, screener),
+		FXService:          service.NewFXService(repo, screener, quoteTTL),
+		APIKeyService:      service.NewAPIKeyService(repo, bootstrapAPIKey),
+		TokenVerifier:      tokenVerifier,
+		RateLimiter:        rateLimiter,
+		IPRateLimiter:      ipRateLimiter,
+		TrustedProxies:     trustedProxies,
 	}
 }
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"traive-engineering-challenge/internal/health"
+)
+
+const MetricsEndpoint = "/metrics"
+
+// NewAdminRouter serves the operational endpoints. They are not authenticated, so the admin port
+// must not be exposed outside of the deployment.
+func NewAdminRouter(metrics http.Handler, checker *health.Checker) http.Handler {
+	r := chi.NewRouter()
+	r.Method(http.MethodGet, MetricsEndpoint, metrics)
+	r.Get(LivenessEndpoint, Healthz())
+	r.Get(ReadinessEndpoint, Readyz(checker))
+	r.Get(StatusEndpoint, Status(checker))
+	return r
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"net/http"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const APIKeyIDParam = "keyId"
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Issues a new API key with the given scopes. The plaintext key is only returned once.
+// @tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body domain.APIKeyRequest true "API key name and scopes"
+// @Success 201 {object} domain.IssuedAPIKey
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 401 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys [post]
+func CreateAPIKey(app service.APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateAPIKey")
+		ctx, span := tr.Start(r.Context(), "Handling CreateAPIKey request")
+		defer span.End()
+
+		var request domain.APIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		issued, err := app.CreateAPIKey(ctx, request)
+		if err != nil {
+			sendAPIKeyError(w, err, support.ErrFailedToCreateAPIKey)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, issued)
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description Retrieves every API key, including revoked ones. Secrets are never returned.
+// @tags api-keys
+// @Produce json
+// @Success 200 {array} domain.APIKey
+// @Failure 401 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys [get]
+func ListAPIKeys(app service.APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListAPIKeys")
+		ctx, span := tr.Start(r.Context(), "Handling ListAPIKeys request")
+		defer span.End()
+
+		keys, err := app.ListAPIKeys(ctx)
+		if err != nil {
+			sendAPIKeyError(w, err, support.ErrFailedToRetrieveAPIKeys)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, keys)
+	}
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key
+// @Description Revokes an API key and issues a new one with the same name and scopes
+// @tags api-keys
+// @Produce json
+// @Param keyId path string true "API key ID"
+// @Success 201 {object} domain.IssuedAPIKey
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 401 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys/{keyId}/rotate [post]
+func RotateAPIKey(app service.APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("RotateAPIKey")
+		ctx, span := tr.Start(r.Context(), "Handling RotateAPIKey request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, APIKeyIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidAPIKeyID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		issued, err := app.RotateAPIKey(ctx, id)
+		if err != nil {
+			sendAPIKeyError(w, err, support.ErrFailedToRotateAPIKey)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, issued)
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @tags api-keys
+// @Param keyId path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 401 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys/{keyId} [delete]
+func RevokeAPIKey(app service.APIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("RevokeAPIKey")
+		ctx, span := tr.Start(r.Context(), "Handling RevokeAPIKey request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, APIKeyIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidAPIKeyID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		if err := app.RevokeAPIKey(ctx, id); err != nil {
+			sendAPIKeyError(w, err, support.ErrFailedToRevokeAPIKey)
+			span.RecordError(err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func sendAPIKeyError(w http.ResponseWriter, err error, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidAPIKeyRequest, http.StatusBadRequest, validationErr.Problems))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrAPIKeyNotFound, http.StatusNotFound))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
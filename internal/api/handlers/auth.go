@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/support"
+)
+
+const (
+	APIKeyHeader  = "X-API-Key"
+	Authorization = "Authorization"
+	BearerScheme  = "Bearer"
+)
+
+// APIKeyAuthenticator resolves a plaintext API key into the stored key
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+}
+
+// Authenticate is a middleware that requires either a valid API key in the X-API-Key header or, when a token
+// verifier is configured, a valid bearer token in the Authorization header. It stores the corresponding
+// principal in the request context and responds 401 otherwise.
+func Authenticate(apiKeys APIKeyAuthenticator, tokens auth.TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var principal auth.Principal
+			var err error
+
+			key := r.Header.Get(APIKeyHeader)
+			token, hasToken := bearerToken(r)
+			switch {
+			case key != "":
+				principal, err = authenticateAPIKey(r.Context(), apiKeys, key)
+			case hasToken && tokens != nil:
+				principal, err = tokens.Verify(r.Context(), token)
+			case hasToken:
+				err = auth.ErrInvalidToken
+			default:
+				sendError(w, httperrors.NewHTTPError(support.ErrMissingCredentials, http.StatusUnauthorized))
+				return
+			}
+
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidAPIKey) || errors.Is(err, auth.ErrInvalidToken) {
+					sendError(w, httperrors.NewHTTPError(support.ErrInvalidCredentials, http.StatusUnauthorized))
+				} else {
+					sendError(w, httperrors.NewHTTPError(support.ErrFailedToAuthenticate, http.StatusInternalServerError))
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func authenticateAPIKey(ctx context.Context, apiKeys APIKeyAuthenticator, key string) (auth.Principal, error) {
+	apiKey, err := apiKeys.Authenticate(ctx, key)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	return auth.Principal{Subject: apiKey.ID.String(), Scopes: apiKey.Scopes, TenantID: apiKey.TenantID, Service: true}, nil
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get(Authorization), " ")
+	if !found || !strings.EqualFold(scheme, BearerScheme) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// RequireScope is a middleware that responds 403 unless the authenticated principal was granted the scope
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if !ok {
+				sendError(w, httperrors.NewHTTPError(support.ErrMissingCredentials, http.StatusUnauthorized))
+				return
+			}
+			if !principal.HasScope(scope) {
+				sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInsufficientScope, http.StatusForbidden, []string{scope}))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireUserAccess is a middleware that responds 403 when a principal restricted to its own data
+// requests the resources of another user, identified by the given URL parameter
+func RequireUserAccess(userIDParam string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if !ok {
+				sendError(w, httperrors.NewHTTPError(support.ErrMissingCredentials, http.StatusUnauthorized))
+				return
+			}
+			if userID, restricted := principal.RestrictedUserID(); restricted && chi.URLParam(r, userIDParam) != userID.String() {
+				sendError(w, httperrors.NewHTTPError(support.ErrAccessDenied, http.StatusForbidden))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"traive-engineering-challenge/internal/api"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+type stubTokenVerifier map[string]auth.Principal
+
+func (s stubTokenVerifier) Verify(_ context.Context, token string) (auth.Principal, error) {
+	principal, ok := s[token]
+	if !ok {
+		return auth.Principal{}, auth.ErrInvalidToken
+	}
+	return principal, nil
+}
+
+func TestRouter_Authorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := uuid.New()
+	apiKeys := mocks.NewMockAPIKeyService(ctrl)
+	transactions := mocks.NewMockTransactionService(ctrl)
+	limits := mocks.NewMockLimitService(ctrl)
+	savedSearches := mocks.NewMockSavedSearchService(ctrl)
+	schedules := mocks.NewMockScheduleService(ctrl)
+	holds := mocks.NewMockHoldService(ctrl)
+
+	router := NewRouter(api.Application{
+		TransactionService: transactions,
+		LimitService:       limits,
+		SavedSearchService: savedSearches,
+		ScheduleService:    schedules,
+		HoldService:        holds,
+		APIKeyService:      apiKeys,
+		TokenVerifier: stubTokenVerifier{
+			"user-token": {Subject: userID.String(), Scopes: []string{domain.ScopeTransactionsRead}, UserID: &userID},
+		},
+	})
+
+	readKey := &domain.APIKey{ID: uuid.New(), Scopes: []string{domain.ScopeTransactionsRead}}
+	adminKey := &domain.APIKey{ID: uuid.New(), Scopes: []string{domain.ScopeAdmin}}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		apiKey         string
+		bearerToken    string
+		prepare        func()
+		wantStatusCode int
+		wantMessage    string
+	}{
+		{
+			name:           "it returns unauthorized without an API key",
+			method:         http.MethodGet,
+			path:           "/v1/transactions",
+			prepare:        func() {},
+			wantStatusCode: http.StatusUnauthorized,
+			wantMessage:    support.ErrMissingCredentials,
+		},
+		{
+			name:   "it returns unauthorized with an invalid API key",
+			method: http.MethodGet,
+			path:   "/v1/transactions",
+			apiKey: "tk_invalid",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_invalid").Return(nil, domain.ErrInvalidAPIKey)
+			},
+			wantStatusCode: http.StatusUnauthorized,
+			wantMessage:    support.ErrInvalidCredentials,
+		},
+		{
+			name:   "it returns internal server error when the key cannot be checked",
+			method: http.MethodGet,
+			path:   "/v1/transactions",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantMessage:    support.ErrFailedToAuthenticate,
+		},
+		{
+			name:   "it lets a read key list transactions",
+			method: http.MethodGet,
+			path:   "/v1/transactions",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+				transactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).Return([]domain.Transaction{}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns forbidden when a read key creates a transaction",
+			method: http.MethodPost,
+			path:   "/v1/transactions",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrInsufficientScope,
+		},
+		{
+			name:   "it lets a read key list saved searches",
+			method: http.MethodGet,
+			path:   "/v1/saved-searches",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+				savedSearches.EXPECT().ListSavedSearches(gomock.Any()).Return([]domain.SavedSearch{}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns forbidden when a read key creates a saved search",
+			method: http.MethodPost,
+			path:   "/v1/saved-searches",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrInsufficientScope,
+		},
+		{
+			name:   "it returns forbidden when a read key deletes a saved search",
+			method: http.MethodDelete,
+			path:   "/v1/saved-searches/" + uuid.NewString(),
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrInsufficientScope,
+		},
+		{
+			name:   "it lets a read key list schedules",
+			method: http.MethodGet,
+			path:   "/v1/schedules",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+				schedules.EXPECT().ListSchedules(gomock.Any()).Return([]domain.Schedule{}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns forbidden when a read key creates a schedule",
+			method: http.MethodPost,
+			path:   "/v1/schedules",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrInsufficientScope,
+		},
+		{
+			name:   "it lets a read key list holds",
+			method: http.MethodGet,
+			path:   "/v1/holds",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+				holds.EXPECT().ListHolds(gomock.Any()).Return([]domain.Hold{}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns forbidden when a read key places a hold",
+			method: http.MethodPost,
+			path:   "/v1/holds",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrInsufficientScope,
+		},
+		{
+			name:   "it returns forbidden when a read key calls an admin endpoint",
+			method: http.MethodGet,
+			path:   "/v1/admin/limits",
+			apiKey: "tk_read",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_read").Return(readKey, nil)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrInsufficientScope,
+		},
+		{
+			name:   "it lets an admin key call every endpoint",
+			method: http.MethodGet,
+			path:   "/v1/admin/limits",
+			apiKey: "tk_admin",
+			prepare: func() {
+				apiKeys.EXPECT().Authenticate(gomock.Any(), "tk_admin").Return(adminKey, nil)
+				limits.EXPECT().ListLimits(gomock.Any()).Return([]domain.Limit{}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "it returns unauthorized with an invalid bearer token",
+			method:         http.MethodGet,
+			path:           "/v1/transactions",
+			bearerToken:    "forged-token",
+			prepare:        func() {},
+			wantStatusCode: http.StatusUnauthorized,
+			wantMessage:    support.ErrInvalidCredentials,
+		},
+		{
+			name:        "it lets a bearer token list transactions",
+			method:      http.MethodGet,
+			path:        "/v1/transactions",
+			bearerToken: "user-token",
+			prepare: func() {
+				transactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).Return([]domain.Transaction{}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:        "it lets a user read their own limits",
+			method:      http.MethodGet,
+			path:        "/v1/users/" + userID.String() + "/limits",
+			bearerToken: "user-token",
+			prepare: func() {
+				limits.EXPECT().GetUserLimits(gomock.Any(), userID).Return([]domain.LimitUsage{}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "it returns forbidden when a user reads the statement of another user",
+			method:         http.MethodGet,
+			path:           "/v1/users/" + uuid.NewString() + "/statements/2026-01",
+			bearerToken:    "user-token",
+			prepare:        func() {},
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrAccessDenied,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepare()
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tc.apiKey)
+			}
+			if tc.bearerToken != "" {
+				req.Header.Set(Authorization, BearerScheme+" "+tc.bearerToken)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			if tc.wantMessage != "" {
+				require.Contains(t, rr.Body.String(), tc.wantMessage)
+			}
+		})
+	}
+}
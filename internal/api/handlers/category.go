@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"net/http"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const (
+	CategoryIDParam     = "categoryId"
+	CategoryRuleIDParam = "ruleId"
+)
+
+// CreateCategory godoc
+// @Summary Create a category
+// @Description Adds a spending category to the tree of the tenant, at the root or below its parent
+// @tags categories
+// @Accept json
+// @Produce json
+// @Param category body domain.Category true "Category definition"
+// @Success 201 {object} domain.Category
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/categories [post]
+func CreateCategory(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateCategory")
+		ctx, span := tr.Start(r.Context(), "Handling CreateCategory request")
+		defer span.End()
+
+		var category domain.Category
+		if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		created, err := app.CreateCategory(ctx, category)
+		if err != nil {
+			sendCategoryError(w, err, support.ErrFailedToCreateCategory)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, created)
+	}
+}
+
+// ListCategories godoc
+// @Summary List categories
+// @Description Retrieves the tree of the categories of the tenant as a list ordered by name, each category referencing its parent
+// @tags categories
+// @Produce json
+// @Success 200 {array} domain.Category
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/categories [get]
+func ListCategories(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListCategories")
+		ctx, span := tr.Start(r.Context(), "Handling ListCategories request")
+		defer span.End()
+
+		categories, err := app.ListCategories(ctx)
+		if err != nil {
+			sendCategoryError(w, err, support.ErrFailedToRetrieveCategories)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, categories)
+	}
+}
+
+// GetCategory godoc
+// @Summary Get a category
+// @tags categories
+// @Produce json
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/categories/{categoryId} [get]
+func GetCategory(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetCategory")
+		ctx, span := tr.Start(r.Context(), "Handling GetCategory request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, CategoryIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCategoryID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		category, err := app.GetCategory(ctx, id)
+		if err != nil {
+			sendCategoryError(w, err, support.ErrFailedToRetrieveCategories)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, category)
+	}
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Renames a category or moves it below another parent, which cannot be one of its subcategories
+// @tags categories
+// @Accept json
+// @Produce json
+// @Param categoryId path string true "Category ID"
+// @Param category body domain.Category true "Category definition"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/categories/{categoryId} [put]
+func UpdateCategory(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("UpdateCategory")
+		ctx, span := tr.Start(r.Context(), "Handling UpdateCategory request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, CategoryIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCategoryID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		var category domain.Category
+		if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+		category.ID = id
+
+		updated, err := app.UpdateCategory(ctx, category)
+		if err != nil {
+			sendCategoryError(w, err, support.ErrFailedToUpdateCategory)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, updated)
+	}
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Deletes a category without subcategories nor rules; its transactions are left uncategorized
+// @tags categories
+// @Param categoryId path string true "Category ID"
+// @Success 204
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/categories/{categoryId} [delete]
+func DeleteCategory(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("DeleteCategory")
+		ctx, span := tr.Start(r.Context(), "Handling DeleteCategory request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, CategoryIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCategoryID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		if err := app.DeleteCategory(ctx, id); err != nil {
+			sendCategoryError(w, err, support.ErrFailedToDeleteCategory)
+			span.RecordError(err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// CreateCategoryRule godoc
+// @Summary Create a categorization rule
+// @Description Adds a rule assigning its category to the transactions matching all of its conditions when they are created
+// @tags categories
+// @Accept json
+// @Produce json
+// @Param rule body domain.CategoryRule true "Rule definition"
+// @Success 201 {object} domain.CategoryRule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/category-rules [post]
+func CreateCategoryRule(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateCategoryRule")
+		ctx, span := tr.Start(r.Context(), "Handling CreateCategoryRule request")
+		defer span.End()
+
+		var rule domain.CategoryRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		created, err := app.CreateCategoryRule(ctx, rule)
+		if err != nil {
+			sendCategoryRuleError(w, err, support.ErrFailedToCreateCategoryRule)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, created)
+	}
+}
+
+// ListCategoryRules godoc
+// @Summary List categorization rules
+// @Description Retrieves the rules of the tenant in the order they are evaluated: by priority, then oldest first
+// @tags categories
+// @Produce json
+// @Success 200 {array} domain.CategoryRule
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/category-rules [get]
+func ListCategoryRules(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListCategoryRules")
+		ctx, span := tr.Start(r.Context(), "Handling ListCategoryRules request")
+		defer span.End()
+
+		rules, err := app.ListCategoryRules(ctx)
+		if err != nil {
+			sendCategoryRuleError(w, err, support.ErrFailedToRetrieveCategoryRules)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, rules)
+	}
+}
+
+// GetCategoryRule godoc
+// @Summary Get a categorization rule
+// @tags categories
+// @Produce json
+// @Param ruleId path string true "Rule ID"
+// @Success 200 {object} domain.CategoryRule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/category-rules/{ruleId} [get]
+func GetCategoryRule(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetCategoryRule")
+		ctx, span := tr.Start(r.Context(), "Handling GetCategoryRule request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, CategoryRuleIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCategoryRuleID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		rule, err := app.GetCategoryRule(ctx, id)
+		if err != nil {
+			sendCategoryRuleError(w, err, support.ErrFailedToRetrieveCategoryRules)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, rule)
+	}
+}
+
+// UpdateCategoryRule godoc
+// @Summary Update a categorization rule
+// @Description Replaces the conditions of a rule; the transactions it already categorized keep their category
+// @tags categories
+// @Accept json
+// @Produce json
+// @Param ruleId path string true "Rule ID"
+// @Param rule body domain.CategoryRule true "Rule definition"
+// @Success 200 {object} domain.CategoryRule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/category-rules/{ruleId} [put]
+func UpdateCategoryRule(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("UpdateCategoryRule")
+		ctx, span := tr.Start(r.Context(), "Handling UpdateCategoryRule request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, CategoryRuleIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCategoryRuleID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		var rule domain.CategoryRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+		rule.ID = id
+
+		updated, err := app.UpdateCategoryRule(ctx, rule)
+		if err != nil {
+			sendCategoryRuleError(w, err, support.ErrFailedToUpdateCategoryRule)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, updated)
+	}
+}
+
+// DeleteCategoryRule godoc
+// @Summary Delete a categorization rule
+// @tags categories
+// @Param ruleId path string true "Rule ID"
+// @Success 204
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/category-rules/{ruleId} [delete]
+func DeleteCategoryRule(app service.CategoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("DeleteCategoryRule")
+		ctx, span := tr.Start(r.Context(), "Handling DeleteCategoryRule request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, CategoryRuleIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCategoryRuleID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		if err := app.DeleteCategoryRule(ctx, id); err != nil {
+			sendCategoryRuleError(w, err, support.ErrFailedToDeleteCategoryRule)
+			span.RecordError(err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// sendCategoryError maps validation, not found, duplicate name and in use errors to client errors, anything else to
+// the given server error
+func sendCategoryError(w http.ResponseWriter, err error, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+	var uniqueErr repository.UniqueIndexError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidCategory, http.StatusBadRequest, validationErr.Problems))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrCategoryNotFound, http.StatusNotFound))
+	case errors.As(err, &uniqueErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrCategoryNameTaken, http.StatusConflict))
+	case errors.Is(err, domain.ErrCategoryInUse):
+		sendError(w, httperrors.NewHTTPError(support.ErrCategoryInUse, http.StatusConflict))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
+
+// sendCategoryRuleError maps validation and not found errors to client errors, anything else to the given server error
+func sendCategoryRuleError(w http.ResponseWriter, err error, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidCategoryRule, http.StatusBadRequest, validationErr.Problems))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrCategoryRuleNotFound, http.StatusNotFound))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
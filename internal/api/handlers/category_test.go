@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestCreateCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCategoryService(ctrl)
+
+	parentID := uuid.New()
+	category := domain.Category{Name: "groceries", ParentID: &parentID}
+	created := category
+	created.ID = uuid.New()
+
+	tests := []struct {
+		name           string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns created with the new category",
+			prepareService: func() {
+				mockService.EXPECT().CreateCategory(gomock.Any(), category).Return(&created, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   created,
+		},
+		{
+			name: "it returns bad request with every validation problem",
+			prepareService: func() {
+				mockService.EXPECT().CreateCategory(gomock.Any(), category).Return(nil, domain.NewValidationError([]string{"parent_id does not exist"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidCategory, http.StatusBadRequest, []string{"parent_id does not exist"}),
+		},
+		{
+			name: "it returns conflict when the name is taken",
+			prepareService: func() {
+				mockService.EXPECT().CreateCategory(gomock.Any(), category).Return(nil, repository.NewUniqueIndexError("Key (tenant_id, name)=(default, groceries) already exists."))
+			},
+			wantStatusCode: http.StatusConflict,
+			wantResponse:   httperrors.NewHTTPError(support.ErrCategoryNameTaken, http.StatusConflict),
+		},
+		{
+			name: "it returns internal server error",
+			prepareService: func() {
+				mockService.EXPECT().CreateCategory(gomock.Any(), category).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToCreateCategory, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(category)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			CreateCategory(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/admin/categories", bytes.NewBuffer(body)))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCategoryService(ctrl)
+	categoryID := uuid.New()
+
+	tests := []struct {
+		name           string
+		categoryID     string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:       "it returns no content",
+			categoryID: categoryID.String(),
+			prepareService: func() {
+				mockService.EXPECT().DeleteCategory(gomock.Any(), categoryID).Return(nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "it returns bad request for an invalid category ID",
+			categoryID:     "invalid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidCategoryID, http.StatusBadRequest),
+		},
+		{
+			name:       "it returns conflict when the category has subcategories or rules",
+			categoryID: categoryID.String(),
+			prepareService: func() {
+				mockService.EXPECT().DeleteCategory(gomock.Any(), categoryID).Return(domain.ErrCategoryInUse)
+			},
+			wantStatusCode: http.StatusConflict,
+			wantResponse:   httperrors.NewHTTPError(support.ErrCategoryInUse, http.StatusConflict),
+		},
+		{
+			name:       "it returns not found",
+			categoryID: categoryID.String(),
+			prepareService: func() {
+				mockService.EXPECT().DeleteCategory(gomock.Any(), categoryID).Return(repository.NewNotFoundError("category not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrCategoryNotFound, http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(httptest.NewRequest(http.MethodDelete, "/v1/admin/categories/"+tc.categoryID, nil), map[string]string{CategoryIDParam: tc.categoryID})
+			rr := httptest.NewRecorder()
+			DeleteCategory(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			if tc.wantResponse != nil {
+				requireJSONBody(t, tc.wantResponse, rr)
+			}
+		})
+	}
+}
+
+func TestUpdateCategoryRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockCategoryService(ctrl)
+	ruleID := uuid.New()
+	minAmount := int64(1000)
+	rule := domain.CategoryRule{ID: ruleID, CategoryID: uuid.New(), Priority: 10, MinAmount: &minAmount}
+
+	tests := []struct {
+		name           string
+		ruleID         string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:   "it returns the updated rule",
+			ruleID: ruleID.String(),
+			prepareService: func() {
+				mockService.EXPECT().UpdateCategoryRule(gomock.Any(), rule).Return(&rule, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   rule,
+		},
+		{
+			name:           "it returns bad request for an invalid rule ID",
+			ruleID:         "invalid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidCategoryRuleID, http.StatusBadRequest),
+		},
+		{
+			name:   "it returns bad request with every validation problem",
+			ruleID: ruleID.String(),
+			prepareService: func() {
+				mockService.EXPECT().UpdateCategoryRule(gomock.Any(), rule).Return(nil, domain.NewValidationError([]string{"category_id does not exist"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidCategoryRule, http.StatusBadRequest, []string{"category_id does not exist"}),
+		},
+		{
+			name:   "it returns not found",
+			ruleID: ruleID.String(),
+			prepareService: func() {
+				mockService.EXPECT().UpdateCategoryRule(gomock.Any(), rule).Return(nil, repository.NewNotFoundError("category rule not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrCategoryRuleNotFound, http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(domain.CategoryRule{CategoryID: rule.CategoryID, Priority: rule.Priority, MinAmount: rule.MinAmount})
+			require.NoError(t, err)
+
+			req := withURLParams(httptest.NewRequest(http.MethodPut, "/v1/admin/category-rules/"+tc.ruleID, bytes.NewBuffer(body)), map[string]string{CategoryRuleIDParam: tc.ruleID})
+			rr := httptest.NewRecorder()
+			UpdateCategoryRule(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
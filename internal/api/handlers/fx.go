@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"mime"
+	"net/http"
+	"time"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/api/render"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const (
+	ConversionIDParam = "conversionId"
+	RateSource        = "source"
+	EffectiveAt       = "effective_at"
+)
+
+// LoadFXRates godoc
+// @Summary Load a snapshot of exchange rates
+// @Description Stores a table of exchange rates, effective from effective_at (now by default) until a snapshot with a later effective_at replaces it. The rates are sent as a JSON snapshot, or as a CSV file of base_currency,quote_currency,rate records with the source and effective_at (RFC 3339) in the query. A pair missing from the snapshot is converted at the inverse of the rate of the reverse pair.
+// @tags fx
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param snapshot body domain.RateSnapshot true "Rate snapshot"
+// @Param source query string false "Source of the CSV rates"
+// @Param effective_at query string false "Time the CSV rates are effective from, RFC 3339"
+// @Success 201 {object} domain.RateSnapshot
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/fx/rates [post]
+func LoadFXRates(app service.FXService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("LoadFXRates")
+		ctx, span := tr.Start(r.Context(), "Handling LoadFXRates request")
+		defer span.End()
+
+		var snapshot domain.RateSnapshot
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get(ContentType)); mediaType == render.TextCSV {
+			rates, err := domain.ParseRatesCSV(r.Body)
+			if err != nil {
+				sendFXError(w, err, support.ErrInvalidFXRates, support.ErrFXRatesNotFound, support.ErrFailedToLoadFXRates)
+				span.RecordError(err)
+				return
+			}
+			snapshot.Rates = rates
+			snapshot.Source = r.URL.Query().Get(RateSource)
+
+			if value := r.URL.Query().Get(EffectiveAt); value != "" {
+				effectiveAt, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidFXRates, http.StatusBadRequest, []string{"effective_at must be an RFC 3339 time"}))
+					span.RecordError(err)
+					return
+				}
+				snapshot.EffectiveAt = effectiveAt
+			}
+		} else if err := json.NewDecoder(r.Body).Decode(&snapshot); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		created, err := app.LoadRates(ctx, snapshot)
+		if err != nil {
+			sendFXError(w, err, support.ErrInvalidFXRates, support.ErrFXRatesNotFound, support.ErrFailedToLoadFXRates)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, created)
+	}
+}
+
+// ListFXRateSnapshots godoc
+// @Summary List the snapshots of exchange rates
+// @Description Retrieves the snapshots of the tenant, the latest effective first, including the ones not yet effective
+// @tags fx
+// @Produce json
+// @Success 200 {array} domain.RateSnapshot
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/fx/rates [get]
+func ListFXRateSnapshots(app service.FXService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListFXRateSnapshots")
+		ctx, span := tr.Start(r.Context(), "Handling ListFXRateSnapshots request")
+		defer span.End()
+
+		snapshots, err := app.ListRateSnapshots(ctx)
+		if err != nil {
+			sendFXError(w, err, support.ErrInvalidFXRates, support.ErrFXRatesNotFound, support.ErrFailedToRetrieveFXRates)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, snapshots)
+	}
+}
+
+// GetFXRates godoc
+// @Summary Get the exchange rates in effect
+// @tags fx
+// @Produce json
+// @Success 200 {object} domain.RateSnapshot
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/fx/rates [get]
+func GetFXRates(app service.FXService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetFXRates")
+		ctx, span := tr.Start(r.Context(), "Handling GetFXRates request")
+		defer span.End()
+
+		snapshot, err := app.GetRates(ctx)
+		if err != nil {
+			sendFXError(w, err, support.ErrInvalidFXRates, support.ErrFXRatesNotFound, support.ErrFailedToRetrieveFXRates)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, snapshot)
+	}
+}
+
+// CreateFXQuote godoc
+// @Summary Quote a currency conversion
+// @Description Converts the source amount, in minor units of the source currency, at the rate in effect, rounded half away from zero to the minor unit of the target currency. The rate is locked until expires_at.
+// @tags fx
+// @Accept json
+// @Produce json
+// @Param quote body domain.FXQuoteRequest true "Quote request"
+// @Success 201 {object} domain.FXQuote
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/fx/quotes [post]
+func CreateFXQuote(app service.FXService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateFXQuote")
+		ctx, span := tr.Start(r.Context(), "Handling CreateFXQuote request")
+		defer span.End()
+
+		var request domain.FXQuoteRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		quote, err := app.CreateQuote(ctx, request)
+		if err != nil {
+			sendFXError(w, err, support.ErrInvalidFXQuote, support.ErrFXRatesNotFound, support.ErrFailedToCreateQuote)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, quote)
+	}
+}
+
+// ConvertFX godoc
+// @Summary Convert a quote
+// @Description Debits the source amount and credits the target amount of a quote that has not expired, at its rate. A quote is converted once. The debit must be covered by the available balance of the user in the source currency, is checked against the limits and is screened like the other transactions.
+// @tags fx
+// @Accept json
+// @Produce json
+// @Param conversion body domain.FXConversionRequest true "Conversion request"
+// @Success 201 {object} domain.FXConversion
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 422 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/fx/conversions [post]
+func ConvertFX(app service.FXService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ConvertFX")
+		ctx, span := tr.Start(r.Context(), "Handling ConvertFX request")
+		defer span.End()
+
+		var request domain.FXConversionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		conversion, err := app.Convert(ctx, request)
+		if err != nil {
+			sendFXError(w, err, support.ErrInvalidFXConversion, support.ErrQuoteNotFound, support.ErrFailedToConvert)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, conversion)
+	}
+}
+
+// ListFXConversions godoc
+// @Summary List currency conversions
+// @Description Retrieves the conversions of the tenant, only their own ones for the callers restricted to a user
+// @tags fx
+// @Produce json
+// @Success 200 {array} domain.FXConversion
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/fx/conversions [get]
+func ListFXConversions(app service.FXService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListFXConversions")
+		ctx, span := tr.Start(r.Context(), "Handling ListFXConversions request")
+		defer span.End()
+
+		conversions, err := app.ListConversions(ctx)
+		if err != nil {
+			sendFXError(w, err, support.ErrInvalidFXConversion, support.ErrConversionNotFound, support.ErrFailedToRetrieveConversions)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, conversions)
+	}
+}
+
+// GetFXConversion godoc
+// @Summary Get a currency conversion
+// @tags fx
+// @Produce json
+// @Param conversionId path string true "Conversion ID"
+// @Success 200 {object} domain.FXConversion
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/fx/conversions/{conversionId} [get]
+func GetFXConversion(app service.FXService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetFXConversion")
+		ctx, span := tr.Start(r.Context(), "Handling GetFXConversion request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, ConversionIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidConversionID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		conversion, err := app.GetConversion(ctx, id)
+		if err != nil {
+			sendFXError(w, err, support.ErrInvalidFXConversion, support.ErrConversionNotFound, support.ErrFailedToRetrieveConversions)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, conversion)
+	}
+}
+
+// sendFXError maps validation errors to the invalid message, not found errors to the not found message, the
+// conversions of unavailable quotes to a conflict, the ones the risk screening denies to a forbidden and the ones the
+// balance or the limits do not allow to an unprocessable entity, anything else to the given server error
+func sendFXError(w http.ResponseWriter, err error, invalid, notFound, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+	var insufficientFunds domain.InsufficientFundsError
+	var limitExceeded domain.LimitExceededError
+	var denied risk.DeniedError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(invalid, http.StatusBadRequest, validationErr.Problems))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(notFound, http.StatusNotFound))
+	case errors.Is(err, domain.ErrQuoteUnavailable):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrQuoteUnavailable, http.StatusConflict, []string{err.Error()}))
+	case errors.As(err, &denied):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrTransactionDenied, http.StatusForbidden, denied.RuleIDs))
+	case errors.As(err, &insufficientFunds):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrConversionInsufficientFunds, http.StatusUnprocessableEntity, []string{insufficientFunds.Error()}))
+	case errors.As(err, &limitExceeded):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrTransactionExceedsLimits, http.StatusUnprocessableEntity, limitExceeded.LimitIDs))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestLoadFXRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockFXService(ctrl)
+	effectiveAt := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	rates := []domain.FXRate{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.085"},
+		{BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: "149.5"},
+	}
+	snapshot := domain.RateSnapshot{Source: "ecb", EffectiveAt: effectiveAt, Rates: rates}
+	created := snapshot
+	created.ID = uuid.New()
+
+	jsonBody, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		query          string
+		body           string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:        "it loads a JSON snapshot",
+			contentType: ApplicationJSON,
+			body:        string(jsonBody),
+			prepareService: func() {
+				mockService.EXPECT().LoadRates(gomock.Any(), snapshot).Return(&created, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   created,
+		},
+		{
+			name:        "it loads a CSV file with the source and effective time of the query",
+			contentType: "text/csv; charset=utf-8",
+			query:       "?source=ecb&effective_at=2024-02-01T00:00:00Z",
+			body:        "base_currency,quote_currency,rate\nEUR,USD,1.085\nUSD,JPY,149.5\n",
+			prepareService: func() {
+				mockService.EXPECT().LoadRates(gomock.Any(), snapshot).Return(&created, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   created,
+		},
+		{
+			name:           "it returns bad request for a malformed CSV record",
+			contentType:    "text/csv",
+			body:           "EUR,USD\n",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidFXRates, http.StatusBadRequest,
+				[]string{"line 1: expected base_currency,quote_currency,rate"}),
+		},
+		{
+			name:           "it returns bad request for an invalid effective time",
+			contentType:    "text/csv",
+			query:          "?effective_at=yesterday",
+			body:           "EUR,USD,1.085\n",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidFXRates, http.StatusBadRequest,
+				[]string{"effective_at must be an RFC 3339 time"}),
+		},
+		{
+			name:        "it returns internal server error",
+			contentType: ApplicationJSON,
+			body:        string(jsonBody),
+			prepareService: func() {
+				mockService.EXPECT().LoadRates(gomock.Any(), snapshot).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToLoadFXRates, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/fx/rates"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set(ContentType, tc.contentType)
+			rr := httptest.NewRecorder()
+			LoadFXRates(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestGetFXRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockFXService(ctrl)
+	snapshot := domain.RateSnapshot{ID: uuid.New(), Rates: []domain.FXRate{{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.085"}}}
+
+	tests := []struct {
+		name           string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns the snapshot in effect",
+			prepareService: func() {
+				mockService.EXPECT().GetRates(gomock.Any()).Return(&snapshot, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   snapshot,
+		},
+		{
+			name: "it returns not found when no rates are loaded",
+			prepareService: func() {
+				mockService.EXPECT().GetRates(gomock.Any()).Return(nil, repository.NewNotFoundError("rate snapshot not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFXRatesNotFound, http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			rr := httptest.NewRecorder()
+			GetFXRates(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/fx/rates", nil))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestCreateFXQuote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockFXService(ctrl)
+	request := domain.FXQuoteRequest{UserID: uuid.New(), SourceCurrency: "EUR", TargetCurrency: "USD", SourceAmount: 10000}
+	quote := domain.FXQuote{
+		ID:             uuid.New(),
+		UserID:         request.UserID,
+		SourceCurrency: "EUR",
+		TargetCurrency: "USD",
+		SourceAmount:   10000,
+		TargetAmount:   10850,
+		Rate:           "1.085",
+		SnapshotID:     uuid.New(),
+		ExpiresAt:      time.Date(2024, time.February, 1, 8, 30, 30, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns created with the quote",
+			prepareService: func() {
+				mockService.EXPECT().CreateQuote(gomock.Any(), request).Return(&quote, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   quote,
+		},
+		{
+			name: "it returns bad request for a pair without rate",
+			prepareService: func() {
+				mockService.EXPECT().CreateQuote(gomock.Any(), request).Return(nil, domain.NewValidationError([]string{"no rate from EUR to USD"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidFXQuote, http.StatusBadRequest, []string{"no rate from EUR to USD"}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(request)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			CreateFXQuote(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/fx/quotes", bytes.NewBuffer(body)))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestConvertFX(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockFXService(ctrl)
+	request := domain.FXConversionRequest{QuoteID: uuid.New(), Origin: domain.OriginDesktopWeb}
+	conversion := domain.FXConversion{ID: uuid.New(), QuoteID: request.QuoteID, Origin: domain.OriginDesktopWeb, Rate: "1.085"}
+	unavailableErr := fmt.Errorf("%w: the quote expired", domain.ErrQuoteUnavailable)
+	insufficientFunds := domain.NewInsufficientFundsError(4000, 10000)
+	limitID := uuid.NewString()
+
+	tests := []struct {
+		name           string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns created with the conversion",
+			prepareService: func() {
+				mockService.EXPECT().Convert(gomock.Any(), request).Return(&conversion, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   conversion,
+		},
+		{
+			name: "it returns not found for an unknown quote",
+			prepareService: func() {
+				mockService.EXPECT().Convert(gomock.Any(), request).Return(nil, repository.NewNotFoundError("quote not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrQuoteNotFound, http.StatusNotFound),
+		},
+		{
+			name: "it returns conflict when the quote expired",
+			prepareService: func() {
+				mockService.EXPECT().Convert(gomock.Any(), request).Return(nil, unavailableErr)
+			},
+			wantStatusCode: http.StatusConflict,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrQuoteUnavailable, http.StatusConflict, []string{unavailableErr.Error()}),
+		},
+		{
+			name: "it returns forbidden when the conversion is denied by risk rules",
+			prepareService: func() {
+				mockService.EXPECT().Convert(gomock.Any(), request).Return(nil, risk.NewDeniedError([]string{"velocity"}))
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrTransactionDenied, http.StatusForbidden, []string{"velocity"}),
+		},
+		{
+			name: "it returns unprocessable entity when the balance does not cover the conversion",
+			prepareService: func() {
+				mockService.EXPECT().Convert(gomock.Any(), request).Return(nil, insufficientFunds)
+			},
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrConversionInsufficientFunds, http.StatusUnprocessableEntity, []string{insufficientFunds.Error()}),
+		},
+		{
+			name: "it returns unprocessable entity when the conversion exceeds a limit",
+			prepareService: func() {
+				mockService.EXPECT().Convert(gomock.Any(), request).Return(nil, domain.NewLimitExceededError([]string{limitID}))
+			},
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrTransactionExceedsLimits, http.StatusUnprocessableEntity, []string{limitID}),
+		},
+		{
+			name: "it returns internal server error",
+			prepareService: func() {
+				mockService.EXPECT().Convert(gomock.Any(), request).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToConvert, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(request)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			ConvertFX(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/fx/conversions", bytes.NewBuffer(body)))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestGetFXConversion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockFXService(ctrl)
+	conversionID := uuid.New()
+	conversion := domain.FXConversion{ID: conversionID, Rate: "1.085"}
+
+	tests := []struct {
+		name           string
+		conversionID   string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:         "it returns the conversion",
+			conversionID: conversionID.String(),
+			prepareService: func() {
+				mockService.EXPECT().GetConversion(gomock.Any(), conversionID).Return(&conversion, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   conversion,
+		},
+		{
+			name:           "it returns bad request for an invalid conversion ID",
+			conversionID:   "invalid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidConversionID, http.StatusBadRequest),
+		},
+		{
+			name:         "it returns not found",
+			conversionID: conversionID.String(),
+			prepareService: func() {
+				mockService.EXPECT().GetConversion(gomock.Any(), conversionID).Return(nil, repository.NewNotFoundError("conversion not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrConversionNotFound, http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(httptest.NewRequest(http.MethodGet, "/v1/fx/conversions/"+tc.conversionID, nil), map[string]string{ConversionIDParam: tc.conversionID})
+			rr := httptest.NewRecorder()
+			GetFXConversion(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"traive-engineering-challenge/internal/health"
+)
+
+const (
+	LivenessEndpoint  = "/healthz"
+	ReadinessEndpoint = "/readyz"
+	StatusEndpoint    = "/status"
+)
+
+// Healthz tells that the process is alive. It does not check the dependencies, so that an unavailable database
+// does not get every instance restarted.
+func Healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sendJSON(w, http.StatusOK, map[string]string{"status": health.StatusOK})
+	}
+}
+
+// Readyz tells whether the dependencies can be used and the API is not shutting down,
+// responding 503 Service Unavailable otherwise
+func Readyz(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, ready := checker.Ready(r.Context())
+		if !ready {
+			sendJSON(w, http.StatusServiceUnavailable, report)
+			return
+		}
+		sendJSON(w, http.StatusOK, report)
+	}
+}
+
+// Status describes the build, uptime and dependencies of the API
+func Status(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sendJSON(w, http.StatusOK, checker.Status(r.Context()))
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/health"
+)
+
+func TestNewAdminRouter(t *testing.T) {
+	var dbErr error
+	checker := health.NewChecker(health.NewBuildInfo("1.2.3"), time.Second,
+		health.Dependency{Name: "postgres", Check: func(context.Context) error { return dbErr }},
+	)
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("metrics"))
+	})
+	router := NewAdminRouter(metrics, checker)
+
+	get := func(path string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if path == MetricsEndpoint {
+			require.Equal(t, "metrics", rec.Body.String())
+			return rec.Code, nil
+		}
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body
+	}
+
+	code, _ := get(MetricsEndpoint)
+	require.Equal(t, http.StatusOK, code)
+
+	code, body := get(ReadinessEndpoint)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, health.StatusOK, body["status"])
+
+	code, body = get(StatusEndpoint)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "1.2.3", body["build"].(map[string]interface{})["version"])
+	require.Contains(t, body, "uptime_seconds")
+
+	// the database is unavailable: the API is alive but not ready
+	dbErr = errors.New("connection refused")
+
+	code, body = get(LivenessEndpoint)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, health.StatusOK, body["status"])
+
+	code, body = get(ReadinessEndpoint)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, health.StatusUnavailable, body["status"])
+	dependency := body["dependencies"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "postgres", dependency["name"])
+	require.Equal(t, "connection refused", dependency["error"])
+
+	code, body = get(StatusEndpoint)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, health.StatusUnavailable, body["status"])
+
+	// readiness keeps failing during the shutdown, whatever the dependencies
+	dbErr = nil
+	checker.SetShuttingDown()
+
+	code, body = get(ReadinessEndpoint)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, health.StatusShuttingDown, body["status"])
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"io"
+	"net/http"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const HoldIDParam = "holdId"
+
+// CreateHold godoc
+// @Summary Place a hold
+// @Description Reserves an amount of the available balance of a user, its balance minus the amounts of its active holds, for ttl_seconds (7 days by default, at most 30 days). The hold is then captured by a debit, released, or expires.
+// @tags holds
+// @Accept json
+// @Produce json
+// @Param hold body domain.Hold true "Hold definition"
+// @Success 201 {object} domain.Hold
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 422 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/holds [post]
+func CreateHold(app service.HoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateHold")
+		ctx, span := tr.Start(r.Context(), "Handling CreateHold request")
+		defer span.End()
+
+		var hold domain.Hold
+		if err := json.NewDecoder(r.Body).Decode(&hold); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		created, err := app.CreateHold(ctx, hold)
+		if err != nil {
+			sendHoldError(w, err, support.ErrFailedToCreateHold)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, created)
+	}
+}
+
+// ListHolds godoc
+// @Summary List holds
+// @Description Retrieves the holds of the tenant, only their own ones for the callers restricted to a user
+// @tags holds
+// @Produce json
+// @Success 200 {array} domain.Hold
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/holds [get]
+func ListHolds(app service.HoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListHolds")
+		ctx, span := tr.Start(r.Context(), "Handling ListHolds request")
+		defer span.End()
+
+		holds, err := app.ListHolds(ctx)
+		if err != nil {
+			sendHoldError(w, err, support.ErrFailedToRetrieveHolds)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, holds)
+	}
+}
+
+// GetHold godoc
+// @Summary Get a hold
+// @tags holds
+// @Produce json
+// @Param holdId path string true "Hold ID"
+// @Success 200 {object} domain.Hold
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/holds/{holdId} [get]
+func GetHold(app service.HoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetHold")
+		ctx, span := tr.Start(r.Context(), "Handling GetHold request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, HoldIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidHoldID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		hold, err := app.GetHold(ctx, id)
+		if err != nil {
+			sendHoldError(w, err, support.ErrFailedToRetrieveHolds)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, hold)
+	}
+}
+
+// CaptureHold godoc
+// @Summary Capture a hold
+// @Description Settles an active hold with a debit of the amount, the whole amount of the hold when not set; what it does not capture is released. The debit is screened and checked against the limits like any other debit, and carries the hold_id metadata.
+// @tags holds
+// @Accept json
+// @Produce json
+// @Param holdId path string true "Hold ID"
+// @Param capture body domain.HoldCaptureRequest false "Amount to capture"
+// @Success 200 {object} domain.Hold
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 422 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/holds/{holdId}/capture [post]
+func CaptureHold(app service.HoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CaptureHold")
+		ctx, span := tr.Start(r.Context(), "Handling CaptureHold request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, HoldIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidHoldID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		// the body is optional, the whole amount being captured without it
+		var request domain.HoldCaptureRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		hold, err := app.CaptureHold(ctx, id, request.Amount)
+		if err != nil {
+			sendHoldError(w, err, support.ErrFailedToCaptureHold)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, hold)
+	}
+}
+
+// ReleaseHold godoc
+// @Summary Release a hold
+// @Description Frees the amount of an active hold without debiting it
+// @tags holds
+// @Produce json
+// @Param holdId path string true "Hold ID"
+// @Success 200 {object} domain.Hold
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/holds/{holdId}/release [post]
+func ReleaseHold(app service.HoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ReleaseHold")
+		ctx, span := tr.Start(r.Context(), "Handling ReleaseHold request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, HoldIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidHoldID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		hold, err := app.ReleaseHold(ctx, id)
+		if err != nil {
+			sendHoldError(w, err, support.ErrFailedToReleaseHold)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, hold)
+	}
+}
+
+// GetUserBalance godoc
+// @Summary Get the balance of a user
+// @Description Retrieves the balance of a user in one currency, the amount its active holds in it reserve and the balance available to new holds
+// @tags holds
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param currency query string false "ISO 4217 code of the currency of the balance" default(USD)
+// @Success 200 {object} domain.Balance
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/users/{userId}/balance [get]
+func GetUserBalance(app service.HoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetUserBalance")
+		ctx, span := tr.Start(r.Context(), "Handling GetUserBalance request")
+		defer span.End()
+
+		userID, err := uuid.Parse(chi.URLParam(r, UserIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidUserID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		currency, ok := getQueryParamAsCurrency(r)
+		if !ok {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCurrency, http.StatusBadRequest))
+			return
+		}
+
+		balance, err := app.GetUserBalance(ctx, userID, currency)
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToRetrieveBalance, http.StatusInternalServerError))
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, balance)
+	}
+}
+
+// sendHoldError maps validation, not found, status transition, insufficient funds, denied and limit errors to client
+// errors, anything else to the given server error
+func sendHoldError(w http.ResponseWriter, err error, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+	var insufficientFunds domain.InsufficientFundsError
+	var denied risk.DeniedError
+	var limitExceeded domain.LimitExceededError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidHold, http.StatusBadRequest, validationErr.Problems))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrHoldNotFound, http.StatusNotFound))
+	case errors.Is(err, domain.ErrHoldTransition):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrHoldTransition, http.StatusConflict, []string{err.Error()}))
+	case errors.As(err, &insufficientFunds):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInsufficientFunds, http.StatusUnprocessableEntity, []string{insufficientFunds.Error()}))
+	case errors.As(err, &denied):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrTransactionDenied, http.StatusForbidden, denied.RuleIDs))
+	case errors.As(err, &limitExceeded):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrTransactionExceedsLimits, http.StatusUnprocessableEntity, limitExceeded.LimitIDs))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestCreateHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockHoldService(ctrl)
+
+	hold := domain.Hold{
+		UserID:      uuid.New(),
+		Origin:      domain.OriginDesktopWeb,
+		Amount:      5000,
+		Description: "Hotel",
+		TTLSeconds:  3600,
+	}
+	created := hold
+	created.ID = uuid.New()
+	created.Status = domain.HoldStatusActive
+	created.ExpiresAt = time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	created.TTLSeconds = 0
+	insufficientFunds := domain.NewInsufficientFundsError(1200, 5000)
+
+	tests := []struct {
+		name           string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns created with the new hold",
+			prepareService: func() {
+				mockService.EXPECT().CreateHold(gomock.Any(), hold).Return(&created, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   created,
+		},
+		{
+			name: "it returns unprocessable entity when the available balance does not cover the hold",
+			prepareService: func() {
+				mockService.EXPECT().CreateHold(gomock.Any(), hold).Return(nil, insufficientFunds)
+			},
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInsufficientFunds, http.StatusUnprocessableEntity, []string{insufficientFunds.Error()}),
+		},
+		{
+			name: "it returns bad request with every validation problem",
+			prepareService: func() {
+				mockService.EXPECT().CreateHold(gomock.Any(), hold).Return(nil, domain.NewValidationError([]string{"a", "b"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidHold, http.StatusBadRequest, []string{"a", "b"}),
+		},
+		{
+			name: "it returns internal server error",
+			prepareService: func() {
+				mockService.EXPECT().CreateHold(gomock.Any(), hold).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToCreateHold, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(hold)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			CreateHold(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/holds", bytes.NewBuffer(body)))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestCaptureHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockHoldService(ctrl)
+	holdID := uuid.New()
+	transactionID := uuid.New()
+	captured := domain.Hold{ID: holdID, Amount: 5000, Status: domain.HoldStatusCaptured, CapturedAmount: 4200, TransactionID: &transactionID}
+	transitionErr := fmt.Errorf("%w: the hold expired", domain.ErrHoldTransition)
+	limitID := uuid.NewString()
+
+	tests := []struct {
+		name           string
+		holdID         string
+		body           io.Reader
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:   "it captures the amount of the body",
+			holdID: holdID.String(),
+			body:   bytes.NewBufferString(`{"amount": 4200}`),
+			prepareService: func() {
+				mockService.EXPECT().CaptureHold(gomock.Any(), holdID, int64(4200)).Return(&captured, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   captured,
+		},
+		{
+			name:   "it captures the whole amount without a body",
+			holdID: holdID.String(),
+			body:   http.NoBody,
+			prepareService: func() {
+				mockService.EXPECT().CaptureHold(gomock.Any(), holdID, int64(0)).Return(&captured, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   captured,
+		},
+		{
+			name:           "it returns bad request for an invalid hold ID",
+			holdID:         "invalid",
+			body:           http.NoBody,
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidHoldID, http.StatusBadRequest),
+		},
+		{
+			name:           "it returns bad request for a malformed body",
+			holdID:         holdID.String(),
+			body:           bytes.NewBufferString(`{"amount": "all"}`),
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest),
+		},
+		{
+			name:   "it returns conflict when the hold is no longer active",
+			holdID: holdID.String(),
+			body:   http.NoBody,
+			prepareService: func() {
+				mockService.EXPECT().CaptureHold(gomock.Any(), holdID, int64(0)).Return(nil, transitionErr)
+			},
+			wantStatusCode: http.StatusConflict,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrHoldTransition, http.StatusConflict, []string{transitionErr.Error()}),
+		},
+		{
+			name:   "it returns unprocessable entity when the debit exceeds a limit",
+			holdID: holdID.String(),
+			body:   http.NoBody,
+			prepareService: func() {
+				mockService.EXPECT().CaptureHold(gomock.Any(), holdID, int64(0)).Return(nil, domain.NewLimitExceededError([]string{limitID}))
+			},
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrTransactionExceedsLimits, http.StatusUnprocessableEntity, []string{limitID}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(httptest.NewRequest(http.MethodPost, "/v1/holds/"+tc.holdID+"/capture", tc.body), map[string]string{HoldIDParam: tc.holdID})
+			rr := httptest.NewRecorder()
+			CaptureHold(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestGetUserBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockHoldService(ctrl)
+	userID := uuid.New()
+	balance := domain.Balance{UserID: userID, Currency: domain.DefaultCurrency, Balance: 10000, Held: 5000, Available: 5000}
+	eurBalance := domain.Balance{UserID: userID, Currency: "EUR", Balance: 300, Available: 300}
+
+	testData := map[string]struct {
+		query          string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		"it returns the balance in the default currency": {
+			prepareService: func() {
+				mockService.EXPECT().GetUserBalance(gomock.Any(), userID, domain.DefaultCurrency).Return(&balance, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   balance,
+		},
+		"it returns the balance in the requested currency": {
+			query: "?currency=EUR",
+			prepareService: func() {
+				mockService.EXPECT().GetUserBalance(gomock.Any(), userID, "EUR").Return(&eurBalance, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   eurBalance,
+		},
+		"it returns bad request for an unsupported currency": {
+			query:          "?currency=XXX",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidCurrency, http.StatusBadRequest),
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(httptest.NewRequest(http.MethodGet, "/v1/users/"+userID.String()+"/balance"+tc.query, nil), map[string]string{UserIDParam: userID.String()})
+			rr := httptest.NewRecorder()
+			GetUserBalance(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package httperrors
 
 type HTTPError struct {
-	Message    string `json:"message"`
-	StatusCode int    `json:"-"`
+	Message    string   `json:"message"`
+	Details    []string `json:"details,omitempty"`
+	StatusCode int      `json:"-"`
 }
 
 func (e HTTPError) Error() string {
@@ -15,3 +16,12 @@ func NewHTTPError(message string, statusCode int) HTTPError {
 		StatusCode: statusCode,
 	}
 }
+
+// NewHTTPErrorWithDetails builds an HTTPError carrying extra information for the client, e.g. the rules that denied a request
+func NewHTTPErrorWithDetails(message string, statusCode int, details []string) HTTPError {
+	return HTTPError{
+		Message:    message,
+		Details:    details,
+		StatusCode: statusCode,
+	}
+}
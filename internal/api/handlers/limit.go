@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"net/http"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const LimitIDParam = "limitId"
+
+// CreateLimit godoc
+// @Summary Create a limit
+// @Description Defines a daily or monthly cap on debits for a user, an origin or globally
+// @tags limits
+// @Accept json
+// @Produce json
+// @Param limit body domain.Limit true "Limit definition"
+// @Success 201 {object} domain.Limit
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/limits [post]
+func CreateLimit(app service.LimitService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateLimit")
+		ctx, span := tr.Start(r.Context(), "Handling CreateLimit request")
+		defer span.End()
+
+		var limit domain.Limit
+		if err := json.NewDecoder(r.Body).Decode(&limit); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		created, err := app.CreateLimit(ctx, limit)
+		if err != nil {
+			sendLimitError(w, err, support.ErrFailedToCreateLimit)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, created)
+	}
+}
+
+// ListLimits godoc
+// @Summary List limits
+// @Description Retrieves every limit definition
+// @tags limits
+// @Produce json
+// @Success 200 {array} domain.Limit
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/limits [get]
+func ListLimits(app service.LimitService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListLimits")
+		ctx, span := tr.Start(r.Context(), "Handling ListLimits request")
+		defer span.End()
+
+		limits, err := app.ListLimits(ctx)
+		if err != nil {
+			sendLimitError(w, err, support.ErrFailedToRetrieveLimits)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, limits)
+	}
+}
+
+// GetLimit godoc
+// @Summary Get a limit
+// @tags limits
+// @Produce json
+// @Param limitId path string true "Limit ID"
+// @Success 200 {object} domain.Limit
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/limits/{limitId} [get]
+func GetLimit(app service.LimitService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetLimit")
+		ctx, span := tr.Start(r.Context(), "Handling GetLimit request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, LimitIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidLimitID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		limit, err := app.GetLimit(ctx, id)
+		if err != nil {
+			sendLimitError(w, err, support.ErrFailedToRetrieveLimits)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, limit)
+	}
+}
+
+// UpdateLimit godoc
+// @Summary Update a limit
+// @Description Replaces the definition of a limit
+// @tags limits
+// @Accept json
+// @Produce json
+// @Param limitId path string true "Limit ID"
+// @Param limit body domain.Limit true "Limit definition"
+// @Success 200 {object} domain.Limit
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/limits/{limitId} [put]
+func UpdateLimit(app service.LimitService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("UpdateLimit")
+		ctx, span := tr.Start(r.Context(), "Handling UpdateLimit request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, LimitIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidLimitID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		var limit domain.Limit
+		if err := json.NewDecoder(r.Body).Decode(&limit); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+		limit.ID = id
+
+		updated, err := app.UpdateLimit(ctx, limit)
+		if err != nil {
+			sendLimitError(w, err, support.ErrFailedToUpdateLimit)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, updated)
+	}
+}
+
+// DeleteLimit godoc
+// @Summary Delete a limit
+// @tags limits
+// @Param limitId path string true "Limit ID"
+// @Success 204
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/limits/{limitId} [delete]
+func DeleteLimit(app service.LimitService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("DeleteLimit")
+		ctx, span := tr.Start(r.Context(), "Handling DeleteLimit request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, LimitIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidLimitID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		if err := app.DeleteLimit(ctx, id); err != nil {
+			sendLimitError(w, err, support.ErrFailedToDeleteLimit)
+			span.RecordError(err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetUserLimits godoc
+// @Summary Get the limits of a user
+// @Description Retrieves every limit that applies to a user together with the remaining headroom
+// @tags limits
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {array} domain.LimitUsage
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/users/{userId}/limits [get]
+func GetUserLimits(app service.LimitService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetUserLimits")
+		ctx, span := tr.Start(r.Context(), "Handling GetUserLimits request")
+		defer span.End()
+
+		userID, err := uuid.Parse(chi.URLParam(r, UserIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidUserID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		usages, err := app.GetUserLimits(ctx, userID)
+		if err != nil {
+			sendLimitError(w, err, support.ErrFailedToRetrieveLimits)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, usages)
+	}
+}
+
+// sendLimitError maps validation and not found errors to client errors, anything else to the given server error
+func sendLimitError(w http.ResponseWriter, err error, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidLimit, http.StatusBadRequest, validationErr.Problems))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrLimitNotFound, http.StatusNotFound))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
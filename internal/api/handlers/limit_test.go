@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestCreateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockLimitService(ctrl)
+
+	limit := domain.Limit{Scope: domain.LimitScopeOrigin, Origin: support.MobileIOS, Period: domain.LimitPeriodDaily, MaxAmount: 1000}
+	created := limit
+	created.ID = uuid.New()
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns created with the new limit",
+			body: limit,
+			prepareService: func() {
+				mockService.EXPECT().CreateLimit(gomock.Any(), limit).Return(&created, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   created,
+		},
+		{
+			name:           "it returns bad request when the request body is invalid",
+			body:           "invalid body",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest),
+		},
+		{
+			name: "it returns bad request with every validation problem",
+			body: limit,
+			prepareService: func() {
+				mockService.EXPECT().CreateLimit(gomock.Any(), limit).Return(nil, domain.NewValidationError([]string{"a", "b"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidLimit, http.StatusBadRequest, []string{"a", "b"}),
+		},
+		{
+			name: "it returns internal server error",
+			body: limit,
+			prepareService: func() {
+				mockService.EXPECT().CreateLimit(gomock.Any(), limit).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToCreateLimit, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			CreateLimit(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/admin/limits", bytes.NewBuffer(body)))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestGetLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockLimitService(ctrl)
+	limitID := uuid.New()
+	limit := domain.Limit{ID: limitID, Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodMonthly, MaxAmount: 1000}
+
+	tests := []struct {
+		name           string
+		limitID        string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:    "it returns the limit",
+			limitID: limitID.String(),
+			prepareService: func() {
+				mockService.EXPECT().GetLimit(gomock.Any(), limitID).Return(&limit, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   limit,
+		},
+		{
+			name:           "it returns bad request for an invalid limit ID",
+			limitID:        "invalid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidLimitID, http.StatusBadRequest),
+		},
+		{
+			name:    "it returns not found",
+			limitID: limitID.String(),
+			prepareService: func() {
+				mockService.EXPECT().GetLimit(gomock.Any(), limitID).Return(nil, repository.NewNotFoundError("limit not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrLimitNotFound, http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(httptest.NewRequest(http.MethodGet, "/v1/admin/limits/"+tc.limitID, nil), map[string]string{LimitIDParam: tc.limitID})
+			rr := httptest.NewRecorder()
+			GetLimit(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestDeleteLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockLimitService(ctrl)
+	limitID := uuid.New()
+
+	mockService.EXPECT().DeleteLimit(gomock.Any(), limitID).Return(nil)
+
+	req := withURLParams(httptest.NewRequest(http.MethodDelete, "/v1/admin/limits/"+limitID.String(), nil), map[string]string{LimitIDParam: limitID.String()})
+	rr := httptest.NewRecorder()
+	DeleteLimit(mockService).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestGetUserLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockLimitService(ctrl)
+	userID := uuid.New()
+	usages := []domain.LimitUsage{
+		domain.NewLimitUsage(domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodDaily, MaxAmount: 1000}, 250),
+	}
+
+	mockService.EXPECT().GetUserLimits(gomock.Any(), userID).Return(usages, nil)
+
+	req := withURLParams(httptest.NewRequest(http.MethodGet, "/v1/users/"+userID.String()+"/limits", nil), map[string]string{UserIDParam: userID.String()})
+	rr := httptest.NewRecorder()
+	GetUserLimits(mockService).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	requireJSONBody(t, usages, rr)
+}
+
+// requireJSONBody compares the JSON body of a response with the JSON representation of the expected value
+func requireJSONBody(t *testing.T, want interface{}, rr *httptest.ResponseRecorder) {
+	t.Helper()
+
+	expected, err := json.Marshal(want)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), rr.Body.String())
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/felixge/httpsnoop"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"net/http"
+)
+
+const instrumentationName = "traive-engineering-challenge/internal/api/handlers"
+
+// UnmatchedRoute is the route of the requests that did not match any route, so that unknown paths do not create
+// a time series each
+const UnmatchedRoute = "unmatched"
+
+// Metrics is a middleware that records the rate, errors and duration of the requests by route template and status,
+// and the number of requests in flight. The request count is the count of the duration histogram.
+func Metrics() func(http.Handler) http.Handler {
+	meter := otel.Meter(instrumentationName)
+
+	duration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Duration of the HTTP requests"),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	inFlight, err := meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithDescription("Number of HTTP requests in flight"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method := metric.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method))
+			inFlight.Add(r.Context(), 1, method)
+			defer inFlight.Add(r.Context(), -1, method)
+
+			m := httpsnoop.CaptureMetrics(next, w, r)
+
+			// the route template is only known once the router matched the request
+			route := UnmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			duration.Record(r.Context(), m.Duration.Seconds(), metric.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.HTTPResponseStatusCode(m.Code),
+			))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(meterProvider) })
+
+	r := chi.NewRouter()
+	r.Use(Metrics())
+	r.Get("/v1/transactions/{transactionId}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/v1/transactions/1", "/v1/transactions/2", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+
+	counts := map[string]uint64{}
+	var inFlight int64 = -1
+	for _, scope := range collected.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					route, _ := point.Attributes.Value(semconv.HTTPRouteKey)
+					status, _ := point.Attributes.Value(semconv.HTTPResponseStatusCodeKey)
+					counts[route.AsString()+" "+status.Emit()] += point.Count
+				}
+			case metricdata.Sum[int64]:
+				inFlight = data.DataPoints[0].Value
+			}
+		}
+	}
+
+	require.Equal(t, map[string]uint64{
+		"/v1/transactions/{transactionId} 404": 2,
+		UnmatchedRoute + " 404":                1,
+	}, counts)
+	require.Zero(t, inFlight, "no request should be in flight")
+}
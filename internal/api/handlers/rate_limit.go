@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	log "github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/ratelimit"
+	"traive-engineering-challenge/internal/support"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+	ForwardedForHeader       = "X-Forwarded-For"
+)
+
+// RateLimit is a middleware that takes a token from the bucket of the caller for the class of the request, and
+// responds 429 with a Retry-After header when the bucket is empty. Every limited response carries the RateLimit-*
+// headers. When the store of the buckets fails, requests are let through rather than rejected.
+// A nil limiter disables rate limiting. The IP address of the requests forwarded by the trusted proxies is the one of
+// the client they forwarded.
+func RateLimit(limiter *ratelimit.Limiter, proxies ratelimit.TrustedProxies) func(http.Handler) http.Handler {
+	return rateLimit(limiter, func(r *http.Request) string { return rateLimitKey(r, limiter.KeyBy(), proxies) })
+}
+
+// RateLimitByIP is a middleware like RateLimit that takes a token from the bucket of the IP address of the request,
+// whoever the caller. It is mounted before the authentication, so that the requests with bad credentials are limited
+// too. Its buckets are kept apart from the ones of RateLimit, even when both share a store and are keyed by IP.
+func RateLimitByIP(limiter *ratelimit.Limiter, proxies ratelimit.TrustedProxies) func(http.Handler) http.Handler {
+	return rateLimit(limiter, func(r *http.Request) string { return "pre-auth:" + ipKey(r, proxies) })
+}
+
+func rateLimit(limiter *ratelimit.Limiter, key func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := limiter.Take(r.Context(), ratelimit.ClassOf(r.Method), key(r))
+			if err != nil {
+				log.WithError(err).Warn("Rate limiting is unavailable, letting the request through")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if result.Limit > 0 {
+				w.Header().Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+				w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+				w.Header().Set(RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.ResetAfter.Seconds())))
+			}
+			if !result.Allowed {
+				w.Header().Set(RetryAfterHeader, strconv.Itoa(ceilSeconds(result.RetryAfter.Seconds())))
+				sendError(w, httperrors.NewHTTPError(support.ErrRateLimitExceeded, http.StatusTooManyRequests))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey identifies the bucket of the caller: its user, or its API key or token subject,
+// or its IP address when the limiter is keyed by IP or the caller is anonymous
+func rateLimitKey(r *http.Request, keyBy ratelimit.KeyBy, proxies ratelimit.TrustedProxies) string {
+	if keyBy == ratelimit.KeyByCaller {
+		if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+			if principal.UserID != nil {
+				return "user:" + principal.UserID.String()
+			}
+			if principal.Subject != "" {
+				return "subject:" + principal.Subject
+			}
+		}
+	}
+
+	return ipKey(r, proxies)
+}
+
+func ipKey(r *http.Request, proxies ratelimit.TrustedProxies) string {
+	return "ip:" + clientIP(r, proxies)
+}
+
+// clientIP is the IP address of the client of the request: the remote address of the connection or, when the
+// connection comes from a trusted proxy, the last address of the X-Forwarded-For header that is not a trusted proxy.
+// The addresses before it are not trusted, as the client can forge them.
+func clientIP(r *http.Request, proxies ratelimit.TrustedProxies) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !proxies.Contains(addr) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values(ForwardedForHeader), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		host = hop.Unmap().String()
+		if !proxies.Contains(hop) {
+			break
+		}
+	}
+	return host
+}
+
+func ceilSeconds(seconds float64) int {
+	s := int(seconds)
+	if float64(s) < seconds {
+		s++
+	}
+	return s
+}
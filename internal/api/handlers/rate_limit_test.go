@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/ratelimit"
+	"traive-engineering-challenge/internal/support"
+)
+
+type stubRateLimitStore struct {
+	keys   []string
+	result ratelimit.Result
+	err    error
+}
+
+func (s *stubRateLimitStore) Take(_ context.Context, key string, _ ratelimit.Limit) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	return s.result, s.err
+}
+
+func TestRateLimit(t *testing.T) {
+	userID := uuid.New()
+	limit := ratelimit.PerMinute(60, 10)
+
+	tests := []struct {
+		name           string
+		method         string
+		keyBy          ratelimit.KeyBy
+		principal      *auth.Principal
+		store          *stubRateLimitStore
+		wantStatusCode int
+		wantKey        string
+		wantHeaders    map[string]string
+	}{
+		{
+			name:      "it lets the request through and reports the remaining tokens",
+			method:    http.MethodGet,
+			keyBy:     ratelimit.KeyByCaller,
+			principal: &auth.Principal{Subject: "key-id", UserID: &userID},
+			store: &stubRateLimitStore{result: ratelimit.Result{
+				Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 1500 * time.Millisecond,
+			}},
+			wantStatusCode: http.StatusOK,
+			wantKey:        "read:user:" + userID.String(),
+			wantHeaders:    map[string]string{RateLimitLimitHeader: "10", RateLimitRemainingHeader: "9", RateLimitResetHeader: "2"},
+		},
+		{
+			name:      "it returns too many requests with a retry after when the bucket is empty",
+			method:    http.MethodPost,
+			keyBy:     ratelimit.KeyByCaller,
+			principal: &auth.Principal{Subject: "key-id"},
+			store: &stubRateLimitStore{result: ratelimit.Result{
+				Limit: 10, ResetAfter: 10 * time.Second, RetryAfter: 200 * time.Millisecond,
+			}},
+			wantStatusCode: http.StatusTooManyRequests,
+			wantKey:        "write:subject:key-id",
+			wantHeaders:    map[string]string{RateLimitRemainingHeader: "0", RateLimitResetHeader: "10", RetryAfterHeader: "1"},
+		},
+		{
+			name:           "it keys the bucket by IP address when configured to",
+			method:         http.MethodGet,
+			keyBy:          ratelimit.KeyByIP,
+			principal:      &auth.Principal{Subject: "key-id", UserID: &userID},
+			store:          &stubRateLimitStore{result: ratelimit.Result{Allowed: true, Limit: 10}},
+			wantStatusCode: http.StatusOK,
+			wantKey:        "read:ip:192.0.2.1",
+		},
+		{
+			name:           "it keys the bucket of anonymous callers by IP address",
+			method:         http.MethodGet,
+			keyBy:          ratelimit.KeyByCaller,
+			store:          &stubRateLimitStore{result: ratelimit.Result{Allowed: true, Limit: 10}},
+			wantStatusCode: http.StatusOK,
+			wantKey:        "read:ip:192.0.2.1",
+		},
+		{
+			name:           "it lets the request through when the store fails",
+			method:         http.MethodGet,
+			keyBy:          ratelimit.KeyByCaller,
+			store:          &stubRateLimitStore{err: errors.New("store unavailable")},
+			wantStatusCode: http.StatusOK,
+			wantKey:        "read:ip:192.0.2.1",
+			wantHeaders:    map[string]string{RateLimitLimitHeader: ""},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := ratelimit.NewLimiter(tc.store, limit, limit, tc.keyBy)
+			handler := RateLimit(limiter, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			req := httptest.NewRequest(tc.method, Endpoint, nil)
+			if tc.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), *tc.principal))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatusCode, rec.Code)
+			require.Equal(t, []string{tc.wantKey}, tc.store.keys)
+			for header, value := range tc.wantHeaders {
+				require.Equal(t, value, rec.Header().Get(header), header)
+			}
+			if tc.wantStatusCode == http.StatusTooManyRequests {
+				require.Contains(t, rec.Body.String(), support.ErrRateLimitExceeded)
+			}
+		})
+	}
+}
+
+func TestRateLimit_NilLimiter(t *testing.T) {
+	handler := RateLimit(nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Endpoint, nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get(RateLimitLimitHeader))
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	userID := uuid.New()
+	limit := ratelimit.PerMinute(60, 10)
+
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		store          *stubRateLimitStore
+		wantStatusCode int
+	}{
+		{
+			name:           "it keys the bucket of anonymous requests by IP address",
+			store:          &stubRateLimitStore{result: ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9}},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "it keys the bucket by IP address whoever the caller",
+			principal:      &auth.Principal{Subject: "key-id", UserID: &userID},
+			store:          &stubRateLimitStore{result: ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9}},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "it returns too many requests before the credentials are checked",
+			store:          &stubRateLimitStore{result: ratelimit.Result{Limit: 10, RetryAfter: time.Second}},
+			wantStatusCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := ratelimit.NewLimiter(tc.store, limit, limit, ratelimit.KeyByIP)
+			reached := false
+			handler := RateLimitByIP(limiter, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { reached = true }))
+
+			req := httptest.NewRequest(http.MethodGet, Endpoint, nil)
+			if tc.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), *tc.principal))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatusCode, rec.Code)
+			require.Equal(t, tc.wantStatusCode == http.StatusOK, reached)
+			require.Equal(t, []string{"read:pre-auth:ip:192.0.2.1"}, tc.store.keys)
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ratelimit.ParseTrustedProxies([]string{"10.0.0.0/8", "2001:db8::1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{
+			name:       "it takes the remote address of a direct request",
+			remoteAddr: "192.0.2.1:1234",
+			want:       "192.0.2.1",
+		},
+		{
+			name:         "it ignores the forwarded address of a request that is not from a trusted proxy",
+			remoteAddr:   "192.0.2.1:1234",
+			forwardedFor: []string{"198.51.100.7"},
+			want:         "192.0.2.1",
+		},
+		{
+			name:         "it takes the forwarded address of a request from a trusted proxy",
+			remoteAddr:   "10.0.0.5:1234",
+			forwardedFor: []string{"198.51.100.7"},
+			want:         "198.51.100.7",
+		},
+		{
+			name:         "it skips the trusted proxies and ignores the addresses forged by the client",
+			remoteAddr:   "[2001:db8::1]:1234",
+			forwardedFor: []string{"203.0.113.9, 198.51.100.7", "10.1.2.3"},
+			want:         "198.51.100.7",
+		},
+		{
+			name:         "it stops at an invalid forwarded address",
+			remoteAddr:   "10.0.0.5:1234",
+			forwardedFor: []string{"198.51.100.7, unknown"},
+			want:         "10.0.0.5",
+		},
+		{
+			name:       "it takes the remote address of a trusted proxy that does not forward the client",
+			remoteAddr: "10.0.0.5:1234",
+			want:       "10.0.0.5",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, Endpoint, nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwardedFor {
+				req.Header.Add(ForwardedForHeader, value)
+			}
+
+			require.Equal(t, tc.want, clientIP(req, proxies))
+		})
+	}
+}
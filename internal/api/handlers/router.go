@@ -6,18 +6,119 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"net/http"
 	"traive-engineering-challenge/internal/api"
+	"traive-engineering-challenge/internal/domain"
 )
 
 func NewRouter(app api.Application) http.Handler {
 	r := chi.NewRouter()
+	r.Use(Metrics())
 
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	))
 
-	// Use toHTTPHandlerFunc directly without the otelhttp prefix
-	r.Post("/v1/transactions", toHTTPHandlerFunc(otelhttp.NewHandler(CreateTransaction(app.TransactionService), "CreateTransaction")))
-	r.Get("/v1/transactions", toHTTPHandlerFunc(otelhttp.NewHandler(ListTransactions(app.TransactionService), "ListTransactions")))
+	// Every API route is rate limited by IP address before the credentials are checked, so that requests with bad
+	// credentials are throttled too, then requires an API key or a bearer token and is rate limited by caller; each
+	// group then requires the scope of its routes
+	r.Group(func(r chi.Router) {
+		r.Use(RateLimitByIP(app.IPRateLimiter, app.TrustedProxies))
+		r.Use(Authenticate(app.APIKeyService, app.TokenVerifier))
+		r.Use(ResolveTenant)
+		r.Use(RateLimit(app.RateLimiter, app.TrustedProxies))
+
+		r.Group(func(r chi.Router) {
+			r.Use(RequireScope(domain.ScopeTransactionsRead))
+			// Use toHTTPHandlerFunc directly without the otelhttp prefix
+			r.Get("/v1/transactions", toHTTPHandlerFunc(otelhttp.NewHandler(ListTransactions(app.TransactionService, app.SavedSearchService), "ListTransactions")))
+			r.Get("/v1/transactions/stats", toHTTPHandlerFunc(otelhttp.NewHandler(GetTransactionStats(app.TransactionService), "GetTransactionStats")))
+			r.Get("/v1/transactions/{transactionId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetTransaction(app.TransactionService), "GetTransaction")))
+			r.Get("/v1/categories", toHTTPHandlerFunc(otelhttp.NewHandler(ListCategories(app.CategoryService), "ListCategories")))
+			r.Get("/v1/categories/{categoryId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetCategory(app.CategoryService), "GetCategory")))
+			r.With(RequireUserAccess(UserIDParam)).Get("/v1/users/{userId}/limits", toHTTPHandlerFunc(otelhttp.NewHandler(GetUserLimits(app.LimitService), "GetUserLimits")))
+			r.With(RequireUserAccess(UserIDParam)).Get("/v1/users/{userId}/statements/{period}", toHTTPHandlerFunc(otelhttp.NewHandler(GetStatement(app.StatementService), "GetStatement")))
+			r.With(RequireUserAccess(UserIDParam)).Get("/v1/users/{userId}/balance", toHTTPHandlerFunc(otelhttp.NewHandler(GetUserBalance(app.HoldService), "GetUserBalance")))
+
+			r.Get("/v1/saved-searches", toHTTPHandlerFunc(otelhttp.NewHandler(ListSavedSearches(app.SavedSearchService), "ListSavedSearches")))
+			r.Get("/v1/saved-searches/{savedSearchId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetSavedSearch(app.SavedSearchService), "GetSavedSearch")))
+
+			r.Get("/v1/schedules", toHTTPHandlerFunc(otelhttp.NewHandler(ListSchedules(app.ScheduleService), "ListSchedules")))
+			r.Get("/v1/schedules/{scheduleId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetSchedule(app.ScheduleService), "GetSchedule")))
+			r.Get("/v1/schedules/{scheduleId}/occurrences", toHTTPHandlerFunc(otelhttp.NewHandler(PreviewSchedule(app.ScheduleService), "PreviewSchedule")))
+
+			r.Get("/v1/holds", toHTTPHandlerFunc(otelhttp.NewHandler(ListHolds(app.HoldService), "ListHolds")))
+			r.Get("/v1/holds/{holdId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetHold(app.HoldService), "GetHold")))
+
+			r.Get("/v1/fx/rates", toHTTPHandlerFunc(otelhttp.NewHandler(GetFXRates(app.FXService), "GetFXRates")))
+			r.Get("/v1/fx/conversions", toHTTPHandlerFunc(otelhttp.NewHandler(ListFXConversions(app.FXService), "ListFXConversions")))
+			r.Get("/v1/fx/conversions/{conversionId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetFXConversion(app.FXService), "GetFXConversion")))
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(RequireScope(domain.ScopeTransactionsWrite))
+			r.Post("/v1/transactions", toHTTPHandlerFunc(otelhttp.NewHandler(CreateTransaction(app.TransactionService), "CreateTransaction")))
+			r.Put("/v1/transactions/{transactionId}/category", toHTTPHandlerFunc(otelhttp.NewHandler(CategorizeTransaction(app.TransactionService), "CategorizeTransaction")))
+
+			// Saved searches are owned by the caller that creates them and may be shared within the tenant
+			r.Post("/v1/saved-searches", toHTTPHandlerFunc(otelhttp.NewHandler(CreateSavedSearch(app.SavedSearchService), "CreateSavedSearch")))
+			r.Put("/v1/saved-searches/{savedSearchId}", toHTTPHandlerFunc(otelhttp.NewHandler(UpdateSavedSearch(app.SavedSearchService), "UpdateSavedSearch")))
+			r.Delete("/v1/saved-searches/{savedSearchId}", toHTTPHandlerFunc(otelhttp.NewHandler(DeleteSavedSearch(app.SavedSearchService), "DeleteSavedSearch")))
+
+			// Schedules create their transactions from the scheduler, as they fall due
+			r.Post("/v1/schedules", toHTTPHandlerFunc(otelhttp.NewHandler(CreateSchedule(app.ScheduleService), "CreateSchedule")))
+			r.Post("/v1/schedules/{scheduleId}/pause", toHTTPHandlerFunc(otelhttp.NewHandler(PauseSchedule(app.ScheduleService), "PauseSchedule")))
+			r.Post("/v1/schedules/{scheduleId}/resume", toHTTPHandlerFunc(otelhttp.NewHandler(ResumeSchedule(app.ScheduleService), "ResumeSchedule")))
+			r.Post("/v1/schedules/{scheduleId}/cancel", toHTTPHandlerFunc(otelhttp.NewHandler(CancelSchedule(app.ScheduleService), "CancelSchedule")))
+
+			// Holds reserve the available balance until they are captured by a debit, released or expire
+			r.Post("/v1/holds", toHTTPHandlerFunc(otelhttp.NewHandler(CreateHold(app.HoldService), "CreateHold")))
+			r.Post("/v1/holds/{holdId}/capture", toHTTPHandlerFunc(otelhttp.NewHandler(CaptureHold(app.HoldService), "CaptureHold")))
+			r.Post("/v1/holds/{holdId}/release", toHTTPHandlerFunc(otelhttp.NewHandler(ReleaseHold(app.HoldService), "ReleaseHold")))
+
+			// Quotes lock the rate in effect for a short while, during which they can be converted once
+			r.Post("/v1/fx/quotes", toHTTPHandlerFunc(otelhttp.NewHandler(CreateFXQuote(app.FXService), "CreateFXQuote")))
+			r.Post("/v1/fx/conversions", toHTTPHandlerFunc(otelhttp.NewHandler(ConvertFX(app.FXService), "ConvertFX")))
+		})
+
+		r.Route("/v1/admin", func(r chi.Router) {
+			r.Use(RequireScope(domain.ScopeAdmin))
+
+			r.Route("/limits", func(r chi.Router) {
+				r.Post("/", toHTTPHandlerFunc(otelhttp.NewHandler(CreateLimit(app.LimitService), "CreateLimit")))
+				r.Get("/", toHTTPHandlerFunc(otelhttp.NewHandler(ListLimits(app.LimitService), "ListLimits")))
+				r.Get("/{limitId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetLimit(app.LimitService), "GetLimit")))
+				r.Put("/{limitId}", toHTTPHandlerFunc(otelhttp.NewHandler(UpdateLimit(app.LimitService), "UpdateLimit")))
+				r.Delete("/{limitId}", toHTTPHandlerFunc(otelhttp.NewHandler(DeleteLimit(app.LimitService), "DeleteLimit")))
+			})
+
+			// Categories are read by every caller through /v1/categories and managed by admins
+			r.Route("/categories", func(r chi.Router) {
+				r.Post("/", toHTTPHandlerFunc(otelhttp.NewHandler(CreateCategory(app.CategoryService), "CreateCategory")))
+				r.Put("/{categoryId}", toHTTPHandlerFunc(otelhttp.NewHandler(UpdateCategory(app.CategoryService), "UpdateCategory")))
+				r.Delete("/{categoryId}", toHTTPHandlerFunc(otelhttp.NewHandler(DeleteCategory(app.CategoryService), "DeleteCategory")))
+			})
+
+			r.Route("/category-rules", func(r chi.Router) {
+				r.Post("/", toHTTPHandlerFunc(otelhttp.NewHandler(CreateCategoryRule(app.CategoryService), "CreateCategoryRule")))
+				r.Get("/", toHTTPHandlerFunc(otelhttp.NewHandler(ListCategoryRules(app.CategoryService), "ListCategoryRules")))
+				r.Get("/{ruleId}", toHTTPHandlerFunc(otelhttp.NewHandler(GetCategoryRule(app.CategoryService), "GetCategoryRule")))
+				r.Put("/{ruleId}", toHTTPHandlerFunc(otelhttp.NewHandler(UpdateCategoryRule(app.CategoryService), "UpdateCategoryRule")))
+				r.Delete("/{ruleId}", toHTTPHandlerFunc(otelhttp.NewHandler(DeleteCategoryRule(app.CategoryService), "DeleteCategoryRule")))
+			})
+
+			// Exchange rates are loaded as snapshots, the latest effective one being used by the quotes
+			r.Route("/fx/rates", func(r chi.Router) {
+				r.Post("/", toHTTPHandlerFunc(otelhttp.NewHandler(LoadFXRates(app.FXService), "LoadFXRates")))
+				r.Get("/", toHTTPHandlerFunc(otelhttp.NewHandler(ListFXRateSnapshots(app.FXService), "ListFXRateSnapshots")))
+			})
+
+			r.Route("/api-keys", func(r chi.Router) {
+				r.Post("/", toHTTPHandlerFunc(otelhttp.NewHandler(CreateAPIKey(app.APIKeyService), "CreateAPIKey")))
+				r.Get("/", toHTTPHandlerFunc(otelhttp.NewHandler(ListAPIKeys(app.APIKeyService), "ListAPIKeys")))
+				r.Post("/{keyId}/rotate", toHTTPHandlerFunc(otelhttp.NewHandler(RotateAPIKey(app.APIKeyService), "RotateAPIKey")))
+				r.Delete("/{keyId}", toHTTPHandlerFunc(otelhttp.NewHandler(RevokeAPIKey(app.APIKeyService), "RevokeAPIKey")))
+			})
+		})
+	})
 	return r
 }
 
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"net/http"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const SavedSearchIDParam = "savedSearchId"
+
+// CreateSavedSearch godoc
+// @Summary Create a saved search
+// @Description Saves a named combination of the filters and the sort of the transaction list, owned by the caller and applied with GET /v1/transactions?view={name}
+// @tags saved searches
+// @Accept json
+// @Produce json
+// @Param search body domain.SavedSearch true "Saved search definition"
+// @Success 201 {object} domain.SavedSearch
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/saved-searches [post]
+func CreateSavedSearch(app service.SavedSearchService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateSavedSearch")
+		ctx, span := tr.Start(r.Context(), "Handling CreateSavedSearch request")
+		defer span.End()
+
+		var search domain.SavedSearch
+		if err := json.NewDecoder(r.Body).Decode(&search); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		created, err := app.CreateSavedSearch(ctx, search)
+		if err != nil {
+			sendSavedSearchError(w, err, support.ErrFailedToCreateSavedSearch)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, created)
+	}
+}
+
+// ListSavedSearches godoc
+// @Summary List saved searches
+// @Description Retrieves the saved searches of the caller and the ones shared with the tenant
+// @tags saved searches
+// @Produce json
+// @Success 200 {array} domain.SavedSearch
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/saved-searches [get]
+func ListSavedSearches(app service.SavedSearchService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListSavedSearches")
+		ctx, span := tr.Start(r.Context(), "Handling ListSavedSearches request")
+		defer span.End()
+
+		searches, err := app.ListSavedSearches(ctx)
+		if err != nil {
+			sendSavedSearchError(w, err, support.ErrFailedToRetrieveSavedSearches)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, searches)
+	}
+}
+
+// GetSavedSearch godoc
+// @Summary Get a saved search
+// @tags saved searches
+// @Produce json
+// @Param savedSearchId path string true "Saved search ID"
+// @Success 200 {object} domain.SavedSearch
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/saved-searches/{savedSearchId} [get]
+func GetSavedSearch(app service.SavedSearchService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetSavedSearch")
+		ctx, span := tr.Start(r.Context(), "Handling GetSavedSearch request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, SavedSearchIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidSavedSearchID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		search, err := app.GetSavedSearch(ctx, id)
+		if err != nil {
+			sendSavedSearchError(w, err, support.ErrFailedToRetrieveSavedSearches)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, search)
+	}
+}
+
+// UpdateSavedSearch godoc
+// @Summary Update a saved search
+// @Description Replaces the definition of a saved search; only its owner and admins may change it
+// @tags saved searches
+// @Accept json
+// @Produce json
+// @Param savedSearchId path string true "Saved search ID"
+// @Param search body domain.SavedSearch true "Saved search definition"
+// @Success 200 {object} domain.SavedSearch
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/saved-searches/{savedSearchId} [put]
+func UpdateSavedSearch(app service.SavedSearchService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("UpdateSavedSearch")
+		ctx, span := tr.Start(r.Context(), "Handling UpdateSavedSearch request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, SavedSearchIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidSavedSearchID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		var search domain.SavedSearch
+		if err := json.NewDecoder(r.Body).Decode(&search); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+		search.ID = id
+
+		updated, err := app.UpdateSavedSearch(ctx, search)
+		if err != nil {
+			sendSavedSearchError(w, err, support.ErrFailedToUpdateSavedSearch)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, updated)
+	}
+}
+
+// DeleteSavedSearch godoc
+// @Summary Delete a saved search
+// @Description Deletes a saved search; only its owner and admins may delete it
+// @tags saved searches
+// @Param savedSearchId path string true "Saved search ID"
+// @Success 204
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/saved-searches/{savedSearchId} [delete]
+func DeleteSavedSearch(app service.SavedSearchService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("DeleteSavedSearch")
+		ctx, span := tr.Start(r.Context(), "Handling DeleteSavedSearch request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, SavedSearchIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidSavedSearchID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		if err := app.DeleteSavedSearch(ctx, id); err != nil {
+			sendSavedSearchError(w, err, support.ErrFailedToDeleteSavedSearch)
+			span.RecordError(err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// sendSavedSearchError maps validation, ownership, not found and duplicate name errors to client errors, anything
+// else to the given server error
+func sendSavedSearchError(w http.ResponseWriter, err error, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+	var uniqueErr repository.UniqueIndexError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidSavedSearch, http.StatusBadRequest, validationErr.Problems))
+	case errors.Is(err, domain.ErrNotSavedSearchOwner):
+		sendError(w, httperrors.NewHTTPError(support.ErrNotSavedSearchOwner, http.StatusForbidden))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrSavedSearchNotFound, http.StatusNotFound))
+	case errors.As(err, &uniqueErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrSavedSearchNameTaken, http.StatusConflict))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
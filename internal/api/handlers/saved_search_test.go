@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestCreateSavedSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockSavedSearchService(ctrl)
+
+	search := domain.SavedSearch{Name: "big-debits", Filter: "amount > 1000", Sort: "-amount", Shared: true}
+	created := search
+	created.ID = uuid.New()
+	created.Owner = "analyst"
+
+	tests := []struct {
+		name           string
+		body           interface{}
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns created with the new saved search",
+			body: search,
+			prepareService: func() {
+				mockService.EXPECT().CreateSavedSearch(gomock.Any(), search).Return(&created, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   created,
+		},
+		{
+			name: "it returns bad request with every validation problem",
+			body: search,
+			prepareService: func() {
+				mockService.EXPECT().CreateSavedSearch(gomock.Any(), search).Return(nil, domain.NewValidationError([]string{"a", "b"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidSavedSearch, http.StatusBadRequest, []string{"a", "b"}),
+		},
+		{
+			name: "it returns conflict when the name is taken",
+			body: search,
+			prepareService: func() {
+				mockService.EXPECT().CreateSavedSearch(gomock.Any(), search).Return(nil, repository.NewUniqueIndexError("Key (tenant_id, name)=(default, big-debits) already exists."))
+			},
+			wantStatusCode: http.StatusConflict,
+			wantResponse:   httperrors.NewHTTPError(support.ErrSavedSearchNameTaken, http.StatusConflict),
+		},
+		{
+			name: "it returns internal server error",
+			body: search,
+			prepareService: func() {
+				mockService.EXPECT().CreateSavedSearch(gomock.Any(), search).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToCreateSavedSearch, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			CreateSavedSearch(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/saved-searches", bytes.NewBuffer(body)))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestUpdateSavedSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockSavedSearchService(ctrl)
+	searchID := uuid.New()
+	search := domain.SavedSearch{ID: searchID, Name: "big-debits", Filter: "amount > 5000"}
+
+	tests := []struct {
+		name           string
+		searchID       string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:     "it returns the updated saved search",
+			searchID: searchID.String(),
+			prepareService: func() {
+				mockService.EXPECT().UpdateSavedSearch(gomock.Any(), search).Return(&search, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   search,
+		},
+		{
+			name:           "it returns bad request for an invalid saved search ID",
+			searchID:       "invalid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidSavedSearchID, http.StatusBadRequest),
+		},
+		{
+			name:     "it returns forbidden to callers other than the owner",
+			searchID: searchID.String(),
+			prepareService: func() {
+				mockService.EXPECT().UpdateSavedSearch(gomock.Any(), search).Return(nil, domain.ErrNotSavedSearchOwner)
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantResponse:   httperrors.NewHTTPError(support.ErrNotSavedSearchOwner, http.StatusForbidden),
+		},
+		{
+			name:     "it returns not found",
+			searchID: searchID.String(),
+			prepareService: func() {
+				mockService.EXPECT().UpdateSavedSearch(gomock.Any(), search).Return(nil, repository.NewNotFoundError("saved search not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrSavedSearchNotFound, http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(domain.SavedSearch{Name: search.Name, Filter: search.Filter})
+			require.NoError(t, err)
+
+			req := withURLParams(httptest.NewRequest(http.MethodPut, "/v1/saved-searches/"+tc.searchID, bytes.NewBuffer(body)), map[string]string{SavedSearchIDParam: tc.searchID})
+			rr := httptest.NewRecorder()
+			UpdateSavedSearch(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestDeleteSavedSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockSavedSearchService(ctrl)
+	searchID := uuid.New()
+
+	mockService.EXPECT().DeleteSavedSearch(gomock.Any(), searchID).Return(nil)
+
+	req := withURLParams(httptest.NewRequest(http.MethodDelete, "/v1/saved-searches/"+searchID.String(), nil), map[string]string{SavedSearchIDParam: searchID.String()})
+	rr := httptest.NewRecorder()
+	DeleteSavedSearch(mockService).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"net/http"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const (
+	ScheduleIDParam = "scheduleId"
+	Count           = "count"
+	// DefaultOccurrencesCount is how many occurrences are previewed when the count is not set
+	DefaultOccurrencesCount = 10
+)
+
+// CreateSchedule godoc
+// @Summary Create a recurring schedule
+// @Description Schedules a transaction recurring on a cron expression or an RFC 5545 RRULE, evaluated in UTC from start_at (now by default, at most 5 minutes in the past). Each occurrence is created by the scheduler when it falls due, screened and checked against the limits like any other transaction; a rejected occurrence is skipped and its reason recorded in last_error.
+// @tags schedules
+// @Accept json
+// @Produce json
+// @Param schedule body domain.Schedule true "Schedule definition"
+// @Success 201 {object} domain.Schedule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/schedules [post]
+func CreateSchedule(app service.ScheduleService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CreateSchedule")
+		ctx, span := tr.Start(r.Context(), "Handling CreateSchedule request")
+		defer span.End()
+
+		var schedule domain.Schedule
+		if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		created, err := app.CreateSchedule(ctx, schedule)
+		if err != nil {
+			sendScheduleError(w, err, support.ErrFailedToCreateSchedule)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusCreated, created)
+	}
+}
+
+// ListSchedules godoc
+// @Summary List recurring schedules
+// @Description Retrieves the schedules of the tenant, only their own ones for the callers restricted to a user
+// @tags schedules
+// @Produce json
+// @Success 200 {array} domain.Schedule
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/schedules [get]
+func ListSchedules(app service.ScheduleService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("ListSchedules")
+		ctx, span := tr.Start(r.Context(), "Handling ListSchedules request")
+		defer span.End()
+
+		schedules, err := app.ListSchedules(ctx)
+		if err != nil {
+			sendScheduleError(w, err, support.ErrFailedToRetrieveSchedules)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, schedules)
+	}
+}
+
+// GetSchedule godoc
+// @Summary Get a recurring schedule
+// @tags schedules
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} domain.Schedule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/schedules/{scheduleId} [get]
+func GetSchedule(app service.ScheduleService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetSchedule")
+		ctx, span := tr.Start(r.Context(), "Handling GetSchedule request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, ScheduleIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidScheduleID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		schedule, err := app.GetSchedule(ctx, id)
+		if err != nil {
+			sendScheduleError(w, err, support.ErrFailedToRetrieveSchedules)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, schedule)
+	}
+}
+
+// PreviewSchedule godoc
+// @Summary Preview the next occurrences of a recurring schedule
+// @Description Lists the next occurrences the scheduler will create, from the next run of an active schedule or from now for a paused one; the list is empty once the schedule is cancelled or completed
+// @tags schedules
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Param count query int false "Number of occurrences, at most 100" default(10)
+// @Success 200 {array} string
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/schedules/{scheduleId}/occurrences [get]
+func PreviewSchedule(app service.ScheduleService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("PreviewSchedule")
+		ctx, span := tr.Start(r.Context(), "Handling PreviewSchedule request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, ScheduleIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidScheduleID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		occurrences, err := app.PreviewSchedule(ctx, id, getQueryParamAsInt(r, Count, DefaultOccurrencesCount))
+		if err != nil {
+			sendScheduleError(w, err, support.ErrFailedToRetrieveSchedules)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, occurrences)
+	}
+}
+
+// PauseSchedule godoc
+// @Summary Pause a recurring schedule
+// @Description Stops creating the occurrences of an active schedule until it is resumed
+// @tags schedules
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} domain.Schedule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/schedules/{scheduleId}/pause [post]
+func PauseSchedule(app service.ScheduleService) http.HandlerFunc {
+	return updateScheduleStatus("PauseSchedule", app, service.ScheduleService.PauseSchedule)
+}
+
+// ResumeSchedule godoc
+// @Summary Resume a paused recurring schedule
+// @Description Reactivates a paused schedule from its first occurrence after now, the occurrences of the pause being skipped. A schedule whose recurrence ended meanwhile is completed instead.
+// @tags schedules
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} domain.Schedule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/schedules/{scheduleId}/resume [post]
+func ResumeSchedule(app service.ScheduleService) http.HandlerFunc {
+	return updateScheduleStatus("ResumeSchedule", app, service.ScheduleService.ResumeSchedule)
+}
+
+// CancelSchedule godoc
+// @Summary Cancel a recurring schedule
+// @Description Ends an active or paused schedule for good; the transactions it already created are kept
+// @tags schedules
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} domain.Schedule
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 409 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/schedules/{scheduleId}/cancel [post]
+func CancelSchedule(app service.ScheduleService) http.HandlerFunc {
+	return updateScheduleStatus("CancelSchedule", app, service.ScheduleService.CancelSchedule)
+}
+
+// updateScheduleStatus handles the requests moving the schedule of the path to another status through update
+func updateScheduleStatus(name string, app service.ScheduleService,
+	update func(app service.ScheduleService, ctx context.Context, id uuid.UUID) (*domain.Schedule, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer(name)
+		ctx, span := tr.Start(r.Context(), "Handling "+name+" request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, ScheduleIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidScheduleID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		schedule, err := update(app, ctx, id)
+		if err != nil {
+			sendScheduleError(w, err, support.ErrFailedToUpdateSchedule)
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, schedule)
+	}
+}
+
+// sendScheduleError maps validation, not found and status transition errors to client errors, anything else to the
+// given server error
+func sendScheduleError(w http.ResponseWriter, err error, message string) {
+	var validationErr domain.ValidationError
+	var notFoundErr repository.NotFoundError
+
+	switch {
+	case errors.As(err, &validationErr):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidSchedule, http.StatusBadRequest, validationErr.Problems))
+	case errors.As(err, &notFoundErr):
+		sendError(w, httperrors.NewHTTPError(support.ErrScheduleNotFound, http.StatusNotFound))
+	case errors.Is(err, domain.ErrScheduleTransition):
+		sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrScheduleTransition, http.StatusConflict, []string{err.Error()}))
+	default:
+		sendError(w, httperrors.NewHTTPError(message, http.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestCreateSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockScheduleService(ctrl)
+
+	schedule := domain.Schedule{
+		UserID:          uuid.New(),
+		Origin:          domain.OriginDesktopWeb,
+		TransactionType: domain.TransactionTypeDebit,
+		Amount:          999,
+		Description:     "Gym",
+		Cron:            "0 9 1 * *",
+	}
+	nextRunAt := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	created := schedule
+	created.ID = uuid.New()
+	created.Status = domain.ScheduleStatusActive
+	created.NextRunAt = &nextRunAt
+
+	tests := []struct {
+		name           string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name: "it returns created with the new schedule",
+			prepareService: func() {
+				mockService.EXPECT().CreateSchedule(gomock.Any(), schedule).Return(&created, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   created,
+		},
+		{
+			name: "it returns bad request with every validation problem",
+			prepareService: func() {
+				mockService.EXPECT().CreateSchedule(gomock.Any(), schedule).Return(nil, domain.NewValidationError([]string{"a", "b"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidSchedule, http.StatusBadRequest, []string{"a", "b"}),
+		},
+		{
+			name: "it returns internal server error",
+			prepareService: func() {
+				mockService.EXPECT().CreateSchedule(gomock.Any(), schedule).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToCreateSchedule, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(schedule)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			CreateSchedule(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/schedules", bytes.NewBuffer(body)))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestResumeSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockScheduleService(ctrl)
+	scheduleID := uuid.New()
+	resumed := domain.Schedule{ID: scheduleID, Status: domain.ScheduleStatusActive, Cron: "0 9 * * *"}
+	transitionErr := fmt.Errorf("%w: the schedule is CANCELLED", domain.ErrScheduleTransition)
+
+	tests := []struct {
+		name           string
+		scheduleID     string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:       "it returns the resumed schedule",
+			scheduleID: scheduleID.String(),
+			prepareService: func() {
+				mockService.EXPECT().ResumeSchedule(gomock.Any(), scheduleID).Return(&resumed, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   resumed,
+		},
+		{
+			name:           "it returns bad request for an invalid schedule ID",
+			scheduleID:     "invalid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidScheduleID, http.StatusBadRequest),
+		},
+		{
+			name:       "it returns not found",
+			scheduleID: scheduleID.String(),
+			prepareService: func() {
+				mockService.EXPECT().ResumeSchedule(gomock.Any(), scheduleID).Return(nil, repository.NewNotFoundError("schedule not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrScheduleNotFound, http.StatusNotFound),
+		},
+		{
+			name:       "it returns conflict when the schedule is not paused",
+			scheduleID: scheduleID.String(),
+			prepareService: func() {
+				mockService.EXPECT().ResumeSchedule(gomock.Any(), scheduleID).Return(nil, transitionErr)
+			},
+			wantStatusCode: http.StatusConflict,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrScheduleTransition, http.StatusConflict, []string{transitionErr.Error()}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(httptest.NewRequest(http.MethodPost, "/v1/schedules/"+tc.scheduleID+"/resume", nil), map[string]string{ScheduleIDParam: tc.scheduleID})
+			rr := httptest.NewRecorder()
+			ResumeSchedule(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockScheduleService(ctrl)
+	scheduleID := uuid.New()
+	occurrences := []time.Time{
+		time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:  "it previews the default count of occurrences",
+			query: "",
+			prepareService: func() {
+				mockService.EXPECT().PreviewSchedule(gomock.Any(), scheduleID, DefaultOccurrencesCount).Return(occurrences, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   occurrences,
+		},
+		{
+			name:  "it returns bad request for a count out of bounds",
+			query: "?count=500",
+			prepareService: func() {
+				mockService.EXPECT().PreviewSchedule(gomock.Any(), scheduleID, 500).Return(nil, domain.NewValidationError([]string{"count must be between 1 and 100"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidSchedule, http.StatusBadRequest, []string{"count must be between 1 and 100"}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(httptest.NewRequest(http.MethodGet, "/v1/schedules/"+scheduleID.String()+"/occurrences"+tc.query, nil), map[string]string{ScheduleIDParam: scheduleID.String()})
+			rr := httptest.NewRecorder()
+			PreviewSchedule(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"mime"
+	"net/http"
+	"strings"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/api/render"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/support"
+)
+
+const (
+	Accept        = "Accept"
+	UserIDParam   = "userId"
+	PeriodParam   = "period"
+	CurrencyParam = "currency"
+)
+
+// GetStatement godoc
+// @Summary Get a monthly statement
+// @Description Retrieves the statement of a user in one currency for a month: opening balance, every credit and debit in the currency with a running balance, totals and closing balance
+// @tags statements
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param userId path string true "User ID"
+// @Param period path string true "Statement period (yyyy-mm)"
+// @Param currency query string false "ISO 4217 code of the currency of the statement" default(USD)
+// @Success 200 {object} domain.Statement
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 406 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/users/{userId}/statements/{period} [get]
+func GetStatement(app service.StatementService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetStatement")
+		ctx, span := tr.Start(r.Context(), "Handling GetStatement request")
+		defer span.End()
+
+		userID, err := uuid.Parse(chi.URLParam(r, UserIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidUserID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		currency, ok := getQueryParamAsCurrency(r)
+		if !ok {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidCurrency, http.StatusBadRequest))
+			return
+		}
+
+		contentType, ok := negotiateStatementContentType(r.Header.Get(Accept))
+		if !ok {
+			sendError(w, httperrors.NewHTTPError(support.ErrUnsupportedStatementFormat, http.StatusNotAcceptable))
+			return
+		}
+
+		statement, err := app.GetMonthlyStatement(ctx, userID, currency, chi.URLParam(r, PeriodParam))
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidStatementPeriod) {
+				sendError(w, httperrors.NewHTTPError(support.ErrInvalidStatementPeriod, http.StatusBadRequest))
+			} else {
+				sendError(w, httperrors.NewHTTPError(support.ErrFailedToRetrieveStatement, http.StatusInternalServerError))
+			}
+			span.RecordError(err)
+			return
+		}
+
+		w.Header().Set(ContentType, contentType)
+		w.WriteHeader(http.StatusOK)
+
+		switch contentType {
+		case render.TextCSV:
+			err = render.StatementCSV(w, statement)
+		case render.ApplicationPDF:
+			err = render.StatementPDF(w, statement)
+		default:
+			err = json.NewEncoder(w).Encode(statement)
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+	}
+}
+
+// negotiateStatementContentType picks the first supported media type of the Accept header, defaulting to JSON
+func negotiateStatementContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return render.ApplicationJSON, true
+	}
+
+	for _, candidate := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(candidate))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case render.ApplicationJSON, "*/*", "application/*":
+			return render.ApplicationJSON, true
+		case render.TextCSV, "text/*":
+			return render.TextCSV, true
+		case render.ApplicationPDF:
+			return render.ApplicationPDF, true
+		}
+	}
+	return "", false
+}
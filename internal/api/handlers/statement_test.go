@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/service/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestGetStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockStatementService(ctrl)
+
+	userID := uuid.New()
+	transaction := support.ValidDomainTransaction(uuid.New(), userID, support.DesktopWeb, "", 700)
+	statement, err := domain.NewStatement(userID, domain.DefaultCurrency, "2026-01", 300, []domain.Transaction{*transaction})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		userID          string
+		period          string
+		currency        string
+		accept          string
+		prepareService  func()
+		wantStatusCode  int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:   "it returns the statement as JSON by default",
+			userID: userID.String(),
+			period: "2026-01",
+			prepareService: func() {
+				mockService.EXPECT().GetMonthlyStatement(gomock.Any(), userID, domain.DefaultCurrency, "2026-01").Return(statement, nil)
+			},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: ApplicationJSON,
+			wantBody:        `"closing_balance":1000`,
+		},
+		{
+			name:   "it returns the statement as CSV",
+			userID: userID.String(),
+			period: "2026-01",
+			accept: "text/csv",
+			prepareService: func() {
+				mockService.EXPECT().GetMonthlyStatement(gomock.Any(), userID, domain.DefaultCurrency, "2026-01").Return(statement, nil)
+			},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "text/csv",
+			wantBody:        "CLOSING BALANCE,,,1000",
+		},
+		{
+			name:   "it returns the statement as PDF",
+			userID: userID.String(),
+			period: "2026-01",
+			accept: "application/pdf",
+			prepareService: func() {
+				mockService.EXPECT().GetMonthlyStatement(gomock.Any(), userID, domain.DefaultCurrency, "2026-01").Return(statement, nil)
+			},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "application/pdf",
+			wantBody:        "%PDF-1.4",
+		},
+		{
+			name:     "it returns the statement in the requested currency",
+			userID:   userID.String(),
+			period:   "2026-01",
+			currency: "EUR",
+			prepareService: func() {
+				mockService.EXPECT().GetMonthlyStatement(gomock.Any(), userID, "EUR", "2026-01").Return(statement, nil)
+			},
+			wantStatusCode:  http.StatusOK,
+			wantContentType: ApplicationJSON,
+			wantBody:        `"closing_balance":1000`,
+		},
+		{
+			name:            "it returns bad request for an unsupported currency",
+			userID:          userID.String(),
+			period:          "2026-01",
+			currency:        "XXX",
+			prepareService:  func() {},
+			wantStatusCode:  http.StatusBadRequest,
+			wantContentType: ApplicationJSON,
+			wantBody:        support.ErrInvalidCurrency,
+		},
+		{
+			name:            "it returns not acceptable for unsupported formats",
+			userID:          userID.String(),
+			period:          "2026-01",
+			accept:          "image/png",
+			prepareService:  func() {},
+			wantStatusCode:  http.StatusNotAcceptable,
+			wantContentType: ApplicationJSON,
+			wantBody:        support.ErrUnsupportedStatementFormat,
+		},
+		{
+			name:            "it returns bad request for an invalid user ID",
+			userID:          "not-a-uuid",
+			period:          "2026-01",
+			prepareService:  func() {},
+			wantStatusCode:  http.StatusBadRequest,
+			wantContentType: ApplicationJSON,
+			wantBody:        support.ErrInvalidUserID,
+		},
+		{
+			name:   "it returns bad request for an invalid period",
+			userID: userID.String(),
+			period: "2026-1",
+			prepareService: func() {
+				mockService.EXPECT().GetMonthlyStatement(gomock.Any(), userID, domain.DefaultCurrency, "2026-1").Return(nil, domain.ErrInvalidStatementPeriod)
+			},
+			wantStatusCode:  http.StatusBadRequest,
+			wantContentType: ApplicationJSON,
+			wantBody:        support.ErrInvalidStatementPeriod,
+		},
+		{
+			name:   "it returns internal server error",
+			userID: userID.String(),
+			period: "2026-01",
+			prepareService: func() {
+				mockService.EXPECT().GetMonthlyStatement(gomock.Any(), userID, domain.DefaultCurrency, "2026-01").Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode:  http.StatusInternalServerError,
+			wantContentType: ApplicationJSON,
+			wantBody:        support.ErrFailedToRetrieveStatement,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(
+				httptest.NewRequest(http.MethodGet, "/v1/users/"+tc.userID+"/statements/"+tc.period+"?currency="+tc.currency, nil),
+				map[string]string{UserIDParam: tc.userID, PeriodParam: tc.period},
+			)
+			if tc.accept != "" {
+				req.Header.Set(Accept, tc.accept)
+			}
+
+			rr := httptest.NewRecorder()
+			GetStatement(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			require.Equal(t, tc.wantContentType, rr.Header().Get(ContentType))
+			require.Contains(t, rr.Body.String(), tc.wantBody)
+
+			if tc.wantContentType == ApplicationJSON && tc.wantStatusCode == http.StatusOK {
+				var got domain.Statement
+				require.NoError(t, json.NewDecoder(strings.NewReader(rr.Body.String())).Decode(&got))
+				require.Equal(t, statement.ClosingBalance, got.ClosingBalance)
+			}
+		})
+	}
+}
+
+// withURLParams attaches chi URL parameters to a request so handlers can be tested without a router
+func withURLParams(r *http.Request, params map[string]string) *http.Request {
+	routeContext := chi.NewRouteContext()
+	for key, value := range params {
+		routeContext.URLParams.Add(key, value)
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeContext))
+}
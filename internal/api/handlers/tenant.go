@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/support"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const TenantHeader = "X-Tenant-ID"
+
+// ResolveTenant is a middleware that stores the tenant of the request in its context. The tenant is the one the
+// authenticated principal is bound to; the service clients that are not bound to a tenant may pick one with the
+// X-Tenant-ID header, and fall back to the default tenant, while the other principals without a tenant, such as the
+// users of a token without a tenant claim, are bound to the default tenant. It responds 403 when the header names
+// another tenant than the one of the principal, and 400 when the tenant ID is malformed.
+func ResolveTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFromContext(r.Context())
+		if !ok {
+			sendError(w, httperrors.NewHTTPError(support.ErrMissingCredentials, http.StatusUnauthorized))
+			return
+		}
+
+		tenantID := principal.TenantID
+		if tenantID == "" && !principal.CanPickTenant() {
+			tenantID = tenant.Default
+		}
+		if requested := r.Header.Get(TenantHeader); requested != "" {
+			if tenantID != "" && requested != tenantID {
+				sendError(w, httperrors.NewHTTPError(support.ErrTenantMismatch, http.StatusForbidden))
+				return
+			}
+			tenantID = requested
+		}
+		if tenantID == "" {
+			tenantID = tenant.Default
+		}
+
+		if err := tenant.ValidateID(tenantID); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidTenantID, http.StatusBadRequest))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), tenantID)))
+	})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/support"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+func TestResolveTenant(t *testing.T) {
+	userID := uuid.New()
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		header         string
+		wantStatusCode int
+		wantTenant     string
+		wantMessage    string
+	}{
+		{
+			name:           "it uses the tenant the principal is bound to",
+			principal:      &auth.Principal{TenantID: "acme"},
+			wantStatusCode: http.StatusOK,
+			wantTenant:     "acme",
+		},
+		{
+			name:           "it accepts a header naming the tenant of the principal",
+			principal:      &auth.Principal{TenantID: "acme"},
+			header:         "acme",
+			wantStatusCode: http.StatusOK,
+			wantTenant:     "acme",
+		},
+		{
+			name:           "it returns forbidden when the header names another tenant",
+			principal:      &auth.Principal{TenantID: "acme"},
+			header:         "globex",
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrTenantMismatch,
+		},
+		{
+			name:           "it lets a service client that is not bound to a tenant pick one",
+			principal:      &auth.Principal{Service: true},
+			header:         "globex",
+			wantStatusCode: http.StatusOK,
+			wantTenant:     "globex",
+		},
+		{
+			name:           "it falls back to the default tenant",
+			principal:      &auth.Principal{Service: true},
+			wantStatusCode: http.StatusOK,
+			wantTenant:     tenant.Default,
+		},
+		{
+			name:           "it binds a user without a tenant to the default tenant",
+			principal:      &auth.Principal{UserID: &userID},
+			wantStatusCode: http.StatusOK,
+			wantTenant:     tenant.Default,
+		},
+		{
+			name:           "it returns forbidden when a user without a tenant names another tenant",
+			principal:      &auth.Principal{UserID: &userID},
+			header:         "globex",
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrTenantMismatch,
+		},
+		{
+			name:           "it returns forbidden when an admin token without a tenant names another tenant",
+			principal:      &auth.Principal{Roles: []string{auth.RoleAdmin}},
+			header:         "globex",
+			wantStatusCode: http.StatusForbidden,
+			wantMessage:    support.ErrTenantMismatch,
+		},
+		{
+			name:           "it returns bad request for a malformed tenant",
+			principal:      &auth.Principal{Service: true},
+			header:         "Globex Corp",
+			wantStatusCode: http.StatusBadRequest,
+			wantMessage:    support.ErrInvalidTenantID,
+		},
+		{
+			name:           "it returns unauthorized without a principal",
+			wantStatusCode: http.StatusUnauthorized,
+			wantMessage:    support.ErrMissingCredentials,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotTenant string
+			handler := ResolveTenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTenant = tenant.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, Endpoint, nil)
+			if tc.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), *tc.principal))
+			}
+			if tc.header != "" {
+				req.Header.Set(TenantHeader, tc.header)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			require.Equal(t, tc.wantTenant, gotTenant)
+			if tc.wantMessage != "" {
+				require.Contains(t, rr.Body.String(), tc.wantMessage)
+			}
+		})
+	}
+}
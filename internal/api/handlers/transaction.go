@@ -1,16 +1,22 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	_ "go.opentelemetry.io/otel"
 	_ "go.opentelemetry.io/otel/trace"
 	"net/http"
 	"strconv"
+	"strings"
 	"traive-engineering-challenge/internal/api/handlers/httperrors"
 	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
 	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/risk"
 	"traive-engineering-challenge/internal/service"
 	"traive-engineering-challenge/internal/support"
 )
@@ -22,7 +28,17 @@ const (
 	PageSizeKey     = "pageSize"
 	Origin          = "origin"
 	TransactionType = "transactionType"
+	Filter          = "filter"
+	Sort            = "sort"
+	View            = "view"
+	Tag             = "tag"
+	MetadataPrefix  = "metadata."
+	Category        = "category"
+	Search          = "q"
+	StatsBy         = "by"
 	Message         = "message"
+
+	TransactionIDParam = "transactionId"
 )
 
 // CreateTransaction godoc
@@ -34,12 +50,16 @@ const (
 // @Success 200 {array} domain.Transaction
 // @Failure 500 {object} httperrors.HTTPError
 // @Failure 400 {object} httperrors.HTTPError
+// @Failure 403 {object} httperrors.HTTPError
+// @Failure 422 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /v1/transactions [post]
 func CreateTransaction(app service.TransactionService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Initialize tracer with the operation name
 		tr := otel.Tracer("CreateTransaction")
-		_, span := tr.Start(r.Context(), "Handling CreateTransaction request")
+		ctx, span := tr.Start(r.Context(), "Handling CreateTransaction request")
 		defer span.End()
 
 		var transaction domain.Transaction
@@ -49,10 +69,25 @@ func CreateTransaction(app service.TransactionService) http.HandlerFunc {
 			return
 		}
 
-		_, err := app.CreateTransaction(r.Context(), transaction)
+		_, err := app.CreateTransaction(ctx, transaction)
 		defer span.End()
 		if err != nil {
-			sendError(w, httperrors.NewHTTPError(support.ErrFailedToCreateTransaction, http.StatusInternalServerError))
+			var validationErr domain.ValidationError
+			var denied risk.DeniedError
+			var limitExceeded domain.LimitExceededError
+			var insufficientFunds domain.InsufficientFundsError
+			switch {
+			case errors.As(err, &validationErr):
+				sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidTransaction, http.StatusBadRequest, validationErr.Problems))
+			case errors.As(err, &denied):
+				sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrTransactionDenied, http.StatusForbidden, denied.RuleIDs))
+			case errors.As(err, &limitExceeded):
+				sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrTransactionExceedsLimits, http.StatusUnprocessableEntity, limitExceeded.LimitIDs))
+			case errors.As(err, &insufficientFunds):
+				sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrTransactionInsufficientFunds, http.StatusUnprocessableEntity, []string{insufficientFunds.Error()}))
+			default:
+				sendError(w, httperrors.NewHTTPError(support.ErrFailedToCreateTransaction, http.StatusInternalServerError))
+			}
 			span.RecordError(err)
 			return
 		}
@@ -75,24 +110,62 @@ func CreateTransaction(app service.TransactionService) http.HandlerFunc {
 // @Param pageSize query int false "Number of items per page for pagination"
 // @Param origin query string false "Filter by transaction origin"
 // @Param transactionType query string false "Filter by transaction type"
+// @Param filter query string false "Filter expression combining comparisons on id, user_id, origin, transaction_type, amount, status and created_at with and, or and not"
+// @Param sort query string false "Sort by created_at or amount, descending with a leading -"
+// @Param view query string false "Name of a saved search to apply; the other parameters refine it"
+// @Param tag query []string false "Tag the transactions must have; repeat it to require several tags" collectionFormat(multi)
+// @Param metadata.{key} query string false "Value the metadata of the transactions must have at the key, e.g. metadata.order_id=A-1001"
+// @Param category query string false "ID of a category; the transactions of its subcategories are included"
+// @Param q query string false "Words the description or the merchant and reference metadata must all contain; the transactions are listed most relevant first unless sorted, with a highlighted snippet"
 // @Success 200 {array} domain.Transaction
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
 // @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /v1/transactions [get]
-func ListTransactions(app service.TransactionService) http.HandlerFunc {
+func ListTransactions(app service.TransactionService, searches service.SavedSearchService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tr := otel.Tracer("ListTransactions")
-		_, span := tr.Start(r.Context(), "Handling ListTransactions request")
+		ctx, span := tr.Start(r.Context(), "Handling ListTransactions request")
 		defer span.End()
 
 		// Extract 'page' and 'pageSize' from query parameters
 		page := getQueryParamAsInt(r, PageKey, 1)
 		pageSize := getQueryParamAsInt(r, PageSizeKey, 10)
 
-		opts := extractAndBuildFilterParams(r)
+		// The saved search of the view comes first, so that the ad-hoc origin, transaction type and sort replace
+		// its own; filter expressions are all applied
+		var opts []filter.Options
+		if name := r.URL.Query().Get(View); name != "" {
+			search, err := searches.GetSavedSearchByName(ctx, name)
+			if err != nil {
+				sendSavedSearchError(w, err, support.ErrFailedToRetrieveSavedSearches)
+				span.RecordError(err)
+				return
+			}
+			if opts, err = buildFilterOptions(search.Origin, search.TransactionType, search.Filter, search.Sort); err != nil {
+				sendError(w, httperrors.NewHTTPError(support.ErrFailedToRetrieveSavedSearches, http.StatusInternalServerError))
+				span.RecordError(err)
+				return
+			}
+		}
+
+		adHoc, err := extractAndBuildFilterParams(r)
+		if err != nil {
+			message := support.ErrInvalidFilter
+			var paramErr queryParamError
+			if errors.As(err, &paramErr) {
+				message = paramErr.message
+			}
+			sendError(w, httperrors.NewHTTPErrorWithDetails(message, http.StatusBadRequest, []string{err.Error()}))
+			span.RecordError(err)
+			return
+		}
+		opts = append(opts, adHoc...)
 
 		// Create a new context with the page and pageSize values
-		ctxWithPagination := context.WithValue(r.Context(), PageKey, page)
-		ctxWithPagination = context.WithValue(ctxWithPagination, pageSize, pageSize)
+		ctxWithPagination := repository.WithPage(ctx, page, pageSize)
 
 		transactions, err := app.ListTransactions(ctxWithPagination, opts...)
 		if err != nil {
@@ -109,11 +182,209 @@ func ListTransactions(app service.TransactionService) http.HandlerFunc {
 	}
 }
 
-func extractAndBuildFilterParams(r *http.Request) []filter.Options {
+// GetTransaction godoc
+// @Summary Get a transaction
+// @Description Retrieves a transaction. End users can only retrieve their own transactions.
+// @tags transactions
+// @Produce json
+// @Param transactionId path string true "Transaction ID"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/transactions/{transactionId} [get]
+func GetTransaction(app service.TransactionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetTransaction")
+		ctx, span := tr.Start(r.Context(), "Handling GetTransaction request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, TransactionIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidTransactionID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		transaction, err := app.GetTransaction(ctx, id)
+		if err != nil {
+			var notFoundErr repository.NotFoundError
+			if errors.As(err, &notFoundErr) {
+				sendError(w, httperrors.NewHTTPError(support.ErrTransactionNotFound, http.StatusNotFound))
+			} else {
+				sendError(w, httperrors.NewHTTPError(support.ErrFailedToRetrieveTransaction, http.StatusInternalServerError))
+			}
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, transaction)
+	}
+}
+
+// CategorizeTransaction godoc
+// @Summary Override the category of a transaction
+// @Description Sets the category of a transaction, or clears it with a null category_id, recording the caller that changed it
+// @tags transactions
+// @Accept json
+// @Produce json
+// @Param transactionId path string true "Transaction ID"
+// @Param category body domain.TransactionCategoryRequest true "New category"
+// @Success 200 {object} domain.Transaction
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 404 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/transactions/{transactionId}/category [put]
+func CategorizeTransaction(app service.TransactionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("CategorizeTransaction")
+		ctx, span := tr.Start(r.Context(), "Handling CategorizeTransaction request")
+		defer span.End()
+
+		id, err := uuid.Parse(chi.URLParam(r, TransactionIDParam))
+		if err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrInvalidTransactionID, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		var request domain.TransactionCategoryRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			sendError(w, httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest))
+			span.RecordError(err)
+			return
+		}
+
+		transaction, err := app.CategorizeTransaction(ctx, id, request.CategoryID)
+		if err != nil {
+			var validationErr domain.ValidationError
+			var notFoundErr repository.NotFoundError
+			switch {
+			case errors.As(err, &validationErr):
+				sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidCategory, http.StatusBadRequest, validationErr.Problems))
+			case errors.As(err, &notFoundErr):
+				sendError(w, httperrors.NewHTTPError(support.ErrTransactionNotFound, http.StatusNotFound))
+			default:
+				sendError(w, httperrors.NewHTTPError(support.ErrFailedToCategorizeTransaction, http.StatusInternalServerError))
+			}
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, transaction)
+	}
+}
+
+// GetTransactionStats godoc
+// @Summary Aggregate transactions
+// @Description Counts the transactions and sums their credits and debits by category, origin, transaction type or status. The filters of the list apply; the key of the uncategorized transactions is empty.
+// @tags transactions
+// @Produce json
+// @Param by query string true "Dimension: category, origin, transaction_type or status"
+// @Param origin query string false "Filter by transaction origin"
+// @Param transactionType query string false "Filter by transaction type"
+// @Param filter query string false "Filter expression, as for the list"
+// @Param category query string false "ID of a category; the transactions of its subcategories are included"
+// @Param q query string false "Full-text search, as for the list"
+// @Success 200 {array} domain.TransactionStats
+// @Failure 400 {object} httperrors.HTTPError
+// @Failure 500 {object} httperrors.HTTPError
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/transactions/stats [get]
+func GetTransactionStats(app service.TransactionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tr := otel.Tracer("GetTransactionStats")
+		ctx, span := tr.Start(r.Context(), "Handling GetTransactionStats request")
+		defer span.End()
+
+		opts, err := extractAndBuildFilterParams(r)
+		if err != nil {
+			message := support.ErrInvalidFilter
+			var paramErr queryParamError
+			if errors.As(err, &paramErr) {
+				message = paramErr.message
+			}
+			sendError(w, httperrors.NewHTTPErrorWithDetails(message, http.StatusBadRequest, []string{err.Error()}))
+			span.RecordError(err)
+			return
+		}
+
+		stats, err := app.GetTransactionStats(ctx, r.URL.Query().Get(StatsBy), opts...)
+		if err != nil {
+			var validationErr domain.ValidationError
+			if errors.As(err, &validationErr) {
+				sendError(w, httperrors.NewHTTPErrorWithDetails(support.ErrInvalidStatsDimension, http.StatusBadRequest, validationErr.Problems))
+			} else {
+				sendError(w, httperrors.NewHTTPError(support.ErrFailedToRetrieveTransactionStats, http.StatusInternalServerError))
+			}
+			span.RecordError(err)
+			return
+		}
+
+		sendJSON(w, http.StatusOK, stats)
+	}
+}
+
+// queryParamError reports an invalid query parameter, with the message of the response
+type queryParamError struct {
+	message string
+	err     error
+}
+
+func (q queryParamError) Error() string {
+	return q.err.Error()
+}
+
+// extractAndBuildFilterParams builds the filter options of the query parameters. It returns a queryParamError when
+// the filter expression, the sort, a metadata key, a tag or the category is invalid.
+func extractAndBuildFilterParams(r *http.Request) ([]filter.Options, error) {
 	// Extract filter parameters
-	origin := r.URL.Query().Get(Origin)
-	transactionType := r.URL.Query().Get(TransactionType)
+	query := r.URL.Query()
+	opts, err := buildFilterOptions(query.Get(Origin), query.Get(TransactionType), query.Get(Filter), query.Get(Sort))
+	if err != nil {
+		return nil, err
+	}
 
+	for param, values := range query {
+		if !strings.HasPrefix(param, MetadataPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(param, MetadataPrefix)
+		if !domain.IsValidMetadataKey(key) {
+			return nil, queryParamError{message: support.ErrInvalidMetadataFilter, err: fmt.Errorf("invalid metadata key %q", key)}
+		}
+		opts = append(opts, filter.WithMetadata(key, values[0]))
+	}
+	for _, tag := range query[Tag] {
+		if !domain.IsValidTag(tag) {
+			return nil, queryParamError{message: support.ErrInvalidTagFilter, err: fmt.Errorf("invalid tag %q", tag)}
+		}
+		opts = append(opts, filter.WithTag(tag))
+	}
+	if category := query.Get(Category); category != "" {
+		categoryID, err := uuid.Parse(category)
+		if err != nil {
+			return nil, queryParamError{message: support.ErrInvalidCategoryFilter, err: fmt.Errorf("invalid category ID %q", category)}
+		}
+		opts = append(opts, filter.WithCategory(categoryID))
+	}
+	if search := query.Get(Search); strings.TrimSpace(search) != "" {
+		if len(search) > filter.MaxSearchLength || len(filter.SearchWords(search)) == 0 {
+			return nil, queryParamError{message: support.ErrInvalidSearch,
+				err: fmt.Errorf("q must have at least one letter or digit and at most %d bytes", filter.MaxSearchLength)}
+		}
+		opts = append(opts, filter.WithSearch(search))
+	}
+	return opts, nil
+}
+
+// buildFilterOptions builds the filter options of the non empty parameters
+func buildFilterOptions(origin, transactionType, expression, sort string) ([]filter.Options, error) {
 	// Create filter options based on the query parameters
 	var opts []filter.Options
 	if origin != "" {
@@ -122,7 +393,21 @@ func extractAndBuildFilterParams(r *http.Request) []filter.Options {
 	if transactionType != "" {
 		opts = append(opts, filter.WithTransactionType(transactionType))
 	}
-	return opts
+	if strings.TrimSpace(expression) != "" {
+		parsed, err := filter.Parse(expression)
+		if err != nil {
+			return nil, queryParamError{message: support.ErrInvalidFilter, err: err}
+		}
+		opts = append(opts, filter.WithExpression(parsed))
+	}
+	if sort != "" {
+		parsed, err := filter.ParseSort(sort)
+		if err != nil {
+			return nil, queryParamError{message: support.ErrInvalidSort, err: err}
+		}
+		opts = append(opts, filter.WithSort(parsed))
+	}
+	return opts, nil
 }
 
 func sendError(w http.ResponseWriter, httpErr httperrors.HTTPError) {
@@ -134,6 +419,24 @@ func sendError(w http.ResponseWriter, httpErr httperrors.HTTPError) {
 	}
 }
 
+func sendJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set(ContentType, ApplicationJSON)
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, support.ErrFailedToEncodeResponse, http.StatusInternalServerError)
+	}
+}
+
+// getQueryParamAsCurrency returns the currency of the query, DefaultCurrency when not set, and whether it is supported
+func getQueryParamAsCurrency(r *http.Request) (string, bool) {
+	currency := r.URL.Query().Get(CurrencyParam)
+	if currency == "" {
+		return domain.DefaultCurrency, true
+	}
+	return currency, domain.IsValidCurrency(currency)
+}
+
 func getQueryParamAsInt(r *http.Request, param string, defaultVal int) int {
 	valueStr := r.URL.Query().Get(param)
 	if value, err := strconv.Atoi(valueStr); err == nil && value > 0 {
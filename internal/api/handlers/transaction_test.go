@@ -2,17 +2,25 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
 	"traive-engineering-challenge/internal/api/handlers/httperrors"
+	"traive-engineering-challenge/internal/auth"
 	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
 	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/repository/memory"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/service"
 	"traive-engineering-challenge/internal/service/mocks"
 	"traive-engineering-challenge/internal/support"
 )
@@ -67,6 +75,40 @@ func TestCreateTransaction(t *testing.T) {
 			wantStatusCode: http.StatusBadRequest,
 			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToDecodeRequest, http.StatusBadRequest),
 		},
+		{
+			name: "it returns forbidden when the transaction is denied by risk rules",
+			body: support.ValidDomainTransaction(
+				id,
+				userID,
+				origin,
+				transactionType,
+				amount,
+			),
+			prepareService: func() {
+				mockService.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil, risk.NewDeniedError([]string{"velocity", "amount"}))
+			},
+			wantStatusCode: http.StatusForbidden,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrTransactionDenied, http.StatusForbidden, []string{"velocity", "amount"}),
+		},
+		{
+			name: "it returns bad request with the problems when the metadata is invalid",
+			body: domain.Transaction{ID: id, UserID: userID, Origin: origin, Amount: amount, Tags: []string{"-vip"}},
+			prepareService: func() {
+				mockService.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil, domain.NewValidationError([]string{"tag is invalid"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidTransaction, http.StatusBadRequest, []string{"tag is invalid"}),
+		},
+		{
+			name: "it returns unprocessable entity when the debit takes held funds",
+			body: domain.Transaction{ID: id, UserID: userID, Origin: origin, TransactionType: domain.TransactionTypeDebit, Amount: amount},
+			prepareService: func() {
+				mockService.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil, domain.NewInsufficientFundsError(40, amount))
+			},
+			wantStatusCode: http.StatusUnprocessableEntity,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrTransactionInsufficientFunds, http.StatusUnprocessableEntity,
+				[]string{domain.NewInsufficientFundsError(40, amount).Error()}),
+		},
 		{
 			name: "it returns internal server error",
 			body: support.ValidDomainTransaction(
@@ -130,6 +172,49 @@ func TestCreateTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateTransaction_RestrictedCaller(t *testing.T) {
+	userID := uuid.New()
+	principal := auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, UserID: &userID}
+	app := service.NewTransactionService(memory.New(), risk.NewEngine())
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:           "it creates the transactions of the caller",
+			userID:         userID,
+			wantStatusCode: http.StatusCreated,
+			wantResponse:   map[string]string{Message: support.MsgTransactionCreatedSuccessfully},
+		},
+		{
+			name:           "it returns bad request for the transactions of another user",
+			userID:         uuid.New(),
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidTransaction, http.StatusBadRequest,
+				[]string{"user_id must be the user of the caller"}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := json.Marshal(support.ValidDomainTransaction(uuid.New(), tc.userID, support.DesktopWeb, string(domain.TransactionTypeCredit), 500))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, Endpoint, bytes.NewBuffer(body))
+			req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+			rr := httptest.NewRecorder()
+			CreateTransaction(app).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
 func TestListTransactions(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -154,12 +239,22 @@ func TestListTransactions(t *testing.T) {
 
 	transactions := support.ValidDomainTransactionList(*transactionOne, *transactionTwo)
 
+	mockSearches := mocks.NewMockSavedSearchService(ctrl)
+	bigDebits := &domain.SavedSearch{
+		Name:            "big-debits",
+		Origin:          support.DesktopWeb,
+		TransactionType: domain.TransactionTypeDebit.String(),
+		Filter:          "amount > 1000",
+		Sort:            "-amount",
+	}
+
 	tests := []struct {
-		name           string
-		queryParams    map[string]string
-		prepareService func(mockSvc *mocks.MockTransactionService)
-		wantStatusCode int
-		wantResponse   interface{}
+		name            string
+		queryParams     map[string]string
+		prepareService  func(mockSvc *mocks.MockTransactionService)
+		prepareSearches func(mockSearches *mocks.MockSavedSearchService)
+		wantStatusCode  int
+		wantResponse    interface{}
 	}{
 		{
 			name: "it lists transactions successfully with default filters",
@@ -182,11 +277,186 @@ func TestListTransactions(t *testing.T) {
 				"transactionType": string(domain.TransactionTypeCredit),
 			},
 			prepareService: func(mockSvc *mocks.MockTransactionService) {
-				mockSvc.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).Return([]domain.Transaction{*transactionOne}, nil)
+				mockSvc.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, _ ...filter.Options) ([]domain.Transaction, error) {
+						page, pageSize := repository.PageFromContext(ctx)
+						if page != 2 || pageSize != 5 {
+							return nil, fmt.Errorf("unexpected page %d of size %d", page, pageSize)
+						}
+						return []domain.Transaction{*transactionOne}, nil
+					})
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   []domain.Transaction{*transactionOne},
+		},
+		{
+			name: "it passes the filter expression to the service",
+			queryParams: map[string]string{
+				"filter": `amount > 1000 and origin in ("mobile-ios", "mobile-android")`,
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {
+				mockSvc.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, opts ...filter.Options) ([]domain.Transaction, error) {
+						expressions := filter.New(opts...).Expressions
+						if len(expressions) != 1 || expressions[0].String() != `(amount > 1000 and origin in ("mobile-ios", "mobile-android"))` {
+							return nil, fmt.Errorf("unexpected expressions %v", expressions)
+						}
+						return []domain.Transaction{*transactionOne}, nil
+					})
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   []domain.Transaction{*transactionOne},
+		},
+		{
+			name: "it passes the metadata and tag filters to the service",
+			queryParams: map[string]string{
+				"metadata.order_id": "A-1001",
+				"tag":               "campaign:summer-2024",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {
+				mockSvc.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, opts ...filter.Options) ([]domain.Transaction, error) {
+						f := filter.New(opts...)
+						if !reflect.DeepEqual(f.Metadata, map[string]string{"order_id": "A-1001"}) ||
+							!reflect.DeepEqual(f.Tags, []string{"campaign:summer-2024"}) {
+							return nil, fmt.Errorf("unexpected filter %+v", f)
+						}
+						return []domain.Transaction{*transactionOne}, nil
+					})
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   []domain.Transaction{*transactionOne},
+		},
+		{
+			name: "it rejects an invalid metadata key",
+			queryParams: map[string]string{
+				"metadata.order id": "A-1001",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidMetadataFilter, http.StatusBadRequest,
+				[]string{`invalid metadata key "order id"`}),
+		},
+		{
+			name: "it rejects an invalid tag",
+			queryParams: map[string]string{
+				"tag": "-vip",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidTagFilter, http.StatusBadRequest,
+				[]string{`invalid tag "-vip"`}),
+		},
+		{
+			name: "it passes the category filter to the service",
+			queryParams: map[string]string{
+				"category": transactionOne.ID.String(),
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {
+				mockSvc.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, opts ...filter.Options) ([]domain.Transaction, error) {
+						if f := filter.New(opts...); f.Category == nil || *f.Category != transactionOne.ID {
+							return nil, fmt.Errorf("unexpected filter %+v", f)
+						}
+						return []domain.Transaction{*transactionOne}, nil
+					})
 			},
 			wantStatusCode: http.StatusOK,
 			wantResponse:   []domain.Transaction{*transactionOne},
 		},
+		{
+			name: "it rejects an invalid category",
+			queryParams: map[string]string{
+				"category": "groceries",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidCategoryFilter, http.StatusBadRequest,
+				[]string{`invalid category ID "groceries"`}),
+		},
+		{
+			name: "it passes the search to the service",
+			queryParams: map[string]string{
+				"q": "corner shop",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {
+				mockSvc.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, opts ...filter.Options) ([]domain.Transaction, error) {
+						if f := filter.New(opts...); f.Search == nil || *f.Search != "corner shop" {
+							return nil, fmt.Errorf("unexpected filter %+v", f)
+						}
+						return []domain.Transaction{*transactionOne}, nil
+					})
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   []domain.Transaction{*transactionOne},
+		},
+		{
+			name: "it rejects a search without words",
+			queryParams: map[string]string{
+				"q": "--",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidSearch, http.StatusBadRequest,
+				[]string{"q must have at least one letter or digit and at most 200 bytes"}),
+		},
+		{
+			name: "it rejects an invalid filter expression with its position",
+			queryParams: map[string]string{
+				"filter": "amount >> 5",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidFilter, http.StatusBadRequest,
+				[]string{`position 9: unexpected ">", expected a number for amount`}),
+		},
+		{
+			name: "it rejects an invalid sort",
+			queryParams: map[string]string{
+				"sort": "origin",
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidSort, http.StatusBadRequest,
+				[]string{`unknown sort "origin", expected one of created_at, amount, optionally prefixed with -`}),
+		},
+		{
+			name: "it applies the saved search of the view, refined by the ad-hoc parameters",
+			queryParams: map[string]string{
+				"view":   bigDebits.Name,
+				"origin": support.MobileIOS,
+				"filter": `status = "COMPLETED"`,
+			},
+			prepareSearches: func(mockSearches *mocks.MockSavedSearchService) {
+				mockSearches.EXPECT().GetSavedSearchByName(gomock.Any(), bigDebits.Name).Return(bigDebits, nil)
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {
+				mockSvc.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, opts ...filter.Options) ([]domain.Transaction, error) {
+						f := filter.New(opts...)
+						if *f.Origin != support.MobileIOS || *f.TransactionType != bigDebits.TransactionType ||
+							len(f.Expressions) != 2 || f.Sort.String() != bigDebits.Sort {
+							return nil, fmt.Errorf("unexpected filter %+v", f)
+						}
+						return []domain.Transaction{*transactionTwo}, nil
+					})
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   []domain.Transaction{*transactionTwo},
+		},
+		{
+			name: "it rejects a view it cannot find",
+			queryParams: map[string]string{
+				"view": "unknown",
+			},
+			prepareSearches: func(mockSearches *mocks.MockSavedSearchService) {
+				mockSearches.EXPECT().GetSavedSearchByName(gomock.Any(), "unknown").Return(nil, repository.NewNotFoundError("saved search not found"))
+			},
+			prepareService: func(mockSvc *mocks.MockTransactionService) {},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrSavedSearchNotFound, http.StatusNotFound),
+		},
 		{
 			name:        "Failed listing due to service error",
 			queryParams: map[string]string{},
@@ -201,6 +471,9 @@ func TestListTransactions(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.prepareService(mockService)
+			if tc.prepareSearches != nil {
+				tc.prepareSearches(mockSearches)
+			}
 
 			req, err := http.NewRequest(http.MethodGet, Endpoint, nil)
 			if err != nil {
@@ -215,7 +488,7 @@ func TestListTransactions(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
-			handler := ListTransactions(mockService)
+			handler := ListTransactions(mockService, mockSearches)
 			handler.ServeHTTP(rr, req)
 
 			filterObj := &filter.TransactionFilter{}
@@ -253,3 +526,216 @@ func TestListTransactions(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockTransactionService(ctrl)
+
+	transaction := support.ValidDomainTransaction(uuid.New(), uuid.New(), support.MobileIOS, string(domain.TransactionTypeCredit), int64(500))
+
+	tests := []struct {
+		name           string
+		transactionID  string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:          "it returns the transaction",
+			transactionID: transaction.ID.String(),
+			prepareService: func() {
+				mockService.EXPECT().GetTransaction(gomock.Any(), transaction.ID).Return(transaction, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   transaction,
+		},
+		{
+			name:           "it returns bad request for an invalid transaction ID",
+			transactionID:  "not-a-uuid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidTransactionID, http.StatusBadRequest),
+		},
+		{
+			name:          "it returns not found when the transaction does not exist or belongs to another user",
+			transactionID: transaction.ID.String(),
+			prepareService: func() {
+				mockService.EXPECT().GetTransaction(gomock.Any(), transaction.ID).Return(nil, repository.NewNotFoundError("transaction not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrTransactionNotFound, http.StatusNotFound),
+		},
+		{
+			name:          "it returns internal server error when the service fails",
+			transactionID: transaction.ID.String(),
+			prepareService: func() {
+				mockService.EXPECT().GetTransaction(gomock.Any(), transaction.ID).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToRetrieveTransaction, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			req := withURLParams(
+				httptest.NewRequest(http.MethodGet, Endpoint+"/"+tc.transactionID, nil),
+				map[string]string{TransactionIDParam: tc.transactionID},
+			)
+
+			rr := httptest.NewRecorder()
+			GetTransaction(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestCategorizeTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockTransactionService(ctrl)
+	transaction := support.ValidDomainTransaction(uuid.New(), uuid.New(), support.DesktopWeb, "", 1000)
+	categoryID := uuid.New()
+	categorized := *transaction
+	categorized.CategoryID = &categoryID
+	categorized.CategorizedBy = "analyst"
+
+	tests := []struct {
+		name           string
+		transactionID  string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:          "it returns the categorized transaction",
+			transactionID: transaction.ID.String(),
+			prepareService: func() {
+				mockService.EXPECT().CategorizeTransaction(gomock.Any(), transaction.ID, &categoryID).Return(&categorized, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   categorized,
+		},
+		{
+			name:           "it returns bad request for an invalid transaction ID",
+			transactionID:  "invalid",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPError(support.ErrInvalidTransactionID, http.StatusBadRequest),
+		},
+		{
+			name:          "it returns bad request for an unknown category",
+			transactionID: transaction.ID.String(),
+			prepareService: func() {
+				mockService.EXPECT().CategorizeTransaction(gomock.Any(), transaction.ID, &categoryID).
+					Return(nil, domain.NewValidationError([]string{"category_id does not exist"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse:   httperrors.NewHTTPErrorWithDetails(support.ErrInvalidCategory, http.StatusBadRequest, []string{"category_id does not exist"}),
+		},
+		{
+			name:          "it returns not found",
+			transactionID: transaction.ID.String(),
+			prepareService: func() {
+				mockService.EXPECT().CategorizeTransaction(gomock.Any(), transaction.ID, &categoryID).Return(nil, repository.NewNotFoundError("transaction not found"))
+			},
+			wantStatusCode: http.StatusNotFound,
+			wantResponse:   httperrors.NewHTTPError(support.ErrTransactionNotFound, http.StatusNotFound),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			body, err := json.Marshal(domain.TransactionCategoryRequest{CategoryID: &categoryID})
+			require.NoError(t, err)
+
+			req := withURLParams(httptest.NewRequest(http.MethodPut, "/v1/transactions/"+tc.transactionID+"/category", bytes.NewBuffer(body)), map[string]string{TransactionIDParam: tc.transactionID})
+			rr := httptest.NewRecorder()
+			CategorizeTransaction(mockService).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
+
+func TestGetTransactionStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockTransactionService(ctrl)
+	stats := []domain.TransactionStats{{Key: support.DesktopWeb, Count: 2, Credits: 1000, Debits: 500}}
+
+	tests := []struct {
+		name           string
+		query          string
+		prepareService func()
+		wantStatusCode int
+		wantResponse   interface{}
+	}{
+		{
+			name:  "it returns the stats of the filtered transactions",
+			query: "?by=origin&tag=vip",
+			prepareService: func() {
+				mockService.EXPECT().GetTransactionStats(gomock.Any(), domain.StatsByOrigin, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, opts ...filter.Options) ([]domain.TransactionStats, error) {
+						if f := filter.New(opts...); !reflect.DeepEqual(f.Tags, []string{"vip"}) {
+							return nil, fmt.Errorf("unexpected filter %+v", f)
+						}
+						return stats, nil
+					})
+			},
+			wantStatusCode: http.StatusOK,
+			wantResponse:   stats,
+		},
+		{
+			name:  "it returns bad request for an unknown dimension",
+			query: "?by=amount",
+			prepareService: func() {
+				mockService.EXPECT().GetTransactionStats(gomock.Any(), "amount", gomock.Any()).
+					Return(nil, domain.NewValidationError([]string{"by must be one of category, origin, transaction_type, status"}))
+			},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidStatsDimension, http.StatusBadRequest,
+				[]string{"by must be one of category, origin, transaction_type, status"}),
+		},
+		{
+			name:           "it returns bad request for an invalid filter",
+			query:          "?by=origin&filter=amount+%3E%3E+5",
+			prepareService: func() {},
+			wantStatusCode: http.StatusBadRequest,
+			wantResponse: httperrors.NewHTTPErrorWithDetails(support.ErrInvalidFilter, http.StatusBadRequest,
+				[]string{`position 9: unexpected ">", expected a number for amount`}),
+		},
+		{
+			name:  "it returns internal server error",
+			query: "?by=origin",
+			prepareService: func() {
+				mockService.EXPECT().GetTransactionStats(gomock.Any(), domain.StatsByOrigin).Return(nil, errors.New(InternalServerErrorMsg))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantResponse:   httperrors.NewHTTPError(support.ErrFailedToRetrieveTransactionStats, http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.prepareService()
+
+			rr := httptest.NewRecorder()
+			GetTransactionStats(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/transactions/stats"+tc.query, nil))
+
+			require.Equal(t, tc.wantStatusCode, rr.Code)
+			requireJSONBody(t, tc.wantResponse, rr)
+		})
+	}
+}
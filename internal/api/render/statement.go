@@ -0,0 +1,69 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+)
+
+const (
+	TextCSV         = "text/csv"
+	ApplicationPDF  = "application/pdf"
+	ApplicationJSON = "application/json"
+)
+
+var statementHeader = []string{"date", "transaction_id", "origin", "type", "credit", "debit", "running_balance"}
+
+// statementRows flattens a statement into rows of text. Both the CSV and the PDF renderings are produced
+// from these rows, so they always show the same numbers as the JSON representation of the statement.
+func statementRows(statement *domain.Statement) [][]string {
+	rows := make([][]string, 0, len(statement.Entries)+6)
+	rows = append(rows, statementHeader)
+	rows = append(rows, []string{statement.PeriodStart.Format(time.DateOnly), "", "", "OPENING BALANCE", "", "", formatAmount(statement.OpeningBalance)})
+
+	for _, entry := range statement.Entries {
+		rows = append(rows, []string{
+			entry.Transaction.CreatedAt.UTC().Format(time.RFC3339),
+			entry.Transaction.ID.String(),
+			entry.Transaction.Origin,
+			entry.Transaction.TransactionType.String(),
+			formatAmount(entry.Credit),
+			formatAmount(entry.Debit),
+			formatAmount(entry.RunningBalance),
+		})
+	}
+
+	rows = append(rows, []string{"", "", "", "TOTALS", formatAmount(statement.TotalCredits), formatAmount(statement.TotalDebits), ""})
+	rows = append(rows, []string{statement.PeriodEnd.AddDate(0, 0, -1).Format(time.DateOnly), "", "", "CLOSING BALANCE", "", "", formatAmount(statement.ClosingBalance)})
+
+	return rows
+}
+
+func formatAmount(amount int64) string {
+	return strconv.FormatInt(amount, 10)
+}
+
+// StatementCSV writes the statement as CSV, one row per transaction surrounded by the opening balance,
+// the totals and the closing balance
+func StatementCSV(w io.Writer, statement *domain.Statement) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(statementRows(statement)); err != nil {
+		return fmt.Errorf("failed to write statement csv: %w", err)
+	}
+	return nil
+}
+
+// StatementPDF writes the statement as a simple single-column PDF document
+func StatementPDF(w io.Writer, statement *domain.Statement) error {
+	title := fmt.Sprintf("Statement %s %s - user %s", statement.Period, statement.Currency, statement.UserID)
+
+	lines := make([]string, 0, len(statement.Entries)+6)
+	for _, row := range statementRows(statement) {
+		lines = append(lines, fmt.Sprintf("%-25s %-36s %-14s %-18s %12s %12s %15s", row[0], row[1], row[2], row[3], row[4], row[5], row[6]))
+	}
+
+	return writePDF(w, title, lines)
+}
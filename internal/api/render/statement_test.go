@@ -0,0 +1,75 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+)
+
+func buildStatement(t *testing.T, transactions int) *domain.Statement {
+	userID := uuid.New()
+	createdAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var list []domain.Transaction
+	for i := 0; i < transactions; i++ {
+		transactionType := domain.TransactionTypeCredit
+		if i%2 == 1 {
+			transactionType = domain.TransactionTypeDebit
+		}
+		list = append(list, domain.Transaction{
+			ID:              uuid.New(),
+			UserID:          userID,
+			Origin:          "mobile-ios",
+			TransactionType: transactionType,
+			Amount:          int64(100 + i),
+			CreatedAt:       createdAt.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	statement, err := domain.NewStatement(userID, domain.DefaultCurrency, "2026-03", 1000, list)
+	require.NoError(t, err)
+	return statement
+}
+
+func TestStatementCSV(t *testing.T) {
+	statement := buildStatement(t, 3)
+
+	var buf bytes.Buffer
+	require.NoError(t, StatementCSV(&buf, statement))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+
+	// header, opening balance, 3 entries, totals and closing balance
+	require.Len(t, records, 7)
+	require.Equal(t, statementHeader, records[0])
+	require.Equal(t, "1000", records[1][6])
+	require.Equal(t, "1100", records[2][6])
+	require.Equal(t, "999", records[3][6])
+	require.Equal(t, "1101", records[4][6])
+	require.Equal(t, []string{"202", "101"}, records[5][4:6])
+	require.Equal(t, "1101", records[6][6])
+}
+
+func TestStatementPDF(t *testing.T) {
+	statement := buildStatement(t, 2*pdfLinesPerPage)
+
+	var buf bytes.Buffer
+	require.NoError(t, StatementPDF(&buf, statement))
+
+	document := buf.String()
+	require.True(t, strings.HasPrefix(document, "%PDF-1.4"))
+	require.True(t, strings.HasSuffix(document, "%%EOF\n"))
+	require.Contains(t, document, "/Count 3")
+
+	// every row of the CSV rendering must appear with the same numbers in the PDF
+	for _, row := range statementRows(statement) {
+		require.Contains(t, document, row[6])
+		require.Contains(t, document, row[1])
+	}
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// jwk is the subset of RFC 7517 needed to verify RS256 and ES256 signatures
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// ParseJWKS decodes a JSON Web Key Set into public keys indexed by key ID.
+// Keys that are not RSA or P-256 EC signing keys are ignored.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			publicKey, err := parseRSAKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rsa key %q: %w", key.Kid, err)
+			}
+			keys[key.Kid] = publicKey
+		case "EC":
+			if key.Crv != "P-256" {
+				continue
+			}
+			publicKey, err := parseECKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ec key %q: %w", key.Kid, err)
+			}
+			keys[key.Kid] = publicKey
+		}
+	}
+
+	return keys, nil
+}
+
+func parseRSAKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(key.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(key.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() {
+		return nil, errors.New("exponent too large")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func parseECKey(key jwk) (*ecdsa.PublicKey, error) {
+	x, err := decodeBigInt(key.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeBigInt(key.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := elliptic.P256()
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// KeySource returns the public key identified by a key ID
+type KeySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// StaticKeySet is a key set loaded once, e.g. from a local JWKS file
+type StaticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+func NewStaticKeySet(keys map[string]crypto.PublicKey) *StaticKeySet {
+	return &StaticKeySet{keys: keys}
+}
+
+// LoadJWKSFile reads a key set from a local JWKS file
+func LoadJWKSFile(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks file: %w", err)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewStaticKeySet(keys), nil
+}
+
+func (s *StaticKeySet) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// RemoteKeySet fetches a key set from a JWKS URL and caches it. The set is refreshed once its TTL expires,
+// or when a token refers to an unknown key ID, at most once per minRefreshInterval so that forged key IDs
+// cannot be used to hammer the identity provider.
+type RemoteKeySet struct {
+	url                string
+	client             *http.Client
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func NewRemoteKeySet(url string, client *http.Client, ttl time.Duration) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:                url,
+		client:             client,
+		ttl:                ttl,
+		minRefreshInterval: 30 * time.Second,
+	}
+}
+
+func (r *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	key, ok := r.keys[kid]
+	expired := now.Sub(r.fetchedAt) > r.ttl
+	if ok && !expired {
+		return key, nil
+	}
+
+	if r.keys == nil || expired || now.Sub(r.lastAttempt) >= r.minRefreshInterval {
+		r.lastAttempt = now
+		keys, err := r.fetch(ctx)
+		if err != nil {
+			// keep serving the cached keys if the identity provider is unavailable
+			if key, ok := r.keys[kid]; ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		r.keys = keys
+		r.fetchedAt = now
+	}
+
+	key, ok = r.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (r *RemoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks: %w", err)
+	}
+	return ParseJWKS(data)
+}
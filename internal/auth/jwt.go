@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+)
+
+// Signing algorithms accepted in bearer tokens
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// ErrInvalidToken is returned when a bearer token is malformed, badly signed, expired or not meant for this API
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenVerifier resolves a bearer token into the principal it was issued to
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (Principal, error)
+}
+
+// Claims are the registered and application claims read from a bearer token
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	// UserID is the user the token was issued to; the subject is used when it is missing
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+	// Scope is the space separated list of granted scopes
+	Scope string `json:"scope"`
+	// TenantID is the tenant the token is bound to
+	TenantID string `json:"tenant_id"`
+}
+
+// audience accepts both forms of the aud claim: a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// JWTVerifier validates RS256 and ES256 signed JWTs against a key set and maps their claims to a principal.
+// Tokens without a scope claim are granted read and write access; the admin role grants the admin scope.
+// Tokens of non admin callers must identify a user, so that their data can be isolated.
+type JWTVerifier struct {
+	keys     KeySource
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewJWTVerifier builds a verifier. Empty issuer or audience are not checked.
+func NewJWTVerifier(keys KeySource, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		leeway:   time.Minute,
+		now:      time.Now,
+	}
+}
+
+// LoadJWTVerifier builds a verifier whose keys are read from a local JWKS file or fetched from a JWKS URL.
+// It returns nil when neither is set, i.e. when bearer tokens are not accepted.
+func LoadJWTVerifier(jwksFile, jwksURL, issuer, audience string) (TokenVerifier, error) {
+	switch {
+	case jwksFile != "" && jwksURL != "":
+		return nil, errors.New("only one of the jwks file and the jwks url can be set")
+	case jwksFile != "":
+		keys, err := LoadJWKSFile(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewJWTVerifier(keys, issuer, audience), nil
+	case jwksURL != "":
+		keys := NewRemoteKeySet(jwksURL, &http.Client{Timeout: 5 * time.Second}, time.Hour)
+		return NewJWTVerifier(keys, issuer, audience), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	key, err := v.keys.Key(ctx, h.Kid)
+	if err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		return Principal{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if err := verifySignature(h.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return principalFromClaims(claims)
+}
+
+func (v *JWTVerifier) validateClaims(claims Claims) error {
+	now := v.now()
+	if claims.ExpiresAt == 0 {
+		return errors.New("missing expiry")
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.leeway)) {
+		return errors.New("token expired")
+	}
+	if claims.NotBefore != 0 && now.Add(v.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return errors.New("token not yet valid")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return errors.New("unexpected issuer")
+	}
+	if v.audience != "" && !contains(claims.Audience, v.audience) {
+		return errors.New("unexpected audience")
+	}
+	return nil
+}
+
+func principalFromClaims(claims Claims) (Principal, error) {
+	principal := Principal{
+		Subject:  claims.Subject,
+		Roles:    claims.Roles,
+		Scopes:   strings.Fields(claims.Scope),
+		TenantID: claims.TenantID,
+	}
+	if len(principal.Scopes) == 0 {
+		principal.Scopes = []string{domain.ScopeTransactionsRead, domain.ScopeTransactionsWrite}
+	}
+	if principal.HasRole(RoleAdmin) {
+		principal.Scopes = append(principal.Scopes, domain.ScopeAdmin)
+	}
+
+	rawUserID := claims.UserID
+	if rawUserID == "" {
+		rawUserID = claims.Subject
+	}
+	if userID, err := uuid.Parse(rawUserID); err == nil {
+		principal.UserID = &userID
+	} else if !principal.IsAdmin() {
+		return Principal{}, fmt.Errorf("%w: token does not identify a user", ErrInvalidToken)
+	}
+
+	return principal, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case AlgorithmRS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key does not match the algorithm")
+		}
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature)
+	case AlgorithmES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key does not match the algorithm")
+		}
+		// JWS encodes ES256 signatures as the 32 byte big endian r and s values
+		if len(signature) != 64 {
+			return errors.New("malformed signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, digest[:], r, s) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+)
+
+const (
+	testIssuer   = "https://id.example.com"
+	testAudience = "transactions-api"
+)
+
+// signToken builds a compact JWS of the claims signed with the key
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		raw, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	signingInput := encode(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// buildJWKS encodes the public keys as a JSON Web Key Set
+func buildJWKS(t *testing.T, keys map[string]crypto.PublicKey) []byte {
+	t.Helper()
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	var set jwkSet
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, jwk{Kty: "RSA", Kid: kid, Use: "sig", N: encode(k.N.Bytes()), E: encode(big.NewInt(int64(k.E)).Bytes())})
+		case *ecdsa.PublicKey:
+			set.Keys = append(set.Keys, jwk{Kty: "EC", Kid: kid, Crv: "P-256", X: encode(k.X.FillBytes(make([]byte, 32))), Y: encode(k.Y.FillBytes(make([]byte, 32)))})
+		}
+	}
+
+	raw, err := json.Marshal(set)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keys, err := ParseJWKS(buildJWKS(t, map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey}))
+	require.NoError(t, err)
+	verifier := NewJWTVerifier(NewStaticKeySet(keys), testIssuer, testAudience)
+
+	userID := uuid.New()
+	now := time.Now()
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub": userID.String(),
+			"iss": testIssuer,
+			"aud": []string{testAudience},
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	tests := map[string]struct {
+		token         string
+		wantPrincipal Principal
+		wantErr       bool
+	}{
+		"it accepts an RS256 token and defaults to read and write scopes": {
+			token: signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(nil)),
+			wantPrincipal: Principal{
+				Subject: userID.String(),
+				Scopes:  []string{domain.ScopeTransactionsRead, domain.ScopeTransactionsWrite},
+				UserID:  &userID,
+			},
+		},
+		"it accepts an ES256 token with a single audience and explicit scopes": {
+			token: signToken(t, AlgorithmES256, "ec", ecKey, claims(map[string]interface{}{"aud": testAudience, "scope": domain.ScopeTransactionsRead})),
+			wantPrincipal: Principal{
+				Subject: userID.String(),
+				Scopes:  []string{domain.ScopeTransactionsRead},
+				UserID:  &userID,
+			},
+		},
+		"it grants the admin scope to admins, which need not identify a user": {
+			token: signToken(t, AlgorithmES256, "ec", ecKey, claims(map[string]interface{}{"sub": "back-office", "roles": []string{RoleAdmin}})),
+			wantPrincipal: Principal{
+				Subject: "back-office",
+				Scopes:  []string{domain.ScopeTransactionsRead, domain.ScopeTransactionsWrite, domain.ScopeAdmin},
+				Roles:   []string{RoleAdmin},
+			},
+		},
+		"it prefers the user_id claim over the subject and binds the tenant": {
+			token: signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(map[string]interface{}{"sub": "auth0|42", "user_id": userID.String(), "tenant_id": "acme"})),
+			wantPrincipal: Principal{
+				Subject:  "auth0|42",
+				Scopes:   []string{domain.ScopeTransactionsRead, domain.ScopeTransactionsWrite},
+				UserID:   &userID,
+				TenantID: "acme",
+			},
+		},
+		"it rejects tokens of end users that do not identify a user": {
+			token:   signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(map[string]interface{}{"sub": "auth0|42"})),
+			wantErr: true,
+		},
+		"it rejects expired tokens": {
+			token:   signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		"it rejects tokens without expiry": {
+			token:   signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(map[string]interface{}{"exp": nil})),
+			wantErr: true,
+		},
+		"it rejects tokens that are not yet valid": {
+			token:   signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})),
+			wantErr: true,
+		},
+		"it rejects tokens of another issuer": {
+			token:   signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+			wantErr: true,
+		},
+		"it rejects tokens for another audience": {
+			token:   signToken(t, AlgorithmRS256, "rsa", rsaKey, claims(map[string]interface{}{"aud": "another-api"})),
+			wantErr: true,
+		},
+		"it rejects tokens signed with another key": {
+			token:   signToken(t, AlgorithmES256, "ec", otherKey, claims(nil)),
+			wantErr: true,
+		},
+		"it rejects tokens signed with an unknown key": {
+			token:   signToken(t, AlgorithmES256, "other", otherKey, claims(nil)),
+			wantErr: true,
+		},
+		"it rejects tokens whose algorithm does not match the key": {
+			token:   signToken(t, AlgorithmES256, "rsa", ecKey, claims(nil)),
+			wantErr: true,
+		},
+		"it rejects unsigned tokens": {
+			token:   signToken(t, "none", "rsa", nil, claims(nil)),
+			wantErr: true,
+		},
+		"it rejects malformed tokens": {
+			token:   "not-a-token",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			principal, err := verifier.Verify(context.Background(), tc.token)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPrincipal, principal)
+		})
+	}
+}
+
+func TestRemoteKeySet_Key(t *testing.T) {
+	first, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rotated, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	served := buildJWKS(t, map[string]crypto.PublicKey{"first": &first.PublicKey})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(served)
+	}))
+	defer server.Close()
+
+	keySet := NewRemoteKeySet(server.URL, server.Client(), time.Hour)
+
+	key, err := keySet.Key(context.Background(), "first")
+	require.NoError(t, err)
+	require.True(t, first.PublicKey.Equal(key))
+
+	_, err = keySet.Key(context.Background(), "first")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, fetches.Load(), "cached keys should not be fetched again")
+
+	// unknown key IDs only trigger a refresh once the minimum refresh interval has elapsed
+	served = buildJWKS(t, map[string]crypto.PublicKey{"first": &first.PublicKey, "rotated": &rotated.PublicKey})
+	_, err = keySet.Key(context.Background(), "rotated")
+	require.True(t, errors.Is(err, ErrUnknownKey))
+	require.EqualValues(t, 1, fetches.Load())
+
+	keySet.minRefreshInterval = 0
+	key, err = keySet.Key(context.Background(), "rotated")
+	require.NoError(t, err)
+	require.True(t, rotated.PublicKey.Equal(key))
+	require.EqualValues(t, 2, fetches.Load())
+}
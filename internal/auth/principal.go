@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/domain"
+)
+
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+// RoleAdmin is the role of callers that may see the data of every user
+const RoleAdmin = "admin"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller, e.g. the ID of the API key or the subject of the token
+	Subject string
+	Scopes  []string
+	Roles   []string
+	// UserID is the user the caller acts for; it is nil for service clients such as API keys
+	UserID *uuid.UUID
+	// TenantID is the tenant the caller is bound to
+	TenantID string
+	// Service is set for the service clients authenticated by an API key, rather than by the token of a user
+	Service bool
+}
+
+// CanPickTenant tells whether the principal may pick the tenant of its requests: only the service clients that are
+// not bound to a tenant may, the other principals without a tenant being bound to the default one
+func (p Principal) CanPickTenant() bool {
+	return p.Service && p.TenantID == "" && p.UserID == nil
+}
+
+// HasScope tells whether the principal was granted the scope, either directly or through the admin scope
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == domain.ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin tells whether the principal has the admin role or was granted the admin scope
+func (p Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin) || p.HasScope(domain.ScopeAdmin)
+}
+
+// RestrictedUserID returns the user whose data the principal is restricted to.
+// Admins and principals that do not act for a user are not restricted.
+func (p Principal) RestrictedUserID() (uuid.UUID, bool) {
+	if p.UserID == nil || p.IsAdmin() {
+		return uuid.Nil, false
+	}
+	return *p.UserID, true
+}
+
+// WithPrincipal returns a copy of the context carrying the principal
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns the principal of the request, if it was authenticated
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
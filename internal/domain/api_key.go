@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes granted to API keys. The admin scope implies every other scope.
+const (
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeAdmin             = "admin"
+)
+
+var Scopes = []string{ScopeTransactionsRead, ScopeTransactionsWrite, ScopeAdmin}
+
+// ErrInvalidAPIKey is returned when a key is malformed, unknown or revoked
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// APIKey identifies a client of the API. Only a hash of the secret is stored; the prefix is used to look the key up.
+// swagger:domain APIKey
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssuedAPIKey is returned when a key is created or rotated; it is the only time the plaintext key is disclosed
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyRequest is the payload used to create an API key
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+func (a APIKey) IsRevoked() bool {
+	return a.RevokedAt != nil
+}
+
+// HasScope tells whether the key grants the scope, either directly or through the admin scope
+func (a APIKey) HasScope(scope string) bool {
+	for _, s := range a.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns a ValidationError listing every problem of the request
+func (r APIKeyRequest) Validate() error {
+	var problems []string
+
+	if r.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if len(r.Scopes) == 0 {
+		problems = append(problems, "at least one scope is required")
+	}
+	for _, scope := range r.Scopes {
+		if !IsValidScope(scope) {
+			problems = append(problems, "unknown scope "+scope)
+		}
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxCategoryNameLength is the number of characters of the name of a category
+	MaxCategoryNameLength = 63
+	// MaxDescriptionPatternLength is the number of bytes of the description pattern of a categorization rule
+	MaxDescriptionPatternLength = 256
+	// CategorizedByRulePrefix prefixes the ID of the rule in the CategorizedBy of the transactions it categorized
+	CategorizedByRulePrefix = "rule:"
+)
+
+// ErrCategoryInUse is returned when a category that still has subcategories or rules is deleted
+var ErrCategoryInUse = errors.New("the category has subcategories or categorization rules")
+
+// Category is a node of the tree of the spending categories of a tenant; root categories have no parent.
+// Names are unique within a tenant.
+// swagger:domain Category
+type Category struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Validate returns a ValidationError listing every problem of the name and the parent of the category. Whether the
+// parent exists and is not one of the subcategories of the category is checked by the service, which knows the tree.
+func (c Category) Validate() error {
+	var problems []string
+
+	if name := strings.TrimSpace(c.Name); name == "" || name != c.Name || len([]rune(name)) > MaxCategoryNameLength {
+		problems = append(problems, fmt.Sprintf("name must be 1 to %d characters without leading or trailing spaces", MaxCategoryNameLength))
+	}
+	if c.ParentID != nil && *c.ParentID == c.ID {
+		problems = append(problems, "parent_id cannot be the category itself")
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// CategorySubtree returns the IDs of the category and of every category below it in the tree
+func CategorySubtree(categories []Category, id uuid.UUID) []uuid.UUID {
+	children := make(map[uuid.UUID][]uuid.UUID, len(categories))
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	subtree := []uuid.UUID{id}
+	seen := map[uuid.UUID]bool{id: true}
+	for i := 0; i < len(subtree); i++ {
+		for _, child := range children[subtree[i]] {
+			if !seen[child] {
+				seen[child] = true
+				subtree = append(subtree, child)
+			}
+		}
+	}
+	return subtree
+}
+
+// CategoryRule assigns its category to the transactions matching every one of its conditions when they are created.
+// The rules are evaluated by priority, lowest first, then by creation time; the first match wins.
+// swagger:domain CategoryRule
+type CategoryRule struct {
+	ID         uuid.UUID `json:"id"`
+	CategoryID uuid.UUID `json:"category_id"`
+	Priority   int       `json:"priority"`
+	Origin     string    `json:"origin,omitempty"`
+	// MinAmount and MaxAmount bound the amount of the transactions, both inclusive
+	MinAmount *int64 `json:"min_amount,omitempty"`
+	MaxAmount *int64 `json:"max_amount,omitempty"`
+	// Metadata holds the entries the metadata of the transactions must contain
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// DescriptionPattern is a regular expression, in the RE2 syntax, the description of the transactions must match
+	DescriptionPattern string    `json:"description_pattern,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// Validate returns a ValidationError listing every problem of the conditions of the rule. Whether the category
+// exists is checked by the service.
+func (r CategoryRule) Validate() error {
+	var problems []string
+
+	if r.CategoryID == uuid.Nil {
+		problems = append(problems, "category_id is required")
+	}
+	if r.Origin == "" && r.MinAmount == nil && r.MaxAmount == nil && len(r.Metadata) == 0 && r.DescriptionPattern == "" {
+		problems = append(problems, "at least one of origin, min_amount, max_amount, metadata and description_pattern is required")
+	}
+	if r.Origin != "" && !IsValidOrigin(r.Origin) {
+		problems = append(problems, fmt.Sprintf("origin must be one of %s", strings.Join(Origins, ", ")))
+	}
+	if (r.MinAmount != nil && *r.MinAmount < 0) || (r.MaxAmount != nil && *r.MaxAmount < 0) {
+		problems = append(problems, "min_amount and max_amount cannot be negative")
+	}
+	if r.MinAmount != nil && r.MaxAmount != nil && *r.MinAmount > *r.MaxAmount {
+		problems = append(problems, "min_amount cannot be greater than max_amount")
+	}
+	for key, value := range r.Metadata {
+		if !IsValidMetadataKey(key) || len(value) > MaxMetadataValueLength {
+			problems = append(problems, fmt.Sprintf("metadata must have keys of 1 to 40 letters, digits, '_' or '-' and values of at most %d bytes", MaxMetadataValueLength))
+			break
+		}
+	}
+	if len(r.DescriptionPattern) > MaxDescriptionPatternLength {
+		problems = append(problems, fmt.Sprintf("description_pattern must be at most %d bytes", MaxDescriptionPatternLength))
+	} else if _, err := regexp.Compile(r.DescriptionPattern); err != nil {
+		problems = append(problems, "description_pattern: "+err.Error())
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// Matches tells whether the transaction meets every condition of the rule. A description pattern that does not
+// compile matches nothing.
+func (r CategoryRule) Matches(t Transaction) bool {
+	switch {
+	case r.Origin != "" && r.Origin != t.Origin:
+		return false
+	case r.MinAmount != nil && t.Amount < *r.MinAmount:
+		return false
+	case r.MaxAmount != nil && t.Amount > *r.MaxAmount:
+		return false
+	}
+	for key, value := range r.Metadata {
+		if actual, ok := t.Metadata[key]; !ok || actual != value {
+			return false
+		}
+	}
+	if r.DescriptionPattern != "" {
+		pattern, err := regexp.Compile(r.DescriptionPattern)
+		if err != nil || !pattern.MatchString(t.Description) {
+			return false
+		}
+	}
+	return true
+}
+
+// Categorize returns the first of the rules, in the order they are evaluated, that matches the transaction
+func Categorize(rules []CategoryRule, t Transaction) (*CategoryRule, bool) {
+	for i := range rules {
+		if rules[i].Matches(t) {
+			return &rules[i], true
+		}
+	}
+	return nil, false
+}
+
+// TransactionCategoryRequest overrides the category of a transaction; a null category_id leaves it uncategorized
+// swagger:domain TransactionCategoryRequest
+type TransactionCategoryRequest struct {
+	CategoryID *uuid.UUID `json:"category_id"`
+}
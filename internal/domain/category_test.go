@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestCategory_Validate(t *testing.T) {
+	id := uuid.New()
+
+	testCases := []struct {
+		name         string
+		category     Category
+		wantProblems int
+	}{
+		{
+			name:     "A root category",
+			category: Category{ID: id, Name: "Groceries"},
+		},
+		{
+			name:     "A subcategory",
+			category: Category{ID: id, Name: "Fresh food", ParentID: &[]uuid.UUID{uuid.New()}[0]},
+		},
+		{
+			name:         "A category without a name",
+			category:     Category{ID: id, Name: "  "},
+			wantProblems: 1,
+		},
+		{
+			name:         "A name too long",
+			category:     Category{ID: id, Name: strings.Repeat("a", MaxCategoryNameLength+1)},
+			wantProblems: 1,
+		},
+		{
+			name:         "A category that is its own parent",
+			category:     Category{ID: id, Name: " Groceries", ParentID: &id},
+			wantProblems: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requireProblems(t, tc.category.Validate(), tc.wantProblems)
+		})
+	}
+}
+
+func TestCategorySubtree(t *testing.T) {
+	food, groceries, restaurants, fresh, travel := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	categories := []Category{
+		{ID: fresh, ParentID: &groceries},
+		{ID: food},
+		{ID: groceries, ParentID: &food},
+		{ID: restaurants, ParentID: &food},
+		{ID: travel},
+	}
+
+	require.ElementsMatch(t, []uuid.UUID{food, groceries, restaurants, fresh}, CategorySubtree(categories, food))
+	require.ElementsMatch(t, []uuid.UUID{groceries, fresh}, CategorySubtree(categories, groceries))
+	require.Equal(t, []uuid.UUID{travel}, CategorySubtree(categories, travel))
+}
+
+func TestCategoryRule_Validate(t *testing.T) {
+	categoryID := uuid.New()
+	amount := func(a int64) *int64 { return &a }
+
+	testCases := []struct {
+		name         string
+		rule         CategoryRule
+		wantProblems int
+	}{
+		{
+			name: "A rule with every condition",
+			rule: CategoryRule{CategoryID: categoryID, Origin: OriginMobileIOS, MinAmount: amount(10), MaxAmount: amount(100),
+				Metadata: map[string]string{"merchant": "Corner shop"}, DescriptionPattern: `(?i)^grocer`},
+		},
+		{
+			name:         "A rule without conditions",
+			rule:         CategoryRule{CategoryID: categoryID},
+			wantProblems: 1,
+		},
+		{
+			name:         "A rule without a category",
+			rule:         CategoryRule{Origin: OriginMobileIOS},
+			wantProblems: 1,
+		},
+		{
+			name:         "An inverted amount range",
+			rule:         CategoryRule{CategoryID: categoryID, MinAmount: amount(100), MaxAmount: amount(10)},
+			wantProblems: 1,
+		},
+		{
+			name:         "Every problem is reported together",
+			rule:         CategoryRule{CategoryID: categoryID, Origin: "smart-tv", MinAmount: amount(-1), Metadata: map[string]string{"a b": "c"}, DescriptionPattern: `(`},
+			wantProblems: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requireProblems(t, tc.rule.Validate(), tc.wantProblems)
+		})
+	}
+}
+
+func TestCategorize(t *testing.T) {
+	amount := func(a int64) *int64 { return &a }
+	groceries := CategoryRule{ID: uuid.New(), DescriptionPattern: `(?i)grocer`, MaxAmount: amount(500)}
+	shop := CategoryRule{ID: uuid.New(), Metadata: map[string]string{"merchant": "Corner shop"}}
+	mobile := CategoryRule{ID: uuid.New(), Origin: OriginMobileIOS, MinAmount: amount(1000)}
+	rules := []CategoryRule{groceries, shop, mobile}
+
+	testCases := []struct {
+		name        string
+		transaction Transaction
+		want        *CategoryRule
+	}{
+		{
+			name:        "The first matching rule wins",
+			transaction: Transaction{Description: "Weekly GROCERIES", Amount: 200, Metadata: map[string]string{"merchant": "Corner shop"}},
+			want:        &groceries,
+		},
+		{
+			name:        "Every condition of a rule must match",
+			transaction: Transaction{Description: "Weekly groceries", Amount: 800, Metadata: map[string]string{"merchant": "Corner shop"}},
+			want:        &shop,
+		},
+		{
+			name:        "The amount bounds are inclusive",
+			transaction: Transaction{Origin: OriginMobileIOS, Amount: 1000},
+			want:        &mobile,
+		},
+		{
+			name:        "A transaction no rule matches",
+			transaction: Transaction{Origin: OriginMobileIOS, Amount: 999, Metadata: map[string]string{"merchant": "Other shop"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := Categorize(rules, tc.transaction)
+			if tc.want == nil {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, tc.want.ID, got.ID)
+		})
+	}
+}
+
+func requireProblems(t *testing.T, err error, wantProblems int) {
+	if wantProblems == 0 {
+		require.NoError(t, err)
+		return
+	}
+
+	var validationErr ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+	require.Len(t, validationErr.Problems, wantProblems, validationErr.Problems)
+}
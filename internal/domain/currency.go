@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// DefaultCurrency is the currency of the transactions created without one, which were all in it before transactions
+// had a currency
+const DefaultCurrency = "USD"
+
+// CurrencyExponents are the ISO 4217 currencies the transactions may be in, with the number of digits of their minor
+// unit: the amounts are integers of minor units, cents for USD and yen for JPY
+var CurrencyExponents = map[string]int{
+	"AUD": 2,
+	"BHD": 3,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MXN": 2,
+	"USD": 2,
+}
+
+// IsValidCurrency tells whether transactions may be in the currency
+func IsValidCurrency(currency string) bool {
+	_, ok := CurrencyExponents[currency]
+	return ok
+}
+
+// Currencies lists the codes of the supported currencies, sorted
+func Currencies() []string {
+	currencies := make([]string, 0, len(CurrencyExponents))
+	for currency := range CurrencyExponents {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// ValidateCurrency returns a ValidationError when the transaction is in an unsupported currency
+func (t Transaction) ValidateCurrency() error {
+	if !IsValidCurrency(t.Currency) {
+		return NewValidationError([]string{fmt.Sprintf("currency must be one of %s", strings.Join(Currencies(), ", "))})
+	}
+	return nil
+}
+
+// ConvertAmount converts an amount of minor units of the source currency to minor units of the target currency at the
+// rate, the number of target units one source unit is worth. The result is rounded to the minor unit of the target
+// currency, half away from zero, with exact arithmetic so that the same conversion always gives the same amount.
+func ConvertAmount(amount int64, source, target string, rate *big.Rat) (int64, error) {
+	sourceExponent, ok := CurrencyExponents[source]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", source)
+	}
+	targetExponent, ok := CurrencyExponents[target]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", target)
+	}
+
+	// amount / 10^sourceExponent major units of the source are worth amount * rate * 10^(targetExponent - sourceExponent)
+	// minor units of the target
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(targetExponent-sourceExponent))), nil))
+	if targetExponent >= sourceExponent {
+		converted.Mul(converted, scale)
+	} else {
+		converted.Quo(converted, scale)
+	}
+
+	rounded := roundHalfAwayFromZero(converted)
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("the converted amount overflows")
+	}
+	return rounded.Int64(), nil
+}
+
+func roundHalfAwayFromZero(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	// the remainder has the sign of the value; it rounds away from zero when twice its size reaches the denominator
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	return quotient
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestConvertAmount(t *testing.T) {
+	testCases := []struct {
+		name   string
+		amount int64
+		source string
+		target string
+		rate   string
+		want   int64
+	}{
+		{
+			name:   "Between currencies with cents",
+			amount: 10000,
+			source: "EUR",
+			target: "USD",
+			rate:   "1.085",
+			want:   10850,
+		},
+		{
+			name:   "Half a cent is rounded away from zero",
+			amount: 1,
+			source: "EUR",
+			target: "USD",
+			rate:   "1.5",
+			want:   2,
+		},
+		{
+			name:   "Less than half a cent is rounded down",
+			amount: 3,
+			source: "EUR",
+			target: "USD",
+			rate:   "1.1",
+			want:   3,
+		},
+		{
+			name:   "To a currency without minor unit",
+			amount: 1001,
+			source: "USD",
+			target: "JPY",
+			rate:   "149.5",
+			want:   1496,
+		},
+		{
+			name:   "From a currency without minor unit",
+			amount: 14950,
+			source: "JPY",
+			target: "USD",
+			rate:   "0.006688963211",
+			want:   10000,
+		},
+		{
+			name:   "To a currency with three decimals",
+			amount: 10000,
+			source: "USD",
+			target: "KWD",
+			rate:   "0.3075",
+			want:   30750,
+		},
+		{
+			name:   "From a currency with three decimals to one without minor unit",
+			amount: 1234,
+			source: "KWD",
+			target: "JPY",
+			rate:   "486.2",
+			want:   600,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := ParseRate(tc.rate)
+			require.NoError(t, err)
+
+			got, err := ConvertAmount(tc.amount, tc.source, tc.target, rate)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := ConvertAmount(100, "USD", "XXX", big.NewRat(1, 1))
+	require.Error(t, err)
+}
+
+func TestTransaction_ValidateCurrency(t *testing.T) {
+	require.NoError(t, Transaction{Currency: "JPY"}.ValidateCurrency())
+	require.Error(t, Transaction{Currency: "usd"}.ValidateCurrency())
+	require.Error(t, Transaction{Currency: "XXX"}.ValidateCurrency())
+}
@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// RateDecimals is the number of decimals of the rates, the inverse rates being rounded to it
+	RateDecimals = 12
+	// MaxSnapshotRates is the number of rates a snapshot may have
+	MaxSnapshotRates = 1000
+	// MaxRateSourceLength is the number of bytes of the source of a snapshot
+	MaxRateSourceLength = 255
+)
+
+// FXConversionIDMetadataKey and FXQuoteIDMetadataKey are the metadata keys set on the debit and the credit of a
+// conversion
+const (
+	FXConversionIDMetadataKey = "fx_conversion_id"
+	FXQuoteIDMetadataKey      = "fx_quote_id"
+)
+
+// ErrQuoteUnavailable is returned when a quote is converted after it expired or once it was already converted
+var ErrQuoteUnavailable = errors.New("the quote is no longer available")
+
+// rateFormat is the format of the rates, decimals with up to RateDecimals decimals
+var rateFormat = regexp.MustCompile(`^[0-9]{1,12}(\.[0-9]{1,12})?$`)
+
+// FXRate is the number of units of the quote currency one unit of the base currency is worth, as a decimal string so
+// that it is used exactly as it was loaded
+type FXRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+}
+
+// RateSnapshot is a table of exchange rates, effective from effective_at until a snapshot with a later effective_at
+// replaces it
+// swagger:domain RateSnapshot
+type RateSnapshot struct {
+	ID uuid.UUID `json:"id"`
+	// Source tells where the rates come from, such as the name of a provider or of a file
+	Source      string    `json:"source,omitempty"`
+	EffectiveAt time.Time `json:"effective_at"`
+	Rates       []FXRate  `json:"rates"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Validate returns a ValidationError listing every problem of the snapshot
+func (s RateSnapshot) Validate() error {
+	var problems []string
+
+	if len(s.Source) > MaxRateSourceLength {
+		problems = append(problems, fmt.Sprintf("source must be at most %d bytes", MaxRateSourceLength))
+	}
+	if len(s.Rates) == 0 || len(s.Rates) > MaxSnapshotRates {
+		problems = append(problems, fmt.Sprintf("rates must have between 1 and %d rates", MaxSnapshotRates))
+	}
+
+	pairs := make(map[string]bool, len(s.Rates))
+	for i, rate := range s.Rates {
+		if !IsValidCurrency(rate.BaseCurrency) || !IsValidCurrency(rate.QuoteCurrency) {
+			problems = append(problems, fmt.Sprintf("rates[%d]: currencies must be one of %s", i, strings.Join(Currencies(), ", ")))
+		} else if rate.BaseCurrency == rate.QuoteCurrency {
+			problems = append(problems, fmt.Sprintf("rates[%d]: the base and quote currencies must differ", i))
+		}
+		if _, err := ParseRate(rate.Rate); err != nil {
+			problems = append(problems, fmt.Sprintf("rates[%d]: %s", i, err))
+		}
+
+		pair := rate.BaseCurrency + "/" + rate.QuoteCurrency
+		if pairs[pair] {
+			problems = append(problems, fmt.Sprintf("rates[%d]: %s is already rated", i, pair))
+		}
+		pairs[pair] = true
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// Rate returns the rate converting the base currency to the quote currency: the rate of the pair when the snapshot has
+// it, otherwise the inverse of the rate of the reverse pair, rounded to RateDecimals decimals
+func (s RateSnapshot) Rate(base, quote string) (string, bool) {
+	for _, rate := range s.Rates {
+		if rate.BaseCurrency == base && rate.QuoteCurrency == quote {
+			return rate.Rate, true
+		}
+	}
+	for _, rate := range s.Rates {
+		if rate.BaseCurrency == quote && rate.QuoteCurrency == base {
+			value, err := ParseRate(rate.Rate)
+			if err != nil {
+				return "", false
+			}
+			inverse := formatRate(new(big.Rat).Inv(value))
+			if inverse == "0" {
+				return "", false
+			}
+			return inverse, true
+		}
+	}
+	return "", false
+}
+
+// ParseRate parses a positive decimal rate with up to RateDecimals decimals
+func ParseRate(rate string) (*big.Rat, error) {
+	if !rateFormat.MatchString(rate) {
+		return nil, fmt.Errorf("rate must be a decimal with up to %d decimals", RateDecimals)
+	}
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+	return value, nil
+}
+
+// formatRate formats the rate with RateDecimals decimals at most, without trailing zeros
+func formatRate(rate *big.Rat) string {
+	formatted := rate.FloatString(RateDecimals)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
+
+// ParseRatesCSV reads the rates of a CSV file whose records are base_currency,quote_currency,rate, with an optional
+// header of these names. It returns a ValidationError pointing at the malformed records.
+func ParseRatesCSV(r io.Reader) ([]FXRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	rates := []FXRate{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, NewValidationError([]string{fmt.Sprintf("line %d: expected base_currency,quote_currency,rate", line)})
+		}
+		if line == 1 && strings.EqualFold(record[0], "base_currency") {
+			continue
+		}
+
+		rates = append(rates, FXRate{
+			BaseCurrency:  strings.TrimSpace(record[0]),
+			QuoteCurrency: strings.TrimSpace(record[1]),
+			Rate:          strings.TrimSpace(record[2]),
+		})
+	}
+}
+
+// FXQuoteRequest asks for the amount of the target currency an amount of the source currency converts to
+// swagger:domain FXQuoteRequest
+type FXQuoteRequest struct {
+	UserID         uuid.UUID `json:"user_id"`
+	SourceCurrency string    `json:"source_currency"`
+	TargetCurrency string    `json:"target_currency"`
+	// SourceAmount is in minor units of the source currency
+	SourceAmount int64 `json:"source_amount"`
+}
+
+// Validate returns a ValidationError listing every problem of the request
+func (q FXQuoteRequest) Validate() error {
+	var problems []string
+
+	if q.UserID == uuid.Nil {
+		problems = append(problems, "user_id is required")
+	}
+	if !IsValidCurrency(q.SourceCurrency) || !IsValidCurrency(q.TargetCurrency) {
+		problems = append(problems, fmt.Sprintf("source_currency and target_currency must be one of %s", strings.Join(Currencies(), ", ")))
+	} else if q.SourceCurrency == q.TargetCurrency {
+		problems = append(problems, "source_currency and target_currency must differ")
+	}
+	if q.SourceAmount <= 0 {
+		problems = append(problems, "source_amount must be positive")
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// FXQuote locks the rate of a conversion until it expires. The amounts are in minor units of their currency.
+// swagger:domain FXQuote
+type FXQuote struct {
+	ID             uuid.UUID `json:"id"`
+	UserID         uuid.UUID `json:"user_id"`
+	SourceCurrency string    `json:"source_currency"`
+	TargetCurrency string    `json:"target_currency"`
+	SourceAmount   int64     `json:"source_amount"`
+	TargetAmount   int64     `json:"target_amount"`
+	Rate           string    `json:"rate"`
+	// SnapshotID is the snapshot the rate was taken from
+	SnapshotID uuid.UUID `json:"snapshot_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Expired tells whether the quote can no longer be converted at now
+func (q FXQuote) Expired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+// FXConversionRequest converts a quote
+// swagger:domain FXConversionRequest
+type FXConversionRequest struct {
+	QuoteID uuid.UUID `json:"quote_id"`
+	Origin  string    `json:"origin"`
+}
+
+// Validate returns a ValidationError listing every problem of the request
+func (c FXConversionRequest) Validate() error {
+	var problems []string
+
+	if c.QuoteID == uuid.Nil {
+		problems = append(problems, "quote_id is required")
+	}
+	if !IsValidOrigin(c.Origin) {
+		problems = append(problems, fmt.Sprintf("origin must be one of %s", strings.Join(Origins, ", ")))
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// FXConversion records a conversion: the debit of the source amount and the credit of the target amount, at the rate
+// of its quote
+// swagger:domain FXConversion
+type FXConversion struct {
+	ID                  uuid.UUID `json:"id"`
+	QuoteID             uuid.UUID `json:"quote_id"`
+	UserID              uuid.UUID `json:"user_id"`
+	Origin              string    `json:"origin"`
+	SourceCurrency      string    `json:"source_currency"`
+	TargetCurrency      string    `json:"target_currency"`
+	SourceAmount        int64     `json:"source_amount"`
+	TargetAmount        int64     `json:"target_amount"`
+	Rate                string    `json:"rate"`
+	DebitTransactionID  uuid.UUID `json:"debit_transaction_id"`
+	CreditTransactionID uuid.UUID `json:"credit_transaction_id"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// NewFXConversion builds the conversion of the quote, with the IDs of its debit and credit
+func NewFXConversion(quote FXQuote, origin string) FXConversion {
+	return FXConversion{
+		ID:                  uuid.New(),
+		QuoteID:             quote.ID,
+		UserID:              quote.UserID,
+		Origin:              origin,
+		SourceCurrency:      quote.SourceCurrency,
+		TargetCurrency:      quote.TargetCurrency,
+		SourceAmount:        quote.SourceAmount,
+		TargetAmount:        quote.TargetAmount,
+		Rate:                quote.Rate,
+		DebitTransactionID:  uuid.New(),
+		CreditTransactionID: uuid.New(),
+	}
+}
+
+// Transactions builds the debit of the source amount and the credit of the target amount of the conversion
+func (c FXConversion) Transactions() (Transaction, Transaction) {
+	transaction := func(id uuid.UUID, transactionType TransactionType, currency string, amount int64) Transaction {
+		return Transaction{
+			ID:              id,
+			UserID:          c.UserID,
+			Origin:          c.Origin,
+			TransactionType: transactionType,
+			Amount:          amount,
+			Currency:        currency,
+			Status:          TransactionStatusCompleted,
+			Description:     fmt.Sprintf("Conversion of %s to %s at %s", c.SourceCurrency, c.TargetCurrency, c.Rate),
+			Metadata: map[string]string{
+				FXConversionIDMetadataKey: c.ID.String(),
+				FXQuoteIDMetadataKey:      c.QuoteID.String(),
+			},
+		}
+	}
+
+	return transaction(c.DebitTransactionID, TransactionTypeDebit, c.SourceCurrency, c.SourceAmount),
+		transaction(c.CreditTransactionID, TransactionTypeCredit, c.TargetCurrency, c.TargetAmount)
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validRateSnapshot() RateSnapshot {
+	return RateSnapshot{
+		Source:      "ecb",
+		EffectiveAt: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Rates: []FXRate{
+			{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.085"},
+			{BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: "149.5"},
+		},
+	}
+}
+
+func TestRateSnapshot_Validate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		modify       func(s *RateSnapshot)
+		wantProblems int
+	}{
+		{
+			name:   "A valid snapshot",
+			modify: func(s *RateSnapshot) {},
+		},
+		{
+			name:         "No rates",
+			modify:       func(s *RateSnapshot) { s.Rates = nil },
+			wantProblems: 1,
+		},
+		{
+			name:         "An unsupported currency",
+			modify:       func(s *RateSnapshot) { s.Rates[0].QuoteCurrency = "XXX" },
+			wantProblems: 1,
+		},
+		{
+			name:         "A pair of the same currency",
+			modify:       func(s *RateSnapshot) { s.Rates[0].QuoteCurrency = "EUR" },
+			wantProblems: 1,
+		},
+		{
+			name: "A pair rated twice",
+			modify: func(s *RateSnapshot) {
+				s.Rates = append(s.Rates, FXRate{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.09"})
+			},
+			wantProblems: 1,
+		},
+		{
+			name: "Every problem is reported together",
+			modify: func(s *RateSnapshot) {
+				s.Source = strings.Repeat("s", MaxRateSourceLength+1)
+				s.Rates[0].Rate = "0"
+				s.Rates[1].Rate = "1.0000000000001"
+			},
+			wantProblems: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshot := validRateSnapshot()
+			tc.modify(&snapshot)
+
+			err := snapshot.Validate()
+			if tc.wantProblems == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Len(t, validationErr.Problems, tc.wantProblems)
+		})
+	}
+}
+
+func TestRateSnapshot_Rate(t *testing.T) {
+	snapshot := validRateSnapshot()
+
+	rate, ok := snapshot.Rate("EUR", "USD")
+	require.True(t, ok)
+	require.Equal(t, "1.085", rate)
+
+	rate, ok = snapshot.Rate("JPY", "USD")
+	require.True(t, ok)
+	require.Equal(t, "0.006688963211", rate)
+
+	rate, ok = snapshot.Rate("USD", "EUR")
+	require.True(t, ok)
+	require.Equal(t, "0.921658986175", rate)
+
+	_, ok = snapshot.Rate("EUR", "JPY")
+	require.False(t, ok)
+}
+
+func TestParseRatesCSV(t *testing.T) {
+	rates, err := ParseRatesCSV(strings.NewReader("base_currency,quote_currency,rate\nEUR, USD, 1.085\nUSD,JPY,149.5\n"))
+	require.NoError(t, err)
+	require.Equal(t, validRateSnapshot().Rates, rates)
+
+	rates, err = ParseRatesCSV(strings.NewReader("EUR,USD,1.085\n"))
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+
+	_, err = ParseRatesCSV(strings.NewReader("EUR,USD,1.085\nUSD,JPY\n"))
+	var validationErr ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []string{"line 2: expected base_currency,quote_currency,rate"}, validationErr.Problems)
+}
+
+func TestFXConversion_Transactions(t *testing.T) {
+	quote := FXQuote{
+		ID:             uuid.MustParse("4f1d2c3b-7f1e-4a8e-9d55-1c1f0b3e6a10"),
+		UserID:         uuid.MustParse("a1b2c3d4-0000-4000-8000-000000000001"),
+		SourceCurrency: "EUR",
+		TargetCurrency: "USD",
+		SourceAmount:   10000,
+		TargetAmount:   10850,
+		Rate:           "1.085",
+	}
+	conversion := NewFXConversion(quote, OriginDesktopWeb)
+
+	debit, credit := conversion.Transactions()
+	require.Equal(t, conversion.DebitTransactionID, debit.ID)
+	require.Equal(t, TransactionTypeDebit, debit.TransactionType)
+	require.Equal(t, "EUR", debit.Currency)
+	require.Equal(t, int64(10000), debit.Amount)
+	require.Equal(t, conversion.CreditTransactionID, credit.ID)
+	require.Equal(t, TransactionTypeCredit, credit.TransactionType)
+	require.Equal(t, "USD", credit.Currency)
+	require.Equal(t, int64(10850), credit.Amount)
+	require.Equal(t, "Conversion of EUR to USD at 1.085", credit.Description)
+	require.Equal(t, quote.ID.String(), debit.Metadata[FXQuoteIDMetadataKey])
+	require.NoError(t, debit.ValidateMetadata())
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldStatus tells whether the amount of a hold is still reserved
+type HoldStatus string
+
+const (
+	// HoldStatusActive reserves the amount until the hold is captured, released or expires
+	HoldStatusActive HoldStatus = "ACTIVE"
+	// HoldStatusCaptured is set once the hold is settled by a debit, the amount it did not capture being released
+	HoldStatusCaptured HoldStatus = "CAPTURED"
+	HoldStatusReleased HoldStatus = "RELEASED"
+	HoldStatusExpired  HoldStatus = "EXPIRED"
+)
+
+// HoldIDMetadataKey is the metadata key set on the debit capturing a hold
+const HoldIDMetadataKey = "hold_id"
+
+// DefaultHoldTTL and MaxHoldTTL bound how long a hold reserves its amount when it is not captured nor released
+const (
+	DefaultHoldTTL = 7 * 24 * time.Hour
+	MaxHoldTTL     = 30 * 24 * time.Hour
+)
+
+// ErrHoldTransition is returned when a hold that is no longer active is captured or released
+var ErrHoldTransition = errors.New("invalid hold status transition")
+
+// Hold reserves an amount of the available balance of a user in one currency, like the authorization of a card
+// payment, until it is captured by a debit of at most its amount, released, or expires
+// swagger:domain Hold
+type Hold struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	Origin string    `json:"origin"`
+	Amount int64     `json:"amount"`
+	// Currency is the ISO 4217 code of the amount, DefaultCurrency when not set; only the balance in it is reserved
+	Currency    string            `json:"currency,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	// TTLSeconds is how long the hold lasts once placed, DefaultHoldTTL when not set; it is only read on creation
+	TTLSeconds int64      `json:"ttl_seconds,omitempty"`
+	Status     HoldStatus `json:"status"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	// CapturedAmount and TransactionID are set once the hold is captured, TransactionID being the ID of the debit
+	CapturedAmount int64      `json:"captured_amount,omitempty"`
+	TransactionID  *uuid.UUID `json:"transaction_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// TenantID is the tenant of the hold, which the expiry needs as it goes through the holds of every tenant
+	TenantID string `json:"-"`
+}
+
+// Validate returns a ValidationError listing every problem of the hold, including the ones of the metadata and the
+// tags of the debit capturing it
+func (h Hold) Validate() error {
+	var problems []string
+
+	if h.UserID == uuid.Nil {
+		problems = append(problems, "user_id is required")
+	}
+	if !IsValidOrigin(h.Origin) {
+		problems = append(problems, fmt.Sprintf("origin must be one of %s", strings.Join(Origins, ", ")))
+	}
+	if h.Amount <= 0 {
+		problems = append(problems, "amount must be positive")
+	}
+	if !IsValidCurrency(h.Currency) {
+		problems = append(problems, fmt.Sprintf("currency must be one of %s", strings.Join(Currencies(), ", ")))
+	}
+	if h.TTLSeconds < 0 || time.Duration(h.TTLSeconds)*time.Second > MaxHoldTTL {
+		problems = append(problems, fmt.Sprintf("ttl_seconds must be between 1 and %d", int64(MaxHoldTTL/time.Second)))
+	}
+	if _, ok := h.Metadata[HoldIDMetadataKey]; ok {
+		problems = append(problems, fmt.Sprintf("metadata key %q is reserved", HoldIDMetadataKey))
+	}
+	var metadataErr ValidationError
+	if err := h.Capture(h.Amount).ValidateMetadata(); errors.As(err, &metadataErr) {
+		problems = append(problems, metadataErr.Problems...)
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// TTL is how long the hold lasts once placed
+func (h Hold) TTL() time.Duration {
+	if h.TTLSeconds == 0 {
+		return DefaultHoldTTL
+	}
+	return time.Duration(h.TTLSeconds) * time.Second
+}
+
+// Expired tells whether the hold is active but past its expiry at now, which releases its amount even before its
+// status is updated
+func (h Hold) Expired(now time.Time) bool {
+	return h.Status == HoldStatusActive && !now.Before(h.ExpiresAt)
+}
+
+// Capture builds the debit settling the amount of the hold, which must be positive and at most the amount of the hold
+func (h Hold) Capture(amount int64) Transaction {
+	metadata := make(map[string]string, len(h.Metadata)+1)
+	for key, value := range h.Metadata {
+		metadata[key] = value
+	}
+	metadata[HoldIDMetadataKey] = h.ID.String()
+
+	return Transaction{
+		ID:              uuid.New(),
+		UserID:          h.UserID,
+		Origin:          h.Origin,
+		TransactionType: TransactionTypeDebit,
+		Amount:          amount,
+		Currency:        h.Currency,
+		Description:     h.Description,
+		Metadata:        metadata,
+		Tags:            h.Tags,
+	}
+}
+
+// ValidateCapture returns a ValidationError when the amount cannot be captured from the hold
+func (h Hold) ValidateCapture(amount int64) error {
+	if amount <= 0 || amount > h.Amount {
+		return NewValidationError([]string{fmt.Sprintf("amount must be between 1 and the amount of the hold, %d", h.Amount)})
+	}
+	return nil
+}
+
+// HoldCaptureRequest captures a hold; a zero or missing amount captures the whole amount of the hold
+// swagger:domain HoldCaptureRequest
+type HoldCaptureRequest struct {
+	Amount int64 `json:"amount,omitempty"`
+}
+
+// InsufficientFundsError is returned when a hold or a conversion would take more than the available balance of the
+// user in its currency, or when a debit would take funds held for the user
+type InsufficientFundsError struct {
+	Available int64
+	Requested int64
+}
+
+func NewInsufficientFundsError(available, requested int64) InsufficientFundsError {
+	return InsufficientFundsError{Available: available, Requested: requested}
+}
+
+func (e InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds: %d available, %d requested", e.Available, e.Requested)
+}
+
+// Balance is the balance of a user in one currency: the sum of its credits minus its debits in it, of which the
+// amounts reserved by its active holds in it are not available
+// swagger:domain Balance
+type Balance struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+	Held      int64     `json:"held"`
+	Available int64     `json:"available"`
+}
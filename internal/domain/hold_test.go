@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func validHold() Hold {
+	return Hold{
+		ID:          uuid.MustParse("4f1d2c3b-7f1e-4a8e-9d55-1c1f0b3e6a10"),
+		UserID:      uuid.MustParse("a1b2c3d4-0000-4000-8000-000000000001"),
+		Origin:      OriginDesktopWeb,
+		Amount:      5000,
+		Currency:    DefaultCurrency,
+		Description: "Hotel booking",
+	}
+}
+
+func TestHold_Validate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		modify       func(h *Hold)
+		wantProblems int
+	}{
+		{
+			name:   "A hold with the default TTL",
+			modify: func(h *Hold) {},
+		},
+		{
+			name:   "A hold with the maximum TTL",
+			modify: func(h *Hold) { h.TTLSeconds = int64(MaxHoldTTL / time.Second) },
+		},
+		{
+			name:         "A TTL over the maximum",
+			modify:       func(h *Hold) { h.TTLSeconds = int64(MaxHoldTTL/time.Second) + 1 },
+			wantProblems: 1,
+		},
+		{
+			name:         "A reserved metadata key",
+			modify:       func(h *Hold) { h.Metadata = map[string]string{HoldIDMetadataKey: "x"} },
+			wantProblems: 1,
+		},
+		{
+			name:         "The metadata problems of the debit",
+			modify:       func(h *Hold) { h.Metadata = map[string]string{"order id": "A-1"} },
+			wantProblems: 1,
+		},
+		{
+			name:         "An unsupported currency",
+			modify:       func(h *Hold) { h.Currency = "XXX" },
+			wantProblems: 1,
+		},
+		{
+			name: "Every problem is reported together",
+			modify: func(h *Hold) {
+				h.UserID, h.Origin, h.Amount, h.Currency, h.TTLSeconds = uuid.Nil, "smart-tv", 0, "", -1
+			},
+			wantProblems: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hold := validHold()
+			tc.modify(&hold)
+
+			err := hold.Validate()
+			if tc.wantProblems == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Len(t, validationErr.Problems, tc.wantProblems)
+		})
+	}
+}
+
+func TestHold_Capture(t *testing.T) {
+	hold := validHold()
+	hold.Metadata = map[string]string{"booking": "42"}
+
+	transaction := hold.Capture(4200)
+	require.Equal(t, hold.UserID, transaction.UserID)
+	require.Equal(t, TransactionTypeDebit, transaction.TransactionType)
+	require.Equal(t, int64(4200), transaction.Amount)
+	require.Equal(t, hold.Currency, transaction.Currency)
+	require.Equal(t, map[string]string{"booking": "42", HoldIDMetadataKey: hold.ID.String()}, transaction.Metadata)
+	require.Len(t, hold.Metadata, 1, "the metadata of the hold is left untouched")
+
+	require.NoError(t, hold.ValidateCapture(hold.Amount))
+	require.Error(t, hold.ValidateCapture(0))
+	require.Error(t, hold.ValidateCapture(hold.Amount+1))
+}
+
+func TestHold_Expired(t *testing.T) {
+	now := time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+	hold := validHold()
+	hold.Status = HoldStatusActive
+	hold.ExpiresAt = now
+
+	require.True(t, hold.Expired(now))
+	require.False(t, hold.Expired(now.Add(-time.Nanosecond)))
+
+	hold.Status = HoldStatusReleased
+	require.False(t, hold.Expired(now), "only the active holds expire")
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Origins a transaction can come from
+const (
+	OriginDesktopWeb    = "desktop-web"
+	OriginMobileAndroid = "mobile-android"
+	OriginMobileIOS     = "mobile-ios"
+)
+
+var Origins = []string{OriginDesktopWeb, OriginMobileAndroid, OriginMobileIOS}
+
+// LimitScope tells which debits of a user a limit caps
+type LimitScope string
+
+const (
+	// LimitScopeUser caps every debit of a single user
+	LimitScopeUser LimitScope = "user"
+	// LimitScopeOrigin caps, per user, the debits each user makes through one origin
+	LimitScopeOrigin LimitScope = "origin"
+	// LimitScopeGlobal applies to every user, capping the debits of each user separately
+	LimitScopeGlobal LimitScope = "global"
+)
+
+// LimitPeriod is the rolling window over which debits are summed
+type LimitPeriod string
+
+const (
+	LimitPeriodDaily   LimitPeriod = "daily"
+	LimitPeriodMonthly LimitPeriod = "monthly"
+)
+
+// Window returns the length of the rolling window of the period
+func (p LimitPeriod) Window() time.Duration {
+	switch p {
+	case LimitPeriodMonthly:
+		return 30 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// Limit caps the sum of the debits of a user in one currency over a rolling window
+// swagger:domain Limit
+type Limit struct {
+	ID        uuid.UUID   `json:"id"`
+	Scope     LimitScope  `json:"scope"`
+	UserID    *uuid.UUID  `json:"user_id,omitempty"`
+	Origin    string      `json:"origin,omitempty"`
+	Period    LimitPeriod `json:"period"`
+	MaxAmount int64       `json:"max_amount"`
+	// Currency is the ISO 4217 code of MaxAmount, DefaultCurrency when not set; only the debits in it are capped
+	Currency  string    `json:"currency,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LimitUsage reports how much of a limit a user has already consumed
+type LimitUsage struct {
+	Limit     Limit `json:"limit"`
+	Used      int64 `json:"used"`
+	Remaining int64 `json:"remaining"`
+}
+
+// NewLimitUsage computes the headroom left on a limit; it never goes below zero
+func NewLimitUsage(limit Limit, used int64) LimitUsage {
+	remaining := limit.MaxAmount - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return LimitUsage{Limit: limit, Used: used, Remaining: remaining}
+}
+
+// Validate returns a ValidationError listing every problem of the limit definition
+func (l Limit) Validate() error {
+	var problems []string
+
+	switch l.Scope {
+	case LimitScopeUser:
+		if l.UserID == nil || *l.UserID == uuid.Nil {
+			problems = append(problems, "user_id is required for user limits")
+		}
+		if l.Origin != "" {
+			problems = append(problems, "origin must be empty for user limits")
+		}
+	case LimitScopeOrigin:
+		if !IsValidOrigin(l.Origin) {
+			problems = append(problems, fmt.Sprintf("origin must be one of %s", strings.Join(Origins, ", ")))
+		}
+		if l.UserID != nil {
+			problems = append(problems, "user_id must be empty for origin limits")
+		}
+	case LimitScopeGlobal:
+		if l.UserID != nil || l.Origin != "" {
+			problems = append(problems, "user_id and origin must be empty for global limits")
+		}
+	default:
+		problems = append(problems, "scope must be one of user, origin, global")
+	}
+
+	if l.Period != LimitPeriodDaily && l.Period != LimitPeriodMonthly {
+		problems = append(problems, "period must be one of daily, monthly")
+	}
+	if l.MaxAmount <= 0 {
+		problems = append(problems, "max_amount must be positive")
+	}
+	if !IsValidCurrency(l.Currency) {
+		problems = append(problems, fmt.Sprintf("currency must be one of %s", strings.Join(Currencies(), ", ")))
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// AppliesTo tells whether the limit caps a debit in the currency made by the user through the origin
+func (l Limit) AppliesTo(userID uuid.UUID, origin, currency string) bool {
+	if l.Currency != currency {
+		return false
+	}
+
+	switch l.Scope {
+	case LimitScopeUser:
+		return l.UserID != nil && *l.UserID == userID
+	case LimitScopeOrigin:
+		return l.Origin == origin
+	case LimitScopeGlobal:
+		return true
+	default:
+		return false
+	}
+}
+
+func IsValidOrigin(origin string) bool {
+	for _, o := range Origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError reports every problem found while validating an input
+type ValidationError struct {
+	Problems []string
+}
+
+func NewValidationError(problems []string) ValidationError {
+	return ValidationError{Problems: problems}
+}
+
+func (v ValidationError) Error() string {
+	return "validation failed: " + strings.Join(v.Problems, "; ")
+}
+
+// LimitExceededError is returned when a debit would take a user over one or more limits
+type LimitExceededError struct {
+	LimitIDs []string
+}
+
+func NewLimitExceededError(limitIDs []string) LimitExceededError {
+	return LimitExceededError{LimitIDs: limitIDs}
+}
+
+func (l LimitExceededError) Error() string {
+	return "transaction exceeds limits: " + strings.Join(l.LimitIDs, ", ")
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLimit_Validate(t *testing.T) {
+	userID := uuid.New()
+
+	testCases := []struct {
+		name         string
+		limit        Limit
+		wantProblems int
+	}{
+		{
+			name:  "A valid user limit",
+			limit: Limit{Scope: LimitScopeUser, UserID: &userID, Period: LimitPeriodDaily, MaxAmount: 1000, Currency: DefaultCurrency},
+		},
+		{
+			name:  "A valid origin limit",
+			limit: Limit{Scope: LimitScopeOrigin, Origin: OriginMobileIOS, Period: LimitPeriodMonthly, MaxAmount: 1000, Currency: DefaultCurrency},
+		},
+		{
+			name:  "A valid global limit",
+			limit: Limit{Scope: LimitScopeGlobal, Period: LimitPeriodDaily, MaxAmount: 1000, Currency: DefaultCurrency},
+		},
+		{
+			name:         "A user limit without user",
+			limit:        Limit{Scope: LimitScopeUser, Period: LimitPeriodDaily, MaxAmount: 1000, Currency: DefaultCurrency},
+			wantProblems: 1,
+		},
+		{
+			name:         "An origin limit with an unknown origin",
+			limit:        Limit{Scope: LimitScopeOrigin, Origin: "smart-tv", Period: LimitPeriodDaily, MaxAmount: 1000, Currency: DefaultCurrency},
+			wantProblems: 1,
+		},
+		{
+			name:         "A limit in an unsupported currency",
+			limit:        Limit{Scope: LimitScopeGlobal, Period: LimitPeriodDaily, MaxAmount: 1000, Currency: "XXX"},
+			wantProblems: 1,
+		},
+		{
+			name:         "Every problem is reported together",
+			limit:        Limit{Scope: "team", Period: "weekly", MaxAmount: 0},
+			wantProblems: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.limit.Validate()
+			if tc.wantProblems == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Len(t, validationErr.Problems, tc.wantProblems)
+		})
+	}
+}
+
+func TestLimit_AppliesTo(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	userLimit := Limit{Scope: LimitScopeUser, UserID: &userID, Currency: DefaultCurrency}
+	originLimit := Limit{Scope: LimitScopeOrigin, Origin: OriginDesktopWeb, Currency: DefaultCurrency}
+	globalLimit := Limit{Scope: LimitScopeGlobal, Currency: DefaultCurrency}
+
+	assert.True(t, userLimit.AppliesTo(userID, OriginMobileIOS, DefaultCurrency))
+	assert.False(t, userLimit.AppliesTo(otherUserID, OriginMobileIOS, DefaultCurrency))
+	assert.True(t, originLimit.AppliesTo(otherUserID, OriginDesktopWeb, DefaultCurrency))
+	assert.False(t, originLimit.AppliesTo(userID, OriginMobileAndroid, DefaultCurrency))
+	assert.True(t, globalLimit.AppliesTo(otherUserID, OriginMobileAndroid, DefaultCurrency))
+	assert.False(t, globalLimit.AppliesTo(userID, OriginMobileAndroid, "EUR"))
+}
+
+func TestNewLimitUsage(t *testing.T) {
+	limit := Limit{MaxAmount: 1000}
+
+	assert.Equal(t, int64(400), NewLimitUsage(limit, 600).Remaining)
+	assert.Equal(t, int64(0), NewLimitUsage(limit, 1200).Remaining)
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const (
+	// MaxDescriptionLength is the number of bytes of the description of a transaction
+	MaxDescriptionLength = 255
+	// MaxMetadataKeys is the number of keys the metadata of a transaction may have
+	MaxMetadataKeys = 50
+	// MaxMetadataValueLength is the number of bytes of a metadata value
+	MaxMetadataValueLength = 500
+	// MaxMetadataSize is the number of bytes of the metadata of a transaction encoded as JSON
+	MaxMetadataSize = 8192
+	// MaxTags is the number of tags of a transaction
+	MaxTags = 20
+)
+
+// metadataKey is the format of the keys of the metadata, which are passed as metadata.{key} query parameters
+var metadataKey = regexp.MustCompile(`^[A-Za-z0-9_-]{1,40}$`)
+
+// tagFormat is the format of the tags, such as campaign:summer-2024
+var tagFormat = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_:.-]{0,49}$`)
+
+// IsValidMetadataKey tells whether the key may be used in the metadata of a transaction
+func IsValidMetadataKey(key string) bool {
+	return metadataKey.MatchString(key)
+}
+
+// IsValidTag tells whether the tag may be attached to a transaction
+func IsValidTag(tag string) bool {
+	return tagFormat.MatchString(tag)
+}
+
+// ValidateMetadata returns a ValidationError listing every problem of the description, the metadata and the tags of
+// the transaction
+func (t Transaction) ValidateMetadata() error {
+	var problems []string
+
+	if len(t.Description) > MaxDescriptionLength {
+		problems = append(problems, fmt.Sprintf("description must be at most %d bytes", MaxDescriptionLength))
+	}
+
+	if len(t.Metadata) > MaxMetadataKeys {
+		problems = append(problems, fmt.Sprintf("metadata must have at most %d keys", MaxMetadataKeys))
+	}
+	keys := make([]string, 0, len(t.Metadata))
+	for key := range t.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !IsValidMetadataKey(key) {
+			problems = append(problems, fmt.Sprintf("metadata key %q must be 1 to 40 letters, digits, '_' or '-'", key))
+		}
+		if len(t.Metadata[key]) > MaxMetadataValueLength {
+			problems = append(problems, fmt.Sprintf("metadata value of %q must be at most %d bytes", key, MaxMetadataValueLength))
+		}
+	}
+	if encoded, err := json.Marshal(t.Metadata); err == nil && len(encoded) > MaxMetadataSize {
+		problems = append(problems, fmt.Sprintf("metadata must be at most %d bytes encoded as JSON", MaxMetadataSize))
+	}
+
+	if len(t.Tags) > MaxTags {
+		problems = append(problems, fmt.Sprintf("tags must have at most %d entries", MaxTags))
+	}
+	seen := make(map[string]bool, len(t.Tags))
+	for _, tag := range t.Tags {
+		switch {
+		case !IsValidTag(tag):
+			problems = append(problems, fmt.Sprintf("tag %q must be 1 to 50 letters, digits, '.', '_', ':' or '-', starting with a letter or a digit", tag))
+		case seen[tag]:
+			problems = append(problems, fmt.Sprintf("tag %q is repeated", tag))
+		}
+		seen[tag] = true
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestTransaction_ValidateMetadata(t *testing.T) {
+	tooManyKeys := make(map[string]string, MaxMetadataKeys+1)
+	for i := 0; i <= MaxMetadataKeys; i++ {
+		tooManyKeys[fmt.Sprintf("key_%d", i)] = "value"
+	}
+	tooLarge := make(map[string]string, 20)
+	for i := 0; i < 20; i++ {
+		tooLarge[fmt.Sprintf("key_%d", i)] = strings.Repeat("v", MaxMetadataValueLength)
+	}
+
+	testCases := []struct {
+		name         string
+		transaction  Transaction
+		wantProblems int
+	}{
+		{
+			name:        "A transaction without metadata nor tags",
+			transaction: Transaction{},
+		},
+		{
+			name: "A transaction with metadata and tags",
+			transaction: Transaction{
+				Metadata: map[string]string{"order_id": "A-1001", "merchant-name": "Corner shop"},
+				Tags:     []string{"campaign:summer-2024", "vip"},
+			},
+		},
+		{
+			name:         "A key that cannot be passed as a query parameter as is",
+			transaction:  Transaction{Metadata: map[string]string{"order id": "A-1001"}},
+			wantProblems: 1,
+		},
+		{
+			name:         "A value too long",
+			transaction:  Transaction{Metadata: map[string]string{"note": strings.Repeat("v", MaxMetadataValueLength+1)}},
+			wantProblems: 1,
+		},
+		{
+			name:         "A description too long",
+			transaction:  Transaction{Description: strings.Repeat("d", MaxDescriptionLength+1)},
+			wantProblems: 1,
+		},
+		{
+			name:         "Too many keys",
+			transaction:  Transaction{Metadata: tooManyKeys},
+			wantProblems: 1,
+		},
+		{
+			name:         "Metadata too large once encoded",
+			transaction:  Transaction{Metadata: tooLarge},
+			wantProblems: 1,
+		},
+		{
+			name:         "Every problem of the tags is reported together",
+			transaction:  Transaction{Tags: []string{"vip", "vip", "-summer", strings.Repeat("t", 51)}},
+			wantProblems: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.transaction.ValidateMetadata()
+			if tc.wantProblems == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			require.Len(t, validationErr.Problems, tc.wantProblems)
+		})
+	}
+}
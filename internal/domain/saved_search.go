@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotSavedSearchOwner is returned when a caller other than the owner changes a saved search
+var ErrNotSavedSearchOwner = errors.New("only the owner can change a saved search")
+
+// savedSearchName is the format of the names of saved searches, which are passed as the view query parameter
+var savedSearchName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,62}$`)
+
+// SavedSearch is a named combination of the filters and the sort of the transaction list, applied with
+// GET /v1/transactions?view={name}. Names are unique within a tenant; a search is visible to its owner only unless
+// it is shared with the tenant.
+// swagger:domain SavedSearch
+type SavedSearch struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Owner is the subject of the caller that created the search
+	Owner           string    `json:"owner"`
+	Origin          string    `json:"origin,omitempty"`
+	TransactionType string    `json:"transaction_type,omitempty"`
+	Filter          string    `json:"filter,omitempty"`
+	Sort            string    `json:"sort,omitempty"`
+	Shared          bool      `json:"shared"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Validate returns a ValidationError listing every problem of the name, origin and transaction type of the search.
+// The filter expression and the sort are checked by the service, which parses them.
+func (s SavedSearch) Validate() error {
+	var problems []string
+
+	if !savedSearchName.MatchString(s.Name) {
+		problems = append(problems, "name must be 1 to 63 letters, digits, '.', '_' or '-', starting with a letter or a digit")
+	}
+	if s.Origin != "" && !IsValidOrigin(s.Origin) {
+		problems = append(problems, fmt.Sprintf("origin must be one of %s", strings.Join(Origins, ", ")))
+	}
+	if _, ok := TransactionTypeValue[s.TransactionType]; s.TransactionType != "" && !ok {
+		problems = append(problems, fmt.Sprintf("transaction_type must be one of %s, %s, %s",
+			TransactionTypeCredit, TransactionTypeDebit, TransactionTypeUnspecified))
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// IsVisibleTo tells whether the subject may see and apply the search
+func (s SavedSearch) IsVisibleTo(subject string) bool {
+	return s.Shared || s.Owner == subject
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestSavedSearch_Validate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		search       SavedSearch
+		wantProblems int
+	}{
+		{
+			name:   "A search with a name only",
+			search: SavedSearch{Name: "everything"},
+		},
+		{
+			name:   "A search with every parameter",
+			search: SavedSearch{Name: "Big_debits.v2", Origin: OriginMobileIOS, TransactionType: "DEBIT TRANSACTION", Filter: "amount > 1000", Sort: "-amount"},
+		},
+		{
+			name:         "A search without a name",
+			search:       SavedSearch{},
+			wantProblems: 1,
+		},
+		{
+			name:         "A name that cannot be passed as a query parameter as is",
+			search:       SavedSearch{Name: "big debits"},
+			wantProblems: 1,
+		},
+		{
+			name:         "A name too long",
+			search:       SavedSearch{Name: strings.Repeat("a", 64)},
+			wantProblems: 1,
+		},
+		{
+			name:         "Every problem is reported together",
+			search:       SavedSearch{Name: "-debits", Origin: "smart-tv", TransactionType: "REFUND"},
+			wantProblems: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.search.Validate()
+			if tc.wantProblems == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Len(t, validationErr.Problems, tc.wantProblems)
+		})
+	}
+}
+
+func TestSavedSearch_IsVisibleTo(t *testing.T) {
+	private := SavedSearch{Owner: "analyst"}
+	shared := SavedSearch{Owner: "analyst", Shared: true}
+
+	assert.True(t, private.IsVisibleTo("analyst"))
+	assert.False(t, private.IsVisibleTo("colleague"))
+	assert.True(t, shared.IsVisibleTo("colleague"))
+}
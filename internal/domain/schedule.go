@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/recurrence"
+)
+
+// ScheduleStatus tells whether the occurrences of a schedule are materialized into transactions
+type ScheduleStatus string
+
+const (
+	ScheduleStatusActive ScheduleStatus = "ACTIVE"
+	// ScheduleStatusPaused skips the occurrences until the schedule is resumed
+	ScheduleStatusPaused    ScheduleStatus = "PAUSED"
+	ScheduleStatusCancelled ScheduleStatus = "CANCELLED"
+	// ScheduleStatusCompleted is set once the recurrence has no more occurrences
+	ScheduleStatusCompleted ScheduleStatus = "COMPLETED"
+)
+
+// Metadata keys set on the transactions materialized from a schedule
+const (
+	ScheduleIDMetadataKey   = "schedule_id"
+	ScheduledForMetadataKey = "scheduled_for"
+)
+
+// MaxScheduleOccurrencesPreview is the number of occurrences a preview lists at most
+const MaxScheduleOccurrencesPreview = 100
+
+// ScheduleStartGracePeriod is how far in the past start_at may be, so that a client can start a schedule at its own
+// now despite the clock skew and the network delay. An earlier start would materialize every occurrence since at once.
+const ScheduleStartGracePeriod = 5 * time.Minute
+
+// ErrScheduleTransition is returned when a schedule cannot be paused, resumed or cancelled in its current status
+var ErrScheduleTransition = errors.New("invalid schedule status transition")
+
+// Schedule is a recurring transaction: every occurrence of its cron expression or recurrence rule is materialized into
+// a transaction of the user once, by the scheduler
+// swagger:domain Schedule
+type Schedule struct {
+	ID              uuid.UUID       `json:"id"`
+	UserID          uuid.UUID       `json:"user_id"`
+	Origin          string          `json:"origin"`
+	TransactionType TransactionType `json:"transaction_type"`
+	Amount          int64           `json:"amount"`
+	// Currency is the ISO 4217 code of the amount of the transactions, DefaultCurrency when not set
+	Currency    string            `json:"currency,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	// Exactly one of Cron, e.g. 0 9 1 * *, and RRule, e.g. FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12, describes the
+	// recurrence, evaluated in UTC from StartAt
+	Cron    string         `json:"cron,omitempty"`
+	RRule   string         `json:"rrule,omitempty"`
+	StartAt time.Time      `json:"start_at"`
+	Status  ScheduleStatus `json:"status"`
+	// NextRunAt is the next occurrence to materialize; it is not set once the recurrence has no more occurrences
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	// RunCount is the number of occurrences the scheduler went through, including the ones that failed
+	RunCount int `json:"run_count"`
+	// LastError tells why the last occurrence was not materialized, such as a denial of the risk screening
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// TenantID is the tenant of the schedule, which the scheduler needs as it materializes the schedules of every tenant
+	TenantID string `json:"-"`
+}
+
+// Recurrence parses the cron expression or the recurrence rule of the schedule
+func (s Schedule) Recurrence() (recurrence.Rule, error) {
+	if s.Cron != "" {
+		return recurrence.ParseCron(s.Cron, s.StartAt)
+	}
+	return recurrence.ParseRule(s.RRule, s.StartAt)
+}
+
+// Validate returns a ValidationError listing every problem of the schedule definition, including the ones of the
+// metadata and the tags its transactions would have
+func (s Schedule) Validate() error {
+	var problems []string
+
+	if s.UserID == uuid.Nil {
+		problems = append(problems, "user_id is required")
+	}
+	if !IsValidOrigin(s.Origin) {
+		problems = append(problems, fmt.Sprintf("origin must be one of %s", strings.Join(Origins, ", ")))
+	}
+	if s.TransactionType != TransactionTypeCredit && s.TransactionType != TransactionTypeDebit {
+		problems = append(problems, fmt.Sprintf("transaction_type must be %d for credits or %d for debits",
+			TransactionTypeCredit, TransactionTypeDebit))
+	}
+	if s.Amount <= 0 {
+		problems = append(problems, "amount must be positive")
+	}
+	if !IsValidCurrency(s.Currency) {
+		problems = append(problems, fmt.Sprintf("currency must be one of %s", strings.Join(Currencies(), ", ")))
+	}
+	for _, key := range []string{ScheduleIDMetadataKey, ScheduledForMetadataKey} {
+		if _, ok := s.Metadata[key]; ok {
+			problems = append(problems, fmt.Sprintf("metadata key %q is reserved", key))
+		}
+	}
+	var metadataErr ValidationError
+	if err := s.Transaction(s.StartAt).ValidateMetadata(); errors.As(err, &metadataErr) {
+		problems = append(problems, metadataErr.Problems...)
+	}
+
+	switch {
+	case s.Cron == "" && s.RRule == "":
+		problems = append(problems, "one of cron and rrule is required")
+	case s.Cron != "" && s.RRule != "":
+		problems = append(problems, "cron and rrule cannot both be set")
+	default:
+		if rule, err := s.Recurrence(); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid recurrence: %s", err))
+		} else if _, ok := rule.Next(s.StartAt.Add(-time.Nanosecond)); !ok {
+			problems = append(problems, "the recurrence has no occurrence from start_at on")
+		}
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError(problems)
+	}
+	return nil
+}
+
+// ValidateStart returns a ValidationError when the schedule starts more than ScheduleStartGracePeriod before now
+func (s Schedule) ValidateStart(now time.Time) error {
+	if s.StartAt.Before(now.Add(-ScheduleStartGracePeriod)) {
+		return NewValidationError([]string{fmt.Sprintf("start_at must not be more than %s in the past", ScheduleStartGracePeriod)})
+	}
+	return nil
+}
+
+// FirstRun is the first occurrence of the schedule from its start on, nil when there is none
+func (s Schedule) FirstRun() (*time.Time, error) {
+	return s.NextRun(s.StartAt.Add(-time.Nanosecond))
+}
+
+// NextRun is the first occurrence of the schedule strictly after the time, nil when the recurrence has ended
+func (s Schedule) NextRun(after time.Time) (*time.Time, error) {
+	rule, err := s.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+	next, ok := rule.Next(after)
+	if !ok {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// Preview lists the next occurrences of the schedule, at most count: the ones from its next run while it is active,
+// the ones it would have once resumed at the time while it is paused, and none once it ended
+func (s Schedule) Preview(now time.Time, count int) ([]time.Time, error) {
+	rule, err := s.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case s.Status == ScheduleStatusActive && s.NextRunAt != nil:
+		return recurrence.Occurrences(rule, s.NextRunAt.Add(-time.Nanosecond), count), nil
+	case s.Status == ScheduleStatusPaused:
+		return recurrence.Occurrences(rule, now, count), nil
+	default:
+		return []time.Time{}, nil
+	}
+}
+
+// ScheduleRun records that an occurrence of a schedule was materialized. It is stored with the transaction of the
+// occurrence, and a schedule has a single run per occurrence, so that an occurrence is never materialized twice.
+type ScheduleRun struct {
+	ScheduleID uuid.UUID
+	Occurrence time.Time
+}
+
+// Transaction is the transaction materializing the occurrence of the schedule, carrying the run of the occurrence
+func (s Schedule) Transaction(occurrence time.Time) Transaction {
+	occurrence = occurrence.UTC()
+
+	metadata := make(map[string]string, len(s.Metadata)+2)
+	for key, value := range s.Metadata {
+		metadata[key] = value
+	}
+	metadata[ScheduleIDMetadataKey] = s.ID.String()
+	metadata[ScheduledForMetadataKey] = occurrence.Format(time.RFC3339)
+
+	return Transaction{
+		ID:              uuid.New(),
+		UserID:          s.UserID,
+		Origin:          s.Origin,
+		TransactionType: s.TransactionType,
+		Amount:          s.Amount,
+		Currency:        s.Currency,
+		Description:     s.Description,
+		Metadata:        metadata,
+		Tags:            append([]string(nil), s.Tags...),
+		ScheduleRun:     &ScheduleRun{ScheduleID: s.ID, Occurrence: occurrence},
+	}
+}
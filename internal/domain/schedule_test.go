@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func validSchedule() Schedule {
+	return Schedule{
+		ID:              uuid.MustParse("9b6e6f2c-7f1e-4a8e-9d55-1c1f0b3e6a10"),
+		UserID:          uuid.MustParse("a1b2c3d4-0000-4000-8000-000000000001"),
+		Origin:          OriginDesktopWeb,
+		TransactionType: TransactionTypeDebit,
+		Amount:          999,
+		Currency:        DefaultCurrency,
+		Description:     "Gym membership",
+		RRule:           "FREQ=MONTHLY;BYMONTHDAY=1",
+		StartAt:         time.Date(2024, time.January, 15, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		modify       func(s *Schedule)
+		wantProblems int
+	}{
+		{
+			name:   "A schedule with a recurrence rule",
+			modify: func(s *Schedule) {},
+		},
+		{
+			name:   "A schedule with a cron expression",
+			modify: func(s *Schedule) { s.RRule, s.Cron = "", "0 8 * * MON" },
+		},
+		{
+			name:         "A schedule without recurrence",
+			modify:       func(s *Schedule) { s.RRule = "" },
+			wantProblems: 1,
+		},
+		{
+			name:         "A schedule with both a cron expression and a recurrence rule",
+			modify:       func(s *Schedule) { s.Cron = "0 8 * * MON" },
+			wantProblems: 1,
+		},
+		{
+			name:         "An invalid recurrence rule",
+			modify:       func(s *Schedule) { s.RRule = "FREQ=SECONDLY" },
+			wantProblems: 1,
+		},
+		{
+			name:         "A recurrence that ended before the start",
+			modify:       func(s *Schedule) { s.RRule = "FREQ=DAILY;UNTIL=20231231" },
+			wantProblems: 1,
+		},
+		{
+			name:         "An unsupported currency",
+			modify:       func(s *Schedule) { s.Currency = "XYZ" },
+			wantProblems: 1,
+		},
+		{
+			name:         "A reserved metadata key",
+			modify:       func(s *Schedule) { s.Metadata = map[string]string{ScheduleIDMetadataKey: "x"} },
+			wantProblems: 1,
+		},
+		{
+			name:         "The metadata problems of the transactions",
+			modify:       func(s *Schedule) { s.Metadata = map[string]string{"order id": "A-1"} },
+			wantProblems: 1,
+		},
+		{
+			name: "Every problem is reported together",
+			modify: func(s *Schedule) {
+				s.UserID, s.Origin, s.TransactionType, s.Amount, s.RRule = uuid.Nil, "smart-tv", TransactionTypeUnspecified, 0, ""
+			},
+			wantProblems: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := validSchedule()
+			tc.modify(&schedule)
+
+			err := schedule.Validate()
+			if tc.wantProblems == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var validationErr ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.Len(t, validationErr.Problems, tc.wantProblems)
+		})
+	}
+}
+
+func TestSchedule_ValidateStart(t *testing.T) {
+	schedule := validSchedule()
+
+	require.NoError(t, schedule.ValidateStart(schedule.StartAt.Add(-time.Hour)))
+	require.NoError(t, schedule.ValidateStart(schedule.StartAt.Add(ScheduleStartGracePeriod)))
+
+	err := schedule.ValidateStart(schedule.StartAt.AddDate(1, 0, 0))
+	var validationErr ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{"start_at must not be more than 5m0s in the past"}, validationErr.Problems)
+}
+
+func TestSchedule_FirstRun(t *testing.T) {
+	schedule := validSchedule()
+
+	first, err := schedule.FirstRun()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC), *first)
+
+	schedule.RRule = "FREQ=DAILY;COUNT=1"
+	next, err := schedule.NextRun(schedule.StartAt)
+	require.NoError(t, err)
+	require.Nil(t, next)
+}
+
+func TestSchedule_Preview(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+	nextRun := time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)
+
+	schedule := validSchedule()
+	schedule.Status = ScheduleStatusActive
+	schedule.NextRunAt = &nextRun
+	occurrences, err := schedule.Preview(now, 2)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{nextRun, time.Date(2024, time.April, 1, 8, 0, 0, 0, time.UTC)}, occurrences)
+
+	schedule.Status = ScheduleStatusPaused
+	occurrences, err = schedule.Preview(now, 2)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{time.Date(2024, time.June, 1, 8, 0, 0, 0, time.UTC), time.Date(2024, time.July, 1, 8, 0, 0, 0, time.UTC)}, occurrences)
+
+	schedule.Status = ScheduleStatusCancelled
+	occurrences, err = schedule.Preview(now, 2)
+	require.NoError(t, err)
+	require.Empty(t, occurrences)
+}
+
+func TestSchedule_Transaction(t *testing.T) {
+	schedule := validSchedule()
+	schedule.Metadata = map[string]string{"plan": "gold"}
+	occurrence := time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+
+	transaction := schedule.Transaction(occurrence)
+	require.Equal(t, schedule.UserID, transaction.UserID)
+	require.Equal(t, TransactionTypeDebit, transaction.TransactionType)
+	require.Equal(t, int64(999), transaction.Amount)
+	require.Equal(t, DefaultCurrency, transaction.Currency)
+	require.Equal(t, map[string]string{
+		"plan":                  "gold",
+		ScheduleIDMetadataKey:   schedule.ID.String(),
+		ScheduledForMetadataKey: "2024-02-01T08:00:00Z",
+	}, transaction.Metadata)
+	require.Len(t, schedule.Metadata, 1, "the metadata of the schedule is left untouched")
+
+	// the run identifies the occurrence, whatever the time zone it is given in, rather than the ID any client could
+	// also choose
+	require.Equal(t, &ScheduleRun{ScheduleID: schedule.ID, Occurrence: occurrence}, transaction.ScheduleRun)
+	require.Equal(t, transaction.ScheduleRun, schedule.Transaction(occurrence.In(time.FixedZone("UTC+2", 2*3600))).ScheduleRun)
+	require.NotEqual(t, transaction.ID, schedule.Transaction(occurrence).ID)
+}
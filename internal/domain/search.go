@@ -0,0 +1,14 @@
+package domain
+
+// SearchableMetadataKeys are the metadata keys whose values are searched along with the description; migration 012
+// indexes the same keys
+var SearchableMetadataKeys = []string{"merchant", "reference"}
+
+// SearchMatch describes how a transaction listed by a search matches it
+type SearchMatch struct {
+	// Rank is the relevance of the transaction, higher first: words of the description weigh more than words of the
+	// metadata. Ranks only compare within the results of a search.
+	Rank float64 `json:"rank"`
+	// Snippet is an excerpt of the searched text, with the matching words between <b> and </b>
+	Snippet string `json:"snippet"`
+}
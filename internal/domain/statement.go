@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatementPeriodLayout is the layout of the period path parameter (yyyy-mm)
+const StatementPeriodLayout = "2006-01"
+
+var ErrInvalidStatementPeriod = errors.New("invalid statement period, expected yyyy-mm")
+
+// StatementEntry is a single credit or debit in a statement together with the balance after it was applied
+type StatementEntry struct {
+	Transaction    Transaction `json:"transaction"`
+	Credit         int64       `json:"credit"`
+	Debit          int64       `json:"debit"`
+	RunningBalance int64       `json:"running_balance"`
+}
+
+// Statement represents the monthly statement of a user in one currency
+// swagger:domain Statement
+type Statement struct {
+	UserID         uuid.UUID        `json:"user_id"`
+	Currency       string           `json:"currency"`
+	Period         string           `json:"period"`
+	PeriodStart    time.Time        `json:"period_start"`
+	PeriodEnd      time.Time        `json:"period_end"`
+	OpeningBalance int64            `json:"opening_balance"`
+	Entries        []StatementEntry `json:"entries"`
+	TotalCredits   int64            `json:"total_credits"`
+	TotalDebits    int64            `json:"total_debits"`
+	ClosingBalance int64            `json:"closing_balance"`
+}
+
+// SignedAmount returns the effect of the transaction on the user balance:
+// credits increase it, debits decrease it and unspecified transactions leave it untouched.
+func (t Transaction) SignedAmount() int64 {
+	switch t.TransactionType {
+	case TransactionTypeCredit:
+		return t.Amount
+	case TransactionTypeDebit:
+		return -t.Amount
+	default:
+		return 0
+	}
+}
+
+// ParseStatementPeriod parses a yyyy-mm period and returns its bounds in UTC.
+// The start is inclusive and the end is exclusive.
+func ParseStatementPeriod(period string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(StatementPeriodLayout, period, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// NewStatement builds a statement from the opening balance and the transactions of the period in the currency.
+// Transactions are expected to be sorted by creation time; every rendering of the statement
+// must be derived from the returned value so that all formats show the same numbers.
+func NewStatement(userID uuid.UUID, currency, period string, openingBalance int64, transactions []Transaction) (*Statement, error) {
+	start, end, err := ParseStatementPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{
+		UserID:         userID,
+		Currency:       currency,
+		Period:         period,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: openingBalance,
+		Entries:        make([]StatementEntry, 0, len(transactions)),
+	}
+
+	balance := openingBalance
+	for _, transaction := range transactions {
+		entry := StatementEntry{Transaction: transaction}
+		switch transaction.TransactionType {
+		case TransactionTypeCredit:
+			entry.Credit = transaction.Amount
+			statement.TotalCredits += transaction.Amount
+		case TransactionTypeDebit:
+			entry.Debit = transaction.Amount
+			statement.TotalDebits += transaction.Amount
+		}
+		balance += transaction.SignedAmount()
+		entry.RunningBalance = balance
+		statement.Entries = append(statement.Entries, entry)
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
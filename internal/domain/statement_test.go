@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseStatementPeriod(t *testing.T) {
+	t.Run("A valid period", func(t *testing.T) {
+		start, end, err := ParseStatementPeriod("2026-02")
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC), start)
+		assert.Equal(t, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), end)
+	})
+
+	t.Run("An invalid period", func(t *testing.T) {
+		_, _, err := ParseStatementPeriod("2026-13")
+
+		assert.ErrorIs(t, err, ErrInvalidStatementPeriod)
+	})
+}
+
+func TestNewStatement(t *testing.T) {
+	userID := uuid.New()
+	createdAt := time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC)
+
+	transactions := []Transaction{
+		{ID: uuid.New(), UserID: userID, TransactionType: TransactionTypeCredit, Amount: 1000, CreatedAt: createdAt},
+		{ID: uuid.New(), UserID: userID, TransactionType: TransactionTypeDebit, Amount: 300, CreatedAt: createdAt.Add(time.Hour)},
+		{ID: uuid.New(), UserID: userID, TransactionType: TransactionTypeUnspecified, Amount: 50, CreatedAt: createdAt.Add(2 * time.Hour)},
+		{ID: uuid.New(), UserID: userID, TransactionType: TransactionTypeDebit, Amount: 200, CreatedAt: createdAt.Add(3 * time.Hour)},
+	}
+
+	statement, err := NewStatement(userID, "EUR", "2026-01", 500, transactions)
+	require.NoError(t, err)
+
+	assert.Equal(t, "EUR", statement.Currency)
+	assert.Equal(t, int64(500), statement.OpeningBalance)
+	assert.Equal(t, int64(1000), statement.TotalCredits)
+	assert.Equal(t, int64(500), statement.TotalDebits)
+	assert.Equal(t, int64(1000), statement.ClosingBalance)
+
+	require.Len(t, statement.Entries, 4)
+	assert.Equal(t, []int64{1500, 1200, 1200, 1000}, []int64{
+		statement.Entries[0].RunningBalance,
+		statement.Entries[1].RunningBalance,
+		statement.Entries[2].RunningBalance,
+		statement.Entries[3].RunningBalance,
+	})
+	assert.Equal(t, int64(300), statement.Entries[1].Debit)
+	assert.Equal(t, int64(0), statement.Entries[1].Credit)
+}
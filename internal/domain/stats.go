@@ -0,0 +1,60 @@
+package domain
+
+// Dimensions the transaction stats are grouped by
+const (
+	StatsByCategory        = "category"
+	StatsByOrigin          = "origin"
+	StatsByTransactionType = "transaction_type"
+	StatsByStatus          = "status"
+)
+
+var StatsDimensions = []string{StatsByCategory, StatsByOrigin, StatsByTransactionType, StatsByStatus}
+
+// TransactionStats aggregates the transactions sharing the value of a dimension. The key of the uncategorized
+// transactions is empty when they are grouped by category.
+// swagger:domain TransactionStats
+type TransactionStats struct {
+	Key     string `json:"key"`
+	Count   int64  `json:"count"`
+	Credits int64  `json:"credits"`
+	Debits  int64  `json:"debits"`
+}
+
+func IsValidStatsDimension(dimension string) bool {
+	for _, d := range StatsDimensions {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// StatsKey returns the value of the dimension for the transaction
+func (t Transaction) StatsKey(dimension string) string {
+	switch dimension {
+	case StatsByCategory:
+		if t.CategoryID == nil {
+			return ""
+		}
+		return t.CategoryID.String()
+	case StatsByOrigin:
+		return t.Origin
+	case StatsByTransactionType:
+		return GetTransactionTypeName(t.TransactionType)
+	case StatsByStatus:
+		return string(t.Status)
+	default:
+		return ""
+	}
+}
+
+// Add counts the transaction in the stats
+func (s *TransactionStats) Add(t Transaction) {
+	s.Count++
+	switch t.TransactionType {
+	case TransactionTypeCredit:
+		s.Credits += t.Amount
+	case TransactionTypeDebit:
+		s.Debits += t.Amount
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTransaction_StatsKey(t *testing.T) {
+	categoryID := uuid.New()
+	transaction := Transaction{Origin: OriginMobileIOS, TransactionType: TransactionTypeDebit, Status: TransactionStatusCompleted, CategoryID: &categoryID}
+
+	require.Equal(t, categoryID.String(), transaction.StatsKey(StatsByCategory))
+	require.Equal(t, OriginMobileIOS, transaction.StatsKey(StatsByOrigin))
+	require.Equal(t, "DEBIT TRANSACTION", transaction.StatsKey(StatsByTransactionType))
+	require.Equal(t, "COMPLETED", transaction.StatsKey(StatsByStatus))
+	require.Equal(t, "", Transaction{}.StatsKey(StatsByCategory))
+}
+
+func TestTransactionStats_Add(t *testing.T) {
+	var stats TransactionStats
+	stats.Add(Transaction{TransactionType: TransactionTypeCredit, Amount: 100})
+	stats.Add(Transaction{TransactionType: TransactionTypeDebit, Amount: 30})
+	stats.Add(Transaction{TransactionType: TransactionTypeUnspecified, Amount: 5})
+
+	require.Equal(t, TransactionStats{Count: 3, Credits: 100, Debits: 30}, stats)
+}
@@ -29,6 +29,14 @@ func (s TransactionType) String() string {
 	return [...]string{"TYPE_UNSPECIFIED", "CREDIT TRANSACTION", "DEBIT TRANSACTION"}[s]
 }
 
+// TransactionStatus tells whether a transaction was accepted straight away or flagged for manual review
+type TransactionStatus string
+
+const (
+	TransactionStatusCompleted     TransactionStatus = "COMPLETED"
+	TransactionStatusPendingReview TransactionStatus = "PENDING_REVIEW"
+)
+
 // Transaction represents a money transaction
 // swagger:domain Transaction
 type Transaction struct {
@@ -37,7 +45,25 @@ type Transaction struct {
 	Origin          string          `json:"origin"`
 	TransactionType TransactionType `json:"transaction_type" validate:"required,oneof=0 1 2"`
 	Amount          int64           `json:"amount" validate:"required"`
-	CreatedAt       time.Time       `json:"created_at"`
+	// Currency is the ISO 4217 code of the amount, which is in its minor units; DefaultCurrency when not set
+	Currency  string    `json:"currency,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Status and FlaggedRules are set by the risk screening when the transaction is created
+	Status       TransactionStatus `json:"status,omitempty"`
+	FlaggedRules []string          `json:"flagged_rules,omitempty"`
+	// Description, Metadata and Tags carry the context of the client, such as order IDs, merchant names or campaign codes
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	// CategoryID is set by the first matching categorization rule when the transaction is created, or overridden
+	// manually; CategorizedBy records the rule, as rule:{id}, or the subject of the caller that set it
+	CategoryID    *uuid.UUID `json:"category_id,omitempty"`
+	CategorizedBy string     `json:"categorized_by,omitempty"`
+	CategorizedAt *time.Time `json:"categorized_at,omitempty"`
+	// Match is only set on the transactions listed by a search
+	Match *SearchMatch `json:"match,omitempty"`
+	// ScheduleRun is only set on the transactions the scheduler materializes, and stored along with them
+	ScheduleRun *ScheduleRun `json:"-"`
 }
 
 func IsValidTransactionType(tType TransactionType) bool {
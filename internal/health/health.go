@@ -0,0 +1,150 @@
+// Package health reports the liveness and readiness of the API and the state of its dependencies
+package health
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a report
+const (
+	StatusOK           = "ok"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckFunc checks a dependency, returning an error when it cannot be used
+type CheckFunc func(ctx context.Context) error
+
+// Dependency is a service the API needs to serve requests, such as its database
+type Dependency struct {
+	Name  string
+	Check CheckFunc
+}
+
+// BuildInfo describes the running build
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// NewBuildInfo describes the running build of the version, with the commit it was built from when known
+func NewBuildInfo(version string) BuildInfo {
+	build := BuildInfo{Version: version, GoVersion: runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				build.Commit = setting.Value
+			}
+		}
+	}
+	return build
+}
+
+// DependencyStatus is the outcome of the check of a dependency
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Healthy   bool    `json:"healthy"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the readiness of the API
+type Report struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// Status is the readiness of the API together with the description of the running build
+type Status struct {
+	Report
+	Build         BuildInfo `json:"build"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+}
+
+// Checker checks the dependencies of the API. Once shutting down, the API is reported as not ready,
+// so that orchestrators stop routing requests to it while in-flight requests drain.
+type Checker struct {
+	build        BuildInfo
+	timeout      time.Duration
+	dependencies []Dependency
+	startedAt    time.Time
+	shuttingDown atomic.Bool
+	now          func() time.Time
+}
+
+// NewChecker builds a checker whose dependency checks are cancelled after the timeout
+func NewChecker(build BuildInfo, timeout time.Duration, dependencies ...Dependency) *Checker {
+	return &Checker{
+		build:        build,
+		timeout:      timeout,
+		dependencies: dependencies,
+		startedAt:    time.Now(),
+		now:          time.Now,
+	}
+}
+
+// SetShuttingDown makes the API report itself as not ready
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready checks every dependency concurrently and tells whether the API can serve requests
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	report := Report{Status: StatusOK, Dependencies: c.checkDependencies(ctx)}
+	for _, dependency := range report.Dependencies {
+		if !dependency.Healthy {
+			report.Status = StatusUnavailable
+		}
+	}
+	if c.shuttingDown.Load() {
+		report.Status = StatusShuttingDown
+	}
+	return report, report.Status == StatusOK
+}
+
+// Status reports the readiness of the API, its build and uptime
+func (c *Checker) Status(ctx context.Context) Status {
+	report, _ := c.Ready(ctx)
+	return Status{
+		Report:        report,
+		Build:         c.build,
+		StartedAt:     c.startedAt,
+		UptimeSeconds: c.now().Sub(c.startedAt).Seconds(),
+	}
+}
+
+func (c *Checker) checkDependencies(ctx context.Context) []DependencyStatus {
+	statuses := make([]DependencyStatus, len(c.dependencies))
+
+	var wg sync.WaitGroup
+	for i, dependency := range c.dependencies {
+		wg.Add(1)
+		go func(i int, dependency Dependency) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := dependency.Check(ctx)
+			statuses[i] = DependencyStatus{
+				Name:      dependency.Name,
+				Healthy:   err == nil,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				statuses[i].Error = err.Error()
+			}
+		}(i, dependency)
+	}
+	wg.Wait()
+
+	return statuses
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	healthy := Dependency{Name: "healthy", Check: func(context.Context) error { return nil }}
+	failing := Dependency{Name: "failing", Check: func(context.Context) error { return errors.New("connection refused") }}
+	slow := Dependency{Name: "slow", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	tests := map[string]struct {
+		dependencies []Dependency
+		shuttingDown bool
+		wantStatus   string
+		wantErrors   map[string]string
+	}{
+		"it is ready when every dependency is healthy": {
+			dependencies: []Dependency{healthy},
+			wantStatus:   StatusOK,
+			wantErrors:   map[string]string{"healthy": ""},
+		},
+		"it is not ready when a dependency fails": {
+			dependencies: []Dependency{healthy, failing},
+			wantStatus:   StatusUnavailable,
+			wantErrors:   map[string]string{"healthy": "", "failing": "connection refused"},
+		},
+		"it gives up on dependencies that do not answer in time": {
+			dependencies: []Dependency{slow},
+			wantStatus:   StatusUnavailable,
+			wantErrors:   map[string]string{"slow": context.DeadlineExceeded.Error()},
+		},
+		"it is not ready while shutting down": {
+			dependencies: []Dependency{healthy},
+			shuttingDown: true,
+			wantStatus:   StatusShuttingDown,
+			wantErrors:   map[string]string{"healthy": ""},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			checker := NewChecker(BuildInfo{}, 10*time.Millisecond, tc.dependencies...)
+			if tc.shuttingDown {
+				checker.SetShuttingDown()
+			}
+
+			report, ready := checker.Ready(context.Background())
+			require.Equal(t, tc.wantStatus, report.Status)
+			require.Equal(t, tc.wantStatus == StatusOK, ready)
+
+			gotErrors := map[string]string{}
+			for _, dependency := range report.Dependencies {
+				require.Equal(t, dependency.Error == "", dependency.Healthy)
+				gotErrors[dependency.Name] = dependency.Error
+			}
+			require.Equal(t, tc.wantErrors, gotErrors)
+		})
+	}
+}
+
+func TestChecker_Status(t *testing.T) {
+	checker := NewChecker(NewBuildInfo("1.2.3"), time.Second)
+	checker.now = func() time.Time { return checker.startedAt.Add(90 * time.Second) }
+
+	status := checker.Status(context.Background())
+	require.Equal(t, StatusOK, status.Status)
+	require.Equal(t, "1.2.3", status.Build.Version)
+	require.NotEmpty(t, status.Build.GoVersion)
+	require.Equal(t, 90.0, status.UptimeSeconds)
+}
@@ -0,0 +1,189 @@
+// Package ratelimit implements token bucket rate limiting with pluggable bucket stores
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"time"
+)
+
+// Class separates the limits of read routes from the limits of write routes
+type Class string
+
+const (
+	ClassRead  Class = "read"
+	ClassWrite Class = "write"
+)
+
+// ClassOf classifies a request by its method: safe methods are reads, every other method is a write
+func ClassOf(method string) Class {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ClassRead
+	default:
+		return ClassWrite
+	}
+}
+
+// KeyBy tells what identifies the caller owning a bucket
+type KeyBy string
+
+const (
+	// KeyByCaller gives a bucket to each API key and each user, falling back to the IP address of anonymous callers
+	KeyByCaller KeyBy = "caller"
+	// KeyByIP gives a bucket to each IP address, whoever the caller
+	KeyByIP KeyBy = "ip"
+)
+
+func ParseKeyBy(value string) (KeyBy, error) {
+	switch KeyBy(value) {
+	case KeyByCaller, KeyByIP:
+		return KeyBy(value), nil
+	default:
+		return "", fmt.Errorf("unknown rate limit key %q", value)
+	}
+}
+
+// TrustedProxies are the IP ranges of the load balancers and proxies in front of the API, which are trusted to
+// forward the IP address of the client in the X-Forwarded-For header
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges; an IP address is a range of a single address
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, value := range values {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: must be an IP address or a CIDR range", value)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// Contains tells whether the address is the one of a trusted proxy
+func (p TrustedProxies) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Limit is a token bucket refilled at Rate tokens per second and holding at most Burst tokens.
+// A zero limit disables rate limiting.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute builds a limit allowing requests per minute on average and bursts of up to burst requests
+func PerMinute(requests, burst int) Limit {
+	return Limit{Rate: float64(requests) / 60, Burst: burst}
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	// Limit is the capacity of the bucket
+	Limit int
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// ResetAfter is the time until the bucket is full again
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next token is available, when the request was not allowed
+	RetryAfter time.Duration
+}
+
+// Bucket is the state of a token bucket as persisted by the stores
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time elapsed since it was last updated, then takes a token from it if one is
+// available. A zero bucket is a full bucket.
+func Take(bucket Bucket, limit Limit, now time.Time) (Bucket, Result) {
+	burst := float64(limit.Burst)
+
+	tokens := burst
+	if !bucket.UpdatedAt.IsZero() {
+		elapsed := math.Max(0, now.Sub(bucket.UpdatedAt).Seconds())
+		tokens = math.Min(burst, bucket.Tokens+elapsed*limit.Rate)
+	}
+
+	result := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.ResetAfter = seconds((burst - tokens) / limit.Rate)
+
+	return Bucket{Tokens: tokens, UpdatedAt: now}, result
+}
+
+// IdleFor returns how long a bucket takes to refill completely; buckets idle for longer are equivalent to
+// missing buckets and can be evicted
+func (l Limit) IdleFor() time.Duration {
+	return seconds(float64(l.Burst) / l.Rate)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// Store keeps the buckets of every key
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limiter applies separate limits to read and write requests
+type Limiter struct {
+	store Store
+	read  Limit
+	write Limit
+	keyBy KeyBy
+}
+
+func NewLimiter(store Store, read, write Limit, keyBy KeyBy) *Limiter {
+	return &Limiter{
+		store: store,
+		read:  read,
+		write: write,
+		keyBy: keyBy,
+	}
+}
+
+func (l *Limiter) KeyBy() KeyBy {
+	return l.keyBy
+}
+
+// Take takes a token from the bucket of the key for the class of request. The buckets of read and write requests
+// are separate. Requests of a class without limit are always allowed, with a zero Result.Limit.
+func (l *Limiter) Take(ctx context.Context, class Class, key string) (Result, error) {
+	limit := l.read
+	if class == ClassWrite {
+		limit = l.write
+	}
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	return l.store.Take(ctx, string(class)+":"+key, limit)
+}
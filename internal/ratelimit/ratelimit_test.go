@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limit := PerMinute(60, 3)
+
+	tests := map[string]struct {
+		bucket     Bucket
+		wantBucket Bucket
+		wantResult Result
+	}{
+		"it takes a token from a missing bucket, which is full": {
+			wantBucket: Bucket{Tokens: 2, UpdatedAt: now},
+			wantResult: Result{Allowed: true, Limit: 3, Remaining: 2, ResetAfter: time.Second},
+		},
+		"it refills the bucket for the elapsed time": {
+			bucket:     Bucket{Tokens: 0.5, UpdatedAt: now.Add(-time.Second)},
+			wantBucket: Bucket{Tokens: 0.5, UpdatedAt: now},
+			wantResult: Result{Allowed: true, Limit: 3, Remaining: 0, ResetAfter: 2500 * time.Millisecond},
+		},
+		"it does not refill the bucket beyond its burst": {
+			bucket:     Bucket{Tokens: 1, UpdatedAt: now.Add(-time.Hour)},
+			wantBucket: Bucket{Tokens: 2, UpdatedAt: now},
+			wantResult: Result{Allowed: true, Limit: 3, Remaining: 2, ResetAfter: time.Second},
+		},
+		"it rejects the request when the bucket is empty and tells when to retry": {
+			bucket:     Bucket{Tokens: 0.25, UpdatedAt: now},
+			wantBucket: Bucket{Tokens: 0.25, UpdatedAt: now},
+			wantResult: Result{Limit: 3, Remaining: 0, ResetAfter: 2750 * time.Millisecond, RetryAfter: 750 * time.Millisecond},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			bucket, result := Take(tc.bucket, limit, now)
+			require.Equal(t, tc.wantBucket, bucket)
+			require.Equal(t, tc.wantResult, result)
+		})
+	}
+}
+
+func TestLimiter_Take(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limiter := NewLimiter(store, PerMinute(60, 2), PerMinute(60, 1), KeyByCaller)
+	ctx := context.Background()
+
+	take := func(class Class, key string) Result {
+		result, err := limiter.Take(ctx, class, key)
+		require.NoError(t, err)
+		return result
+	}
+
+	require.True(t, take(ClassWrite, "user:1").Allowed)
+	require.False(t, take(ClassWrite, "user:1").Allowed, "the write bucket should be empty")
+	require.True(t, take(ClassRead, "user:1").Allowed, "reads should not be limited by writes")
+	require.True(t, take(ClassWrite, "user:2").Allowed, "callers should not share buckets")
+
+	now = now.Add(time.Second)
+	require.True(t, take(ClassWrite, "user:1").Allowed, "the write bucket should have refilled")
+
+	unlimited := NewLimiter(store, Limit{}, PerMinute(60, 1), KeyByCaller)
+	for i := 0; i < 10; i++ {
+		result, err := unlimited.Take(ctx, ClassRead, "user:1")
+		require.NoError(t, err)
+		require.Equal(t, Result{Allowed: true}, result)
+	}
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	_, err := store.Take(context.Background(), "idle", PerMinute(60, 1))
+	require.NoError(t, err)
+
+	now = now.Add(sweepInterval)
+	_, err = store.Take(context.Background(), "active", PerMinute(60, 1))
+	require.NoError(t, err)
+
+	require.NotContains(t, store.buckets, "idle")
+	require.Contains(t, store.buckets, "active")
+}
+
+func TestClassOf(t *testing.T) {
+	require.Equal(t, ClassRead, ClassOf("GET"))
+	require.Equal(t, ClassRead, ClassOf("HEAD"))
+	require.Equal(t, ClassWrite, ClassOf("POST"))
+	require.Equal(t, ClassWrite, ClassOf("DELETE"))
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	require.True(t, proxies.Contains(netip.MustParseAddr("10.1.2.3")))
+	require.True(t, proxies.Contains(netip.MustParseAddr("::ffff:10.1.2.3")))
+	require.True(t, proxies.Contains(netip.MustParseAddr("192.0.2.1")))
+	require.False(t, proxies.Contains(netip.MustParseAddr("192.0.2.2")))
+	require.True(t, proxies.Contains(netip.MustParseAddr("2001:db8::5")))
+
+	_, err = ParseTrustedProxies([]string{"load-balancer"})
+	require.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package recurrence
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseCron_Occurrences(t *testing.T) {
+	start := date(2024, time.January, 1, 0, 0)
+
+	tests := map[string]struct {
+		expr  string
+		after time.Time
+		want  []time.Time
+	}{
+		"it matches every step of a field": {
+			expr:  "*/20 * * * *",
+			after: date(2024, time.March, 4, 10, 35),
+			want:  []time.Time{date(2024, time.March, 4, 10, 40), date(2024, time.March, 4, 11, 0), date(2024, time.March, 4, 11, 20)},
+		},
+		"it matches the days of the week by name": {
+			expr:  "30 9 * * MON-FRI",
+			after: date(2024, time.March, 8, 9, 30),
+			want:  []time.Time{date(2024, time.March, 11, 9, 30), date(2024, time.March, 12, 9, 30), date(2024, time.March, 13, 9, 30)},
+		},
+		"it matches a day of the month or a day of the week when both are restricted": {
+			expr:  "0 0 15 * 7",
+			after: date(2024, time.March, 9, 12, 0),
+			want:  []time.Time{date(2024, time.March, 10, 0, 0), date(2024, time.March, 15, 0, 0), date(2024, time.March, 17, 0, 0)},
+		},
+		"it matches lists and months by name": {
+			expr:  "0 8 1 jan,jul *",
+			after: date(2024, time.February, 1, 0, 0),
+			want:  []time.Time{date(2024, time.July, 1, 8, 0), date(2025, time.January, 1, 8, 0), date(2025, time.July, 1, 8, 0)},
+		},
+		"it matches the shorthands": {
+			expr:  "@monthly",
+			after: date(2024, time.January, 31, 0, 0),
+			want:  []time.Time{date(2024, time.February, 1, 0, 0), date(2024, time.March, 1, 0, 0), date(2024, time.April, 1, 0, 0)},
+		},
+		"it finds leap days": {
+			expr:  "0 12 29 2 *",
+			after: date(2024, time.March, 1, 0, 0),
+			want:  []time.Time{date(2028, time.February, 29, 12, 0), date(2032, time.February, 29, 12, 0), date(2036, time.February, 29, 12, 0)},
+		},
+		"it starts at the start": {
+			expr:  "@daily",
+			after: date(2023, time.June, 1, 0, 0),
+			want:  []time.Time{date(2024, time.January, 1, 0, 0), date(2024, time.January, 2, 0, 0), date(2024, time.January, 3, 0, 0)},
+		},
+		"it has no occurrence on a day that does not exist": {
+			expr:  "0 0 30 2 *",
+			after: start,
+			want:  []time.Time{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule, err := ParseCron(tc.expr, start)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, Occurrences(rule, tc.after, 3))
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"it rejects a missing field":      "0 9 * *",
+		"it rejects a value out of range": "0 24 * * *",
+		"it rejects a reversed range":     "0 9 * * FRI-MON",
+		"it rejects a zero step":          "*/0 * * * *",
+		"it rejects an unknown name":      "0 9 * FOO *",
+		"it rejects an unknown macro":     "@fortnightly",
+	}
+
+	for name, expr := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseCron(expr, time.Time{})
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Package recurrence computes the occurrences of the recurring schedules, described either by a cron expression or
+// by an RFC 5545 recurrence rule. Every time is evaluated in UTC, to the minute.
+package recurrence
+
+import "time"
+
+// Rule is a recurrence whose occurrences start at a given time
+type Rule interface {
+	// Next returns the first occurrence strictly after the time, and false when the rule has no more occurrences
+	Next(after time.Time) (time.Time, bool)
+}
+
+// Occurrences returns the first n occurrences of the rule strictly after the time, fewer when the rule ends before
+func Occurrences(rule Rule, after time.Time, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	for len(occurrences) < n {
+		next, ok := rule.Next(after)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next)
+		after = next
+	}
+	return occurrences
+}
+
+// firstMinute is the first whole minute of t in UTC that is not before it
+func firstMinute(t time.Time) time.Time {
+	t = t.UTC()
+	truncated := t.Truncate(time.Minute)
+	if truncated.Before(t) {
+		return truncated.Add(time.Minute)
+	}
+	return truncated
+}
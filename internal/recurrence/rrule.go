@@ -0,0 +1,358 @@
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ruleSearchYears bounds the search of the next occurrence past the time asked for, so that rules that never match,
+// such as the 30th of February, end instead of looping forever
+const ruleSearchYears = 10
+
+type frequency int
+
+const (
+	daily frequency = iota
+	weekly
+	monthly
+	yearly
+)
+
+var frequencies = map[string]frequency{"DAILY": daily, "WEEKLY": weekly, "MONTHLY": monthly, "YEARLY": yearly}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// weekdayNum is a BYDAY value: a day of the week, and its rank in the month or year when n is not 0, e.g. -1FR for
+// the last Friday
+type weekdayNum struct {
+	weekday time.Weekday
+	n       int
+}
+
+// rrule is a recurrence rule of RFC 5545. Its occurrences are generated period by period, a period being the day, the
+// week, the month or the year of the frequency, every interval periods from the one of the start.
+type rrule struct {
+	start      time.Time
+	freq       frequency
+	interval   int
+	count      int
+	until      time.Time
+	weekStart  time.Weekday
+	byMonth    []int
+	byMonthDay []int
+	byDay      []weekdayNum
+	byHour     []int
+	byMinute   []int
+}
+
+// ParseRule parses an RFC 5545 recurrence rule, with or without its RRULE: prefix, e.g.
+// FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12. It supports the DAILY, WEEKLY, MONTHLY and YEARLY frequencies with INTERVAL,
+// COUNT, UNTIL, WKST, BYMONTH, BYMONTHDAY, BYDAY, BYHOUR and BYMINUTE. Start plays the role of DTSTART: it is the
+// first time the rule is evaluated from, and gives the unit of time the rule does not set, such as the hour and
+// minute of the occurrences when there is no BYHOUR nor BYMINUTE. Start is only an occurrence when it matches the rule.
+func ParseRule(rule string, start time.Time) (Rule, error) {
+	r := rrule{start: firstMinute(start), interval: 1, weekStart: time.Monday}
+
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, fmt.Errorf("recurrence rule must not be empty")
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part %q, expected NAME=VALUE", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s must not be repeated", name)
+		}
+		seen[name] = true
+
+		if err := r.set(name, strings.ToUpper(value)); err != nil {
+			return nil, err
+		}
+	}
+
+	if !seen["FREQ"] {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return nil, fmt.Errorf("COUNT and UNTIL must not both be set")
+	}
+	if r.freq == weekly && len(r.byMonthDay) > 0 {
+		return nil, fmt.Errorf("BYMONTHDAY must not be set with FREQ=WEEKLY")
+	}
+	if r.freq == daily || r.freq == weekly {
+		for _, day := range r.byDay {
+			if day.n != 0 {
+				return nil, fmt.Errorf("BYDAY must not have a rank with FREQ=DAILY or FREQ=WEEKLY")
+			}
+		}
+	}
+	return r, nil
+}
+
+// set sets the part of the rule
+func (r *rrule) set(name, value string) error {
+	var err error
+	switch name {
+	case "FREQ":
+		freq, ok := frequencies[value]
+		if !ok {
+			return fmt.Errorf("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY, got %q", value)
+		}
+		r.freq = freq
+	case "INTERVAL":
+		r.interval, err = strconv.Atoi(value)
+		if err != nil || r.interval < 1 {
+			return fmt.Errorf("INTERVAL must be a positive integer, got %q", value)
+		}
+	case "COUNT":
+		r.count, err = strconv.Atoi(value)
+		if err != nil || r.count < 1 {
+			return fmt.Errorf("COUNT must be a positive integer, got %q", value)
+		}
+	case "UNTIL":
+		r.until, err = parseUntil(value)
+		if err != nil {
+			return err
+		}
+	case "WKST":
+		weekday, ok := weekdays[value]
+		if !ok {
+			return fmt.Errorf("WKST must be a day of the week such as MO, got %q", value)
+		}
+		r.weekStart = weekday
+	case "BYMONTH":
+		r.byMonth, err = parseInts(name, value, 1, 12, false)
+	case "BYMONTHDAY":
+		r.byMonthDay, err = parseInts(name, value, 1, 31, true)
+	case "BYHOUR":
+		r.byHour, err = parseInts(name, value, 0, 23, false)
+	case "BYMINUTE":
+		r.byMinute, err = parseInts(name, value, 0, 59, false)
+	case "BYDAY":
+		r.byDay, err = parseByDay(value)
+	default:
+		return fmt.Errorf("%s is not supported", name)
+	}
+	return err
+}
+
+// parseUntil parses the UTC date-time, or the date, of UNTIL; a date includes the whole day
+func parseUntil(value string) (time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, nil
+	}
+	if until, err := time.Parse("20060102", value); err == nil {
+		return until.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return time.Time{}, fmt.Errorf("UNTIL must be a UTC date-time such as 20251231T235959Z or a date such as 20251231, got %q", value)
+}
+
+// parseInts parses the comma separated values of the part, within min and max, or within -max and -min when negative
+// values are allowed
+func parseInts(name, value string, min, max int, negative bool) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		inRange := n >= min && n <= max || negative && n <= -min && n >= -max
+		if err != nil || !inRange {
+			return nil, fmt.Errorf("invalid %s value %q", name, item)
+		}
+		values = append(values, n)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// parseByDay parses the days of the week of BYDAY, optionally ranked, e.g. MO,WE or 1MO,-1FR
+func parseByDay(value string) ([]weekdayNum, error) {
+	var days []weekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY value %q", item)
+		}
+		weekday, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY value %q", item)
+		}
+
+		day := weekdayNum{weekday: weekday}
+		if rank := item[:len(item)-2]; rank != "" {
+			n, err := strconv.Atoi(rank)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid BYDAY value %q", item)
+			}
+			day.n = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func (r rrule) Next(after time.Time) (time.Time, bool) {
+	horizon := after
+	if horizon.Before(r.start) {
+		horizon = r.start
+	}
+	horizon = horizon.AddDate(ruleSearchYears, 0, 0)
+
+	emitted := 0
+	for period := 0; ; period++ {
+		periodStart := r.periodStart(period)
+		if periodStart.After(horizon) {
+			return time.Time{}, false
+		}
+
+		for _, occurrence := range r.expand(periodStart) {
+			if occurrence.Before(r.start) {
+				continue
+			}
+			if !r.until.IsZero() && occurrence.After(r.until) {
+				return time.Time{}, false
+			}
+			emitted++
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+			if r.count > 0 && emitted >= r.count {
+				return time.Time{}, false
+			}
+		}
+	}
+}
+
+// periodStart is the first day of the nth period of the rule
+func (r rrule) periodStart(n int) time.Time {
+	year, month, day := r.start.Date()
+	switch r.freq {
+	case weekly:
+		offset := (int(r.start.Weekday()) - int(r.weekStart) + 7) % 7
+		return time.Date(year, month, day-offset+7*r.interval*n, 0, 0, 0, 0, time.UTC)
+	case monthly:
+		return time.Date(year, month+time.Month(r.interval*n), 1, 0, 0, 0, 0, time.UTC)
+	case yearly:
+		return time.Date(year+r.interval*n, time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(year, month, day+r.interval*n, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// expand returns the occurrences of the period starting on the day, in chronological order
+func (r rrule) expand(periodStart time.Time) []time.Time {
+	var periodEnd time.Time
+	switch r.freq {
+	case weekly:
+		periodEnd = periodStart.AddDate(0, 0, 7)
+	case monthly:
+		periodEnd = periodStart.AddDate(0, 1, 0)
+	case yearly:
+		periodEnd = periodStart.AddDate(1, 0, 0)
+	default:
+		periodEnd = periodStart.AddDate(0, 0, 1)
+	}
+
+	hours := r.byHour
+	if len(hours) == 0 {
+		hours = []int{r.start.Hour()}
+	}
+	minutes := r.byMinute
+	if len(minutes) == 0 {
+		minutes = []int{r.start.Minute()}
+	}
+
+	var occurrences []time.Time
+	for day := periodStart; day.Before(periodEnd); day = day.AddDate(0, 0, 1) {
+		if !r.matchesDay(day) {
+			continue
+		}
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				occurrences = append(occurrences, day.Add(time.Duration(hour)*time.Hour+time.Duration(minute)*time.Minute))
+			}
+		}
+	}
+	return occurrences
+}
+
+// matchesDay tells whether the day has occurrences. The BY parts restrict the days of the period; the unit of the
+// start fills in for the ones the rule leaves out, such as the day of the month of a monthly rule without BYDAY nor
+// BYMONTHDAY.
+func (r rrule) matchesDay(day time.Time) bool {
+	if len(r.byMonth) > 0 && !containsInt(r.byMonth, int(day.Month())) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 && !r.matchesMonthDay(day) {
+		return false
+	}
+	if len(r.byDay) > 0 && !r.matchesWeekday(day) {
+		return false
+	}
+
+	if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+		switch r.freq {
+		case weekly:
+			return day.Weekday() == r.start.Weekday()
+		case monthly:
+			return day.Day() == r.start.Day()
+		case yearly:
+			return day.Day() == r.start.Day() && (len(r.byMonth) > 0 || day.Month() == r.start.Month())
+		}
+	}
+	return true
+}
+
+// matchesMonthDay tells whether the day is one of BYMONTHDAY, negative days counting from the end of the month
+func (r rrule) matchesMonthDay(day time.Time) bool {
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range r.byMonthDay {
+		if monthDay == day.Day() || monthDay < 0 && daysInMonth+monthDay+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday tells whether the day is one of BYDAY. Ranks count within the month for monthly rules and yearly
+// rules with BYMONTH, and within the year for the other yearly rules.
+func (r rrule) matchesWeekday(day time.Time) bool {
+	scopeStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	scopeEnd := scopeStart.AddDate(0, 1, -1)
+	if r.freq == yearly && len(r.byMonth) == 0 {
+		scopeStart = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		scopeEnd = scopeStart.AddDate(1, 0, -1)
+	}
+
+	for _, weekday := range r.byDay {
+		if weekday.weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case weekday.n == 0:
+			return true
+		case weekday.n > 0 && int(day.Sub(scopeStart).Hours()/24)/7+1 == weekday.n:
+			return true
+		case weekday.n < 0 && -(int(scopeEnd.Sub(day).Hours()/24)/7+1) == weekday.n:
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package recurrence
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseRule_Occurrences(t *testing.T) {
+	// a Wednesday
+	start := date(2024, time.January, 31, 9, 30)
+
+	tests := map[string]struct {
+		rule  string
+		after time.Time
+		want  []time.Time
+	}{
+		"it repeats every interval days at the time of the start": {
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			after: start,
+			want:  []time.Time{date(2024, time.February, 2, 9, 30), date(2024, time.February, 4, 9, 30), date(2024, time.February, 6, 9, 30)},
+		},
+		"it includes the start when it matches": {
+			rule:  "RRULE:FREQ=WEEKLY",
+			after: start.Add(-time.Minute),
+			want:  []time.Time{start, date(2024, time.February, 7, 9, 30), date(2024, time.February, 14, 9, 30)},
+		},
+		"it repeats on the days of the week every other week": {
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			after: start,
+			want:  []time.Time{date(2024, time.February, 2, 9, 30), date(2024, time.February, 12, 9, 30), date(2024, time.February, 16, 9, 30)},
+		},
+		"it skips the months without the day of the start": {
+			rule:  "FREQ=MONTHLY",
+			after: start,
+			want:  []time.Time{date(2024, time.March, 31, 9, 30), date(2024, time.May, 31, 9, 30), date(2024, time.July, 31, 9, 30)},
+		},
+		"it counts the days of the month from its end": {
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=18;BYMINUTE=0",
+			after: start,
+			want:  []time.Time{date(2024, time.January, 31, 18, 0), date(2024, time.February, 29, 18, 0), date(2024, time.March, 31, 18, 0)},
+		},
+		"it ranks the days of the week within the month": {
+			rule:  "FREQ=MONTHLY;BYDAY=1MO,-1FR",
+			after: start,
+			want:  []time.Time{date(2024, time.February, 5, 9, 30), date(2024, time.February, 23, 9, 30), date(2024, time.March, 4, 9, 30)},
+		},
+		"it repeats on the months of the year": {
+			rule:  "FREQ=YEARLY;BYMONTH=1,7;BYMONTHDAY=15",
+			after: start,
+			want:  []time.Time{date(2024, time.July, 15, 9, 30), date(2025, time.January, 15, 9, 30), date(2025, time.July, 15, 9, 30)},
+		},
+		"it ranks the days of the week within the year": {
+			rule:  "FREQ=YEARLY;BYDAY=-1SU",
+			after: start,
+			want:  []time.Time{date(2024, time.December, 29, 9, 30), date(2025, time.December, 28, 9, 30), date(2026, time.December, 27, 9, 30)},
+		},
+		"it stops after count occurrences": {
+			rule:  "FREQ=DAILY;COUNT=3",
+			after: date(2024, time.February, 1, 0, 0),
+			want:  []time.Time{date(2024, time.February, 1, 9, 30), date(2024, time.February, 2, 9, 30)},
+		},
+		"it stops after until": {
+			rule:  "FREQ=WEEKLY;UNTIL=20240214",
+			after: start,
+			want:  []time.Time{date(2024, time.February, 7, 9, 30), date(2024, time.February, 14, 9, 30)},
+		},
+		"it has no occurrence on a day that does not exist": {
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			after: start,
+			want:  []time.Time{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule, err := ParseRule(tc.rule, start)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, Occurrences(rule, tc.after, 3))
+		})
+	}
+}
+
+func TestParseRule_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"it rejects a rule without frequency":        "INTERVAL=2",
+		"it rejects an unsupported frequency":        "FREQ=HOURLY",
+		"it rejects an unsupported part":             "FREQ=MONTHLY;BYSETPOS=-1",
+		"it rejects count together with until":       "FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"it rejects a repeated part":                 "FREQ=DAILY;FREQ=WEEKLY",
+		"it rejects an invalid day of the week":      "FREQ=WEEKLY;BYDAY=XX",
+		"it rejects a ranked day in a weekly rule":   "FREQ=WEEKLY;BYDAY=2MO",
+		"it rejects a day of the month out of range": "FREQ=MONTHLY;BYMONTHDAY=32",
+		"it rejects a part without value":            "FREQ=DAILY;INTERVAL",
+		"it rejects an empty rule":                   "",
+	}
+
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseRule(rule, time.Time{})
+			require.Error(t, err)
+		})
+	}
+}
@@ -1,5 +1,13 @@
 package repository
 
+import "errors"
+
+// ErrLeaseLost is returned when a lease held on a row expired and was taken over, or when the row changed meanwhile
+var ErrLeaseLost = errors.New("lease lost")
+
+// ErrScheduleRunExists is returned when the transaction of an occurrence of a schedule is created again
+var ErrScheduleRunExists = errors.New("the occurrence of the schedule was already materialized")
+
 // TODO [Improvements - error handling] Use NotFoundError to handle errors in the repository layer and propagate them to the api layer
 type NotFoundError struct {
 	message string
@@ -0,0 +1,178 @@
+package filter
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+)
+
+// Expression is a node of a filter expression, as returned by Parse. Its fields and values are checked against the
+// allow-list of fields, so that a repository can compile it without further validation.
+type Expression interface {
+	// Matches evaluates the expression on a transaction
+	Matches(transaction domain.Transaction) bool
+	String() string
+}
+
+// LogicalOperator joins two expressions
+type LogicalOperator string
+
+const (
+	And LogicalOperator = "and"
+	Or  LogicalOperator = "or"
+)
+
+// Logical is the conjunction or the disjunction of two expressions
+type Logical struct {
+	Operator    LogicalOperator
+	Left, Right Expression
+}
+
+func (l Logical) Matches(transaction domain.Transaction) bool {
+	if l.Operator == And {
+		return l.Left.Matches(transaction) && l.Right.Matches(transaction)
+	}
+	return l.Left.Matches(transaction) || l.Right.Matches(transaction)
+}
+
+func (l Logical) String() string {
+	return fmt.Sprintf("(%s %s %s)", l.Left, l.Operator, l.Right)
+}
+
+// Not negates an expression
+type Not struct {
+	Operand Expression
+}
+
+func (n Not) Matches(transaction domain.Transaction) bool {
+	return !n.Operand.Matches(transaction)
+}
+
+func (n Not) String() string {
+	return fmt.Sprintf("not %s", n.Operand)
+}
+
+// Operator compares a field with values
+type Operator string
+
+const (
+	Equal          Operator = "="
+	NotEqual       Operator = "!="
+	Greater        Operator = ">"
+	GreaterOrEqual Operator = ">="
+	Less           Operator = "<"
+	LessOrEqual    Operator = "<="
+	In             Operator = "in"
+	NotIn          Operator = "not in"
+)
+
+// Comparison compares a field of the transactions with one value, or with a list of values for In and NotIn.
+// The values are int64 for amount, time.Time for created_at, uuid.UUID for the IDs and strings otherwise.
+type Comparison struct {
+	Field    string
+	Operator Operator
+	Values   []interface{}
+}
+
+func (c Comparison) Matches(transaction domain.Transaction) bool {
+	actual := fieldValue(c.Field, transaction)
+
+	switch c.Operator {
+	case In, NotIn:
+		found := false
+		for _, value := range c.Values {
+			if compare(actual, value) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (c.Operator == In)
+	}
+
+	cmp := compare(actual, c.Values[0])
+	switch c.Operator {
+	case Equal:
+		return cmp == 0
+	case NotEqual:
+		return cmp != 0
+	case Greater:
+		return cmp > 0
+	case GreaterOrEqual:
+		return cmp >= 0
+	case Less:
+		return cmp < 0
+	case LessOrEqual:
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+func (c Comparison) String() string {
+	values := make([]string, 0, len(c.Values))
+	for _, value := range c.Values {
+		values = append(values, formatValue(value))
+	}
+	if c.Operator == In || c.Operator == NotIn {
+		return fmt.Sprintf("%s %s (%s)", c.Field, c.Operator, strings.Join(values, ", "))
+	}
+	return fmt.Sprintf("%s %s %s", c.Field, c.Operator, values[0])
+}
+
+// fieldValue is the value of the field of the transaction, of the type of the values of the comparisons on it
+func fieldValue(field string, transaction domain.Transaction) interface{} {
+	switch field {
+	case ID:
+		return transaction.ID
+	case UserID:
+		return transaction.UserID
+	case Origin:
+		return transaction.Origin
+	case TransactionType:
+		return domain.GetTransactionTypeName(transaction.TransactionType)
+	case Amount:
+		return transaction.Amount
+	case Status:
+		return string(transaction.Status)
+	case CreatedAt:
+		return transaction.CreatedAt
+	default:
+		return nil
+	}
+}
+
+// compare returns -1, 0 or 1 when a is less than, equal to or greater than b, which are of the same type
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		b := b.(int64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uuid.UUID:
+		return strings.Compare(a.String(), b.(uuid.UUID).String())
+	case string:
+		return strings.Compare(a, b.(string))
+	default:
+		return -1
+	}
+}
+
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case int64:
+		return fmt.Sprint(value)
+	case time.Time:
+		return fmt.Sprintf("%q", value.Format(time.RFC3339Nano))
+	default:
+		return fmt.Sprintf("%q", fmt.Sprint(value))
+	}
+}
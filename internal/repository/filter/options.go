@@ -1,28 +1,141 @@
 package filter
 
 import (
-	"github.com/uptrace/bun"
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/domain"
 )
 
 const (
+	ID              string = "id"
 	Origin          string = "origin"
 	TransactionType string = "transaction_type"
+	UserID          string = "user_id"
+	TenantID        string = "tenant_id"
+	Amount          string = "amount"
+	Status          string = "status"
+	CreatedAt       string = "created_at"
 )
 
 type Options func(*TransactionFilter)
 
+// TransactionFilter holds the criteria a transaction has to match to be listed; a nil criterion matches every
+// transaction. Each repository translates the criteria into its own query.
 type TransactionFilter struct {
-	Query *bun.SelectQuery
+	Origin          *string
+	TransactionType *string
+	UserID          *uuid.UUID
+	TenantID        *string
+	// Expressions are the parsed filter expressions the transactions must all match
+	Expressions []Expression
+	// Metadata holds the entries the metadata of the transactions must contain
+	Metadata map[string]string
+	// Tags are the tags the transactions must all have
+	Tags []string
+	// Category restricts the transactions to a category and its subcategories. Matches ignores it, as only the
+	// repository knows the tree of the categories.
+	Category *uuid.UUID
+	// Search is a full-text search of the description and of the searchable metadata, see WithSearch
+	Search *string
+	// Sort orders the transactions; they are listed oldest first when it is nil
+	Sort *Sort
+}
+
+// New builds the filter of the given options
+func New(opts ...Options) TransactionFilter {
+	var f TransactionFilter
+	for _, opt := range opts {
+		opt(&f)
+	}
+	return f
+}
+
+// Matches tells whether a transaction stored in the tenant matches every criterion of the filter but the category
+func (f TransactionFilter) Matches(tenantID string, transaction domain.Transaction) bool {
+	switch {
+	case f.Origin != nil && *f.Origin != transaction.Origin:
+		return false
+	case f.TransactionType != nil && *f.TransactionType != domain.GetTransactionTypeName(transaction.TransactionType):
+		return false
+	case f.UserID != nil && *f.UserID != transaction.UserID:
+		return false
+	case f.TenantID != nil && *f.TenantID != tenantID:
+		return false
+	}
+	for key, value := range f.Metadata {
+		if actual, ok := transaction.Metadata[key]; !ok || actual != value {
+			return false
+		}
+	}
+	for _, tag := range f.Tags {
+		if !contains(transaction.Tags, tag) {
+			return false
+		}
+	}
+	for _, expression := range f.Expressions {
+		if !expression.Matches(transaction) {
+			return false
+		}
+	}
+	if f.Search != nil {
+		if _, ok := MatchSearch(*f.Search, transaction); !ok {
+			return false
+		}
+	}
+	return true
 }
 
 func WithOrigin(origin string) Options {
 	return func(f *TransactionFilter) {
-		f.Query = f.Query.Where("? = ?", bun.Ident(Origin), origin)
+		f.Origin = &origin
 	}
 }
 
 func WithTransactionType(transactionType string) Options {
 	return func(f *TransactionFilter) {
-		f.Query = f.Query.Where("? = ?", bun.Ident(TransactionType), transactionType)
+		f.TransactionType = &transactionType
+	}
+}
+
+func WithUserID(userID uuid.UUID) Options {
+	return func(f *TransactionFilter) {
+		f.UserID = &userID
+	}
+}
+
+// WithTenantID restricts the query to the rows of a tenant; the repository applies it to every query
+func WithTenantID(tenantID string) Options {
+	return func(f *TransactionFilter) {
+		f.TenantID = &tenantID
+	}
+}
+
+// WithExpression restricts the query to the transactions matching a filter expression returned by Parse
+func WithExpression(expression Expression) Options {
+	return func(f *TransactionFilter) {
+		f.Expressions = append(f.Expressions, expression)
+	}
+}
+
+// WithMetadata restricts the query to the transactions whose metadata has the value at the key
+func WithMetadata(key, value string) Options {
+	return func(f *TransactionFilter) {
+		if f.Metadata == nil {
+			f.Metadata = map[string]string{}
+		}
+		f.Metadata[key] = value
+	}
+}
+
+// WithTag restricts the query to the transactions having the tag
+func WithTag(tag string) Options {
+	return func(f *TransactionFilter) {
+		f.Tags = append(f.Tags, tag)
+	}
+}
+
+// WithCategory restricts the query to the transactions of the category or of one of its subcategories
+func WithCategory(categoryID uuid.UUID) Options {
+	return func(f *TransactionFilter) {
+		f.Category = &categoryID
 	}
 }
@@ -0,0 +1,414 @@
+package filter
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"unicode"
+)
+
+// Limits of the expressions, so that a request cannot make the parser or the database do unbounded work
+const (
+	MaxExpressionLength = 1024
+	maxDepth            = 16
+	maxListLength       = 100
+)
+
+// ParseError reports an invalid filter expression, with the position in characters, from 1, where it was found
+type ParseError struct {
+	Position int
+	Message  string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Position, e.Message)
+}
+
+// valueKind is the kind of the values a field is compared with
+type valueKind int
+
+const (
+	numberValue valueKind = iota
+	stringValue
+	uuidValue
+	timeValue
+)
+
+var (
+	equalityOperators = []Operator{Equal, NotEqual, In, NotIn}
+	orderOperators    = []Operator{Equal, NotEqual, Greater, GreaterOrEqual, Less, LessOrEqual, In, NotIn}
+)
+
+// field describes a field that expressions may filter on
+type field struct {
+	kind      valueKind
+	operators []Operator
+	// accepted lists the values of fields with a closed set of values
+	accepted []string
+}
+
+// fields is the allow-list of the fields of the expressions, named after the columns of the transactions
+var fields = map[string]field{
+	ID:              {kind: uuidValue, operators: equalityOperators},
+	UserID:          {kind: uuidValue, operators: equalityOperators},
+	Origin:          {kind: stringValue, operators: equalityOperators},
+	TransactionType: {kind: stringValue, operators: equalityOperators, accepted: []string{domain.TransactionTypeCredit.String(), domain.TransactionTypeDebit.String(), domain.TransactionTypeUnspecified.String()}},
+	Amount:          {kind: numberValue, operators: orderOperators},
+	Status:          {kind: stringValue, operators: equalityOperators, accepted: []string{string(domain.TransactionStatusCompleted), string(domain.TransactionStatusPendingReview)}},
+	CreatedAt:       {kind: timeValue, operators: orderOperators},
+}
+
+// Parse parses a filter expression such as
+//
+//	amount > 1000 and origin in ("mobile-ios", "mobile-android") and created_at >= "2026-01-01"
+//
+// Comparisons on the fields of the allow-list are combined with and, or, not and parentheses; and binds tighter
+// than or. Strings are double quoted, times are RFC 3339 strings or dates, taken as midnight UTC. It returns a
+// ParseError for an invalid expression.
+func Parse(input string) (Expression, error) {
+	if len([]rune(input)) > MaxExpressionLength {
+		return nil, ParseError{Position: MaxExpressionLength + 1, Message: fmt.Sprintf("expression is longer than %d characters", MaxExpressionLength)}
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expression, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != endToken {
+		return nil, next.errorf("unexpected %s, expected and, or or the end of the expression", next)
+	}
+	return expression, nil
+}
+
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	identToken
+	stringToken
+	numberToken
+	operatorToken
+	leftParenToken
+	rightParenToken
+	commaToken
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value string
+	// position is the position of the first character of the token, from 1
+	position int
+}
+
+func (t token) String() string {
+	if t.kind == endToken {
+		return "end of the expression"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (t token) errorf(format string, args ...interface{}) ParseError {
+	return ParseError{Position: t.position, Message: fmt.Sprintf(format, args...)}
+}
+
+// is tells whether the token is the keyword, whatever its case
+func (t token) is(keyword string) bool {
+	return t.kind == identToken && strings.EqualFold(t.text, keyword)
+}
+
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{kind: leftParenToken, text: "(", position: start + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: rightParenToken, text: ")", position: start + 1})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: commaToken, text: ",", position: start + 1})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			text := string(runes[start:i])
+			if text == "!" {
+				return nil, ParseError{Position: start + 1, Message: `unexpected "!", expected "!="`}
+			}
+			if text == "==" {
+				text = "="
+			}
+			tokens = append(tokens, token{kind: operatorToken, text: text, position: start + 1})
+		case r == '"':
+			value, end, err := lexString(runes, start)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, token{kind: stringToken, text: string(runes[start:i]), value: value, position: start + 1})
+		case r == '-' || unicode.IsDigit(r):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: string(runes[start:i]), position: start + 1})
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: identToken, text: string(runes[start:i]), position: start + 1})
+		default:
+			return nil, ParseError{Position: start + 1, Message: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	return append(tokens, token{kind: endToken, position: len(runes) + 1}), nil
+}
+
+// lexString reads the double quoted string starting at start, where \" and \\ escape a quote and a backslash.
+// It returns the unquoted value and the position following the closing quote.
+func lexString(runes []rune, start int) (string, int, error) {
+	var value strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return value.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				value.WriteRune(runes[i])
+				continue
+			}
+			return "", 0, ParseError{Position: i + 1, Message: `invalid escape, only \" and \\ are supported`}
+		default:
+			value.WriteRune(runes[i])
+		}
+	}
+	return "", 0, ParseError{Position: start + 1, Message: "unterminated string"}
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != endToken {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr(depth int) (Expression, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is(string(Or)) {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Logical{Operator: Or, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (Expression, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is(string(And)) {
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Logical{Operator: And, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (Expression, error) {
+	t := p.peek()
+	if depth >= maxDepth {
+		return nil, t.errorf("expression is nested more than %d levels deep", maxDepth)
+	}
+
+	switch {
+	case t.is("not"):
+		p.next()
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Operand: operand}, nil
+	case t.kind == leftParenToken:
+		p.next()
+		expression, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != rightParenToken {
+			return nil, closing.errorf(`unexpected %s, expected ")"`, closing)
+		}
+		return expression, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	name := p.next()
+	if name.kind != identToken || name.is(string(And)) || name.is(string(Or)) || name.is("in") {
+		return nil, name.errorf("unexpected %s, expected a field", name)
+	}
+	f, ok := fields[strings.ToLower(name.text)]
+	if !ok {
+		return nil, name.errorf("unknown field %q, expected one of %s", name.text, fieldNames())
+	}
+	comparison := Comparison{Field: strings.ToLower(name.text)}
+
+	operator := p.next()
+	switch {
+	case operator.kind == operatorToken:
+		comparison.Operator = Operator(operator.text)
+	case operator.is("in"):
+		comparison.Operator = In
+	case operator.is("not") && p.peek().is("in"):
+		p.next()
+		comparison.Operator = NotIn
+	default:
+		return nil, operator.errorf("unexpected %s, expected an operator", operator)
+	}
+	if !f.accepts(comparison.Operator) {
+		return nil, operator.errorf("operator %s is not supported on %s", comparison.Operator, comparison.Field)
+	}
+
+	if comparison.Operator != In && comparison.Operator != NotIn {
+		value, err := p.parseValue(comparison.Field, f)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Values = []interface{}{value}
+		return comparison, nil
+	}
+
+	if open := p.next(); open.kind != leftParenToken {
+		return nil, open.errorf(`unexpected %s, expected "(" and a list of values`, open)
+	}
+	for {
+		if len(comparison.Values) == maxListLength {
+			return nil, p.peek().errorf("lists are limited to %d values", maxListLength)
+		}
+		value, err := p.parseValue(comparison.Field, f)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Values = append(comparison.Values, value)
+
+		separator := p.next()
+		if separator.kind == rightParenToken {
+			return comparison, nil
+		}
+		if separator.kind != commaToken {
+			return nil, separator.errorf(`unexpected %s, expected "," or ")"`, separator)
+		}
+	}
+}
+
+// parseValue reads a value of the field and converts it to the type of the field
+func (p *parser) parseValue(name string, f field) (interface{}, error) {
+	t := p.next()
+
+	if f.kind == numberValue {
+		if t.kind != numberToken {
+			return nil, t.errorf("unexpected %s, expected a number for %s", t, name)
+		}
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, t.errorf("%s is not a whole number", t)
+		}
+		return n, nil
+	}
+
+	if t.kind != stringToken {
+		return nil, t.errorf("unexpected %s, expected a quoted string for %s", t, name)
+	}
+	switch f.kind {
+	case uuidValue:
+		id, err := uuid.Parse(t.value)
+		if err != nil {
+			return nil, t.errorf("%s is not a valid UUID", t)
+		}
+		return id, nil
+	case timeValue:
+		if instant, err := time.Parse(time.RFC3339Nano, t.value); err == nil {
+			return instant.UTC(), nil
+		}
+		if date, err := time.Parse(time.DateOnly, t.value); err == nil {
+			return date, nil
+		}
+		return nil, t.errorf("%s is not a date (2006-01-02) nor an RFC 3339 time", t)
+	default:
+		if len(f.accepted) > 0 && !contains(f.accepted, t.value) {
+			return nil, t.errorf("%s is not a valid %s, expected one of %s", t, name, strings.Join(f.accepted, ", "))
+		}
+		return t.value, nil
+	}
+}
+
+func (f field) accepts(operator Operator) bool {
+	for _, o := range f.operators {
+		if o == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldNames() string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
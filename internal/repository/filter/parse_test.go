@@ -0,0 +1,208 @@
+package filter
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"it parses a comparison": {
+			input: "amount > 1000",
+			want:  "amount > 1000",
+		},
+		"it binds and tighter than or": {
+			input: `origin = "desktop-web" or amount >= 10 and amount <= 20`,
+			want:  `(origin = "desktop-web" or (amount >= 10 and amount <= 20))`,
+		},
+		"it groups with parentheses": {
+			input: `(origin = "desktop-web" or amount >= 10) and amount <= 20`,
+			want:  `((origin = "desktop-web" or amount >= 10) and amount <= 20)`,
+		},
+		"it parses lists and negations": {
+			input: `not status = "COMPLETED" and origin not in ("mobile-ios", "mobile-android")`,
+			want:  `(not status = "COMPLETED" and origin not in ("mobile-ios", "mobile-android"))`,
+		},
+		"it accepts keywords and fields whatever their case, and == for =": {
+			input: `Origin IN ("desktop-web") AND NOT amount == -5`,
+			want:  `(origin in ("desktop-web") and not amount = -5)`,
+		},
+		"it reads dates as midnight UTC and times as RFC 3339": {
+			input: `created_at >= "2026-01-01" and created_at < "2026-01-02T10:00:00+02:00"`,
+			want:  `(created_at >= "2026-01-01T00:00:00Z" and created_at < "2026-01-02T08:00:00Z")`,
+		},
+		"it unescapes strings": {
+			input: `origin != "say \"hi\" \\ bye"`,
+			want:  `origin != "say \"hi\" \\ bye"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			expression, err := Parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, expression.String())
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]struct {
+		input        string
+		wantPosition int
+		wantMessage  string
+	}{
+		"it rejects an empty expression": {
+			input:        "",
+			wantPosition: 1,
+			wantMessage:  "unexpected end of the expression, expected a field",
+		},
+		"it rejects an unknown field": {
+			input:        "amount > 1 and tenant_id = \"other\"",
+			wantPosition: 16,
+			wantMessage:  `unknown field "tenant_id"`,
+		},
+		"it rejects an operator the field does not support": {
+			input:        `origin > "a"`,
+			wantPosition: 8,
+			wantMessage:  "operator > is not supported on origin",
+		},
+		"it rejects a value of the wrong type": {
+			input:        `amount = "1000"`,
+			wantPosition: 10,
+			wantMessage:  "expected a number for amount",
+		},
+		"it rejects a decimal amount": {
+			input:        "amount = 10.5",
+			wantPosition: 10,
+			wantMessage:  `"10.5" is not a whole number`,
+		},
+		"it rejects a value outside of the accepted ones": {
+			input:        `transaction_type = "REFUND"`,
+			wantPosition: 20,
+			wantMessage:  "expected one of CREDIT TRANSACTION, DEBIT TRANSACTION, TYPE_UNSPECIFIED",
+		},
+		"it rejects an invalid UUID": {
+			input:        `user_id = "me"`,
+			wantPosition: 11,
+			wantMessage:  `"\"me\"" is not a valid UUID`,
+		},
+		"it rejects an invalid time": {
+			input:        `created_at >= "yesterday"`,
+			wantPosition: 15,
+			wantMessage:  "is not a date",
+		},
+		"it rejects an unterminated string": {
+			input:        `origin = "desktop-web`,
+			wantPosition: 10,
+			wantMessage:  "unterminated string",
+		},
+		"it rejects an unknown character": {
+			input:        "amount > 1 && amount < 5",
+			wantPosition: 12,
+			wantMessage:  `unexpected character '&'`,
+		},
+		"it rejects an unclosed parenthesis": {
+			input:        "(amount > 1",
+			wantPosition: 12,
+			wantMessage:  `unexpected end of the expression, expected ")"`,
+		},
+		"it rejects a trailing token": {
+			input:        "amount > 1 amount",
+			wantPosition: 12,
+			wantMessage:  `unexpected "amount", expected and, or or the end of the expression`,
+		},
+		"it rejects a list without parentheses": {
+			input:        `origin in "desktop-web"`,
+			wantPosition: 11,
+			wantMessage:  `expected "(" and a list of values`,
+		},
+		"it rejects expressions nested too deep": {
+			input:        strings.Repeat("(", maxDepth) + "amount > 1" + strings.Repeat(")", maxDepth),
+			wantPosition: maxDepth + 1,
+			wantMessage:  "nested more than",
+		},
+		"it rejects a list too long": {
+			input:        "amount in (" + strings.Repeat("1, ", maxListLength) + "1)",
+			wantPosition: 12 + 3*maxListLength,
+			wantMessage:  "lists are limited to",
+		},
+		"it rejects an expression too long": {
+			input:        strings.Repeat(" ", MaxExpressionLength) + "amount > 1",
+			wantPosition: MaxExpressionLength + 1,
+			wantMessage:  "expression is longer than",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tc.input)
+
+			var parseErr ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, tc.wantPosition, parseErr.Position, parseErr.Error())
+			require.Contains(t, parseErr.Message, tc.wantMessage)
+		})
+	}
+}
+
+func TestExpression_Matches(t *testing.T) {
+	transaction := domain.Transaction{
+		ID:              uuid.MustParse("73b2228a-be4a-43dd-8c07-4668e59da688"),
+		UserID:          uuid.MustParse("f3b2228a-be4a-43dd-8c07-4668e59da688"),
+		Origin:          "mobile-ios",
+		TransactionType: domain.TransactionTypeDebit,
+		Amount:          1500,
+		CreatedAt:       time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Status:          domain.TransactionStatusPendingReview,
+	}
+
+	tests := map[string]struct {
+		input string
+		want  bool
+	}{
+		"it matches the example of the documentation": {
+			input: `amount > 1000 and origin in ("mobile-ios","mobile-android") and created_at >= "2026-01-01"`,
+			want:  true,
+		},
+		"it compares amounts": {
+			input: "amount < 1500",
+			want:  false,
+		},
+		"it compares times": {
+			input: `created_at > "2026-03-01T12:00:00Z"`,
+			want:  false,
+		},
+		"it compares IDs": {
+			input: `id = "73B2228A-BE4A-43DD-8C07-4668E59DA688" and user_id != "73b2228a-be4a-43dd-8c07-4668e59da688"`,
+			want:  true,
+		},
+		"it compares the transaction type and status by name": {
+			input: `transaction_type = "DEBIT TRANSACTION" and status = "PENDING_REVIEW"`,
+			want:  true,
+		},
+		"it negates": {
+			input: `not origin not in ("desktop-web")`,
+			want:  false,
+		},
+		"it matches either side of or": {
+			input: `origin = "desktop-web" or amount = 1500`,
+			want:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			expression, err := Parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, expression.Matches(transaction))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package filter
+
+import (
+	"strings"
+	"traive-engineering-challenge/internal/domain"
+	"unicode"
+)
+
+// MaxSearchLength is the length of the longest search, in bytes
+const MaxSearchLength = 200
+
+const (
+	// descriptionWeight and metadataWeight weigh the words of the description and of the metadata in the rank, like
+	// the A and B weights of ts_rank
+	descriptionWeight = 1.0
+	metadataWeight    = 0.4
+	// snippetMaxWords is the number of words of the snippets, like the MaxWords option of ts_headline
+	snippetMaxWords = 20
+)
+
+// WithSearch restricts the query to the transactions whose description or searchable metadata contain every word of
+// the search. They are listed by rank, most relevant first, unless the filter has a Sort.
+func WithSearch(search string) Options {
+	return func(f *TransactionFilter) {
+		f.Search = &search
+	}
+}
+
+// SearchWords splits a text into the lower case words a search matches: the runs of letters and digits, as the simple
+// text search configuration of Postgres splits most texts
+func SearchWords(text string) []string {
+	var words []string
+	for _, span := range wordSpans(text) {
+		words = append(words, strings.ToLower(text[span[0]:span[1]]))
+	}
+	return words
+}
+
+// MatchSearch tells whether the transaction matches the search and, if so, ranks it and highlights the matching
+// words. It approximates the ranks and snippets of Postgres: they order and read alike, but are not equal.
+func MatchSearch(search string, transaction domain.Transaction) (*domain.SearchMatch, bool) {
+	searched := SearchWords(search)
+	if len(searched) == 0 {
+		return nil, false
+	}
+
+	metadata := searchableMetadata(transaction)
+	descriptionWords := countWords(transaction.Description)
+	metadataWords := countWords(strings.Join(metadata, " "))
+
+	var rank float64
+	for _, word := range searched {
+		if descriptionWords[word] == 0 && metadataWords[word] == 0 {
+			return nil, false
+		}
+		rank += descriptionWeight*float64(descriptionWords[word]) + metadataWeight*float64(metadataWords[word])
+	}
+
+	parts := metadata
+	if transaction.Description != "" {
+		parts = append([]string{transaction.Description}, metadata...)
+	}
+	return &domain.SearchMatch{
+		Rank:    rank / float64(len(searched)),
+		Snippet: highlight(strings.Join(parts, " "), searched),
+	}, true
+}
+
+// searchableMetadata returns the non empty values of the searchable metadata keys, in the order of the keys
+func searchableMetadata(transaction domain.Transaction) []string {
+	var values []string
+	for _, key := range domain.SearchableMetadataKeys {
+		if value := transaction.Metadata[key]; value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func countWords(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, word := range SearchWords(text) {
+		counts[word]++
+	}
+	return counts
+}
+
+// highlight wraps the searched words of the text between <b> and </b>. A text longer than snippetMaxWords words is cut
+// to the words from the first match on.
+func highlight(text string, searched []string) string {
+	spans := wordSpans(text)
+	from, to := 0, len(text)
+	if len(spans) > snippetMaxWords {
+		first := 0
+		for i, span := range spans {
+			if contains(searched, strings.ToLower(text[span[0]:span[1]])) {
+				first = i
+				break
+			}
+		}
+		if first > len(spans)-snippetMaxWords {
+			first = len(spans) - snippetMaxWords
+		}
+		spans = spans[first : first+snippetMaxWords]
+		from, to = spans[0][0], spans[len(spans)-1][1]
+	}
+
+	var b strings.Builder
+	end := from
+	for _, span := range spans {
+		b.WriteString(text[end:span[0]])
+		word := text[span[0]:span[1]]
+		if contains(searched, strings.ToLower(word)) {
+			word = "<b>" + word + "</b>"
+		}
+		b.WriteString(word)
+		end = span[1]
+	}
+	b.WriteString(text[end:to])
+	return b.String()
+}
+
+// wordSpans returns the byte offsets of the start and the end of the words of the text
+func wordSpans(text string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(text)})
+	}
+	return spans
+}
@@ -0,0 +1,90 @@
+package filter
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"traive-engineering-challenge/internal/domain"
+)
+
+func TestSearchWords(t *testing.T) {
+	require.Equal(t, []string{"corner", "shop", "a", "1001", "café"}, SearchWords(" Corner-shop, A-1001 (Café)"))
+	require.Nil(t, SearchWords("!?"))
+}
+
+func TestMatchSearch(t *testing.T) {
+	transaction := domain.Transaction{
+		Description: "Weekly groceries at the corner shop",
+		Metadata:    map[string]string{"merchant": "Corner Shop Ltd", "reference": "INV-2024-17", "order_id": "A-1001"},
+	}
+
+	tests := map[string]struct {
+		search      string
+		wantSnippet string
+		wantMatch   bool
+	}{
+		"it matches the words of the description in any case": {
+			search:      "GROCERIES weekly",
+			wantSnippet: "<b>Weekly</b> <b>groceries</b> at the corner shop Corner Shop Ltd INV-2024-17",
+			wantMatch:   true,
+		},
+		"it matches the words of the searchable metadata": {
+			search:      "inv 17",
+			wantSnippet: "Weekly groceries at the corner shop Corner Shop Ltd <b>INV</b>-2024-<b>17</b>",
+			wantMatch:   true,
+		},
+		"it matches words of the description and of the metadata together": {
+			search:      "ltd groceries",
+			wantSnippet: "Weekly <b>groceries</b> at the corner shop Corner Shop <b>Ltd</b> INV-2024-17",
+			wantMatch:   true,
+		},
+		"it does not match when a word is missing": {
+			search: "groceries pharmacy",
+		},
+		"it does not match the other metadata": {
+			search: "1001",
+		},
+		"it does not match prefixes of words": {
+			search: "grocer",
+		},
+		"it does not match a search without words": {
+			search: "-",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, ok := MatchSearch(tc.search, transaction)
+			require.Equal(t, tc.wantMatch, ok)
+			if tc.wantMatch {
+				require.Equal(t, tc.wantSnippet, match.Snippet)
+				require.Positive(t, match.Rank)
+			}
+		})
+	}
+}
+
+func TestMatchSearch_Rank(t *testing.T) {
+	inDescription := domain.Transaction{Description: "Corner shop"}
+	inMetadata := domain.Transaction{Description: "Groceries", Metadata: map[string]string{"merchant": "Corner shop"}}
+
+	description, ok := MatchSearch("corner", inDescription)
+	require.True(t, ok)
+	metadata, ok := MatchSearch("corner", inMetadata)
+	require.True(t, ok)
+	require.Greater(t, description.Rank, metadata.Rank)
+}
+
+func TestMatchSearch_Snippet(t *testing.T) {
+	words := strings.Fields("one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twenty-one needle twenty-three")
+	match, ok := MatchSearch("needle", domain.Transaction{Description: strings.Join(words, " ")})
+	require.True(t, ok)
+	// the snippet keeps the last 20 words, as the needle is among them
+	require.Equal(t, "six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twenty-one <b>needle</b> twenty-three", match.Snippet)
+}
+
+func TestTransactionFilter_Matches_Search(t *testing.T) {
+	transaction := domain.Transaction{Description: "Corner shop"}
+	require.True(t, New(WithSearch("shop")).Matches("default", transaction))
+	require.False(t, New(WithSearch("pharmacy")).Matches("default", transaction))
+}
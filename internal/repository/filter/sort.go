@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"traive-engineering-challenge/internal/domain"
+)
+
+// sortFields are the fields the transactions can be sorted by
+var sortFields = []string{CreatedAt, Amount}
+
+// Sort orders the listed transactions by a field. Ties are broken by creation time then ID, in the direction of the
+// sort when it is on created_at and oldest first otherwise. Transactions are listed oldest first without a Sort.
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSort parses a sort such as amount or -created_at, where a leading minus sorts in descending order
+func ParseSort(input string) (Sort, error) {
+	sort := Sort{Field: strings.ToLower(strings.TrimSpace(input))}
+	if strings.HasPrefix(sort.Field, "-") {
+		sort.Field, sort.Descending = sort.Field[1:], true
+	}
+
+	if !contains(sortFields, sort.Field) {
+		return Sort{}, fmt.Errorf("unknown sort %q, expected one of %s, optionally prefixed with -", input, strings.Join(sortFields, ", "))
+	}
+	return sort, nil
+}
+
+func (s Sort) String() string {
+	if s.Descending {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Less tells whether the transaction a is listed before b
+func (s Sort) Less(a, b domain.Transaction) bool {
+	if s.Field == Amount && a.Amount != b.Amount {
+		return (a.Amount < b.Amount) != s.Descending
+	}
+
+	descending := s.Field == CreatedAt && s.Descending
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt) != descending
+	}
+	if a.ID == b.ID {
+		return false
+	}
+	return (a.ID.String() < b.ID.String()) != descending
+}
+
+// WithSort orders the listed transactions
+func WithSort(sort Sort) Options {
+	return func(f *TransactionFilter) {
+		f.Sort = &sort
+	}
+}
@@ -0,0 +1,54 @@
+package filter
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		want    Sort
+		wantErr bool
+	}{
+		"it sorts in ascending order":                    {input: "amount", want: Sort{Field: Amount}},
+		"it sorts in descending order with a minus":      {input: "-created_at", want: Sort{Field: CreatedAt, Descending: true}},
+		"it ignores the case and the spaces":             {input: " -Amount ", want: Sort{Field: Amount, Descending: true}},
+		"it rejects the fields it cannot sort by":        {input: "origin", wantErr: true},
+		"it rejects a minus without a field":             {input: "-", wantErr: true},
+		"it rejects a field with more than one minus":    {input: "--amount", wantErr: true},
+		"it rejects an empty sort, which is the default": {input: "", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseSort(tc.input)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSort_Less(t *testing.T) {
+	now := time.Now()
+	older := domain.Transaction{ID: uuid.MustParse("f3b2228a-be4a-43dd-8c07-4668e59da688"), Amount: 500, CreatedAt: now.Add(-time.Minute)}
+	newer := domain.Transaction{ID: uuid.MustParse("73b2228a-be4a-43dd-8c07-4668e59da688"), Amount: 500, CreatedAt: now}
+	bigger := domain.Transaction{ID: uuid.MustParse("03b2228a-be4a-43dd-8c07-4668e59da688"), Amount: 900, CreatedAt: now}
+	sameTime := domain.Transaction{ID: uuid.MustParse("83b2228a-be4a-43dd-8c07-4668e59da688"), Amount: 100, CreatedAt: now}
+
+	require.True(t, Sort{Field: CreatedAt}.Less(older, newer))
+	require.True(t, Sort{Field: CreatedAt, Descending: true}.Less(newer, older))
+	require.True(t, Sort{Field: CreatedAt, Descending: true}.Less(sameTime, newer), "ties are broken by descending ID")
+
+	require.True(t, Sort{Field: Amount}.Less(newer, bigger))
+	require.True(t, Sort{Field: Amount, Descending: true}.Less(bigger, newer))
+	require.True(t, Sort{Field: Amount, Descending: true}.Less(older, newer), "ties are broken oldest first")
+	require.False(t, Sort{Field: Amount}.Less(newer, newer))
+}
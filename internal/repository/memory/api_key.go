@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// Like in Postgres, a key is looked up by its prefix across tenants to learn the tenant of the request. Every other
+// operation is restricted to the tenant of the context.
+
+// CreateAPIKey stores a key bound to the tenant of the context
+func (r *Repository) CreateAPIKey(ctx context.Context, key domain.APIKey) (*domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.TenantID = tenant.FromContext(ctx)
+	key.CreatedAt = r.now()
+	return r.insertAPIKey(key)
+}
+
+// insertAPIKey stores a copy of the key, whose ID and prefix must be unique. The lock must be held for writing.
+func (r *Repository) insertAPIKey(key domain.APIKey) (*domain.APIKey, error) {
+	if err := r.checkAPIKeyUnique(key); err != nil {
+		return nil, err
+	}
+
+	key = cloneAPIKey(key)
+	r.apiKeys[key.ID] = key
+
+	created := cloneAPIKey(key)
+	return &created, nil
+}
+
+func (r *Repository) checkAPIKeyUnique(key domain.APIKey) error {
+	if _, ok := r.apiKeys[key.ID]; ok {
+		return uniqueIDError("id", key.ID)
+	}
+	for _, existing := range r.apiKeys {
+		if existing.Prefix == key.Prefix {
+			return uniqueIDError("prefix", key.Prefix)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.apiKeys[id]
+	if !ok || key.TenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(apiKeyNotFoundMessage)
+	}
+
+	key = cloneAPIKey(key)
+	return &key, nil
+}
+
+// GetAPIKeyByPrefix retrieves a key of any tenant, revoked or not, by the public prefix of its plaintext
+func (r *Repository) GetAPIKeyByPrefix(_ context.Context, prefix string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.apiKeys {
+		if key.Prefix == prefix {
+			key = cloneAPIKey(key)
+			return &key, nil
+		}
+	}
+	return nil, repository.NewNotFoundError(apiKeyNotFoundMessage)
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []domain.APIKey{}
+	for _, key := range r.apiKeys {
+		if key.TenantID == tenantID {
+			keys = append(keys, cloneAPIKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return before(keys[i].CreatedAt, keys[i].ID, keys[j].CreatedAt, keys[j].ID)
+	})
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an active key. Revoking an unknown or already revoked key returns a repository.NotFoundError.
+func (r *Repository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.revokeAPIKey(tenant.FromContext(ctx), id, r.now())
+}
+
+// RotateAPIKey atomically revokes an active key and stores its replacement
+func (r *Repository) RotateAPIKey(ctx context.Context, id uuid.UUID, replacement domain.APIKey) (*domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	replacement.TenantID = tenant.FromContext(ctx)
+	replacement.CreatedAt = now
+
+	// the replacement is checked first, so that a conflict leaves the key active like a rolled back transaction
+	if err := r.checkAPIKeyUnique(replacement); err != nil {
+		if _, ok := r.activeAPIKey(replacement.TenantID, id); !ok {
+			return nil, repository.NewNotFoundError(apiKeyNotFoundMessage)
+		}
+		return nil, err
+	}
+	if err := r.revokeAPIKey(replacement.TenantID, id, now); err != nil {
+		return nil, err
+	}
+	return r.insertAPIKey(replacement)
+}
+
+// TouchAPIKey records when a key was last used. Like GetAPIKeyByPrefix, it is used before the tenant is known.
+func (r *Repository) TouchAPIKey(_ context.Context, id uuid.UUID, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.apiKeys[id]; ok {
+		key.LastUsedAt = &usedAt
+		r.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (r *Repository) revokeAPIKey(tenantID string, id uuid.UUID, revokedAt time.Time) error {
+	key, ok := r.activeAPIKey(tenantID, id)
+	if !ok {
+		return repository.NewNotFoundError(apiKeyNotFoundMessage)
+	}
+
+	key.RevokedAt = &revokedAt
+	r.apiKeys[id] = key
+	return nil
+}
+
+func (r *Repository) activeAPIKey(tenantID string, id uuid.UUID) (domain.APIKey, bool) {
+	key, ok := r.apiKeys[id]
+	return key, ok && key.TenantID == tenantID && !key.IsRevoked()
+}
+
+func cloneAPIKey(key domain.APIKey) domain.APIKey {
+	if key.Scopes != nil {
+		key.Scopes = append([]string(nil), key.Scopes...)
+	}
+	if key.LastUsedAt != nil {
+		lastUsedAt := *key.LastUsedAt
+		key.LastUsedAt = &lastUsedAt
+	}
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		key.RevokedAt = &revokedAt
+	}
+	return key
+}
@@ -0,0 +1,248 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// CreateCategory stores a category. It returns a repository.UniqueIndexError when the tenant already has a category
+// of the same name.
+func (r *Repository) CreateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[category.ID]; ok {
+		return nil, uniqueIDError("id", category.ID)
+	}
+	if err := r.checkCategoryName(tenantID, category); err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+	category = cloneCategory(category)
+	r.categories[category.ID] = storedCategory{Category: category, tenantID: tenantID}
+
+	created := cloneCategory(category)
+	return &created, nil
+}
+
+func (r *Repository) GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.categories[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(categoryNotFoundMessage)
+	}
+
+	category := cloneCategory(stored.Category)
+	return &category, nil
+}
+
+// ListCategories retrieves every category of the tenant, by name
+func (r *Repository) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := r.tenantCategories(tenant.FromContext(ctx))
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return categories, nil
+}
+
+// UpdateCategory renames or moves an existing category, keeping its tenant and creation time
+func (r *Repository) UpdateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.categories[category.ID]
+	if !ok || stored.tenantID != tenantID {
+		return nil, repository.NewNotFoundError(categoryNotFoundMessage)
+	}
+	if err := r.checkCategoryName(tenantID, category); err != nil {
+		return nil, err
+	}
+
+	category.CreatedAt = stored.CreatedAt
+	category.UpdatedAt = r.now()
+	category = cloneCategory(category)
+	r.categories[category.ID] = storedCategory{Category: category, tenantID: tenantID}
+
+	updated := cloneCategory(category)
+	return &updated, nil
+}
+
+// DeleteCategory deletes a category, leaving its transactions uncategorized. It returns domain.ErrCategoryInUse
+// when the category still has subcategories or rules, like the foreign keys of Postgres.
+func (r *Repository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.categories[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return repository.NewNotFoundError(categoryNotFoundMessage)
+	}
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			return domain.ErrCategoryInUse
+		}
+	}
+	for _, rule := range r.categoryRules {
+		if rule.CategoryID == id {
+			return domain.ErrCategoryInUse
+		}
+	}
+
+	delete(r.categories, id)
+	for key, transaction := range r.transactions {
+		if transaction.CategoryID != nil && *transaction.CategoryID == id {
+			transaction.CategoryID = nil
+			r.transactions[key] = transaction
+		}
+	}
+	return nil
+}
+
+func (r *Repository) CreateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categoryRules[rule.ID]; ok {
+		return nil, uniqueIDError("id", rule.ID)
+	}
+
+	now := r.now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	rule = cloneCategoryRule(rule)
+	r.categoryRules[rule.ID] = storedCategoryRule{CategoryRule: rule, tenantID: tenant.FromContext(ctx)}
+
+	created := cloneCategoryRule(rule)
+	return &created, nil
+}
+
+func (r *Repository) GetCategoryRule(ctx context.Context, id uuid.UUID) (*domain.CategoryRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.categoryRules[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(categoryRuleNotFoundMessage)
+	}
+
+	rule := cloneCategoryRule(stored.CategoryRule)
+	return &rule, nil
+}
+
+// ListCategoryRules retrieves every rule of the tenant in the order they are evaluated: by priority, then oldest first
+func (r *Repository) ListCategoryRules(ctx context.Context) ([]domain.CategoryRule, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := []domain.CategoryRule{}
+	for _, stored := range r.categoryRules {
+		if stored.tenantID == tenantID {
+			rules = append(rules, cloneCategoryRule(stored.CategoryRule))
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return before(rules[i].CreatedAt, rules[i].ID, rules[j].CreatedAt, rules[j].ID)
+	})
+	return rules, nil
+}
+
+// UpdateCategoryRule replaces the conditions of an existing rule, keeping its tenant and creation time
+func (r *Repository) UpdateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.categoryRules[rule.ID]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(categoryRuleNotFoundMessage)
+	}
+
+	rule.CreatedAt = stored.CreatedAt
+	rule.UpdatedAt = r.now()
+	rule = cloneCategoryRule(rule)
+	r.categoryRules[rule.ID] = storedCategoryRule{CategoryRule: rule, tenantID: stored.tenantID}
+
+	updated := cloneCategoryRule(rule)
+	return &updated, nil
+}
+
+func (r *Repository) DeleteCategoryRule(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.categoryRules[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return repository.NewNotFoundError(categoryRuleNotFoundMessage)
+	}
+
+	delete(r.categoryRules, id)
+	return nil
+}
+
+// tenantCategories returns copies of the categories of the tenant, in no particular order. The lock must be held.
+func (r *Repository) tenantCategories(tenantID string) []domain.Category {
+	categories := []domain.Category{}
+	for _, stored := range r.categories {
+		if stored.tenantID == tenantID {
+			categories = append(categories, cloneCategory(stored.Category))
+		}
+	}
+	return categories
+}
+
+// checkCategoryName returns a repository.UniqueIndexError when another category of the tenant has the name of the
+// category, like the unique index of Postgres. The lock must be held.
+func (r *Repository) checkCategoryName(tenantID string, category domain.Category) error {
+	for _, stored := range r.categories {
+		if stored.tenantID == tenantID && stored.Name == category.Name && stored.ID != category.ID {
+			return uniqueIDError("tenant_id, name", fmt.Sprintf("%s, %s", tenantID, category.Name))
+		}
+	}
+	return nil
+}
+
+func cloneCategory(category domain.Category) domain.Category {
+	if category.ParentID != nil {
+		parentID := *category.ParentID
+		category.ParentID = &parentID
+	}
+	return category
+}
+
+func cloneCategoryRule(rule domain.CategoryRule) domain.CategoryRule {
+	if rule.Metadata != nil {
+		metadata := make(map[string]string, len(rule.Metadata))
+		for key, value := range rule.Metadata {
+			metadata[key] = value
+		}
+		rule.Metadata = metadata
+	}
+	if rule.MinAmount != nil {
+		minAmount := *rule.MinAmount
+		rule.MinAmount = &minAmount
+	}
+	if rule.MaxAmount != nil {
+		maxAmount := *rule.MaxAmount
+		rule.MaxAmount = &maxAmount
+	}
+	return rule
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+func (r *Repository) CreateRateSnapshot(ctx context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rateSnapshots[snapshot.ID]; ok {
+		return nil, uniqueIDError("id", snapshot.ID)
+	}
+
+	snapshot.CreatedAt = r.now()
+	snapshot = cloneRateSnapshot(snapshot)
+	r.rateSnapshots[snapshot.ID] = storedRateSnapshot{RateSnapshot: snapshot, tenantID: tenant.FromContext(ctx)}
+
+	created := cloneRateSnapshot(snapshot)
+	return &created, nil
+}
+
+// ListRateSnapshots retrieves the snapshots of the tenant, the latest effective first
+func (r *Repository) ListRateSnapshots(ctx context.Context) ([]domain.RateSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.rateSnapshotsOf(tenant.FromContext(ctx), nil), nil
+}
+
+// GetEffectiveRateSnapshot retrieves the snapshot in effect at the given time: the one with the latest effective_at
+// up to it, the latest loaded among them. It returns a repository.NotFoundError when there is none.
+func (r *Repository) GetEffectiveRateSnapshot(ctx context.Context, at time.Time) (*domain.RateSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots := r.rateSnapshotsOf(tenant.FromContext(ctx), &at)
+	if len(snapshots) == 0 {
+		return nil, repository.NewNotFoundError(rateSnapshotNotFoundMessage)
+	}
+	return &snapshots[0], nil
+}
+
+// rateSnapshotsOf returns the snapshots of the tenant effective up to at when set, the latest effective first, then
+// the latest loaded. The lock must be held.
+func (r *Repository) rateSnapshotsOf(tenantID string, at *time.Time) []domain.RateSnapshot {
+	snapshots := []domain.RateSnapshot{}
+	for _, stored := range r.rateSnapshots {
+		if stored.tenantID == tenantID && (at == nil || !stored.EffectiveAt.After(*at)) {
+			snapshots = append(snapshots, cloneRateSnapshot(stored.RateSnapshot))
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].EffectiveAt.Equal(snapshots[j].EffectiveAt) {
+			return snapshots[i].EffectiveAt.After(snapshots[j].EffectiveAt)
+		}
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots
+}
+
+func (r *Repository) CreateFXQuote(ctx context.Context, quote domain.FXQuote) (*domain.FXQuote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.fxQuotes[quote.ID]; ok {
+		return nil, uniqueIDError("id", quote.ID)
+	}
+
+	quote.CreatedAt = r.now()
+	r.fxQuotes[quote.ID] = storedFXQuote{FXQuote: quote, tenantID: tenant.FromContext(ctx)}
+
+	return &quote, nil
+}
+
+func (r *Repository) GetFXQuote(ctx context.Context, id uuid.UUID) (*domain.FXQuote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.fxQuotes[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(fxQuoteNotFoundMessage)
+	}
+
+	quote := stored.FXQuote
+	return &quote, nil
+}
+
+// CreateFXConversion stores the debit, the credit and the conversion of a quote at once, so that the quote is
+// converted once. The debit must be covered by the available balance of the user in its currency and is checked
+// against the limits like CreateTransactionWithinLimits. It returns an error wrapping domain.ErrQuoteUnavailable when
+// the quote expired or was already converted, a domain.InsufficientFundsError or a domain.LimitExceededError.
+func (r *Repository) CreateFXConversion(ctx context.Context, conversion domain.FXConversion, debit, credit domain.Transaction, limits []domain.Limit) (*domain.FXConversion, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	quote, ok := r.fxQuotes[conversion.QuoteID]
+	if !ok || quote.tenantID != tenantID {
+		return nil, repository.NewNotFoundError(fxQuoteNotFoundMessage)
+	}
+	if quote.Expired(now) {
+		return nil, fmt.Errorf("%w: the quote expired", domain.ErrQuoteUnavailable)
+	}
+	for _, stored := range r.fxConversions {
+		if stored.QuoteID == conversion.QuoteID {
+			return nil, fmt.Errorf("%w: the quote was already converted", domain.ErrQuoteUnavailable)
+		}
+	}
+
+	available := r.userBalance(tenantID, debit.UserID, debit.Currency, now) - r.heldAmount(tenantID, debit.UserID, debit.Currency, now)
+	if available < debit.Amount {
+		return nil, domain.NewInsufficientFundsError(available, debit.Amount)
+	}
+	if err := r.checkLimits(tenantID, debit.UserID, debit.Amount, limits, now); err != nil {
+		return nil, err
+	}
+
+	// every insert is checked before the first one, so that nothing is stored when one of them fails
+	if _, ok := r.fxConversions[conversion.ID]; ok {
+		return nil, uniqueIDError("id", conversion.ID)
+	}
+	for _, transaction := range []domain.Transaction{debit, credit} {
+		if _, ok := r.transactions[transactionKey{tenantID: tenantID, id: transaction.ID}]; ok {
+			return nil, uniqueIDError("tenant_id, id", fmt.Sprintf("%s, %s", tenantID, transaction.ID))
+		}
+	}
+	if debit.ID == credit.ID {
+		return nil, uniqueIDError("id", credit.ID)
+	}
+	for _, transaction := range []domain.Transaction{debit, credit} {
+		if _, err := r.insertTransaction(tenantID, transaction, now); err != nil {
+			return nil, err
+		}
+	}
+
+	conversion.CreatedAt = now
+	r.fxConversions[conversion.ID] = storedFXConversion{FXConversion: conversion, tenantID: tenantID}
+
+	return &conversion, nil
+}
+
+func (r *Repository) GetFXConversion(ctx context.Context, id uuid.UUID) (*domain.FXConversion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.fxConversions[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(fxConversionNotFoundMessage)
+	}
+
+	conversion := stored.FXConversion
+	return &conversion, nil
+}
+
+// ListFXConversions retrieves the conversions of the tenant, only the ones of the user when set, by creation time
+func (r *Repository) ListFXConversions(ctx context.Context, userID *uuid.UUID) ([]domain.FXConversion, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conversions := []domain.FXConversion{}
+	for _, stored := range r.fxConversions {
+		if stored.tenantID == tenantID && (userID == nil || stored.UserID == *userID) {
+			conversions = append(conversions, stored.FXConversion)
+		}
+	}
+	sort.Slice(conversions, func(i, j int) bool {
+		return before(conversions[i].CreatedAt, conversions[i].ID, conversions[j].CreatedAt, conversions[j].ID)
+	})
+	return conversions, nil
+}
+
+func cloneRateSnapshot(snapshot domain.RateSnapshot) domain.RateSnapshot {
+	if snapshot.Rates != nil {
+		snapshot.Rates = append([]domain.FXRate(nil), snapshot.Rates...)
+	}
+	return snapshot
+}
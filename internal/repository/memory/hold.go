@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// CreateHold stores an active hold only if its amount is available: the balance of the user in the currency of the
+// hold, DefaultCurrency when it has none, minus the amounts of its other active holds in it that have not expired. It
+// returns a domain.InsufficientFundsError otherwise.
+func (r *Repository) CreateHold(ctx context.Context, hold domain.Hold) (*domain.Hold, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.holds[hold.ID]; ok {
+		return nil, uniqueIDError("id", hold.ID)
+	}
+
+	if hold.Currency == "" {
+		hold.Currency = domain.DefaultCurrency
+	}
+	now := r.now()
+	available := r.userBalance(tenantID, hold.UserID, hold.Currency, now) - r.heldAmount(tenantID, hold.UserID, hold.Currency, now)
+	if available < hold.Amount {
+		return nil, domain.NewInsufficientFundsError(available, hold.Amount)
+	}
+
+	hold.TenantID = tenantID
+	hold.CreatedAt = now
+	hold.UpdatedAt = now
+	r.holds[hold.ID] = cloneHold(hold)
+
+	return &hold, nil
+}
+
+func (r *Repository) GetHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.holds[id]
+	if !ok || stored.TenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(holdNotFoundMessage)
+	}
+
+	hold := cloneHold(stored)
+	return &hold, nil
+}
+
+// ListHolds retrieves the holds of the tenant, only the ones of the user when set, by creation time
+func (r *Repository) ListHolds(ctx context.Context, userID *uuid.UUID) ([]domain.Hold, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	holds := []domain.Hold{}
+	for _, stored := range r.holds {
+		if stored.TenantID == tenantID && (userID == nil || stored.UserID == *userID) {
+			holds = append(holds, cloneHold(stored))
+		}
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		return before(holds[i].CreatedAt, holds[i].ID, holds[j].CreatedAt, holds[j].ID)
+	})
+	return holds, nil
+}
+
+// CaptureHold settles an active hold that has not expired with the debit, which is checked against the limits like
+// CreateTransactionWithinLimits, then marks the hold captured with the amount of the debit. It returns an error
+// wrapping domain.ErrHoldTransition when the hold is no longer active.
+func (r *Repository) CaptureHold(ctx context.Context, id uuid.UUID, transaction domain.Transaction, limits []domain.Limit) (*domain.Hold, *domain.Transaction, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	hold, err := r.activeHold(tenantID, id, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.checkLimits(tenantID, hold.UserID, transaction.Amount, limits, now); err != nil {
+		return nil, nil, err
+	}
+	created, err := r.insertTransaction(tenantID, transaction, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactionID := created.ID
+	hold.Status = domain.HoldStatusCaptured
+	hold.CapturedAmount = created.Amount
+	hold.TransactionID = &transactionID
+	hold.UpdatedAt = now
+	r.holds[id] = cloneHold(hold)
+
+	return &hold, created, nil
+}
+
+// ReleaseHold frees the amount of an active hold that has not expired. It returns an error wrapping
+// domain.ErrHoldTransition when the hold is no longer active.
+func (r *Repository) ReleaseHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	hold, err := r.activeHold(tenant.FromContext(ctx), id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.Status = domain.HoldStatusReleased
+	hold.UpdatedAt = now
+	r.holds[id] = cloneHold(hold)
+
+	return &hold, nil
+}
+
+// GetHeldAmount returns the sum of the amounts of the active holds of a user in the currency that have not expired
+func (r *Repository) GetHeldAmount(ctx context.Context, userID uuid.UUID, currency string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.heldAmount(tenant.FromContext(ctx), userID, currency, r.now()), nil
+}
+
+// ExpireHolds marks expired up to limit active holds of any tenant whose expiry is at or before now, and returns how
+// many it marked
+func (r *Repository) ExpireHolds(_ context.Context, now time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := 0
+	for id, hold := range r.holds {
+		if expired == limit {
+			break
+		}
+		if hold.Expired(now) {
+			hold.Status = domain.HoldStatusExpired
+			hold.UpdatedAt = now
+			r.holds[id] = hold
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// activeHold returns the hold of the tenant when it can still be captured or released at now. The caller must hold
+// the lock.
+func (r *Repository) activeHold(tenantID string, id uuid.UUID, now time.Time) (domain.Hold, error) {
+	stored, ok := r.holds[id]
+	if !ok || stored.TenantID != tenantID {
+		return domain.Hold{}, repository.NewNotFoundError(holdNotFoundMessage)
+	}
+
+	switch {
+	case stored.Status != domain.HoldStatusActive:
+		return domain.Hold{}, fmt.Errorf("%w: the hold is %s", domain.ErrHoldTransition, stored.Status)
+	case stored.Expired(now):
+		return domain.Hold{}, fmt.Errorf("%w: the hold expired", domain.ErrHoldTransition)
+	}
+	return cloneHold(stored), nil
+}
+
+// checkHeldFunds returns a domain.InsufficientFundsError when a debit of the amount would take funds held for the user
+// in the currency at now: while holds are active, the balance minus their amounts must cover the debit. The caller
+// must hold the lock until the debit is inserted.
+func (r *Repository) checkHeldFunds(tenantID string, userID uuid.UUID, currency string, amount int64, now time.Time) error {
+	held := r.heldAmount(tenantID, userID, currency, now)
+	if held == 0 {
+		return nil
+	}
+	if available := r.userBalance(tenantID, userID, currency, now) - held; available < amount {
+		return domain.NewInsufficientFundsError(available, amount)
+	}
+	return nil
+}
+
+// heldAmount is GetHeldAmount for the callers already holding the lock
+func (r *Repository) heldAmount(tenantID string, userID uuid.UUID, currency string, now time.Time) int64 {
+	var held int64
+	for _, hold := range r.holds {
+		if hold.TenantID == tenantID && hold.UserID == userID && hold.Currency == currency &&
+			hold.Status == domain.HoldStatusActive && !hold.Expired(now) {
+			held += hold.Amount
+		}
+	}
+	return held
+}
+
+func cloneHold(hold domain.Hold) domain.Hold {
+	if hold.Metadata != nil {
+		metadata := make(map[string]string, len(hold.Metadata))
+		for key, value := range hold.Metadata {
+			metadata[key] = value
+		}
+		hold.Metadata = metadata
+	}
+	if hold.Tags != nil {
+		hold.Tags = append([]string(nil), hold.Tags...)
+	}
+	if hold.TransactionID != nil {
+		transactionID := *hold.TransactionID
+		hold.TransactionID = &transactionID
+	}
+	return hold
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// CreateLimit stores a copy of the limit, in DefaultCurrency when it has no currency like the mapper of the Postgres
+// repository
+func (r *Repository) CreateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.limits[limit.ID]; ok {
+		return nil, uniqueIDError("id", limit.ID)
+	}
+
+	if limit.Currency == "" {
+		limit.Currency = domain.DefaultCurrency
+	}
+	now := r.now()
+	limit.CreatedAt = now
+	limit.UpdatedAt = now
+	limit = cloneLimit(limit)
+	r.limits[limit.ID] = storedLimit{Limit: limit, tenantID: tenant.FromContext(ctx)}
+
+	created := cloneLimit(limit)
+	return &created, nil
+}
+
+func (r *Repository) GetLimit(ctx context.Context, id uuid.UUID) (*domain.Limit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.limits[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(limitNotFoundMessage)
+	}
+
+	limit := cloneLimit(stored.Limit)
+	return &limit, nil
+}
+
+func (r *Repository) ListLimits(ctx context.Context) ([]domain.Limit, error) {
+	return r.tenantLimits(tenant.FromContext(ctx), func(domain.Limit) bool { return true }), nil
+}
+
+// UpdateLimit replaces the definition of an existing limit, keeping its tenant and creation time
+func (r *Repository) UpdateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.limits[limit.ID]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(limitNotFoundMessage)
+	}
+
+	if limit.Currency == "" {
+		limit.Currency = domain.DefaultCurrency
+	}
+	limit.CreatedAt = stored.CreatedAt
+	limit.UpdatedAt = r.now()
+	limit = cloneLimit(limit)
+	r.limits[limit.ID] = storedLimit{Limit: limit, tenantID: stored.tenantID}
+
+	updated := cloneLimit(limit)
+	return &updated, nil
+}
+
+func (r *Repository) DeleteLimit(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.limits[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return repository.NewNotFoundError(limitNotFoundMessage)
+	}
+
+	delete(r.limits, id)
+	return nil
+}
+
+// ListApplicableLimits retrieves the global limits, every origin limit and the limits of the given user
+func (r *Repository) ListApplicableLimits(ctx context.Context, userID uuid.UUID) ([]domain.Limit, error) {
+	return r.tenantLimits(tenant.FromContext(ctx), func(limit domain.Limit) bool {
+		return limit.Scope == domain.LimitScopeGlobal || limit.Scope == domain.LimitScopeOrigin ||
+			(limit.UserID != nil && *limit.UserID == userID)
+	}), nil
+}
+
+// tenantLimits returns copies of the limits of the tenant matching the predicate, oldest first
+func (r *Repository) tenantLimits(tenantID string, match func(domain.Limit) bool) []domain.Limit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limits := []domain.Limit{}
+	for _, stored := range r.limits {
+		if stored.tenantID == tenantID && match(stored.Limit) {
+			limits = append(limits, cloneLimit(stored.Limit))
+		}
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		return before(limits[i].CreatedAt, limits[i].ID, limits[j].CreatedAt, limits[j].ID)
+	})
+	return limits
+}
+
+// SumUserDebitsSince sums the debits of a user in the currency created at or after the given instant.
+// An empty origin sums the debits of every origin.
+func (r *Repository) SumUserDebitsSince(ctx context.Context, userID uuid.UUID, origin, currency string, since time.Time) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sumUserDebitsSince(tenant.FromContext(ctx), userID, origin, currency, since), nil
+}
+
+// CreateTransactionWithinLimits stores a debit only if it keeps the user within every given limit and leaves the funds
+// held for the user in its currency. The checks and the insert happen under the same lock, so that the rolling sums
+// and the held amount cannot change in between. It returns a domain.LimitExceededError or a
+// domain.InsufficientFundsError otherwise.
+func (r *Repository) CreateTransactionWithinLimits(ctx context.Context, transaction domain.Transaction, limits []domain.Limit) (*domain.Transaction, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	currency := transaction.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	if err := r.checkHeldFunds(tenantID, transaction.UserID, currency, transaction.Amount, now); err != nil {
+		return nil, err
+	}
+	if err := r.checkLimits(tenantID, transaction.UserID, transaction.Amount, limits, now); err != nil {
+		return nil, err
+	}
+
+	return r.insertTransaction(tenantID, transaction, now)
+}
+
+// checkLimits returns a domain.LimitExceededError when a debit of the amount would take the user over one of the
+// limits at now, each one summing the debits in its currency. The caller must hold the lock until the debit is
+// inserted.
+func (r *Repository) checkLimits(tenantID string, userID uuid.UUID, amount int64, limits []domain.Limit, now time.Time) error {
+	var exceeded []string
+	for _, limit := range limits {
+		origin := ""
+		if limit.Scope == domain.LimitScopeOrigin {
+			origin = limit.Origin
+		}
+
+		used := r.sumUserDebitsSince(tenantID, userID, origin, limit.Currency, now.Add(-limit.Period.Window()))
+		if used+amount > limit.MaxAmount {
+			exceeded = append(exceeded, limit.ID.String())
+		}
+	}
+	if len(exceeded) > 0 {
+		return domain.NewLimitExceededError(exceeded)
+	}
+	return nil
+}
+
+// sumUserDebitsSince is SumUserDebitsSince for the callers already holding the lock
+func (r *Repository) sumUserDebitsSince(tenantID string, userID uuid.UUID, origin, currency string, since time.Time) int64 {
+	debits := r.matchingTransactions(tenantID, func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID &&
+			transaction.TransactionType == domain.TransactionTypeDebit &&
+			transaction.Currency == currency &&
+			!transaction.CreatedAt.Before(since) &&
+			(origin == "" || transaction.Origin == origin)
+	})
+
+	var sum int64
+	for _, debit := range debits {
+		sum += debit.Amount
+	}
+	return sum
+}
+
+func cloneLimit(limit domain.Limit) domain.Limit {
+	if limit.UserID != nil {
+		userID := *limit.UserID
+		limit.UserID = &userID
+	}
+	return limit
+}
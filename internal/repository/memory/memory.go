@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+const (
+	transactionNotFoundMessage  = "transaction not found"
+	limitNotFoundMessage        = "limit not found"
+	apiKeyNotFoundMessage       = "api key not found"
+	savedSearchNotFoundMessage  = "saved search not found"
+	categoryNotFoundMessage     = "category not found"
+	categoryRuleNotFoundMessage = "category rule not found"
+	scheduleNotFoundMessage     = "schedule not found"
+	holdNotFoundMessage         = "hold not found"
+	rateSnapshotNotFoundMessage = "rate snapshot not found"
+	fxQuoteNotFoundMessage      = "quote not found"
+	fxConversionNotFoundMessage = "conversion not found"
+)
+
+// Repository keeps the data of every tenant in the memory of the process. It behaves like the Postgres repository,
+// down to its errors and the order of its lists, so that it can stand in for it in tests and local development.
+// Nothing is persisted nor shared between instances.
+type Repository struct {
+	mu sync.RWMutex
+	// the rows of every tenant are keyed by their ID, which is unique across tenants like a Postgres primary key, but
+	// for the transactions whose IDs are only unique within their tenant
+	transactions  map[transactionKey]storedTransaction
+	limits        map[uuid.UUID]storedLimit
+	apiKeys       map[uuid.UUID]domain.APIKey
+	savedSearches map[uuid.UUID]storedSavedSearch
+	categories    map[uuid.UUID]storedCategory
+	categoryRules map[uuid.UUID]storedCategoryRule
+	schedules     map[uuid.UUID]*storedSchedule
+	scheduleRuns  map[scheduleRunKey]uuid.UUID
+	holds         map[uuid.UUID]domain.Hold
+	rateSnapshots map[uuid.UUID]storedRateSnapshot
+	fxQuotes      map[uuid.UUID]storedFXQuote
+	fxConversions map[uuid.UUID]storedFXConversion
+	now           func() time.Time
+}
+
+type transactionKey struct {
+	tenantID string
+	id       uuid.UUID
+}
+
+type storedTransaction struct {
+	domain.Transaction
+	tenantID string
+}
+
+type storedLimit struct {
+	domain.Limit
+	tenantID string
+}
+
+type storedSavedSearch struct {
+	domain.SavedSearch
+	tenantID string
+}
+
+type storedCategory struct {
+	domain.Category
+	tenantID string
+}
+
+type storedCategoryRule struct {
+	domain.CategoryRule
+	tenantID string
+}
+
+type storedRateSnapshot struct {
+	domain.RateSnapshot
+	tenantID string
+}
+
+type storedFXQuote struct {
+	domain.FXQuote
+	tenantID string
+}
+
+type storedFXConversion struct {
+	domain.FXConversion
+	tenantID string
+}
+
+// scheduleRunKey identifies the run of an occurrence of a schedule, like the primary key of the Postgres table
+type scheduleRunKey struct {
+	tenantID   string
+	scheduleID uuid.UUID
+	occurrence int64
+}
+
+func newScheduleRunKey(tenantID string, scheduleID uuid.UUID, occurrence time.Time) scheduleRunKey {
+	return scheduleRunKey{tenantID: tenantID, scheduleID: scheduleID, occurrence: occurrence.UnixNano()}
+}
+
+// storedSchedule keeps the tenant in the schedule itself, along with its lease
+type storedSchedule struct {
+	domain.Schedule
+	leaseOwner     string
+	leaseExpiresAt time.Time
+}
+
+func New() *Repository {
+	return &Repository{
+		transactions:  make(map[transactionKey]storedTransaction),
+		limits:        make(map[uuid.UUID]storedLimit),
+		apiKeys:       make(map[uuid.UUID]domain.APIKey),
+		savedSearches: make(map[uuid.UUID]storedSavedSearch),
+		categories:    make(map[uuid.UUID]storedCategory),
+		categoryRules: make(map[uuid.UUID]storedCategoryRule),
+		schedules:     make(map[uuid.UUID]*storedSchedule),
+		scheduleRuns:  make(map[scheduleRunKey]uuid.UUID),
+		holds:         make(map[uuid.UUID]domain.Hold),
+		rateSnapshots: make(map[uuid.UUID]storedRateSnapshot),
+		fxQuotes:      make(map[uuid.UUID]storedFXQuote),
+		fxConversions: make(map[uuid.UUID]storedFXConversion),
+		now:           time.Now,
+	}
+}
+
+// uniqueIDError is the error of an insert reusing the ID of an existing row, worded like the detail of Postgres
+func uniqueIDError(column string, value interface{}) error {
+	return repository.NewUniqueIndexError(fmt.Sprintf("Key (%s)=(%v) already exists.", column, value))
+}
+
+// before orders rows by creation time then ID, like the ORDER BY "created_at" ASC, "id" ASC of the Postgres queries
+func before(createdAt time.Time, id uuid.UUID, otherCreatedAt time.Time, otherID uuid.UUID) bool {
+	if !createdAt.Equal(otherCreatedAt) {
+		return createdAt.Before(otherCreatedAt)
+	}
+	return id.String() < otherID.String()
+}
+
+func sortTransactions(transactions []domain.Transaction) {
+	sort.Slice(transactions, func(i, j int) bool {
+		return before(transactions[i].CreatedAt, transactions[i].ID, transactions[j].CreatedAt, transactions[j].ID)
+	})
+}
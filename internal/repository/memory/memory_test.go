@@ -0,0 +1,10 @@
+package memory
+
+import (
+	"testing"
+	"traive-engineering-challenge/internal/repository/repositorytest"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, New())
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// CreateSavedSearch stores a saved search. It returns a repository.UniqueIndexError when the tenant already has a
+// search of the same name.
+func (r *Repository) CreateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.savedSearches[search.ID]; ok {
+		return nil, uniqueIDError("id", search.ID)
+	}
+	if err := r.checkSavedSearchName(tenantID, search); err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	search.CreatedAt = now
+	search.UpdatedAt = now
+	r.savedSearches[search.ID] = storedSavedSearch{SavedSearch: search, tenantID: tenantID}
+
+	return &search, nil
+}
+
+func (r *Repository) GetSavedSearch(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error) {
+	return r.findSavedSearch(tenant.FromContext(ctx), func(search domain.SavedSearch) bool { return search.ID == id })
+}
+
+func (r *Repository) GetSavedSearchByName(ctx context.Context, name string) (*domain.SavedSearch, error) {
+	return r.findSavedSearch(tenant.FromContext(ctx), func(search domain.SavedSearch) bool { return search.Name == name })
+}
+
+// ListSavedSearches retrieves every saved search of the tenant, by name
+func (r *Repository) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	searches := []domain.SavedSearch{}
+	for _, stored := range r.savedSearches {
+		if stored.tenantID == tenantID {
+			searches = append(searches, stored.SavedSearch)
+		}
+	}
+	sort.Slice(searches, func(i, j int) bool { return searches[i].Name < searches[j].Name })
+	return searches, nil
+}
+
+// UpdateSavedSearch replaces the definition of an existing saved search, keeping its tenant, owner and creation time
+func (r *Repository) UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.savedSearches[search.ID]
+	if !ok || stored.tenantID != tenantID {
+		return nil, repository.NewNotFoundError(savedSearchNotFoundMessage)
+	}
+	if err := r.checkSavedSearchName(tenantID, search); err != nil {
+		return nil, err
+	}
+
+	search.Owner = stored.Owner
+	search.CreatedAt = stored.CreatedAt
+	search.UpdatedAt = r.now()
+	r.savedSearches[search.ID] = storedSavedSearch{SavedSearch: search, tenantID: tenantID}
+
+	return &search, nil
+}
+
+func (r *Repository) DeleteSavedSearch(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.savedSearches[id]
+	if !ok || stored.tenantID != tenant.FromContext(ctx) {
+		return repository.NewNotFoundError(savedSearchNotFoundMessage)
+	}
+
+	delete(r.savedSearches, id)
+	return nil
+}
+
+// findSavedSearch returns a copy of the saved search of the tenant matching the predicate
+func (r *Repository) findSavedSearch(tenantID string, match func(domain.SavedSearch) bool) (*domain.SavedSearch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.savedSearches {
+		if stored.tenantID == tenantID && match(stored.SavedSearch) {
+			search := stored.SavedSearch
+			return &search, nil
+		}
+	}
+	return nil, repository.NewNotFoundError(savedSearchNotFoundMessage)
+}
+
+// checkSavedSearchName returns a repository.UniqueIndexError when another search of the tenant has the name of the
+// search, like the unique index of Postgres. The lock must be held.
+func (r *Repository) checkSavedSearchName(tenantID string, search domain.SavedSearch) error {
+	for _, stored := range r.savedSearches {
+		if stored.tenantID == tenantID && stored.Name == search.Name && stored.ID != search.ID {
+			return uniqueIDError("tenant_id, name", fmt.Sprintf("%s, %s", tenantID, search.Name))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+func (r *Repository) CreateSchedule(ctx context.Context, schedule domain.Schedule) (*domain.Schedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.schedules[schedule.ID]; ok {
+		return nil, uniqueIDError("id", schedule.ID)
+	}
+
+	if schedule.Currency == "" {
+		schedule.Currency = domain.DefaultCurrency
+	}
+	now := r.now()
+	schedule.TenantID = tenant.FromContext(ctx)
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	r.schedules[schedule.ID] = &storedSchedule{Schedule: cloneSchedule(schedule)}
+
+	return &schedule, nil
+}
+
+func (r *Repository) GetSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.schedules[id]
+	if !ok || stored.TenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(scheduleNotFoundMessage)
+	}
+
+	schedule := cloneSchedule(stored.Schedule)
+	return &schedule, nil
+}
+
+// ListSchedules retrieves the schedules of the tenant, only the ones of the user when set, by creation time
+func (r *Repository) ListSchedules(ctx context.Context, userID *uuid.UUID) ([]domain.Schedule, error) {
+	tenantID := tenant.FromContext(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := []domain.Schedule{}
+	for _, stored := range r.schedules {
+		if stored.TenantID == tenantID && (userID == nil || stored.UserID == *userID) {
+			schedules = append(schedules, cloneSchedule(stored.Schedule))
+		}
+	}
+	sortSchedules(schedules)
+	return schedules, nil
+}
+
+// UpdateScheduleStatus moves a schedule in one of the from statuses to the status, setting its next run. It returns
+// an error wrapping domain.ErrScheduleTransition when the schedule is in another status, which it may have just
+// changed to.
+func (r *Repository) UpdateScheduleStatus(ctx context.Context, id uuid.UUID, from []domain.ScheduleStatus, to domain.ScheduleStatus, nextRunAt *time.Time) (*domain.Schedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.schedules[id]
+	if !ok || stored.TenantID != tenant.FromContext(ctx) {
+		return nil, repository.NewNotFoundError(scheduleNotFoundMessage)
+	}
+	if !containsStatus(from, stored.Status) {
+		return nil, fmt.Errorf("%w: the schedule is %s", domain.ErrScheduleTransition, stored.Status)
+	}
+
+	stored.Status = to
+	stored.NextRunAt = cloneTime(nextRunAt)
+	stored.UpdatedAt = r.now()
+
+	updated := cloneSchedule(stored.Schedule)
+	return &updated, nil
+}
+
+// ClaimDueSchedules leases to the owner, until now plus the lease, up to limit active schedules of any tenant whose next
+// run is due at now, and whose lease is free or expired
+func (r *Repository) ClaimDueSchedules(_ context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]domain.Schedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*storedSchedule
+	for _, stored := range r.schedules {
+		if stored.Status == domain.ScheduleStatusActive && stored.NextRunAt != nil && !stored.NextRunAt.After(now) &&
+			(stored.leaseExpiresAt.IsZero() || !stored.leaseExpiresAt.After(now)) {
+			due = append(due, stored)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextRunAt.Before(*due[j].NextRunAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]domain.Schedule, 0, len(due))
+	for _, stored := range due {
+		stored.leaseOwner = owner
+		stored.leaseExpiresAt = now.Add(lease)
+		claimed = append(claimed, cloneSchedule(stored.Schedule))
+	}
+	return claimed, nil
+}
+
+// AdvanceSchedule records that the occurrence of a schedule leased by the owner was processed, failing with the error
+// when not empty, and moves the schedule to its next run; the schedule is completed when there is none. It returns
+// repository.ErrLeaseLost when the owner no longer holds the lease, or when the schedule was paused, cancelled or
+// already advanced past the occurrence meanwhile.
+func (r *Repository) AdvanceSchedule(ctx context.Context, id uuid.UUID, owner string, occurrence time.Time, nextRunAt *time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.schedules[id]
+	if !ok || stored.TenantID != tenant.FromContext(ctx) || stored.leaseOwner != owner ||
+		stored.Status != domain.ScheduleStatusActive || stored.NextRunAt == nil || !stored.NextRunAt.Equal(occurrence) {
+		return repository.ErrLeaseLost
+	}
+
+	if nextRunAt == nil {
+		stored.Status = domain.ScheduleStatusCompleted
+	}
+	stored.NextRunAt = cloneTime(nextRunAt)
+	stored.LastRunAt = cloneTime(&occurrence)
+	stored.RunCount++
+	stored.LastError = lastError
+	stored.UpdatedAt = r.now()
+	return nil
+}
+
+// ReleaseSchedule frees the lease the owner holds on a schedule, if it still does
+func (r *Repository) ReleaseSchedule(ctx context.Context, id uuid.UUID, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.schedules[id]; ok && stored.TenantID == tenant.FromContext(ctx) && stored.leaseOwner == owner {
+		stored.leaseOwner = ""
+		stored.leaseExpiresAt = time.Time{}
+	}
+	return nil
+}
+
+// HasScheduleRun tells whether the occurrence of the schedule was materialized into a transaction
+func (r *Repository) HasScheduleRun(ctx context.Context, scheduleID uuid.UUID, occurrence time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.scheduleRuns[newScheduleRunKey(tenant.FromContext(ctx), scheduleID, occurrence)]
+	return ok, nil
+}
+
+func sortSchedules(schedules []domain.Schedule) {
+	sort.Slice(schedules, func(i, j int) bool {
+		return before(schedules[i].CreatedAt, schedules[i].ID, schedules[j].CreatedAt, schedules[j].ID)
+	})
+}
+
+func containsStatus(statuses []domain.ScheduleStatus, status domain.ScheduleStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneSchedule copies the schedule, so that the stored one is not shared with the callers
+func cloneSchedule(schedule domain.Schedule) domain.Schedule {
+	if schedule.Metadata != nil {
+		metadata := make(map[string]string, len(schedule.Metadata))
+		for key, value := range schedule.Metadata {
+			metadata[key] = value
+		}
+		schedule.Metadata = metadata
+	}
+	if schedule.Tags != nil {
+		schedule.Tags = append([]string(nil), schedule.Tags...)
+	}
+	schedule.NextRunAt = cloneTime(schedule.NextRunAt)
+	schedule.LastRunAt = cloneTime(schedule.LastRunAt)
+	return schedule
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := *t
+	return &value
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// GetBalance returns the balance of a user in the currency computed from every credit and debit in it created before
+// the given instant
+func (r *Repository) GetBalance(ctx context.Context, userID uuid.UUID, currency string, before time.Time) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.userBalance(tenant.FromContext(ctx), userID, currency, before), nil
+}
+
+// userBalance is GetBalance for the callers already holding the lock
+func (r *Repository) userBalance(tenantID string, userID uuid.UUID, currency string, before time.Time) int64 {
+	transactions := r.matchingTransactions(tenantID, func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID && transaction.Currency == currency && transaction.CreatedAt.Before(before)
+	})
+
+	var balance int64
+	for _, transaction := range transactions {
+		switch transaction.TransactionType {
+		case domain.TransactionTypeCredit:
+			balance += transaction.Amount
+		case domain.TransactionTypeDebit:
+			balance -= transaction.Amount
+		}
+	}
+	return balance
+}
+
+// ListUserTransactionsInPeriod retrieves every transaction of a user in the currency created in [from, to), oldest first
+func (r *Repository) ListUserTransactionsInPeriod(ctx context.Context, userID uuid.UUID, currency string, from, to time.Time) ([]domain.Transaction, error) {
+	transactions := r.tenantTransactions(tenant.FromContext(ctx), func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID && transaction.Currency == currency &&
+			!transaction.CreatedAt.Before(from) && transaction.CreatedAt.Before(to)
+	})
+	sortTransactions(transactions)
+	return transactions, nil
+}
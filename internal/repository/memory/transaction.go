@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+func (r *Repository) CreateTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insertTransaction(tenant.FromContext(ctx), transaction, r.now())
+}
+
+// insertTransaction stores a copy of the transaction created at the given instant, in DefaultCurrency when it has no
+// currency, like the mapper of the Postgres repository, along with the run of the occurrence of a schedule it
+// materializes if any. The lock must be held for writing.
+func (r *Repository) insertTransaction(tenantID string, transaction domain.Transaction, now time.Time) (*domain.Transaction, error) {
+	// the run is stored apart from the transaction, which reads back without it like from Postgres
+	run := transaction.ScheduleRun
+	transaction.ScheduleRun = nil
+	var runKey scheduleRunKey
+	if run != nil {
+		runKey = newScheduleRunKey(tenantID, run.ScheduleID, run.Occurrence)
+		if _, ok := r.scheduleRuns[runKey]; ok {
+			return nil, repository.ErrScheduleRunExists
+		}
+	}
+	key := transactionKey{tenantID: tenantID, id: transaction.ID}
+	if _, ok := r.transactions[key]; ok {
+		return nil, uniqueIDError("tenant_id, id", fmt.Sprintf("%s, %s", tenantID, transaction.ID))
+	}
+
+	if transaction.Currency == "" {
+		transaction.Currency = domain.DefaultCurrency
+	}
+	transaction.CreatedAt = now
+	transaction = cloneTransaction(transaction)
+	r.transactions[key] = storedTransaction{Transaction: transaction, tenantID: tenantID}
+	if run != nil {
+		r.scheduleRuns[runKey] = transaction.ID
+	}
+
+	created := cloneTransaction(transaction)
+	return &created, nil
+}
+
+// GetTransaction retrieves a transaction by ID. It returns a repository.NotFoundError when it does not exist.
+func (r *Repository) GetTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.transactions[transactionKey{tenantID: tenant.FromContext(ctx), id: id}]
+	if !ok {
+		return nil, repository.NewNotFoundError(transactionNotFoundMessage)
+	}
+
+	transaction := cloneTransaction(stored.Transaction)
+	return &transaction, nil
+}
+
+// ListTransactions retrieves the page of the context of the transactions of the tenant matching the filters,
+// in the order of their sort, oldest first by default
+func (r *Repository) ListTransactions(ctx context.Context, filters ...filter.Options) ([]domain.Transaction, error) {
+	tenantID := tenant.FromContext(ctx)
+	transactionsFilter := filter.New(filters...)
+
+	transactions := r.filteredTransactions(tenantID, transactionsFilter)
+	if transactionsFilter.Sort != nil {
+		sort.SliceStable(transactions, func(i, j int) bool {
+			return transactionsFilter.Sort.Less(transactions[i], transactions[j])
+		})
+	} else {
+		sortTransactions(transactions)
+	}
+	if search := transactionsFilter.Search; search != nil {
+		for i := range transactions {
+			transactions[i].Match, _ = filter.MatchSearch(*search, transactions[i])
+		}
+		if transactionsFilter.Sort == nil {
+			// the most relevant first, then oldest first like Postgres
+			sort.SliceStable(transactions, func(i, j int) bool {
+				return transactions[i].Match.Rank > transactions[j].Match.Rank
+			})
+		}
+	}
+
+	page, pageSize := repository.PageFromContext(ctx)
+	offset := (page - 1) * pageSize
+	if offset >= len(transactions) {
+		return []domain.Transaction{}, nil
+	}
+	end := offset + pageSize
+	if end > len(transactions) {
+		end = len(transactions)
+	}
+
+	return transactions[offset:end], nil
+}
+
+// filteredTransactions returns copies of the transactions of the tenant matching the filter, in no particular order
+func (r *Repository) filteredTransactions(tenantID string, f filter.TransactionFilter) []domain.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// the category of the filter includes its subcategories, which Matches cannot know of
+	var subtree map[uuid.UUID]bool
+	if f.Category != nil {
+		subtree = make(map[uuid.UUID]bool)
+		for _, id := range domain.CategorySubtree(r.tenantCategories(tenantID), *f.Category) {
+			subtree[id] = true
+		}
+	}
+
+	return r.matchingTransactions(tenantID, func(transaction domain.Transaction) bool {
+		if subtree != nil && (transaction.CategoryID == nil || !subtree[*transaction.CategoryID]) {
+			return false
+		}
+		return f.Matches(tenantID, transaction)
+	})
+}
+
+// SetTransactionCategory sets or clears the category of a transaction, recording who changed it and when.
+// It returns a repository.NotFoundError when the transaction does not exist.
+func (r *Repository) SetTransactionCategory(ctx context.Context, id uuid.UUID, categoryID *uuid.UUID, categorizedBy string) (*domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := transactionKey{tenantID: tenant.FromContext(ctx), id: id}
+	stored, ok := r.transactions[key]
+	if !ok {
+		return nil, repository.NewNotFoundError(transactionNotFoundMessage)
+	}
+
+	now := r.now()
+	stored.CategoryID = categoryID
+	stored.CategorizedBy = categorizedBy
+	stored.CategorizedAt = &now
+	stored.Transaction = cloneTransaction(stored.Transaction)
+	r.transactions[key] = stored
+
+	transaction := cloneTransaction(stored.Transaction)
+	return &transaction, nil
+}
+
+// GetTransactionStats counts and sums the transactions of the tenant matching the filters by the value of the
+// dimension, ordered by that value. The sort and the page of the filters are ignored.
+func (r *Repository) GetTransactionStats(ctx context.Context, dimension string, filters ...filter.Options) ([]domain.TransactionStats, error) {
+	if !domain.IsValidStatsDimension(dimension) {
+		return nil, fmt.Errorf("unknown stats dimension %q", dimension)
+	}
+
+	byKey := make(map[string]*domain.TransactionStats)
+	for _, transaction := range r.filteredTransactions(tenant.FromContext(ctx), filter.New(filters...)) {
+		key := transaction.StatsKey(dimension)
+		if byKey[key] == nil {
+			byKey[key] = &domain.TransactionStats{Key: key}
+		}
+		byKey[key].Add(transaction)
+	}
+
+	stats := make([]domain.TransactionStats, 0, len(byKey))
+	for _, s := range byKey {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Key < stats[j].Key })
+	return stats, nil
+}
+
+// CountUserTransactionsSince counts the transactions of a user created at or after the given instant
+func (r *Repository) CountUserTransactionsSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	transactions := r.tenantTransactions(tenant.FromContext(ctx), func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID && !transaction.CreatedAt.Before(since)
+	})
+	return len(transactions), nil
+}
+
+// GetLatestUserTransaction retrieves the most recent transaction of a user.
+// It returns a repository.NotFoundError when the user has no transactions.
+func (r *Repository) GetLatestUserTransaction(ctx context.Context, userID uuid.UUID) (*domain.Transaction, error) {
+	transactions := r.tenantTransactions(tenant.FromContext(ctx), func(transaction domain.Transaction) bool {
+		return transaction.UserID == userID
+	})
+	if len(transactions) == 0 {
+		return nil, repository.NewNotFoundError("no transactions found for user")
+	}
+
+	sortTransactions(transactions)
+	return &transactions[len(transactions)-1], nil
+}
+
+// tenantTransactions returns copies of the transactions of the tenant matching the predicate, in no particular order
+func (r *Repository) tenantTransactions(tenantID string, match func(domain.Transaction) bool) []domain.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.matchingTransactions(tenantID, match)
+}
+
+// matchingTransactions is tenantTransactions for the callers already holding the lock
+func (r *Repository) matchingTransactions(tenantID string, match func(domain.Transaction) bool) []domain.Transaction {
+	transactions := []domain.Transaction{}
+	for _, stored := range r.transactions {
+		if stored.tenantID == tenantID && match(stored.Transaction) {
+			transactions = append(transactions, cloneTransaction(stored.Transaction))
+		}
+	}
+	return transactions
+}
+
+func cloneTransaction(transaction domain.Transaction) domain.Transaction {
+	if transaction.FlaggedRules != nil {
+		transaction.FlaggedRules = append([]string(nil), transaction.FlaggedRules...)
+	}
+	if transaction.Metadata != nil {
+		metadata := make(map[string]string, len(transaction.Metadata))
+		for key, value := range transaction.Metadata {
+			metadata[key] = value
+		}
+		transaction.Metadata = metadata
+	}
+	if transaction.Tags != nil {
+		transaction.Tags = append([]string(nil), transaction.Tags...)
+	}
+	if transaction.CategoryID != nil {
+		categoryID := *transaction.CategoryID
+		transaction.CategoryID = &categoryID
+	}
+	if transaction.CategorizedAt != nil {
+		categorizedAt := *transaction.CategorizedAt
+		transaction.CategorizedAt = &categorizedAt
+	}
+	return transaction
+}
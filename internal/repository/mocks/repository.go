@@ -7,10 +7,12 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	domain "traive-engineering-challenge/internal/domain"
 	filter "traive-engineering-challenge/internal/repository/filter"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockRepository is a mock of Repository interface.
@@ -36,6 +38,216 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AdvanceSchedule mocks base method.
+func (m *MockRepository) AdvanceSchedule(ctx context.Context, id uuid.UUID, owner string, occurrence time.Time, nextRunAt *time.Time, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceSchedule", ctx, id, owner, occurrence, nextRunAt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceSchedule indicates an expected call of AdvanceSchedule.
+func (mr *MockRepositoryMockRecorder) AdvanceSchedule(ctx, id, owner, occurrence, nextRunAt, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceSchedule", reflect.TypeOf((*MockRepository)(nil).AdvanceSchedule), ctx, id, owner, occurrence, nextRunAt, lastError)
+}
+
+// CaptureHold mocks base method.
+func (m *MockRepository) CaptureHold(ctx context.Context, id uuid.UUID, transaction domain.Transaction, limits []domain.Limit) (*domain.Hold, *domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, id, transaction, limits)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(*domain.Transaction)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockRepositoryMockRecorder) CaptureHold(ctx, id, transaction, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockRepository)(nil).CaptureHold), ctx, id, transaction, limits)
+}
+
+// ClaimDueSchedules mocks base method.
+func (m *MockRepository) ClaimDueSchedules(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueSchedules", ctx, owner, now, lease, limit)
+	ret0, _ := ret[0].([]domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueSchedules indicates an expected call of ClaimDueSchedules.
+func (mr *MockRepositoryMockRecorder) ClaimDueSchedules(ctx, owner, now, lease, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueSchedules", reflect.TypeOf((*MockRepository)(nil).ClaimDueSchedules), ctx, owner, now, lease, limit)
+}
+
+// CountUserTransactionsSince mocks base method.
+func (m *MockRepository) CountUserTransactionsSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserTransactionsSince", ctx, userID, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserTransactionsSince indicates an expected call of CountUserTransactionsSince.
+func (mr *MockRepositoryMockRecorder) CountUserTransactionsSince(ctx, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserTransactionsSince", reflect.TypeOf((*MockRepository)(nil).CountUserTransactionsSince), ctx, userID, since)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockRepository) CreateAPIKey(ctx context.Context, key domain.APIKey) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockRepositoryMockRecorder) CreateAPIKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockRepository)(nil).CreateAPIKey), ctx, key)
+}
+
+// CreateCategory mocks base method.
+func (m *MockRepository) CreateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, category)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockRepositoryMockRecorder) CreateCategory(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockRepository)(nil).CreateCategory), ctx, category)
+}
+
+// CreateCategoryRule mocks base method.
+func (m *MockRepository) CreateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategoryRule", ctx, rule)
+	ret0, _ := ret[0].(*domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategoryRule indicates an expected call of CreateCategoryRule.
+func (mr *MockRepositoryMockRecorder) CreateCategoryRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryRule", reflect.TypeOf((*MockRepository)(nil).CreateCategoryRule), ctx, rule)
+}
+
+// CreateFXConversion mocks base method.
+func (m *MockRepository) CreateFXConversion(ctx context.Context, conversion domain.FXConversion, debit, credit domain.Transaction, limits []domain.Limit) (*domain.FXConversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFXConversion", ctx, conversion, debit, credit, limits)
+	ret0, _ := ret[0].(*domain.FXConversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFXConversion indicates an expected call of CreateFXConversion.
+func (mr *MockRepositoryMockRecorder) CreateFXConversion(ctx, conversion, debit, credit, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFXConversion", reflect.TypeOf((*MockRepository)(nil).CreateFXConversion), ctx, conversion, debit, credit, limits)
+}
+
+// CreateFXQuote mocks base method.
+func (m *MockRepository) CreateFXQuote(ctx context.Context, quote domain.FXQuote) (*domain.FXQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFXQuote", ctx, quote)
+	ret0, _ := ret[0].(*domain.FXQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFXQuote indicates an expected call of CreateFXQuote.
+func (mr *MockRepositoryMockRecorder) CreateFXQuote(ctx, quote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFXQuote", reflect.TypeOf((*MockRepository)(nil).CreateFXQuote), ctx, quote)
+}
+
+// CreateHold mocks base method.
+func (m *MockRepository) CreateHold(ctx context.Context, hold domain.Hold) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, hold)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockRepositoryMockRecorder) CreateHold(ctx, hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockRepository)(nil).CreateHold), ctx, hold)
+}
+
+// CreateLimit mocks base method.
+func (m *MockRepository) CreateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLimit", ctx, limit)
+	ret0, _ := ret[0].(*domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLimit indicates an expected call of CreateLimit.
+func (mr *MockRepositoryMockRecorder) CreateLimit(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLimit", reflect.TypeOf((*MockRepository)(nil).CreateLimit), ctx, limit)
+}
+
+// CreateRateSnapshot mocks base method.
+func (m *MockRepository) CreateRateSnapshot(ctx context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRateSnapshot", ctx, snapshot)
+	ret0, _ := ret[0].(*domain.RateSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRateSnapshot indicates an expected call of CreateRateSnapshot.
+func (mr *MockRepositoryMockRecorder) CreateRateSnapshot(ctx, snapshot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRateSnapshot", reflect.TypeOf((*MockRepository)(nil).CreateRateSnapshot), ctx, snapshot)
+}
+
+// CreateSavedSearch mocks base method.
+func (m *MockRepository) CreateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavedSearch", ctx, search)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavedSearch indicates an expected call of CreateSavedSearch.
+func (mr *MockRepositoryMockRecorder) CreateSavedSearch(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavedSearch", reflect.TypeOf((*MockRepository)(nil).CreateSavedSearch), ctx, search)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockRepository) CreateSchedule(ctx context.Context, schedule domain.Schedule) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", ctx, schedule)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockRepositoryMockRecorder) CreateSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockRepository)(nil).CreateSchedule), ctx, schedule)
+}
+
 // CreateTransaction mocks base method.
 func (m *MockRepository) CreateTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +263,517 @@ func (mr *MockRepositoryMockRecorder) CreateTransaction(ctx, transaction interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockRepository)(nil).CreateTransaction), ctx, transaction)
 }
 
+// CreateTransactionWithinLimits mocks base method.
+func (m *MockRepository) CreateTransactionWithinLimits(ctx context.Context, transaction domain.Transaction, limits []domain.Limit) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransactionWithinLimits", ctx, transaction, limits)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransactionWithinLimits indicates an expected call of CreateTransactionWithinLimits.
+func (mr *MockRepositoryMockRecorder) CreateTransactionWithinLimits(ctx, transaction, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransactionWithinLimits", reflect.TypeOf((*MockRepository)(nil).CreateTransactionWithinLimits), ctx, transaction, limits)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockRepositoryMockRecorder) DeleteCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockRepository)(nil).DeleteCategory), ctx, id)
+}
+
+// DeleteCategoryRule mocks base method.
+func (m *MockRepository) DeleteCategoryRule(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategoryRule indicates an expected call of DeleteCategoryRule.
+func (mr *MockRepositoryMockRecorder) DeleteCategoryRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryRule", reflect.TypeOf((*MockRepository)(nil).DeleteCategoryRule), ctx, id)
+}
+
+// DeleteLimit mocks base method.
+func (m *MockRepository) DeleteLimit(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLimit", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLimit indicates an expected call of DeleteLimit.
+func (mr *MockRepositoryMockRecorder) DeleteLimit(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLimit", reflect.TypeOf((*MockRepository)(nil).DeleteLimit), ctx, id)
+}
+
+// DeleteSavedSearch mocks base method.
+func (m *MockRepository) DeleteSavedSearch(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSavedSearch", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSavedSearch indicates an expected call of DeleteSavedSearch.
+func (mr *MockRepositoryMockRecorder) DeleteSavedSearch(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSavedSearch", reflect.TypeOf((*MockRepository)(nil).DeleteSavedSearch), ctx, id)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockRepository) ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx, now, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockRepositoryMockRecorder) ExpireHolds(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockRepository)(nil).ExpireHolds), ctx, now, limit)
+}
+
+// GetAPIKey mocks base method.
+func (m *MockRepository) GetAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", ctx, id)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockRepositoryMockRecorder) GetAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockRepository)(nil).GetAPIKey), ctx, id)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockRepositoryMockRecorder) GetAPIKeyByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockRepository)(nil).GetAPIKeyByPrefix), ctx, prefix)
+}
+
+// GetBalance mocks base method.
+func (m *MockRepository) GetBalance(ctx context.Context, userID uuid.UUID, currency string, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, userID, currency, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockRepositoryMockRecorder) GetBalance(ctx, userID, currency, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockRepository)(nil).GetBalance), ctx, userID, currency, before)
+}
+
+// GetCategory mocks base method.
+func (m *MockRepository) GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", ctx, id)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockRepositoryMockRecorder) GetCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockRepository)(nil).GetCategory), ctx, id)
+}
+
+// GetCategoryRule mocks base method.
+func (m *MockRepository) GetCategoryRule(ctx context.Context, id uuid.UUID) (*domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryRule", ctx, id)
+	ret0, _ := ret[0].(*domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryRule indicates an expected call of GetCategoryRule.
+func (mr *MockRepositoryMockRecorder) GetCategoryRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryRule", reflect.TypeOf((*MockRepository)(nil).GetCategoryRule), ctx, id)
+}
+
+// GetEffectiveRateSnapshot mocks base method.
+func (m *MockRepository) GetEffectiveRateSnapshot(ctx context.Context, at time.Time) (*domain.RateSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEffectiveRateSnapshot", ctx, at)
+	ret0, _ := ret[0].(*domain.RateSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEffectiveRateSnapshot indicates an expected call of GetEffectiveRateSnapshot.
+func (mr *MockRepositoryMockRecorder) GetEffectiveRateSnapshot(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEffectiveRateSnapshot", reflect.TypeOf((*MockRepository)(nil).GetEffectiveRateSnapshot), ctx, at)
+}
+
+// GetFXConversion mocks base method.
+func (m *MockRepository) GetFXConversion(ctx context.Context, id uuid.UUID) (*domain.FXConversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFXConversion", ctx, id)
+	ret0, _ := ret[0].(*domain.FXConversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFXConversion indicates an expected call of GetFXConversion.
+func (mr *MockRepositoryMockRecorder) GetFXConversion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFXConversion", reflect.TypeOf((*MockRepository)(nil).GetFXConversion), ctx, id)
+}
+
+// GetFXQuote mocks base method.
+func (m *MockRepository) GetFXQuote(ctx context.Context, id uuid.UUID) (*domain.FXQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFXQuote", ctx, id)
+	ret0, _ := ret[0].(*domain.FXQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFXQuote indicates an expected call of GetFXQuote.
+func (mr *MockRepositoryMockRecorder) GetFXQuote(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFXQuote", reflect.TypeOf((*MockRepository)(nil).GetFXQuote), ctx, id)
+}
+
+// GetHeldAmount mocks base method.
+func (m *MockRepository) GetHeldAmount(ctx context.Context, userID uuid.UUID, currency string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeldAmount", ctx, userID, currency)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeldAmount indicates an expected call of GetHeldAmount.
+func (mr *MockRepositoryMockRecorder) GetHeldAmount(ctx, userID, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeldAmount", reflect.TypeOf((*MockRepository)(nil).GetHeldAmount), ctx, userID, currency)
+}
+
+// GetHold mocks base method.
+func (m *MockRepository) GetHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, id)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockRepositoryMockRecorder) GetHold(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockRepository)(nil).GetHold), ctx, id)
+}
+
+// GetLatestUserTransaction mocks base method.
+func (m *MockRepository) GetLatestUserTransaction(ctx context.Context, userID uuid.UUID) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestUserTransaction", ctx, userID)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestUserTransaction indicates an expected call of GetLatestUserTransaction.
+func (mr *MockRepositoryMockRecorder) GetLatestUserTransaction(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestUserTransaction", reflect.TypeOf((*MockRepository)(nil).GetLatestUserTransaction), ctx, userID)
+}
+
+// GetLimit mocks base method.
+func (m *MockRepository) GetLimit(ctx context.Context, id uuid.UUID) (*domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimit", ctx, id)
+	ret0, _ := ret[0].(*domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimit indicates an expected call of GetLimit.
+func (mr *MockRepositoryMockRecorder) GetLimit(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimit", reflect.TypeOf((*MockRepository)(nil).GetLimit), ctx, id)
+}
+
+// GetSavedSearch mocks base method.
+func (m *MockRepository) GetSavedSearch(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedSearch", ctx, id)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedSearch indicates an expected call of GetSavedSearch.
+func (mr *MockRepositoryMockRecorder) GetSavedSearch(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedSearch", reflect.TypeOf((*MockRepository)(nil).GetSavedSearch), ctx, id)
+}
+
+// GetSavedSearchByName mocks base method.
+func (m *MockRepository) GetSavedSearchByName(ctx context.Context, name string) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedSearchByName", ctx, name)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedSearchByName indicates an expected call of GetSavedSearchByName.
+func (mr *MockRepositoryMockRecorder) GetSavedSearchByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedSearchByName", reflect.TypeOf((*MockRepository)(nil).GetSavedSearchByName), ctx, name)
+}
+
+// GetSchedule mocks base method.
+func (m *MockRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", ctx, id)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockRepositoryMockRecorder) GetSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockRepository)(nil).GetSchedule), ctx, id)
+}
+
+// GetTransaction mocks base method.
+func (m *MockRepository) GetTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, id)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockRepositoryMockRecorder) GetTransaction(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockRepository)(nil).GetTransaction), ctx, id)
+}
+
+// GetTransactionStats mocks base method.
+func (m *MockRepository) GetTransactionStats(ctx context.Context, dimension string, filters ...filter.Options) ([]domain.TransactionStats, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, dimension}
+	for _, a := range filters {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetTransactionStats", varargs...)
+	ret0, _ := ret[0].([]domain.TransactionStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionStats indicates an expected call of GetTransactionStats.
+func (mr *MockRepositoryMockRecorder) GetTransactionStats(ctx, dimension interface{}, filters ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, dimension}, filters...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionStats", reflect.TypeOf((*MockRepository)(nil).GetTransactionStats), varargs...)
+}
+
+// HasScheduleRun mocks base method.
+func (m *MockRepository) HasScheduleRun(ctx context.Context, scheduleID uuid.UUID, occurrence time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasScheduleRun", ctx, scheduleID, occurrence)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasScheduleRun indicates an expected call of HasScheduleRun.
+func (mr *MockRepositoryMockRecorder) HasScheduleRun(ctx, scheduleID, occurrence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasScheduleRun", reflect.TypeOf((*MockRepository)(nil).HasScheduleRun), ctx, scheduleID, occurrence)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockRepositoryMockRecorder) ListAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockRepository)(nil).ListAPIKeys), ctx)
+}
+
+// ListApplicableLimits mocks base method.
+func (m *MockRepository) ListApplicableLimits(ctx context.Context, userID uuid.UUID) ([]domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListApplicableLimits", ctx, userID)
+	ret0, _ := ret[0].([]domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListApplicableLimits indicates an expected call of ListApplicableLimits.
+func (mr *MockRepositoryMockRecorder) ListApplicableLimits(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApplicableLimits", reflect.TypeOf((*MockRepository)(nil).ListApplicableLimits), ctx, userID)
+}
+
+// ListCategories mocks base method.
+func (m *MockRepository) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", ctx)
+	ret0, _ := ret[0].([]domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockRepositoryMockRecorder) ListCategories(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockRepository)(nil).ListCategories), ctx)
+}
+
+// ListCategoryRules mocks base method.
+func (m *MockRepository) ListCategoryRules(ctx context.Context) ([]domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategoryRules", ctx)
+	ret0, _ := ret[0].([]domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategoryRules indicates an expected call of ListCategoryRules.
+func (mr *MockRepositoryMockRecorder) ListCategoryRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategoryRules", reflect.TypeOf((*MockRepository)(nil).ListCategoryRules), ctx)
+}
+
+// ListFXConversions mocks base method.
+func (m *MockRepository) ListFXConversions(ctx context.Context, userID *uuid.UUID) ([]domain.FXConversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFXConversions", ctx, userID)
+	ret0, _ := ret[0].([]domain.FXConversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFXConversions indicates an expected call of ListFXConversions.
+func (mr *MockRepositoryMockRecorder) ListFXConversions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFXConversions", reflect.TypeOf((*MockRepository)(nil).ListFXConversions), ctx, userID)
+}
+
+// ListHolds mocks base method.
+func (m *MockRepository) ListHolds(ctx context.Context, userID *uuid.UUID) ([]domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHolds", ctx, userID)
+	ret0, _ := ret[0].([]domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHolds indicates an expected call of ListHolds.
+func (mr *MockRepositoryMockRecorder) ListHolds(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHolds", reflect.TypeOf((*MockRepository)(nil).ListHolds), ctx, userID)
+}
+
+// ListLimits mocks base method.
+func (m *MockRepository) ListLimits(ctx context.Context) ([]domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLimits", ctx)
+	ret0, _ := ret[0].([]domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLimits indicates an expected call of ListLimits.
+func (mr *MockRepositoryMockRecorder) ListLimits(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLimits", reflect.TypeOf((*MockRepository)(nil).ListLimits), ctx)
+}
+
+// ListRateSnapshots mocks base method.
+func (m *MockRepository) ListRateSnapshots(ctx context.Context) ([]domain.RateSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRateSnapshots", ctx)
+	ret0, _ := ret[0].([]domain.RateSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRateSnapshots indicates an expected call of ListRateSnapshots.
+func (mr *MockRepositoryMockRecorder) ListRateSnapshots(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRateSnapshots", reflect.TypeOf((*MockRepository)(nil).ListRateSnapshots), ctx)
+}
+
+// ListSavedSearches mocks base method.
+func (m *MockRepository) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSavedSearches", ctx)
+	ret0, _ := ret[0].([]domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSavedSearches indicates an expected call of ListSavedSearches.
+func (mr *MockRepositoryMockRecorder) ListSavedSearches(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSavedSearches", reflect.TypeOf((*MockRepository)(nil).ListSavedSearches), ctx)
+}
+
+// ListSchedules mocks base method.
+func (m *MockRepository) ListSchedules(ctx context.Context, userID *uuid.UUID) ([]domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", ctx, userID)
+	ret0, _ := ret[0].([]domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockRepositoryMockRecorder) ListSchedules(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockRepository)(nil).ListSchedules), ctx, userID)
+}
+
 // ListTransactions mocks base method.
 func (m *MockRepository) ListTransactions(ctx context.Context, filters ...filter.Options) ([]domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -70,3 +793,195 @@ func (mr *MockRepositoryMockRecorder) ListTransactions(ctx interface{}, filters
 	varargs := append([]interface{}{ctx}, filters...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockRepository)(nil).ListTransactions), varargs...)
 }
+
+// ListUserTransactionsInPeriod mocks base method.
+func (m *MockRepository) ListUserTransactionsInPeriod(ctx context.Context, userID uuid.UUID, currency string, from, to time.Time) ([]domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserTransactionsInPeriod", ctx, userID, currency, from, to)
+	ret0, _ := ret[0].([]domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserTransactionsInPeriod indicates an expected call of ListUserTransactionsInPeriod.
+func (mr *MockRepositoryMockRecorder) ListUserTransactionsInPeriod(ctx, userID, currency, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserTransactionsInPeriod", reflect.TypeOf((*MockRepository)(nil).ListUserTransactionsInPeriod), ctx, userID, currency, from, to)
+}
+
+// ReleaseHold mocks base method.
+func (m *MockRepository) ReleaseHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", ctx, id)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockRepositoryMockRecorder) ReleaseHold(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockRepository)(nil).ReleaseHold), ctx, id)
+}
+
+// ReleaseSchedule mocks base method.
+func (m *MockRepository) ReleaseSchedule(ctx context.Context, id uuid.UUID, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSchedule", ctx, id, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSchedule indicates an expected call of ReleaseSchedule.
+func (mr *MockRepositoryMockRecorder) ReleaseSchedule(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSchedule", reflect.TypeOf((*MockRepository)(nil).ReleaseSchedule), ctx, id, owner)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockRepositoryMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockRepository)(nil).RevokeAPIKey), ctx, id)
+}
+
+// RotateAPIKey mocks base method.
+func (m *MockRepository) RotateAPIKey(ctx context.Context, id uuid.UUID, replacement domain.APIKey) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAPIKey", ctx, id, replacement)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockRepositoryMockRecorder) RotateAPIKey(ctx, id, replacement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockRepository)(nil).RotateAPIKey), ctx, id, replacement)
+}
+
+// SetTransactionCategory mocks base method.
+func (m *MockRepository) SetTransactionCategory(ctx context.Context, id uuid.UUID, categoryID *uuid.UUID, categorizedBy string) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransactionCategory", ctx, id, categoryID, categorizedBy)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTransactionCategory indicates an expected call of SetTransactionCategory.
+func (mr *MockRepositoryMockRecorder) SetTransactionCategory(ctx, id, categoryID, categorizedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransactionCategory", reflect.TypeOf((*MockRepository)(nil).SetTransactionCategory), ctx, id, categoryID, categorizedBy)
+}
+
+// SumUserDebitsSince mocks base method.
+func (m *MockRepository) SumUserDebitsSince(ctx context.Context, userID uuid.UUID, origin, currency string, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumUserDebitsSince", ctx, userID, origin, currency, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumUserDebitsSince indicates an expected call of SumUserDebitsSince.
+func (mr *MockRepositoryMockRecorder) SumUserDebitsSince(ctx, userID, origin, currency, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumUserDebitsSince", reflect.TypeOf((*MockRepository)(nil).SumUserDebitsSince), ctx, userID, origin, currency, since)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockRepositoryMockRecorder) TouchAPIKey(ctx, id, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockRepository)(nil).TouchAPIKey), ctx, id, usedAt)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockRepository) UpdateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", ctx, category)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockRepositoryMockRecorder) UpdateCategory(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockRepository)(nil).UpdateCategory), ctx, category)
+}
+
+// UpdateCategoryRule mocks base method.
+func (m *MockRepository) UpdateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategoryRule", ctx, rule)
+	ret0, _ := ret[0].(*domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategoryRule indicates an expected call of UpdateCategoryRule.
+func (mr *MockRepositoryMockRecorder) UpdateCategoryRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategoryRule", reflect.TypeOf((*MockRepository)(nil).UpdateCategoryRule), ctx, rule)
+}
+
+// UpdateLimit mocks base method.
+func (m *MockRepository) UpdateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLimit", ctx, limit)
+	ret0, _ := ret[0].(*domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLimit indicates an expected call of UpdateLimit.
+func (mr *MockRepositoryMockRecorder) UpdateLimit(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLimit", reflect.TypeOf((*MockRepository)(nil).UpdateLimit), ctx, limit)
+}
+
+// UpdateSavedSearch mocks base method.
+func (m *MockRepository) UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSavedSearch", ctx, search)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSavedSearch indicates an expected call of UpdateSavedSearch.
+func (mr *MockRepositoryMockRecorder) UpdateSavedSearch(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSavedSearch", reflect.TypeOf((*MockRepository)(nil).UpdateSavedSearch), ctx, search)
+}
+
+// UpdateScheduleStatus mocks base method.
+func (m *MockRepository) UpdateScheduleStatus(ctx context.Context, id uuid.UUID, from []domain.ScheduleStatus, to domain.ScheduleStatus, nextRunAt *time.Time) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduleStatus", ctx, id, from, to, nextRunAt)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduleStatus indicates an expected call of UpdateScheduleStatus.
+func (mr *MockRepositoryMockRecorder) UpdateScheduleStatus(ctx, id, from, to, nextRunAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduleStatus", reflect.TypeOf((*MockRepository)(nil).UpdateScheduleStatus), ctx, id, from, to, nextRunAt)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type APIKey struct {
+	bun.BaseModel `bun:"table:api_keys,alias:k"`
+
+	ID         uuid.UUID  `bun:",pk,notnull,type:uuid"`
+	TenantID   string     `bun:",notnull"`
+	Name       string     `bun:",notnull"`
+	Prefix     string     `bun:",notnull,unique"`
+	KeyHash    string     `bun:",notnull"`
+	Scopes     []string   `bun:",array"`
+	CreatedAt  time.Time  `bun:",nullzero,notnull,default:current_timestamp"`
+	LastUsedAt *time.Time `bun:""`
+	RevokedAt  *time.Time `bun:""`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type Category struct {
+	bun.BaseModel `bun:"table:categories,alias:c"`
+
+	ID        uuid.UUID  `bun:",pk,notnull,type:uuid"`
+	TenantID  string     `bun:",notnull"`
+	Name      string     `bun:",notnull"`
+	ParentID  *uuid.UUID `bun:",type:uuid"`
+	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp"`
+	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+type CategoryRule struct {
+	bun.BaseModel `bun:"table:category_rules,alias:cr"`
+
+	ID                 uuid.UUID `bun:",pk,notnull,type:uuid"`
+	TenantID           string    `bun:",notnull"`
+	CategoryID         uuid.UUID `bun:",notnull,type:uuid"`
+	Priority           int       `bun:",notnull"`
+	Origin             string    `bun:",nullzero"`
+	MinAmount          *int64
+	MaxAmount          *int64
+	Metadata           map[string]string `bun:"type:jsonb,nullzero"`
+	DescriptionPattern string            `bun:",nullzero"`
+	CreatedAt          time.Time         `bun:",nullzero,notnull,default:current_timestamp"`
+	UpdatedAt          time.Time         `bun:",nullzero,notnull,default:current_timestamp"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type RateSnapshot struct {
+	bun.BaseModel `bun:"table:fx_rate_snapshots,alias:fxr"`
+
+	ID          uuid.UUID `bun:",pk,notnull,type:uuid"`
+	TenantID    string    `bun:",notnull"`
+	Source      string    `bun:",notnull"`
+	EffectiveAt time.Time `bun:",notnull"`
+	Rates       []FXRate  `bun:"type:jsonb,notnull"`
+	CreatedAt   time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+// FXRate is an element of the rates of a snapshot
+type FXRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+}
+
+type FXQuote struct {
+	bun.BaseModel `bun:"table:fx_quotes,alias:fxq"`
+
+	ID             uuid.UUID `bun:",pk,notnull,type:uuid"`
+	TenantID       string    `bun:",notnull"`
+	UserID         uuid.UUID `bun:",notnull,type:uuid"`
+	SourceCurrency string    `bun:",notnull"`
+	TargetCurrency string    `bun:",notnull"`
+	SourceAmount   int64     `bun:",notnull"`
+	TargetAmount   int64     `bun:",notnull"`
+	Rate           string    `bun:",notnull"`
+	SnapshotID     uuid.UUID `bun:",notnull,type:uuid"`
+	ExpiresAt      time.Time `bun:",notnull"`
+	CreatedAt      time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+type FXConversion struct {
+	bun.BaseModel `bun:"table:fx_conversions,alias:fxc"`
+
+	ID                  uuid.UUID `bun:",pk,notnull,type:uuid"`
+	TenantID            string    `bun:",notnull"`
+	QuoteID             uuid.UUID `bun:",notnull,type:uuid"`
+	UserID              uuid.UUID `bun:",notnull,type:uuid"`
+	Origin              string    `bun:",notnull"`
+	SourceCurrency      string    `bun:",notnull"`
+	TargetCurrency      string    `bun:",notnull"`
+	SourceAmount        int64     `bun:",notnull"`
+	TargetAmount        int64     `bun:",notnull"`
+	Rate                string    `bun:",notnull"`
+	DebitTransactionID  uuid.UUID `bun:",notnull,type:uuid"`
+	CreditTransactionID uuid.UUID `bun:",notnull,type:uuid"`
+	CreatedAt           time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
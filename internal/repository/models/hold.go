@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type Hold struct {
+	bun.BaseModel `bun:"table:holds,alias:h"`
+
+	ID             uuid.UUID         `bun:",pk,notnull,type:uuid"`
+	TenantID       string            `bun:",notnull"`
+	UserID         uuid.UUID         `bun:",notnull,type:uuid"`
+	Origin         string            `bun:",notnull"`
+	Amount         int64             `bun:",notnull"`
+	Currency       string            `bun:",notnull"`
+	Description    string            `bun:",notnull"`
+	Metadata       map[string]string `bun:"type:jsonb,notnull"`
+	Tags           []string          `bun:",array,notnull"`
+	Status         string            `bun:",notnull"`
+	ExpiresAt      time.Time         `bun:",notnull"`
+	CapturedAmount int64             `bun:",notnull"`
+	// TransactionID is NULL until the hold is captured
+	TransactionID uuid.NullUUID `bun:",type:uuid"`
+	CreatedAt     time.Time     `bun:",nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time     `bun:",nullzero,notnull,default:current_timestamp"`
+}
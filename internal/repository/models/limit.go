@@ -0,0 +1,22 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type Limit struct {
+	bun.BaseModel `bun:"table:transaction_limits,alias:l"`
+
+	ID        uuid.UUID  `bun:",pk,notnull,type:uuid"`
+	TenantID  string     `bun:",notnull"`
+	Scope     string     `bun:",notnull"`
+	UserID    *uuid.UUID `bun:",type:uuid"`
+	Origin    string     `bun:",nullzero"`
+	Period    string     `bun:",notnull"`
+	MaxAmount int64      `bun:",notnull"`
+	Currency  string     `bun:",notnull"`
+	CreatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp"`
+	UpdatedAt time.Time  `bun:",nullzero,notnull,default:current_timestamp"`
+}
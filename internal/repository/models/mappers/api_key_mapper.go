@@ -0,0 +1,44 @@
+package mappers
+
+import (
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// ConvertAPIKeyDomainToModel converts a domain.APIKey to a models.APIKey.
+func ConvertAPIKeyDomainToModel(key domain.APIKey) *models.APIKey {
+	return &models.APIKey{
+		ID:         key.ID,
+		TenantID:   key.TenantID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		KeyHash:    key.KeyHash,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+// ConvertAPIKeyModelToDomain converts a models.APIKey to a domain.APIKey.
+func ConvertAPIKeyModelToDomain(keyModel models.APIKey) domain.APIKey {
+	return domain.APIKey{
+		ID:         keyModel.ID,
+		TenantID:   keyModel.TenantID,
+		Name:       keyModel.Name,
+		Prefix:     keyModel.Prefix,
+		KeyHash:    keyModel.KeyHash,
+		Scopes:     keyModel.Scopes,
+		CreatedAt:  keyModel.CreatedAt,
+		LastUsedAt: keyModel.LastUsedAt,
+		RevokedAt:  keyModel.RevokedAt,
+	}
+}
+
+func ConvertAPIKeyToDomainList(keyModels []*models.APIKey) []domain.APIKey {
+	domainList := make([]domain.APIKey, 0, len(keyModels))
+	for _, model := range keyModels {
+		domainList = append(domainList, ConvertAPIKeyModelToDomain(*model))
+	}
+	return domainList
+}
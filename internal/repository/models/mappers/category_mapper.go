@@ -0,0 +1,79 @@
+package mappers
+
+import (
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// ConvertCategoryDomainToModel converts a domain.Category to a models.Category.
+func ConvertCategoryDomainToModel(category domain.Category) *models.Category {
+	return &models.Category{
+		ID:        category.ID,
+		Name:      category.Name,
+		ParentID:  category.ParentID,
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}
+}
+
+// ConvertCategoryModelToDomain converts a models.Category to a domain.Category.
+func ConvertCategoryModelToDomain(categoryModel models.Category) domain.Category {
+	return domain.Category{
+		ID:        categoryModel.ID,
+		Name:      categoryModel.Name,
+		ParentID:  categoryModel.ParentID,
+		CreatedAt: categoryModel.CreatedAt,
+		UpdatedAt: categoryModel.UpdatedAt,
+	}
+}
+
+func ConvertCategoryToDomainList(categoryModels []*models.Category) []domain.Category {
+	categories := make([]domain.Category, 0, len(categoryModels))
+	for _, categoryModel := range categoryModels {
+		categories = append(categories, ConvertCategoryModelToDomain(*categoryModel))
+	}
+	return categories
+}
+
+// ConvertCategoryRuleDomainToModel converts a domain.CategoryRule to a models.CategoryRule.
+func ConvertCategoryRuleDomainToModel(rule domain.CategoryRule) *models.CategoryRule {
+	return &models.CategoryRule{
+		ID:                 rule.ID,
+		CategoryID:         rule.CategoryID,
+		Priority:           rule.Priority,
+		Origin:             rule.Origin,
+		MinAmount:          rule.MinAmount,
+		MaxAmount:          rule.MaxAmount,
+		Metadata:           rule.Metadata,
+		DescriptionPattern: rule.DescriptionPattern,
+		CreatedAt:          rule.CreatedAt,
+		UpdatedAt:          rule.UpdatedAt,
+	}
+}
+
+// ConvertCategoryRuleModelToDomain converts a models.CategoryRule to a domain.CategoryRule.
+func ConvertCategoryRuleModelToDomain(ruleModel models.CategoryRule) domain.CategoryRule {
+	rule := domain.CategoryRule{
+		ID:                 ruleModel.ID,
+		CategoryID:         ruleModel.CategoryID,
+		Priority:           ruleModel.Priority,
+		Origin:             ruleModel.Origin,
+		MinAmount:          ruleModel.MinAmount,
+		MaxAmount:          ruleModel.MaxAmount,
+		DescriptionPattern: ruleModel.DescriptionPattern,
+		CreatedAt:          ruleModel.CreatedAt,
+		UpdatedAt:          ruleModel.UpdatedAt,
+	}
+	if len(ruleModel.Metadata) > 0 {
+		rule.Metadata = ruleModel.Metadata
+	}
+	return rule
+}
+
+func ConvertCategoryRuleToDomainList(ruleModels []*models.CategoryRule) []domain.CategoryRule {
+	rules := make([]domain.CategoryRule, 0, len(ruleModels))
+	for _, ruleModel := range ruleModels {
+		rules = append(rules, ConvertCategoryRuleModelToDomain(*ruleModel))
+	}
+	return rules
+}
@@ -0,0 +1,119 @@
+package mappers
+
+import (
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// ConvertRateSnapshotDomainToModel converts a domain.RateSnapshot to a models.RateSnapshot.
+func ConvertRateSnapshotDomainToModel(snapshot domain.RateSnapshot) *models.RateSnapshot {
+	rates := make([]models.FXRate, 0, len(snapshot.Rates))
+	for _, rate := range snapshot.Rates {
+		rates = append(rates, models.FXRate{BaseCurrency: rate.BaseCurrency, QuoteCurrency: rate.QuoteCurrency, Rate: rate.Rate})
+	}
+
+	return &models.RateSnapshot{
+		ID:          snapshot.ID,
+		Source:      snapshot.Source,
+		EffectiveAt: snapshot.EffectiveAt,
+		Rates:       rates,
+		CreatedAt:   snapshot.CreatedAt,
+	}
+}
+
+func ConvertRateSnapshotModelToDomain(snapshotModel models.RateSnapshot) domain.RateSnapshot {
+	rates := make([]domain.FXRate, 0, len(snapshotModel.Rates))
+	for _, rate := range snapshotModel.Rates {
+		rates = append(rates, domain.FXRate{BaseCurrency: rate.BaseCurrency, QuoteCurrency: rate.QuoteCurrency, Rate: rate.Rate})
+	}
+
+	return domain.RateSnapshot{
+		ID:          snapshotModel.ID,
+		Source:      snapshotModel.Source,
+		EffectiveAt: snapshotModel.EffectiveAt,
+		Rates:       rates,
+		CreatedAt:   snapshotModel.CreatedAt,
+	}
+}
+
+func ConvertRateSnapshotToDomainList(snapshotModels []*models.RateSnapshot) []domain.RateSnapshot {
+	domainList := make([]domain.RateSnapshot, 0, len(snapshotModels))
+	for _, model := range snapshotModels {
+		domainList = append(domainList, ConvertRateSnapshotModelToDomain(*model))
+	}
+	return domainList
+}
+
+// ConvertFXQuoteDomainToModel converts a domain.FXQuote to a models.FXQuote.
+func ConvertFXQuoteDomainToModel(quote domain.FXQuote) *models.FXQuote {
+	return &models.FXQuote{
+		ID:             quote.ID,
+		UserID:         quote.UserID,
+		SourceCurrency: quote.SourceCurrency,
+		TargetCurrency: quote.TargetCurrency,
+		SourceAmount:   quote.SourceAmount,
+		TargetAmount:   quote.TargetAmount,
+		Rate:           quote.Rate,
+		SnapshotID:     quote.SnapshotID,
+		ExpiresAt:      quote.ExpiresAt,
+		CreatedAt:      quote.CreatedAt,
+	}
+}
+
+func ConvertFXQuoteModelToDomain(quoteModel models.FXQuote) domain.FXQuote {
+	return domain.FXQuote{
+		ID:             quoteModel.ID,
+		UserID:         quoteModel.UserID,
+		SourceCurrency: quoteModel.SourceCurrency,
+		TargetCurrency: quoteModel.TargetCurrency,
+		SourceAmount:   quoteModel.SourceAmount,
+		TargetAmount:   quoteModel.TargetAmount,
+		Rate:           quoteModel.Rate,
+		SnapshotID:     quoteModel.SnapshotID,
+		ExpiresAt:      quoteModel.ExpiresAt,
+		CreatedAt:      quoteModel.CreatedAt,
+	}
+}
+
+// ConvertFXConversionDomainToModel converts a domain.FXConversion to a models.FXConversion.
+func ConvertFXConversionDomainToModel(conversion domain.FXConversion) *models.FXConversion {
+	return &models.FXConversion{
+		ID:                  conversion.ID,
+		QuoteID:             conversion.QuoteID,
+		UserID:              conversion.UserID,
+		Origin:              conversion.Origin,
+		SourceCurrency:      conversion.SourceCurrency,
+		TargetCurrency:      conversion.TargetCurrency,
+		SourceAmount:        conversion.SourceAmount,
+		TargetAmount:        conversion.TargetAmount,
+		Rate:                conversion.Rate,
+		DebitTransactionID:  conversion.DebitTransactionID,
+		CreditTransactionID: conversion.CreditTransactionID,
+		CreatedAt:           conversion.CreatedAt,
+	}
+}
+
+func ConvertFXConversionModelToDomain(conversionModel models.FXConversion) domain.FXConversion {
+	return domain.FXConversion{
+		ID:                  conversionModel.ID,
+		QuoteID:             conversionModel.QuoteID,
+		UserID:              conversionModel.UserID,
+		Origin:              conversionModel.Origin,
+		SourceCurrency:      conversionModel.SourceCurrency,
+		TargetCurrency:      conversionModel.TargetCurrency,
+		SourceAmount:        conversionModel.SourceAmount,
+		TargetAmount:        conversionModel.TargetAmount,
+		Rate:                conversionModel.Rate,
+		DebitTransactionID:  conversionModel.DebitTransactionID,
+		CreditTransactionID: conversionModel.CreditTransactionID,
+		CreatedAt:           conversionModel.CreatedAt,
+	}
+}
+
+func ConvertFXConversionToDomainList(conversionModels []*models.FXConversion) []domain.FXConversion {
+	domainList := make([]domain.FXConversion, 0, len(conversionModels))
+	for _, model := range conversionModels {
+		domainList = append(domainList, ConvertFXConversionModelToDomain(*model))
+	}
+	return domainList
+}
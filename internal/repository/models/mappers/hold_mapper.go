@@ -0,0 +1,80 @@
+package mappers
+
+import (
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// ConvertHoldDomainToModel converts a domain.Hold to a models.Hold.
+func ConvertHoldDomainToModel(hold domain.Hold) *models.Hold {
+	// The columns are never NULL, so missing metadata and tags are stored empty
+	metadata := hold.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	tags := hold.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	currency := hold.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	return &models.Hold{
+		ID:             hold.ID,
+		TenantID:       hold.TenantID,
+		UserID:         hold.UserID,
+		Origin:         hold.Origin,
+		Amount:         hold.Amount,
+		Currency:       currency,
+		Description:    hold.Description,
+		Metadata:       metadata,
+		Tags:           tags,
+		Status:         string(hold.Status),
+		ExpiresAt:      hold.ExpiresAt,
+		CapturedAmount: hold.CapturedAmount,
+		TransactionID:  nullUUID(hold.TransactionID),
+		CreatedAt:      hold.CreatedAt,
+		UpdatedAt:      hold.UpdatedAt,
+	}
+}
+
+// ConvertHoldModelToDomain converts a models.Hold to a domain.Hold, keeping its tenant
+func ConvertHoldModelToDomain(holdModel models.Hold) domain.Hold {
+	// Empty metadata and tags read back as nil, like the ones of a hold created without them
+	var metadata map[string]string
+	if len(holdModel.Metadata) > 0 {
+		metadata = holdModel.Metadata
+	}
+	var tags []string
+	if len(holdModel.Tags) > 0 {
+		tags = holdModel.Tags
+	}
+
+	return domain.Hold{
+		ID:             holdModel.ID,
+		UserID:         holdModel.UserID,
+		Origin:         holdModel.Origin,
+		Amount:         holdModel.Amount,
+		Currency:       holdModel.Currency,
+		Description:    holdModel.Description,
+		Metadata:       metadata,
+		Tags:           tags,
+		Status:         domain.HoldStatus(holdModel.Status),
+		ExpiresAt:      holdModel.ExpiresAt,
+		CapturedAmount: holdModel.CapturedAmount,
+		TransactionID:  uuidPointer(holdModel.TransactionID),
+		CreatedAt:      holdModel.CreatedAt,
+		UpdatedAt:      holdModel.UpdatedAt,
+		TenantID:       holdModel.TenantID,
+	}
+}
+
+func ConvertHoldToDomainList(holdModels []*models.Hold) []domain.Hold {
+	domainList := make([]domain.Hold, 0, len(holdModels))
+	for _, model := range holdModels {
+		domainList = append(domainList, ConvertHoldModelToDomain(*model))
+	}
+	return domainList
+}
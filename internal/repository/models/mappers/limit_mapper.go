@@ -0,0 +1,49 @@
+package mappers
+
+import (
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// ConvertLimitDomainToModel converts a domain.Limit to a models.Limit, in DefaultCurrency when it has no currency.
+func ConvertLimitDomainToModel(limit domain.Limit) *models.Limit {
+	currency := limit.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	return &models.Limit{
+		ID:        limit.ID,
+		Scope:     string(limit.Scope),
+		UserID:    limit.UserID,
+		Origin:    limit.Origin,
+		Period:    string(limit.Period),
+		MaxAmount: limit.MaxAmount,
+		Currency:  currency,
+		CreatedAt: limit.CreatedAt,
+		UpdatedAt: limit.UpdatedAt,
+	}
+}
+
+// ConvertLimitModelToDomain converts a models.Limit to a domain.Limit.
+func ConvertLimitModelToDomain(limitModel models.Limit) domain.Limit {
+	return domain.Limit{
+		ID:        limitModel.ID,
+		Scope:     domain.LimitScope(limitModel.Scope),
+		UserID:    limitModel.UserID,
+		Origin:    limitModel.Origin,
+		Period:    domain.LimitPeriod(limitModel.Period),
+		MaxAmount: limitModel.MaxAmount,
+		Currency:  limitModel.Currency,
+		CreatedAt: limitModel.CreatedAt,
+		UpdatedAt: limitModel.UpdatedAt,
+	}
+}
+
+func ConvertLimitToDomainList(limitModels []*models.Limit) []domain.Limit {
+	domainList := make([]domain.Limit, 0, len(limitModels))
+	for _, model := range limitModels {
+		domainList = append(domainList, ConvertLimitModelToDomain(*model))
+	}
+	return domainList
+}
@@ -0,0 +1,46 @@
+package mappers
+
+import (
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// ConvertSavedSearchDomainToModel converts a domain.SavedSearch to a models.SavedSearch.
+func ConvertSavedSearchDomainToModel(search domain.SavedSearch) *models.SavedSearch {
+	return &models.SavedSearch{
+		ID:              search.ID,
+		Name:            search.Name,
+		Owner:           search.Owner,
+		Origin:          search.Origin,
+		TransactionType: search.TransactionType,
+		Filter:          search.Filter,
+		Sort:            search.Sort,
+		Shared:          search.Shared,
+		CreatedAt:       search.CreatedAt,
+		UpdatedAt:       search.UpdatedAt,
+	}
+}
+
+// ConvertSavedSearchModelToDomain converts a models.SavedSearch to a domain.SavedSearch.
+func ConvertSavedSearchModelToDomain(searchModel models.SavedSearch) domain.SavedSearch {
+	return domain.SavedSearch{
+		ID:              searchModel.ID,
+		Name:            searchModel.Name,
+		Owner:           searchModel.Owner,
+		Origin:          searchModel.Origin,
+		TransactionType: searchModel.TransactionType,
+		Filter:          searchModel.Filter,
+		Sort:            searchModel.Sort,
+		Shared:          searchModel.Shared,
+		CreatedAt:       searchModel.CreatedAt,
+		UpdatedAt:       searchModel.UpdatedAt,
+	}
+}
+
+func ConvertSavedSearchToDomainList(searchModels []*models.SavedSearch) []domain.SavedSearch {
+	domainList := make([]domain.SavedSearch, 0, len(searchModels))
+	for _, model := range searchModels {
+		domainList = append(domainList, ConvertSavedSearchModelToDomain(*model))
+	}
+	return domainList
+}
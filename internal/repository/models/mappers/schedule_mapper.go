@@ -0,0 +1,93 @@
+package mappers
+
+import (
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// ConvertScheduleDomainToModel converts a domain.Schedule to a models.Schedule.
+func ConvertScheduleDomainToModel(schedule domain.Schedule) *models.Schedule {
+	// The columns are never NULL, so missing metadata and tags are stored empty
+	metadata := schedule.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	tags := schedule.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	currency := schedule.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	return &models.Schedule{
+		ID:              schedule.ID,
+		TenantID:        schedule.TenantID,
+		UserID:          schedule.UserID,
+		Origin:          schedule.Origin,
+		TransactionType: schedule.TransactionType.String(),
+		Amount:          schedule.Amount,
+		Currency:        currency,
+		Description:     schedule.Description,
+		Metadata:        metadata,
+		Tags:            tags,
+		Cron:            schedule.Cron,
+		RRule:           schedule.RRule,
+		StartAt:         schedule.StartAt,
+		Status:          string(schedule.Status),
+		NextRunAt:       nullTime(schedule.NextRunAt),
+		LastRunAt:       nullTime(schedule.LastRunAt),
+		RunCount:        schedule.RunCount,
+		LastError:       schedule.LastError,
+		CreatedAt:       schedule.CreatedAt,
+		UpdatedAt:       schedule.UpdatedAt,
+	}
+}
+
+// ConvertScheduleModelToDomain converts a models.Schedule to a domain.Schedule. The tenant is kept, for the schedules
+// claimed by the scheduler.
+func ConvertScheduleModelToDomain(scheduleModel models.Schedule) domain.Schedule {
+	transactionType, _ := domain.StringToTransactionType(scheduleModel.TransactionType)
+
+	// Empty metadata and tags read back as nil, like the ones of a schedule created without them
+	var metadata map[string]string
+	if len(scheduleModel.Metadata) > 0 {
+		metadata = scheduleModel.Metadata
+	}
+	var tags []string
+	if len(scheduleModel.Tags) > 0 {
+		tags = scheduleModel.Tags
+	}
+
+	return domain.Schedule{
+		ID:              scheduleModel.ID,
+		UserID:          scheduleModel.UserID,
+		Origin:          scheduleModel.Origin,
+		TransactionType: transactionType,
+		Amount:          scheduleModel.Amount,
+		Currency:        scheduleModel.Currency,
+		Description:     scheduleModel.Description,
+		Metadata:        metadata,
+		Tags:            tags,
+		Cron:            scheduleModel.Cron,
+		RRule:           scheduleModel.RRule,
+		StartAt:         scheduleModel.StartAt,
+		Status:          domain.ScheduleStatus(scheduleModel.Status),
+		NextRunAt:       timePointer(scheduleModel.NextRunAt),
+		LastRunAt:       timePointer(scheduleModel.LastRunAt),
+		RunCount:        scheduleModel.RunCount,
+		LastError:       scheduleModel.LastError,
+		CreatedAt:       scheduleModel.CreatedAt,
+		UpdatedAt:       scheduleModel.UpdatedAt,
+		TenantID:        scheduleModel.TenantID,
+	}
+}
+
+func ConvertScheduleToDomainList(scheduleModels []*models.Schedule) []domain.Schedule {
+	domainList := make([]domain.Schedule, 0, len(scheduleModels))
+	for _, model := range scheduleModels {
+		domainList = append(domainList, ConvertScheduleModelToDomain(*model))
+	}
+	return domainList
+}
@@ -3,6 +3,8 @@ package mappers
 import (
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
 	"traive-engineering-challenge/internal/domain"
 	"traive-engineering-challenge/internal/repository/models"
 )
@@ -19,13 +21,36 @@ func ConvertTransactionDomainToModel(transaction domain.Transaction) (*models.Tr
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
+	// The columns are never NULL, so missing metadata and tags are stored empty
+	metadata := transaction.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	tags := transaction.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	currency := transaction.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
 	return &models.Transaction{
 		ID:              id,
 		UserID:          userID,
 		Origin:          transaction.Origin,
 		TransactionType: transaction.TransactionType.String(),
 		Amount:          transaction.Amount,
+		Currency:        currency,
 		CreatedAt:       transaction.CreatedAt,
+		Status:          string(transaction.Status),
+		FlaggedRules:    transaction.FlaggedRules,
+		Metadata:        metadata,
+		Tags:            tags,
+		Description:     transaction.Description,
+		CategoryID:      nullUUID(transaction.CategoryID),
+		CategorizedBy:   transaction.CategorizedBy,
+		CategorizedAt:   nullTime(transaction.CategorizedAt),
 	}, nil
 }
 
@@ -35,13 +60,38 @@ func ConvertTransactionModelToDomain(transactionModel models.Transaction) (*doma
 		return nil, fmt.Errorf("invalid transaction type: %w", err)
 	}
 
+	// Empty metadata and tags read back as nil, like the ones of a transaction created without them
+	var metadata map[string]string
+	if len(transactionModel.Metadata) > 0 {
+		metadata = transactionModel.Metadata
+	}
+	var tags []string
+	if len(transactionModel.Tags) > 0 {
+		tags = transactionModel.Tags
+	}
+
+	var match *domain.SearchMatch
+	if transactionModel.SearchRank != nil {
+		match = &domain.SearchMatch{Rank: *transactionModel.SearchRank, Snippet: transactionModel.SearchSnippet}
+	}
+
 	return &domain.Transaction{
 		ID:              transactionModel.ID,
 		UserID:          transactionModel.UserID,
 		Origin:          transactionModel.Origin,
 		TransactionType: domain.TransactionType(transactionType),
 		Amount:          transactionModel.Amount,
+		Currency:        transactionModel.Currency,
 		CreatedAt:       transactionModel.CreatedAt,
+		Status:          domain.TransactionStatus(transactionModel.Status),
+		FlaggedRules:    transactionModel.FlaggedRules,
+		Metadata:        metadata,
+		Tags:            tags,
+		Description:     transactionModel.Description,
+		CategoryID:      uuidPointer(transactionModel.CategoryID),
+		CategorizedBy:   transactionModel.CategorizedBy,
+		CategorizedAt:   timePointer(transactionModel.CategorizedAt),
+		Match:           match,
 	}, nil
 }
 
@@ -65,3 +115,31 @@ func ValidateUUID(value string) (uuid.UUID, error) {
 	}
 	return id, nil
 }
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func uuidPointer(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullTime(t *time.Time) bun.NullTime {
+	if t == nil {
+		return bun.NullTime{}
+	}
+	return bun.NullTime{Time: *t}
+}
+
+func timePointer(t bun.NullTime) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t.Time
+}
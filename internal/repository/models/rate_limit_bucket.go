@@ -0,0 +1,17 @@
+package models
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type RateLimitBucket struct {
+	bun.BaseModel `bun:"table:rate_limit_buckets,alias:b"`
+
+	TenantID  string    `bun:",pk"`
+	Key       string    `bun:",pk"`
+	Tokens    float64   `bun:",notnull"`
+	UpdatedAt time.Time `bun:",notnull"`
+	// ExpiresAt is when the bucket will have refilled completely under the limit it was last taken from
+	ExpiresAt time.Time `bun:",notnull"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type SavedSearch struct {
+	bun.BaseModel `bun:"table:saved_searches,alias:s"`
+
+	ID              uuid.UUID `bun:",pk,notnull,type:uuid"`
+	TenantID        string    `bun:",notnull"`
+	Name            string    `bun:",notnull"`
+	Owner           string    `bun:",notnull"`
+	Origin          string    `bun:",nullzero"`
+	TransactionType string    `bun:",nullzero"`
+	Filter          string    `bun:",nullzero"`
+	Sort            string    `bun:",nullzero"`
+	Shared          bool      `bun:",notnull"`
+	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	UpdatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
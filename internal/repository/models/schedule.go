@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type Schedule struct {
+	bun.BaseModel `bun:"table:schedules,alias:sc"`
+
+	ID              uuid.UUID         `bun:",pk,notnull,type:uuid"`
+	TenantID        string            `bun:",notnull"`
+	UserID          uuid.UUID         `bun:",notnull,type:uuid"`
+	Origin          string            `bun:",notnull"`
+	TransactionType string            `bun:",notnull"`
+	Amount          int64             `bun:",notnull"`
+	Currency        string            `bun:",notnull"`
+	Description     string            `bun:",notnull"`
+	Metadata        map[string]string `bun:"type:jsonb,notnull"`
+	Tags            []string          `bun:",array,notnull"`
+	Cron            string            `bun:",nullzero"`
+	RRule           string            `bun:"rrule,nullzero"`
+	StartAt         time.Time         `bun:",notnull"`
+	Status          string            `bun:",notnull"`
+	NextRunAt       bun.NullTime
+	LastRunAt       bun.NullTime
+	RunCount        int    `bun:",notnull"`
+	LastError       string `bun:",notnull"`
+	// LeaseOwner and LeaseExpiresAt are only set while an instance of the scheduler processes the schedule
+	LeaseOwner     string `bun:",nullzero"`
+	LeaseExpiresAt bun.NullTime
+	CreatedAt      time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	UpdatedAt      time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
+
+// ScheduleRun is an occurrence of a schedule materialized into the transaction, inserted along with it
+type ScheduleRun struct {
+	bun.BaseModel `bun:"table:schedule_runs,alias:sr"`
+
+	TenantID      string    `bun:",pk,notnull"`
+	ScheduleID    uuid.UUID `bun:",pk,notnull,type:uuid"`
+	Occurrence    time.Time `bun:",pk,notnull"`
+	TransactionID uuid.UUID `bun:",notnull,type:uuid"`
+	CreatedAt     time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+}
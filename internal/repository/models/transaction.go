@@ -2,6 +2,7 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"github.com/uptrace/bun"
 	"time"
 )
 
@@ -15,9 +16,33 @@ const (
 
 type Transaction struct {
 	ID              uuid.UUID `bun:",pk,notnull,type:uuid"`
+	TenantID        string    `bun:",pk,notnull"`
 	UserID          uuid.UUID `bun:",notnull,type:uuid"`
 	Origin          string
 	TransactionType string
 	Amount          int64
+	Currency        string    `bun:",notnull"`
 	CreatedAt       time.Time `bun:",nullzero,notnull,default:current_timestamp"`
+	Status          string    `bun:",notnull"`
+	FlaggedRules    []string  `bun:",array"`
+	// Metadata and Tags are never NULL, so that the GIN indexes serve every containment query
+	Metadata map[string]string `bun:"type:jsonb,notnull"`
+	Tags     []string          `bun:",array,notnull"`
+	// Description and CategorizedBy are empty rather than NULL; CategoryID and CategorizedAt are NULL until the
+	// transaction is categorized, without a RETURNING clause on insert
+	Description   string        `bun:",notnull"`
+	CategoryID    uuid.NullUUID `bun:",type:uuid"`
+	CategorizedBy string        `bun:",notnull"`
+	CategorizedAt bun.NullTime
+	// SearchRank and SearchSnippet are only selected by a search, from the generated search_vector column
+	SearchRank    *float64 `bun:",scanonly"`
+	SearchSnippet string   `bun:",scanonly"`
+}
+
+// TransactionStats is a row of the transaction stats grouped by a dimension
+type TransactionStats struct {
+	Key     string
+	Count   int64
+	Credits int64
+	Debits  int64
 }
@@ -0,0 +1,36 @@
+package repository
+
+import "context"
+
+type contextKey string
+
+// Keys of the page of a list query and of its size in the context
+const (
+	PageKey     contextKey = "page"
+	PageSizeKey contextKey = "pageSize"
+)
+
+const (
+	DefaultPage     = 1
+	DefaultPageSize = 10
+)
+
+// PageFromContext returns the page and the page size the context carries, or the defaults when it carries none or
+// values below 1
+func PageFromContext(ctx context.Context) (page, pageSize int) {
+	page, ok := ctx.Value(PageKey).(int)
+	if !ok || page < 1 {
+		page = DefaultPage
+	}
+	pageSize, ok = ctx.Value(PageSizeKey).(int)
+	if !ok || pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	return page, pageSize
+}
+
+// WithPage returns a copy of the context carrying the page of the list queries and its size
+func WithPage(ctx context.Context, page, pageSize int) context.Context {
+	ctx = context.WithValue(ctx, PageKey, page)
+	return context.WithValue(ctx, PageSizeKey, pageSize)
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const apiKeyNotFoundMessage = "api key not found"
+
+// API keys are not protected by row level security: a key has to be looked up by its prefix to learn the tenant
+// of the request. Every other query filters on the tenant of the context.
+
+// CreateAPIKey stores a key bound to the tenant of the context
+func (r *Repository) CreateAPIKey(ctx context.Context, key domain.APIKey) (*domain.APIKey, error) {
+	keyModel := mappers.ConvertAPIKeyDomainToModel(key)
+	keyModel.TenantID = tenant.FromContext(ctx)
+	keyModel.CreatedAt = time.Now()
+
+	if _, err := r.db.NewInsert().Model(keyModel).Exec(ctx); err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertAPIKeyModelToDomain(*keyModel)
+	return &created, nil
+}
+
+func (r *Repository) GetAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	return r.getAPIKey(ctx, r.db.NewSelect().
+		Where("? = ?", bun.Ident(TenantIDColumn), tenant.FromContext(ctx)).
+		Where("? = ?", bun.Ident("id"), id))
+}
+
+// GetAPIKeyByPrefix retrieves a key of any tenant, revoked or not, by the public prefix of its plaintext
+func (r *Repository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	return r.getAPIKey(ctx, r.db.NewSelect().Where("? = ?", bun.Ident("prefix"), prefix))
+}
+
+func (r *Repository) getAPIKey(ctx context.Context, query *bun.SelectQuery) (*domain.APIKey, error) {
+	keyModel := new(models.APIKey)
+
+	err := r.retryRead(ctx, func() error {
+		return query.Model(keyModel).Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(apiKeyNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve api key")
+	}
+
+	key := mappers.ConvertAPIKeyModelToDomain(*keyModel)
+	return &key, nil
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	var keyModels []*models.APIKey
+
+	err := r.retryRead(ctx, func() error {
+		return r.db.NewSelect().
+			Model(&keyModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenant.FromContext(ctx)).
+			Order("created_at ASC", "id ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list api keys")
+	}
+
+	return mappers.ConvertAPIKeyToDomainList(keyModels), nil
+}
+
+// RevokeAPIKey revokes an active key. Revoking an unknown or already revoked key returns a repository.NotFoundError.
+func (r *Repository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	return revokeAPIKey(ctx, r.db, tenant.FromContext(ctx), id, time.Now())
+}
+
+// RotateAPIKey atomically revokes an active key and stores its replacement
+func (r *Repository) RotateAPIKey(ctx context.Context, id uuid.UUID, replacement domain.APIKey) (*domain.APIKey, error) {
+	keyModel := mappers.ConvertAPIKeyDomainToModel(replacement)
+	keyModel.TenantID = tenant.FromContext(ctx)
+	now := time.Now()
+	keyModel.CreatedAt = now
+
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := revokeAPIKey(ctx, tx, keyModel.TenantID, id, now); err != nil {
+			return err
+		}
+		_, err := tx.NewInsert().Model(keyModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		var notFound repository.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, notFound
+		}
+		return nil, translateInsertError(err)
+	}
+
+	rotated := mappers.ConvertAPIKeyModelToDomain(*keyModel)
+	return &rotated, nil
+}
+
+// TouchAPIKey records when a key was last used. Like GetAPIKeyByPrefix, it is used before the tenant is known.
+func (r *Repository) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	_, err := r.db.NewUpdate().
+		Model((*models.APIKey)(nil)).
+		Set("? = ?", bun.Ident("last_used_at"), usedAt).
+		Where("? = ?", bun.Ident("id"), id).
+		Exec(ctx)
+	if err != nil {
+		return errors.New("failed to update api key usage")
+	}
+	return nil
+}
+
+func revokeAPIKey(ctx context.Context, db bun.IDB, tenantID string, id uuid.UUID, revokedAt time.Time) error {
+	result, err := db.NewUpdate().
+		Model((*models.APIKey)(nil)).
+		Set("? = ?", bun.Ident("revoked_at"), revokedAt).
+		Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+		Where("? = ?", bun.Ident("id"), id).
+		Where("? IS NULL", bun.Ident("revoked_at")).
+		Exec(ctx)
+	if err != nil {
+		return errors.New("failed to revoke api key")
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return repository.NewNotFoundError(apiKeyNotFoundMessage)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+const (
+	RevokeAPIKeyQuery = `^UPDATE "api_keys" AS "k" SET "revoked_at" = (.+) WHERE \("tenant_id" = 'default'\) AND \("id" = (.+)\) AND \("revoked_at" IS NULL\)`
+	InsertAPIKeyQuery = `^INSERT INTO "api_keys"`
+)
+
+func TestRepository_RotateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	replacement := domain.APIKey{ID: uuid.New(), Name: "billing", Prefix: "abcdef012345", KeyHash: "hash", Scopes: []string{domain.ScopeAdmin}}
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - revokes the key and stores its replacement": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(RevokeAPIKeyQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(InsertAPIKeyQuery).WillReturnRows(sqlmock.NewRows([]string{"last_used_at", "revoked_at"}).AddRow(nil, nil))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the key is unknown or already revoked": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(RevokeAPIKeyQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			rotated, err := repo.RotateAPIKey(context.Background(), uuid.New(), replacement)
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, replacement.ID, rotated.ID)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
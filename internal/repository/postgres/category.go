@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/uptrace/bun"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+)
+
+const (
+	categoryNotFoundMessage     = "category not found"
+	categoryRuleNotFoundMessage = "category rule not found"
+)
+
+// CreateCategory stores a category. It returns a repository.UniqueIndexError when the tenant already has a category
+// of the same name.
+func (r *Repository) CreateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	categoryModel := mappers.ConvertCategoryDomainToModel(category)
+
+	now := time.Now()
+	categoryModel.CreatedAt = now
+	categoryModel.UpdatedAt = now
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		categoryModel.TenantID = tenantID
+		_, err := tx.NewInsert().Model(categoryModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertCategoryModelToDomain(*categoryModel)
+	return &created, nil
+}
+
+func (r *Repository) GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	categoryModel := new(models.Category)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(categoryModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(categoryNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve category")
+	}
+
+	category := mappers.ConvertCategoryModelToDomain(*categoryModel)
+	return &category, nil
+}
+
+// ListCategories retrieves every category of the tenant, by name
+func (r *Repository) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	var categoryModels []*models.Category
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(&categoryModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Order("name ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list categories")
+	}
+
+	return mappers.ConvertCategoryToDomainList(categoryModels), nil
+}
+
+// UpdateCategory renames or moves an existing category, keeping its tenant and creation time
+func (r *Repository) UpdateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	categoryModel := mappers.ConvertCategoryDomainToModel(category)
+	categoryModel.UpdatedAt = time.Now()
+
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewUpdate().
+			Model(categoryModel).
+			ExcludeColumn("id", TenantIDColumn, "created_at").
+			WherePK().
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Returning("created_at").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		categoryModel.TenantID = tenantID
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(categoryNotFoundMessage)
+		}
+		var unique repository.UniqueIndexError
+		if translated := translateInsertError(err); errors.As(translated, &unique) {
+			return nil, translated
+		}
+		return nil, errors.New("failed to update category")
+	}
+	if rows == 0 {
+		return nil, repository.NewNotFoundError(categoryNotFoundMessage)
+	}
+
+	updated := mappers.ConvertCategoryModelToDomain(*categoryModel)
+	return &updated, nil
+}
+
+// DeleteCategory deletes a category, leaving its transactions uncategorized. It returns domain.ErrCategoryInUse
+// when the category still has subcategories or rules.
+func (r *Repository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewDelete().
+			Model((*models.Category)(nil)).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrCategoryInUse
+		}
+		return errors.New("failed to delete category")
+	}
+	if rows == 0 {
+		return repository.NewNotFoundError(categoryNotFoundMessage)
+	}
+	return nil
+}
+
+func (r *Repository) CreateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	ruleModel := mappers.ConvertCategoryRuleDomainToModel(rule)
+
+	now := time.Now()
+	ruleModel.CreatedAt = now
+	ruleModel.UpdatedAt = now
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		ruleModel.TenantID = tenantID
+		_, err := tx.NewInsert().Model(ruleModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertCategoryRuleModelToDomain(*ruleModel)
+	return &created, nil
+}
+
+func (r *Repository) GetCategoryRule(ctx context.Context, id uuid.UUID) (*domain.CategoryRule, error) {
+	ruleModel := new(models.CategoryRule)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(ruleModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(categoryRuleNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve category rule")
+	}
+
+	rule := mappers.ConvertCategoryRuleModelToDomain(*ruleModel)
+	return &rule, nil
+}
+
+// ListCategoryRules retrieves every rule of the tenant in the order they are evaluated: by priority, then oldest first
+func (r *Repository) ListCategoryRules(ctx context.Context) ([]domain.CategoryRule, error) {
+	var ruleModels []*models.CategoryRule
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(&ruleModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Order("priority ASC", "created_at ASC", "id ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list category rules")
+	}
+
+	return mappers.ConvertCategoryRuleToDomainList(ruleModels), nil
+}
+
+// UpdateCategoryRule replaces the conditions of an existing rule, keeping its tenant and creation time
+func (r *Repository) UpdateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	ruleModel := mappers.ConvertCategoryRuleDomainToModel(rule)
+	ruleModel.UpdatedAt = time.Now()
+
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewUpdate().
+			Model(ruleModel).
+			ExcludeColumn("id", TenantIDColumn, "created_at").
+			WherePK().
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Returning("created_at").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		ruleModel.TenantID = tenantID
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(categoryRuleNotFoundMessage)
+		}
+		return nil, errors.New("failed to update category rule")
+	}
+	if rows == 0 {
+		return nil, repository.NewNotFoundError(categoryRuleNotFoundMessage)
+	}
+
+	updated := mappers.ConvertCategoryRuleModelToDomain(*ruleModel)
+	return &updated, nil
+}
+
+func (r *Repository) DeleteCategoryRule(ctx context.Context, id uuid.UUID) error {
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewDelete().
+			Model((*models.CategoryRule)(nil)).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return errors.New("failed to delete category rule")
+	}
+	if rows == 0 {
+		return repository.NewNotFoundError(categoryRuleNotFoundMessage)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	InsertCategoryQuery    = `^INSERT INTO "categories" (.+) VALUES (.+)'default', 'groceries'`
+	DeleteCategoryQuery    = `^DELETE FROM "categories" AS "c" WHERE \("tenant_id" = 'default'\) AND \("id" = '(.+)'\)`
+	ListCategoryRulesQuery = `^SELECT (.+) FROM "category_rules" AS "cr" WHERE \("tenant_id" = 'default'\) ORDER BY "priority" ASC, "created_at" ASC, "id" ASC`
+)
+
+var categoryRuleSchema = []string{"id", "category_id", "priority", "origin", "min_amount", "max_amount", "metadata", "description_pattern", "created_at", "updated_at"}
+
+func TestRepository_CreateCategory(t *testing.T) {
+	t.Parallel()
+
+	category := domain.Category{ID: uuid.New(), Name: "groceries"}
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		wantUnique bool
+		wantErr    bool
+	}{
+		"happy path - stores the category in the tenant": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(InsertCategoryQuery).WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the name is taken": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(InsertCategoryQuery).WillReturnError(&pgconn.PgError{
+					Code:   "23505",
+					Detail: "Key (tenant_id, name)=(default, groceries) already exists.",
+				})
+				mock.ExpectRollback()
+			},
+			wantUnique: true,
+			wantErr:    true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			created, err := repo.CreateCategory(context.Background(), category)
+			if tc.wantErr {
+				require.Error(t, err)
+				var unique repository.UniqueIndexError
+				require.Equal(t, tc.wantUnique, errors.As(err, &unique))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, category.ID, created.ID)
+				require.False(t, created.CreatedAt.IsZero())
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_DeleteCategory(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		wantErr    func(t *testing.T, err error)
+	}{
+		"happy path - deletes the category": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(DeleteCategoryQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the category does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(DeleteCategoryQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantErr: func(t *testing.T, err error) {
+				var notFound repository.NotFoundError
+				require.True(t, errors.As(err, &notFound))
+			},
+		},
+		"failure - the category has subcategories or rules": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(DeleteCategoryQuery).WillReturnError(&pgconn.PgError{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			wantErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, domain.ErrCategoryInUse)
+			},
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			err = repo.DeleteCategory(context.Background(), uuid.New())
+			if tc.wantErr != nil {
+				tc.wantErr(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_ListCategoryRules(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+
+	repo, err := NewRepository(db)
+	require.NoError(t, err)
+
+	ruleID, categoryID := uuid.New(), uuid.New()
+	expectTenantTx(mock, tenant.Default)
+	mock.ExpectQuery(ListCategoryRulesQuery).WillReturnRows(sqlmock.NewRows(categoryRuleSchema).
+		AddRow(ruleID.String(), categoryID.String(), 0, nil, 100, nil, []byte(`{"merchant":"acme"}`), `(?i)grocer`, time.Now(), time.Now()))
+	mock.ExpectCommit()
+
+	rules, err := repo.ListCategoryRules(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, ruleID, rules[0].ID)
+	require.Equal(t, categoryID, rules[0].CategoryID)
+	require.Equal(t, int64(100), *rules[0].MinAmount)
+	require.Nil(t, rules[0].MaxAmount)
+	require.Equal(t, map[string]string{"merchant": "acme"}, rules[0].Metadata)
+
+	expectationMet(t, mock)
+}
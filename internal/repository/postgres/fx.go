@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+)
+
+const (
+	rateSnapshotNotFoundMessage = "rate snapshot not found"
+	fxQuoteNotFoundMessage      = "quote not found"
+	fxConversionNotFoundMessage = "conversion not found"
+)
+
+func (r *Repository) CreateRateSnapshot(ctx context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error) {
+	snapshotModel := mappers.ConvertRateSnapshotDomainToModel(snapshot)
+	snapshotModel.CreatedAt = time.Now()
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		snapshotModel.TenantID = tenantID
+		_, err := tx.NewInsert().Model(snapshotModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertRateSnapshotModelToDomain(*snapshotModel)
+	return &created, nil
+}
+
+// ListRateSnapshots retrieves the snapshots of the tenant, the latest effective first
+func (r *Repository) ListRateSnapshots(ctx context.Context) ([]domain.RateSnapshot, error) {
+	var snapshotModels []*models.RateSnapshot
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(&snapshotModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Order("effective_at DESC", "created_at DESC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list rate snapshots")
+	}
+
+	return mappers.ConvertRateSnapshotToDomainList(snapshotModels), nil
+}
+
+// GetEffectiveRateSnapshot retrieves the snapshot in effect at the given time: the one with the latest effective_at
+// up to it, the latest loaded among them. It returns a repository.NotFoundError when there is none.
+func (r *Repository) GetEffectiveRateSnapshot(ctx context.Context, at time.Time) (*domain.RateSnapshot, error) {
+	snapshotModel := new(models.RateSnapshot)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(snapshotModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? <= ?", bun.Ident("effective_at"), at).
+			Order("effective_at DESC", "created_at DESC").
+			Limit(1).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(rateSnapshotNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve rate snapshot")
+	}
+
+	snapshot := mappers.ConvertRateSnapshotModelToDomain(*snapshotModel)
+	return &snapshot, nil
+}
+
+func (r *Repository) CreateFXQuote(ctx context.Context, quote domain.FXQuote) (*domain.FXQuote, error) {
+	quoteModel := mappers.ConvertFXQuoteDomainToModel(quote)
+	quoteModel.CreatedAt = time.Now()
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		quoteModel.TenantID = tenantID
+		_, err := tx.NewInsert().Model(quoteModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertFXQuoteModelToDomain(*quoteModel)
+	return &created, nil
+}
+
+func (r *Repository) GetFXQuote(ctx context.Context, id uuid.UUID) (*domain.FXQuote, error) {
+	quoteModel := new(models.FXQuote)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(quoteModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(fxQuoteNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve quote")
+	}
+
+	quote := mappers.ConvertFXQuoteModelToDomain(*quoteModel)
+	return &quote, nil
+}
+
+// CreateFXConversion stores the debit, the credit and the conversion of a quote in one database transaction, with the
+// quote locked so that it is converted once. The debit must be covered by the available balance of the user in its
+// currency and is checked against the limits like CreateTransactionWithinLimits, serialised with the other debits and
+// holds of the user. It returns an error wrapping domain.ErrQuoteUnavailable when the quote expired or was already
+// converted, a domain.InsufficientFundsError or a domain.LimitExceededError.
+func (r *Repository) CreateFXConversion(ctx context.Context, conversion domain.FXConversion, debit, credit domain.Transaction, limits []domain.Limit) (*domain.FXConversion, error) {
+	debitModel, err := mappers.ConvertTransactionDomainToModel(debit)
+	if err != nil {
+		return nil, err
+	}
+	creditModel, err := mappers.ConvertTransactionDomainToModel(credit)
+	if err != nil {
+		return nil, err
+	}
+	conversionModel := mappers.ConvertFXConversionDomainToModel(conversion)
+
+	now := time.Now()
+	debitModel.CreatedAt, creditModel.CreatedAt, conversionModel.CreatedAt = now, now, now
+
+	err = r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		debitModel.TenantID, creditModel.TenantID, conversionModel.TenantID = tenantID, tenantID, tenantID
+
+		quoteModel := new(models.FXQuote)
+		err := tx.NewSelect().
+			Model(quoteModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), conversion.QuoteID).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if !now.Before(quoteModel.ExpiresAt) {
+			return fmt.Errorf("%w: the quote expired", domain.ErrQuoteUnavailable)
+		}
+
+		converted, err := tx.NewSelect().
+			Model((*models.FXConversion)(nil)).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("quote_id"), conversion.QuoteID).
+			Exists(ctx)
+		if err != nil {
+			return err
+		}
+		if converted {
+			return fmt.Errorf("%w: the quote was already converted", domain.ErrQuoteUnavailable)
+		}
+
+		if err := lockUserDebits(ctx, tx, tenantID, debitModel.UserID); err != nil {
+			return err
+		}
+		balance, err := userBalance(ctx, tx, tenantID, debitModel.UserID, debitModel.Currency, now)
+		if err != nil {
+			return err
+		}
+		held, err := heldAmount(ctx, tx, tenantID, debitModel.UserID, debitModel.Currency, now)
+		if err != nil {
+			return err
+		}
+		if available := balance - held; available < debitModel.Amount {
+			return domain.NewInsufficientFundsError(available, debitModel.Amount)
+		}
+		if err := checkLimits(ctx, tx, tenantID, debitModel.UserID, debitModel.Amount, limits, now); err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{debitModel, creditModel, conversionModel} {
+			if _, err := tx.NewInsert().Model(model).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var insufficientFunds domain.InsufficientFundsError
+		var limitExceeded domain.LimitExceededError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.NewNotFoundError(fxQuoteNotFoundMessage)
+		case errors.Is(err, domain.ErrQuoteUnavailable):
+			return nil, err
+		case errors.As(err, &insufficientFunds):
+			return nil, insufficientFunds
+		case errors.As(err, &limitExceeded):
+			return nil, limitExceeded
+		}
+		var uniqueErr repository.UniqueIndexError
+		if errors.As(translateInsertError(err), &uniqueErr) {
+			return nil, uniqueErr
+		}
+		return nil, errors.New("failed to create conversion")
+	}
+
+	created := mappers.ConvertFXConversionModelToDomain(*conversionModel)
+	return &created, nil
+}
+
+func (r *Repository) GetFXConversion(ctx context.Context, id uuid.UUID) (*domain.FXConversion, error) {
+	conversionModel := new(models.FXConversion)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(conversionModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(fxConversionNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve conversion")
+	}
+
+	conversion := mappers.ConvertFXConversionModelToDomain(*conversionModel)
+	return &conversion, nil
+}
+
+// ListFXConversions retrieves the conversions of the tenant, only the ones of the user when set, by creation time
+func (r *Repository) ListFXConversions(ctx context.Context, userID *uuid.UUID) ([]domain.FXConversion, error) {
+	var conversionModels []*models.FXConversion
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		query := tx.NewSelect().
+			Model(&conversionModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID)
+		if userID != nil {
+			query = query.Where("? = ?", bun.Ident("user_id"), *userID)
+		}
+		return query.Order("created_at ASC", "id ASC").Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list conversions")
+	}
+
+	return mappers.ConvertFXConversionToDomainList(conversionModels), nil
+}
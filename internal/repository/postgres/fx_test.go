@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	EffectiveRateSnapshotQuery = `^SELECT (.+) FROM "fx_rate_snapshots" AS "fxr" WHERE \("tenant_id" = 'default'\) AND \("effective_at" <= (.+)\) ` +
+		`ORDER BY "effective_at" DESC, "created_at" DESC LIMIT 1`
+	LockFXQuoteQuery        = `^SELECT (.+) FROM "fx_quotes" AS "fxq" WHERE \("tenant_id" = 'default'\) AND \("id" = (.+)\) FOR UPDATE`
+	FXQuoteConvertedQuery   = `^SELECT EXISTS \(SELECT (.+) FROM "fx_conversions" AS "fxc" WHERE \("tenant_id" = 'default'\) AND \("quote_id" = (.+)\)\)`
+	InsertFXConversionQuery = `^INSERT INTO "fx_conversions" (.+)`
+	EURBalanceQuery         = `^SELECT COALESCE\(SUM\(CASE WHEN "transaction_type" = 'CREDIT TRANSACTION' (.+) FROM "transactions" AS "transaction" WHERE (.+) AND \("currency" = 'EUR'\)`
+	EURHeldAmountQuery      = `^SELECT COALESCE\(SUM\("amount"\), 0\)::bigint FROM "holds" AS "h" WHERE (.+) AND \("currency" = 'EUR'\)`
+	EURSumDebitsQuery       = `^SELECT COALESCE\(SUM\("amount"\), 0\)::bigint FROM "transactions" AS "transaction" WHERE (.+)'DEBIT TRANSACTION'\) AND \("currency" = 'EUR'\)`
+)
+
+var (
+	rateSnapshotSchema = []string{"id", "tenant_id", "source", "effective_at", "rates", "created_at"}
+	fxQuoteSchema      = []string{"id", "tenant_id", "user_id", "source_currency", "target_currency", "source_amount", "target_amount", "rate",
+		"snapshot_id", "expires_at", "created_at"}
+)
+
+func TestRepository_GetEffectiveRateSnapshot(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	snapshotID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - returns the latest effective snapshot": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(EffectiveRateSnapshotQuery).WillReturnRows(sqlmock.NewRows(rateSnapshotSchema).
+					AddRow(snapshotID.String(), tenant.Default, "ecb", now.Add(-time.Hour),
+						[]byte(`[{"base_currency":"EUR","quote_currency":"USD","rate":"1.085"}]`), now))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - no snapshot is effective": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(EffectiveRateSnapshotQuery).WillReturnRows(sqlmock.NewRows(rateSnapshotSchema))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			snapshot, err := repo.GetEffectiveRateSnapshot(context.Background(), now)
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, snapshotID, snapshot.ID)
+				require.Equal(t, []domain.FXRate{{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.085"}}, snapshot.Rates)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_CreateFXConversion(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	quote := domain.FXQuote{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		SourceCurrency: "EUR",
+		TargetCurrency: "USD",
+		SourceAmount:   10000,
+		TargetAmount:   10850,
+		Rate:           "1.085",
+		SnapshotID:     uuid.New(),
+	}
+	conversion := domain.NewFXConversion(quote, domain.OriginDesktopWeb)
+	debit, credit := conversion.Transactions()
+	limit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &quote.UserID, Period: domain.LimitPeriodDaily, MaxAmount: 15000, Currency: "EUR"}
+
+	quoteRow := func(expiresAt time.Time) *sqlmock.Rows {
+		return sqlmock.NewRows(fxQuoteSchema).
+			AddRow(quote.ID.String(), tenant.Default, quote.UserID.String(), "EUR", "USD", 10000, 10850, "1.085",
+				quote.SnapshotID.String(), expiresAt, now)
+	}
+
+	testData := map[string]struct {
+		setupMocks            func(sqlmock.Sqlmock)
+		wantUnavailable       bool
+		wantNotFound          bool
+		wantInsufficientFunds bool
+		wantLimitExceeded     bool
+		wantErr               bool
+	}{
+		"happy path - stores the debit, the credit and the conversion": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(LockFXQuoteQuery).WillReturnRows(quoteRow(now.Add(time.Minute)))
+				mock.ExpectQuery(FXQuoteConvertedQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(EURBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(12000))
+				mock.ExpectQuery(EURHeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(2000))
+				mock.ExpectQuery(EURSumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(5000))
+				mock.ExpectExec(InsertTransactionQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(InsertTransactionQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(InsertFXConversionQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the quote expired": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(LockFXQuoteQuery).WillReturnRows(quoteRow(now.Add(-time.Second)))
+				mock.ExpectRollback()
+			},
+			wantUnavailable: true,
+			wantErr:         true,
+		},
+		"failure - the quote was already converted": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(LockFXQuoteQuery).WillReturnRows(quoteRow(now.Add(time.Minute)))
+				mock.ExpectQuery(FXQuoteConvertedQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			wantUnavailable: true,
+			wantErr:         true,
+		},
+		"failure - the balance net of the holds does not cover the debit": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(LockFXQuoteQuery).WillReturnRows(quoteRow(now.Add(time.Minute)))
+				mock.ExpectQuery(FXQuoteConvertedQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(EURBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(12000))
+				mock.ExpectQuery(EURHeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(2001))
+				mock.ExpectRollback()
+			},
+			wantInsufficientFunds: true,
+			wantErr:               true,
+		},
+		"failure - the debit exceeds the limit": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(LockFXQuoteQuery).WillReturnRows(quoteRow(now.Add(time.Minute)))
+				mock.ExpectQuery(FXQuoteConvertedQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(EURBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(12000))
+				mock.ExpectQuery(EURHeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(0))
+				mock.ExpectQuery(EURSumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(5001))
+				mock.ExpectRollback()
+			},
+			wantLimitExceeded: true,
+			wantErr:           true,
+		},
+		"failure - the quote does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(LockFXQuoteQuery).WillReturnRows(sqlmock.NewRows(fxQuoteSchema))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+		"failure - the insert of the credit fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(LockFXQuoteQuery).WillReturnRows(quoteRow(now.Add(time.Minute)))
+				mock.ExpectQuery(FXQuoteConvertedQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(EURBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(12000))
+				mock.ExpectQuery(EURHeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(0))
+				mock.ExpectQuery(EURSumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+				mock.ExpectExec(InsertTransactionQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(InsertTransactionQuery).WillReturnError(errors.New("connection reset"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			created, err := repo.CreateFXConversion(context.Background(), conversion, debit, credit, []domain.Limit{limit})
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tc.wantUnavailable, errors.Is(err, domain.ErrQuoteUnavailable))
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+				var insufficientFunds domain.InsufficientFundsError
+				require.Equal(t, tc.wantInsufficientFunds, errors.As(err, &insufficientFunds))
+				var limitExceeded domain.LimitExceededError
+				require.Equal(t, tc.wantLimitExceeded, errors.As(err, &limitExceeded))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, conversion.ID, created.ID)
+				require.Equal(t, debit.ID, created.DebitTransactionID)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
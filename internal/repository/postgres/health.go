@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/uptrace/bun"
+)
+
+// SchemaVersion is the version of the latest migration of postgres-init the repository relies on
+const SchemaVersion = 15
+
+// Ping checks that a connection to the database can be established
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the version of the latest migration applied to the database
+func (r *Repository) MigrationVersion(ctx context.Context) (int, error) {
+	var version int
+	err := r.db.NewSelect().
+		TableExpr("schema_migrations").
+		ColumnExpr("COALESCE(MAX(?), 0)", bun.Ident("version")).
+		Scan(ctx, &version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the migration version: %w", err)
+	}
+	return version, nil
+}
+
+// CheckSchema checks that the database can be reached and that its schema is at least SchemaVersion
+func (r *Repository) CheckSchema(ctx context.Context) error {
+	if err := r.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to reach the database: %w", err)
+	}
+
+	version, err := r.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("database schema is at version %d, version %d is required", version, SchemaVersion)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const MigrationVersionQuery = `^SELECT COALESCE\(MAX\("version"\), 0\) FROM schema_migrations`
+
+func TestRepository_CheckSchema(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		wantErr    bool
+	}{
+		"happy path - the schema is up to date": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+				mock.ExpectQuery(MigrationVersionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(SchemaVersion))
+			},
+		},
+		"failure - the database cannot be reached": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: true,
+		},
+		"failure - migrations are missing": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+				mock.ExpectQuery(MigrationVersionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(SchemaVersion - 1))
+			},
+			wantErr: true,
+		},
+		"failure - the migrations table does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+				mock.ExpectQuery(MigrationVersionQuery).WillReturnError(errors.New(`relation "schema_migrations" does not exist`))
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp), sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			err = repo.CheckSchema(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
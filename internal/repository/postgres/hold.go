@@ -0,0 +1,314 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+)
+
+const holdNotFoundMessage = "hold not found"
+
+// CreateHold stores an active hold only if its amount is available: the balance of the user in the currency of the
+// hold minus the amounts of its other active holds in it that have not expired. The check and the insert are serialised with the debits of the user, so
+// that the available balance cannot change in between. It returns a domain.InsufficientFundsError otherwise.
+func (r *Repository) CreateHold(ctx context.Context, hold domain.Hold) (*domain.Hold, error) {
+	holdModel := mappers.ConvertHoldDomainToModel(hold)
+
+	now := time.Now()
+	holdModel.CreatedAt = now
+	holdModel.UpdatedAt = now
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		holdModel.TenantID = tenantID
+
+		if err := lockUserDebits(ctx, tx, tenantID, holdModel.UserID); err != nil {
+			return err
+		}
+		balance, err := userBalance(ctx, tx, tenantID, holdModel.UserID, holdModel.Currency, now)
+		if err != nil {
+			return err
+		}
+		held, err := heldAmount(ctx, tx, tenantID, holdModel.UserID, holdModel.Currency, now)
+		if err != nil {
+			return err
+		}
+		if available := balance - held; available < holdModel.Amount {
+			return domain.NewInsufficientFundsError(available, holdModel.Amount)
+		}
+
+		_, err = tx.NewInsert().Model(holdModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		var insufficientFunds domain.InsufficientFundsError
+		if errors.As(err, &insufficientFunds) {
+			return nil, insufficientFunds
+		}
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertHoldModelToDomain(*holdModel)
+	return &created, nil
+}
+
+func (r *Repository) GetHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	holdModel := new(models.Hold)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(holdModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(holdNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve hold")
+	}
+
+	hold := mappers.ConvertHoldModelToDomain(*holdModel)
+	return &hold, nil
+}
+
+// ListHolds retrieves the holds of the tenant, only the ones of the user when set, by creation time
+func (r *Repository) ListHolds(ctx context.Context, userID *uuid.UUID) ([]domain.Hold, error) {
+	var holdModels []*models.Hold
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		query := tx.NewSelect().
+			Model(&holdModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID)
+		if userID != nil {
+			query = query.Where("? = ?", bun.Ident("user_id"), *userID)
+		}
+		return query.Order("created_at ASC", "id ASC").Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list holds")
+	}
+
+	return mappers.ConvertHoldToDomainList(holdModels), nil
+}
+
+// CaptureHold settles an active hold that has not expired with the debit, which is checked against the limits like
+// CreateTransactionWithinLimits, then marks the hold captured with the amount of the debit, all in one database
+// transaction. It returns an error wrapping domain.ErrHoldTransition when the hold is no longer active.
+func (r *Repository) CaptureHold(ctx context.Context, id uuid.UUID, transaction domain.Transaction, limits []domain.Limit) (*domain.Hold, *domain.Transaction, error) {
+	transactionModel, err := mappers.ConvertTransactionDomainToModel(transaction)
+	if err != nil {
+		return nil, nil, err
+	}
+	holdModel := new(models.Hold)
+
+	now := time.Now()
+	transactionModel.CreatedAt = now
+
+	err = r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		transactionModel.TenantID = tenantID
+
+		err := tx.NewSelect().
+			Model(holdModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if err := holdTransitionError(holdModel.Status, holdModel.ExpiresAt, now); err != nil {
+			return err
+		}
+
+		if err := lockUserDebits(ctx, tx, tenantID, holdModel.UserID); err != nil {
+			return err
+		}
+		if err := checkLimits(ctx, tx, tenantID, holdModel.UserID, transactionModel.Amount, limits, now); err != nil {
+			return err
+		}
+		if _, err := tx.NewInsert().Model(transactionModel).Exec(ctx); err != nil {
+			return err
+		}
+
+		return tx.NewUpdate().
+			Model(holdModel).
+			Set("? = ?", bun.Ident("status"), domain.HoldStatusCaptured).
+			Set("? = ?", bun.Ident("captured_amount"), transactionModel.Amount).
+			Set("? = ?", bun.Ident("transaction_id"), transactionModel.ID).
+			Set("? = ?", bun.Ident("updated_at"), now).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Returning("*").
+			Scan(ctx)
+	})
+	if err != nil {
+		var limitExceeded domain.LimitExceededError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, repository.NewNotFoundError(holdNotFoundMessage)
+		case errors.Is(err, domain.ErrHoldTransition):
+			return nil, nil, err
+		case errors.As(err, &limitExceeded):
+			return nil, nil, limitExceeded
+		}
+		var uniqueErr repository.UniqueIndexError
+		if errors.As(translateInsertError(err), &uniqueErr) {
+			return nil, nil, uniqueErr
+		}
+		return nil, nil, errors.New("failed to capture hold")
+	}
+
+	hold := mappers.ConvertHoldModelToDomain(*holdModel)
+	created, err := mappers.ConvertTransactionModelToDomain(*transactionModel)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &hold, created, nil
+}
+
+// ReleaseHold frees the amount of an active hold that has not expired. It returns an error wrapping
+// domain.ErrHoldTransition when the hold is no longer active.
+func (r *Repository) ReleaseHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	holdModel := new(models.Hold)
+
+	now := time.Now()
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		err := tx.NewUpdate().
+			Model(holdModel).
+			Set("? = ?", bun.Ident("status"), domain.HoldStatusReleased).
+			Set("? = ?", bun.Ident("updated_at"), now).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Where("? = ?", bun.Ident("status"), domain.HoldStatusActive).
+			Where("? > ?", bun.Ident("expires_at"), now).
+			Returning("*").
+			Scan(ctx)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// the hold does not exist, is no longer active or expired
+		if err := tx.NewSelect().
+			Model(holdModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx); err != nil {
+			return err
+		}
+		return holdTransitionError(holdModel.Status, holdModel.ExpiresAt, now)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.NewNotFoundError(holdNotFoundMessage)
+		case errors.Is(err, domain.ErrHoldTransition):
+			return nil, err
+		}
+		return nil, errors.New("failed to release hold")
+	}
+
+	hold := mappers.ConvertHoldModelToDomain(*holdModel)
+	return &hold, nil
+}
+
+// GetHeldAmount returns the sum of the amounts of the active holds of a user in the currency that have not expired
+func (r *Repository) GetHeldAmount(ctx context.Context, userID uuid.UUID, currency string) (int64, error) {
+	var held int64
+
+	err := r.readReplicaInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		var err error
+		held, err = heldAmount(ctx, tx, tenantID, userID, currency, time.Now())
+		return err
+	})
+	if err != nil {
+		return 0, errors.New("failed to compute held amount")
+	}
+
+	return held, nil
+}
+
+// ExpireHolds marks expired up to limit active holds of any tenant whose expiry is at or before now, and returns how
+// many it marked. The holds locked by a capture or a release are skipped.
+func (r *Repository) ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error) {
+	var rows int64
+
+	err := r.runAsScheduler(ctx, func(ctx context.Context, tx bun.Tx) error {
+		expired := tx.NewSelect().
+			Model((*models.Hold)(nil)).
+			Column("id").
+			Where("? = ?", bun.Ident("status"), domain.HoldStatusActive).
+			Where("? <= ?", bun.Ident("expires_at"), now).
+			Limit(limit).
+			For("UPDATE SKIP LOCKED")
+
+		result, err := tx.NewUpdate().
+			Model((*models.Hold)(nil)).
+			Set("? = ?", bun.Ident("status"), domain.HoldStatusExpired).
+			Set("? = ?", bun.Ident("updated_at"), now).
+			Where("? IN (?)", bun.Ident("id"), expired).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, errors.New("failed to expire holds")
+	}
+
+	return int(rows), nil
+}
+
+// checkHeldFunds returns a domain.InsufficientFundsError when a debit of the amount, at now, would take funds held for
+// the user in the currency: while holds are active, the balance minus their amounts must cover the debit
+func checkHeldFunds(ctx context.Context, tx bun.Tx, tenantID string, userID uuid.UUID, currency string, amount int64, now time.Time) error {
+	held, err := heldAmount(ctx, tx, tenantID, userID, currency, now)
+	if err != nil || held == 0 {
+		return err
+	}
+	balance, err := userBalance(ctx, tx, tenantID, userID, currency, now)
+	if err != nil {
+		return err
+	}
+	if available := balance - held; available < amount {
+		return domain.NewInsufficientFundsError(available, amount)
+	}
+	return nil
+}
+
+func heldAmount(ctx context.Context, db bun.IDB, tenantID string, userID uuid.UUID, currency string, now time.Time) (int64, error) {
+	var held int64
+
+	err := db.NewSelect().
+		Model((*models.Hold)(nil)).
+		ColumnExpr("COALESCE(SUM(?), 0)::bigint", bun.Ident("amount")).
+		Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+		Where("? = ?", bun.Ident("user_id"), userID).
+		Where("? = ?", bun.Ident("currency"), currency).
+		Where("? = ?", bun.Ident("status"), domain.HoldStatusActive).
+		Where("? > ?", bun.Ident("expires_at"), now).
+		Scan(ctx, &held)
+	return held, err
+}
+
+// holdTransitionError returns an error wrapping domain.ErrHoldTransition when a hold in the status and expiring at
+// expiresAt can no longer be captured nor released at now
+func holdTransitionError(status string, expiresAt, now time.Time) error {
+	switch {
+	case status != string(domain.HoldStatusActive):
+		return fmt.Errorf("%w: the hold is %s", domain.ErrHoldTransition, status)
+	case !now.Before(expiresAt):
+		return fmt.Errorf("%w: the hold expired", domain.ErrHoldTransition)
+	}
+	return nil
+}
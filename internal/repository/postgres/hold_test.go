@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	HeldAmountQuery = `^SELECT COALESCE\(SUM\("amount"\), 0\)::bigint FROM "holds" AS "h" WHERE \("tenant_id" = 'default'\) AND \("user_id" = (.+)\) ` +
+		`AND \("currency" = 'USD'\) AND \("status" = 'ACTIVE'\) AND \("expires_at" > (.+)\)`
+	InsertHoldQuery  = `^INSERT INTO "holds" (.+)`
+	ReleaseHoldQuery = `^UPDATE "holds" AS "h" SET "status" = 'RELEASED', (.+) AND \("status" = 'ACTIVE'\) AND \("expires_at" > (.+)\) RETURNING \*`
+	GetHoldQuery     = `^SELECT (.+) FROM "holds" AS "h" WHERE \("tenant_id" = 'default'\) AND \("id" = (.+)\)`
+	ExpireHoldsQuery = `^UPDATE "holds" AS "h" SET "status" = 'EXPIRED', "updated_at" = '2024-02-01 08:00:00\+00:00' WHERE \("id" IN \(SELECT "h"."id" FROM "holds" AS "h" ` +
+		`WHERE \("status" = 'ACTIVE'\) AND \("expires_at" <= '2024-02-01 08:00:00\+00:00'\) LIMIT 50 FOR UPDATE SKIP LOCKED\)\)`
+)
+
+var holdSchema = []string{"id", "tenant_id", "user_id", "origin", "amount", "description", "metadata", "tags", "status", "expires_at",
+	"captured_amount", "transaction_id", "created_at", "updated_at"}
+
+func TestRepository_CreateHold(t *testing.T) {
+	t.Parallel()
+
+	hold := domain.Hold{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Origin:    domain.OriginDesktopWeb,
+		Amount:    60,
+		Status:    domain.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	testData := map[string]struct {
+		setupMocks            func(sqlmock.Sqlmock)
+		wantInsufficientFunds bool
+		wantErr               bool
+	}{
+		"happy path - the amount is available": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(GetBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(40))
+				mock.ExpectExec(InsertHoldQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the other holds reserve the balance": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(GetBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(50))
+				mock.ExpectRollback()
+			},
+			wantInsufficientFunds: true,
+			wantErr:               true,
+		},
+		"failure - the insert fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(GetBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(0))
+				mock.ExpectExec(InsertHoldQuery).WillReturnError(errors.New("connection reset"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			created, err := repo.CreateHold(context.Background(), hold)
+			if tc.wantErr {
+				require.Error(t, err)
+				var insufficientFunds domain.InsufficientFundsError
+				require.Equal(t, tc.wantInsufficientFunds, errors.As(err, &insufficientFunds))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, hold.ID, created.ID)
+				require.Equal(t, tenant.Default, created.TenantID)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_ReleaseHold(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	holdID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks     func(sqlmock.Sqlmock)
+		wantTransition bool
+		wantNotFound   bool
+		wantErr        bool
+	}{
+		"happy path - releases the hold": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ReleaseHoldQuery).WillReturnRows(sqlmock.NewRows(holdSchema).
+					AddRow(holdID.String(), tenant.Default, uuid.NewString(), domain.OriginDesktopWeb, 60, "", []byte(`{}`), "{}", "RELEASED",
+						now.Add(time.Hour), 0, nil, now, now))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the hold was captured": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ReleaseHoldQuery).WillReturnRows(sqlmock.NewRows(holdSchema))
+				mock.ExpectQuery(GetHoldQuery).WillReturnRows(sqlmock.NewRows(holdSchema).
+					AddRow(holdID.String(), tenant.Default, uuid.NewString(), domain.OriginDesktopWeb, 60, "", []byte(`{}`), "{}", "CAPTURED",
+						now.Add(time.Hour), 60, uuid.NewString(), now, now))
+				mock.ExpectRollback()
+			},
+			wantTransition: true,
+			wantErr:        true,
+		},
+		"failure - the hold does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ReleaseHoldQuery).WillReturnRows(sqlmock.NewRows(holdSchema))
+				mock.ExpectQuery(GetHoldQuery).WillReturnRows(sqlmock.NewRows(holdSchema))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			hold, err := repo.ReleaseHold(context.Background(), holdID)
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tc.wantTransition, errors.Is(err, domain.ErrHoldTransition))
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, domain.HoldStatusReleased, hold.Status)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_ExpireHolds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+
+	repo, err := NewRepository(db)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(SchedulerTxQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(ExpireHoldsQuery).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	count, err := repo.ExpireHolds(context.Background(), now, 50)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	expectationMet(t, mock)
+}
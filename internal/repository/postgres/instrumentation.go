@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+const instrumentationName = "traive-engineering-challenge/internal/repository/postgres"
+
+// RowsAffectedKey is the span attribute holding the number of rows returned or changed by a query
+const RowsAffectedKey = attribute.Key("db.rows_affected")
+
+// queryHook traces every query as a child span of the caller and records its duration per operation.
+// Statements are recorded without their parameters, which may hold personal or secret data.
+type queryHook struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+}
+
+func newQueryHook() (*queryHook, error) {
+	duration, err := otel.Meter(instrumentationName).Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of the database queries"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queryHook{
+		tracer:   otel.Tracer(instrumentationName),
+		duration: duration,
+	}, nil
+}
+
+func (h *queryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	operation := event.Operation()
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperation(operation),
+		semconv.DBStatement(redactStatement(event.Query)),
+	}
+
+	name := operation
+	if event.IQuery != nil {
+		if table := event.IQuery.GetTableName(); table != "" {
+			attrs = append(attrs, semconv.DBSQLTable(table))
+			name += " " + table
+		}
+	}
+
+	ctx, _ = h.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (h *queryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if event.Result != nil {
+		if rows, err := event.Result.RowsAffected(); err == nil {
+			span.SetAttributes(RowsAffectedKey.Int64(rows))
+		}
+	}
+
+	// a missing row is an answer rather than a failure of the query
+	failed := event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows)
+	if failed {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+
+	h.duration.Record(ctx, time.Since(event.StartTime).Seconds(), metric.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperation(event.Operation()),
+		attribute.Bool("error", failed),
+	))
+}
+
+// redactStatement replaces the string and numeric literals of a formatted statement with placeholders, keeping
+// its keywords and quoted identifiers
+func redactStatement(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+2])
+			i += end + 1
+		case c == '\'':
+			// skip to the closing quote; doubled quotes are escaped quotes
+			for i++; i < len(query); i++ {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte('?')
+		case isDigit(c) && (i == 0 || !isIdentifierByte(query[i-1])):
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+// PoolNameKey is the attribute of the pool metrics naming the pool, PoolPrimary or PoolReplica
+const PoolNameKey = attribute.Key("pool.name")
+
+// Names of the connection pools
+const (
+	PoolPrimary = "primary"
+	PoolReplica = "replica"
+)
+
+// registerPoolMetrics reports the statistics of the connection pool through the meter provider
+func registerPoolMetrics(connection *sql.DB, pool string) error {
+	meter := otel.Meter(instrumentationName)
+
+	open, err := meter.Int64ObservableGauge("db.client.connections.open",
+		metric.WithDescription("Number of open connections, in use or idle"))
+	if err != nil {
+		return err
+	}
+	usage, err := meter.Int64ObservableGauge("db.client.connections.usage",
+		metric.WithDescription("Number of connections by state, idle or used"))
+	if err != nil {
+		return err
+	}
+	maxOpen, err := meter.Int64ObservableGauge("db.client.connections.max",
+		metric.WithDescription("Maximum number of open connections, 0 when unlimited"))
+	if err != nil {
+		return err
+	}
+	waits, err := meter.Int64ObservableCounter("db.client.connections.wait_count",
+		metric.WithDescription("Number of times a query waited for a connection"))
+	if err != nil {
+		return err
+	}
+	waitTime, err := meter.Float64ObservableCounter("db.client.connections.wait_time",
+		metric.WithDescription("Total time spent waiting for a connection"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+
+	system := metric.WithAttributes(semconv.DBSystemPostgreSQL, PoolNameKey.String(pool))
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := connection.Stats()
+		o.ObserveInt64(open, int64(stats.OpenConnections), system)
+		o.ObserveInt64(usage, int64(stats.Idle), metric.WithAttributes(semconv.DBSystemPostgreSQL, PoolNameKey.String(pool), attribute.String("state", "idle")))
+		o.ObserveInt64(usage, int64(stats.InUse), metric.WithAttributes(semconv.DBSystemPostgreSQL, PoolNameKey.String(pool), attribute.String("state", "used")))
+		o.ObserveInt64(maxOpen, int64(stats.MaxOpenConnections), system)
+		o.ObserveInt64(waits, stats.WaitCount, system)
+		o.ObserveFloat64(waitTime, stats.WaitDuration.Seconds(), system)
+		return nil
+	}, open, usage, maxOpen, waits, waitTime)
+	return err
+}
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// TestRepository_Instrumentation checks that queries are traced as children of the span of the caller, without their
+// parameters, and that their durations and the statistics of the pool are reported
+func TestRepository_Instrumentation(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	tracerProvider, meterProvider := otel.GetTracerProvider(), otel.GetMeterProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetMeterProvider(meterProvider)
+	})
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+
+	repo, err := NewRepository(db)
+	require.NoError(t, err)
+
+	limitID := uuid.New()
+	expectTenantTx(mock, tenant.Default)
+	mock.ExpectQuery(GetLimitQuery).WillReturnRows(sqlmock.NewRows(limitSchema).
+		AddRow(limitID.String(), "global", nil, nil, "daily", 1000, time.Now(), time.Now()))
+	mock.ExpectCommit()
+	expectTenantTx(mock, tenant.Default)
+	mock.ExpectExec(DeleteLimitQuery).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "handler")
+	_, err = repo.GetLimit(ctx, limitID)
+	require.NoError(t, err)
+	require.Error(t, repo.DeleteLimit(ctx, limitID))
+	parent.End()
+	expectationMet(t, mock)
+
+	var selectSpan, deleteSpan sdktrace.ReadOnlySpan
+	for _, span := range spans.Ended() {
+		require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		switch span.Name() {
+		case "SELECT transaction_limits":
+			selectSpan = span
+		case "DELETE transaction_limits":
+			deleteSpan = span
+		}
+	}
+	require.NotNil(t, selectSpan)
+	require.NotNil(t, deleteSpan)
+
+	selectAttrs := attributes(selectSpan)
+	require.Equal(t, parent.SpanContext().SpanID(), selectSpan.Parent().SpanID())
+	require.Equal(t, "postgresql", selectAttrs[semconv.DBSystemKey].AsString())
+	require.Equal(t, int64(1), selectAttrs[RowsAffectedKey].AsInt64())
+	require.Contains(t, selectAttrs[semconv.DBStatementKey].AsString(), `WHERE ("tenant_id" = ?) AND ("id" = ?)`)
+	require.NotContains(t, selectAttrs[semconv.DBStatementKey].AsString(), limitID.String())
+	require.Equal(t, codes.Unset, selectSpan.Status().Code)
+
+	require.Equal(t, codes.Error, deleteSpan.Status().Code)
+	require.Equal(t, "connection reset", deleteSpan.Status().Description)
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+	names := map[string]bool{}
+	for _, scope := range collected.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			names[m.Name] = true
+		}
+	}
+	require.True(t, names["db.client.operation.duration"])
+	require.True(t, names["db.client.connections.open"])
+	require.True(t, names["db.client.connections.usage"])
+	require.True(t, names["db.client.connections.wait_time"])
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestRedactStatement(t *testing.T) {
+	tests := map[string]string{
+		`SELECT "l"."id" FROM "transaction_limits" AS "l" WHERE ("tenant_id" = 'default') AND ("id" = '8f1c')`: `SELECT "l"."id" FROM "transaction_limits" AS "l" WHERE ("tenant_id" = ?) AND ("id" = ?)`,
+		`INSERT INTO "transactions" ("amount", "origin") VALUES (300, 'O''Brien')`:                             `INSERT INTO "transactions" ("amount", "origin") VALUES (?, ?)`,
+		`SELECT set_config('app.tenant_id', 'acme', true)`:                                                     `SELECT set_config(?, ?, true)`,
+		`SELECT "t1"."amount" FROM "t2" LIMIT 10 OFFSET 2.5`:                                                   `SELECT "t1"."amount" FROM "t2" LIMIT ? OFFSET ?`,
+		`SELECT md5('x')::uuid`: `SELECT md5(?)::uuid`,
+	}
+
+	for query, want := range tests {
+		require.Equal(t, want, redactStatement(query))
+	}
+}
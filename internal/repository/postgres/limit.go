@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+)
+
+const limitNotFoundMessage = "limit not found"
+
+func (r *Repository) CreateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	limitModel := mappers.ConvertLimitDomainToModel(limit)
+
+	now := time.Now()
+	limitModel.CreatedAt = now
+	limitModel.UpdatedAt = now
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		limitModel.TenantID = tenantID
+		_, err := tx.NewInsert().Model(limitModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertLimitModelToDomain(*limitModel)
+	return &created, nil
+}
+
+func (r *Repository) GetLimit(ctx context.Context, id uuid.UUID) (*domain.Limit, error) {
+	limitModel := new(models.Limit)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(limitModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(limitNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve limit")
+	}
+
+	limit := mappers.ConvertLimitModelToDomain(*limitModel)
+	return &limit, nil
+}
+
+func (r *Repository) ListLimits(ctx context.Context) ([]domain.Limit, error) {
+	var limitModels []*models.Limit
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(&limitModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Order("created_at ASC", "id ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list limits")
+	}
+
+	return mappers.ConvertLimitToDomainList(limitModels), nil
+}
+
+// UpdateLimit replaces the definition of an existing limit, keeping its tenant and creation time
+func (r *Repository) UpdateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	limitModel := mappers.ConvertLimitDomainToModel(limit)
+	limitModel.UpdatedAt = time.Now()
+
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewUpdate().
+			Model(limitModel).
+			ExcludeColumn("id", TenantIDColumn, "created_at").
+			WherePK().
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Returning("created_at").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		limitModel.TenantID = tenantID
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(limitNotFoundMessage)
+		}
+		return nil, errors.New("failed to update limit")
+	}
+	if rows == 0 {
+		return nil, repository.NewNotFoundError(limitNotFoundMessage)
+	}
+
+	updated := mappers.ConvertLimitModelToDomain(*limitModel)
+	return &updated, nil
+}
+
+func (r *Repository) DeleteLimit(ctx context.Context, id uuid.UUID) error {
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewDelete().
+			Model((*models.Limit)(nil)).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return errors.New("failed to delete limit")
+	}
+	if rows == 0 {
+		return repository.NewNotFoundError(limitNotFoundMessage)
+	}
+	return nil
+}
+
+// ListApplicableLimits retrieves the global limits, every origin limit and the limits of the given user
+func (r *Repository) ListApplicableLimits(ctx context.Context, userID uuid.UUID) ([]domain.Limit, error) {
+	var limitModels []*models.Limit
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(&limitModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.
+					WhereOr("? IN (?)", bun.Ident("scope"), bun.In([]string{string(domain.LimitScopeGlobal), string(domain.LimitScopeOrigin)})).
+					WhereOr("? = ?", bun.Ident("user_id"), userID)
+			}).
+			Order("created_at ASC", "id ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list limits")
+	}
+
+	return mappers.ConvertLimitToDomainList(limitModels), nil
+}
+
+// SumUserDebitsSince sums the debits of a user in the currency created at or after the given instant.
+// An empty origin sums the debits of every origin.
+func (r *Repository) SumUserDebitsSince(ctx context.Context, userID uuid.UUID, origin, currency string, since time.Time) (int64, error) {
+	var sum int64
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		var err error
+		sum, err = sumUserDebitsSince(ctx, tx, tenantID, userID, origin, currency, since)
+		return err
+	})
+	if err != nil {
+		return 0, errors.New("failed to sum debits")
+	}
+	return sum, nil
+}
+
+// CreateTransactionWithinLimits persists a debit only if it keeps the user within every given limit and leaves the
+// funds held for the user in its currency. The debits of the user are serialised with a transaction-scoped advisory
+// lock, so that the rolling sums and the held amount cannot change between the check and the insert. It returns a
+// domain.LimitExceededError or a domain.InsufficientFundsError otherwise.
+func (r *Repository) CreateTransactionWithinLimits(ctx context.Context, transaction domain.Transaction, limits []domain.Limit) (*domain.Transaction, error) {
+	transactionModel, err := mappers.ConvertTransactionDomainToModel(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	transactionModel.CreatedAt = now
+
+	err = r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		transactionModel.TenantID = tenantID
+
+		if err := lockUserDebits(ctx, tx, tenantID, transactionModel.UserID); err != nil {
+			return err
+		}
+		if err := checkHeldFunds(ctx, tx, tenantID, transactionModel.UserID, transactionModel.Currency, transactionModel.Amount, now); err != nil {
+			return err
+		}
+		if err := checkLimits(ctx, tx, tenantID, transactionModel.UserID, transactionModel.Amount, limits, now); err != nil {
+			return err
+		}
+
+		return insertTransaction(ctx, tx, transactionModel, transaction.ScheduleRun)
+	})
+	if err != nil {
+		var insufficientFunds domain.InsufficientFundsError
+		var limitExceeded domain.LimitExceededError
+		switch {
+		case errors.As(err, &insufficientFunds):
+			return nil, insufficientFunds
+		case errors.As(err, &limitExceeded):
+			return nil, limitExceeded
+		}
+		return nil, translateInsertError(err)
+	}
+
+	return mappers.ConvertTransactionModelToDomain(*transactionModel)
+}
+
+// lockUserDebits serialises the debits and the holds of the user until the end of the database transaction, so that
+// the limits and the available balance cannot change between their check and the insert
+func lockUserDebits(ctx context.Context, tx bun.Tx, tenantID string, userID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext(?))", tenantID+"/"+userID.String())
+	return err
+}
+
+// checkLimits returns a domain.LimitExceededError when a debit of the amount, at now, would take the user over one or
+// more of the limits, each one summing the debits in its currency
+func checkLimits(ctx context.Context, tx bun.Tx, tenantID string, userID uuid.UUID, amount int64, limits []domain.Limit, now time.Time) error {
+	var exceeded []string
+	for _, limit := range limits {
+		origin := ""
+		if limit.Scope == domain.LimitScopeOrigin {
+			origin = limit.Origin
+		}
+
+		used, err := sumUserDebitsSince(ctx, tx, tenantID, userID, origin, limit.Currency, now.Add(-limit.Period.Window()))
+		if err != nil {
+			return err
+		}
+		if used+amount > limit.MaxAmount {
+			exceeded = append(exceeded, limit.ID.String())
+		}
+	}
+	if len(exceeded) > 0 {
+		return domain.NewLimitExceededError(exceeded)
+	}
+	return nil
+}
+
+func sumUserDebitsSince(ctx context.Context, db bun.IDB, tenantID string, userID uuid.UUID, origin, currency string, since time.Time) (int64, error) {
+	var sum int64
+
+	query := db.NewSelect().
+		Model((*models.Transaction)(nil)).
+		ColumnExpr("COALESCE(SUM(?), 0)::bigint", bun.Ident("amount")).
+		Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+		Where("? = ?", bun.Ident("user_id"), userID).
+		Where("? = ?", bun.Ident("transaction_type"), domain.TransactionTypeDebit.String()).
+		Where("? = ?", bun.Ident("currency"), currency).
+		Where("? >= ?", bun.Ident("created_at"), since)
+	if origin != "" {
+		query = query.Where("? = ?", bun.Ident("origin"), origin)
+	}
+
+	if err := query.Scan(ctx, &sum); err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
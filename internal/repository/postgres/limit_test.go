@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/support"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	AdvisoryLockQuery = `^SELECT pg_advisory_xact_lock\(hashtext\(`
+	SumDebitsQuery    = `^SELECT COALESCE\(SUM\("amount"\), 0\)::bigint FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'default'\) (.+)'DEBIT TRANSACTION'\) AND \("currency" = 'USD'\)`
+	GetLimitQuery     = `^SELECT (.+) FROM "transaction_limits" AS "l" WHERE \("tenant_id" = 'default'\) AND \("id" = (.+)\)`
+	DeleteLimitQuery  = `^DELETE FROM "transaction_limits" AS "l" WHERE \("tenant_id" = 'default'\) AND \("id" = (.+)\)`
+)
+
+var limitSchema = []string{"id", "scope", "user_id", "origin", "period", "max_amount", "created_at", "updated_at"}
+
+func TestRepository_CreateTransactionWithinLimits(t *testing.T) {
+	t.Parallel()
+
+	transaction := domain.Transaction{
+		ID:              uuid.New(),
+		UserID:          uuid.New(),
+		Origin:          support.MobileIOS,
+		TransactionType: domain.TransactionTypeDebit,
+		Amount:          300,
+		Status:          domain.TransactionStatusCompleted,
+	}
+	dailyLimit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodDaily, MaxAmount: 1000, Currency: domain.DefaultCurrency}
+	originLimit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeOrigin, Origin: support.MobileIOS, Period: domain.LimitPeriodMonthly, MaxAmount: 500, Currency: domain.DefaultCurrency}
+
+	testData := map[string]struct {
+		setupMocks            func(sqlmock.Sqlmock)
+		wantExceeded          []string
+		wantInsufficientFunds bool
+		wantErr               bool
+	}{
+		"happy path - the debit fits every limit": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(0))
+				mock.ExpectQuery(SumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(700))
+				mock.ExpectQuery(SumDebitsQuery + `(.+)"origin" = 'mobile-ios'`).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(200))
+				mock.ExpectExec(InsertTransactionQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the debit exceeds a limit": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(0))
+				mock.ExpectQuery(SumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(700))
+				mock.ExpectQuery(SumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(201))
+				mock.ExpectRollback()
+			},
+			wantExceeded: []string{originLimit.ID.String()},
+			wantErr:      true,
+		},
+		"happy path - the debit leaves the held funds": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(500))
+				mock.ExpectQuery(GetBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(800))
+				mock.ExpectQuery(SumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+				mock.ExpectQuery(SumDebitsQuery).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+				mock.ExpectExec(InsertTransactionQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the debit takes held funds": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(500))
+				mock.ExpectQuery(GetBalanceQuery).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(799))
+				mock.ExpectRollback()
+			},
+			wantInsufficientFunds: true,
+			wantErr:               true,
+		},
+		"failure - the sum fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvisoryLockQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(HeldAmountQuery).WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(0))
+				mock.ExpectQuery(SumDebitsQuery).WillReturnError(fmt.Errorf("query failed"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			_, err = repo.CreateTransactionWithinLimits(context.Background(), transaction, []domain.Limit{dailyLimit, originLimit})
+			if tc.wantErr {
+				require.Error(t, err)
+				var insufficientFunds domain.InsufficientFundsError
+				require.Equal(t, tc.wantInsufficientFunds, errors.As(err, &insufficientFunds))
+				var exceeded domain.LimitExceededError
+				if errors.As(err, &exceeded) {
+					require.Equal(t, tc.wantExceeded, exceeded.LimitIDs)
+				} else {
+					require.Nil(t, tc.wantExceeded)
+				}
+			} else {
+				require.NoError(t, err)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_GetLimit(t *testing.T) {
+	t.Parallel()
+
+	limitID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - returns the limit": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetLimitQuery).WillReturnRows(sqlmock.NewRows(limitSchema).
+					AddRow(limitID.String(), "global", nil, nil, "daily", 1000, time.Now(), time.Now()))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the limit does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetLimitQuery).WillReturnRows(sqlmock.NewRows(limitSchema))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			limit, err := repo.GetLimit(context.Background(), limitID)
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, limitID, limit.ID)
+				require.Equal(t, domain.LimitScopeGlobal, limit.Scope)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_DeleteLimit(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - deletes the limit": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(DeleteLimitQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the limit does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(DeleteLimitQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			err = repo.DeleteLimit(context.Background(), uuid.New())
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
@@ -1,35 +1,156 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/stdlib"
+	log "github.com/sirupsen/logrus"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
+	"strconv"
+	"time"
 )
 
 var ErrInvalidDatabaseURL = errors.New("invalid database URL")
 
 type Repository struct {
 	db *bun.DB
+	// statementTimeout bounds the queries run in a tenant transaction, 0 when they are only bound by their context
+	statementTimeout time.Duration
+	// readRetry is how read-only transactions failing on transient errors are retried
+	readRetry retryPolicy
+	// replica serves the list and report queries when set
+	replica *replica
 }
 
-func NewRepository(connection *sql.DB) (*Repository, error) {
+// Option configures a Repository
+type Option func(*Repository)
+
+// WithStatementTimeout makes the database cancel the queries of a tenant transaction that run longer than the timeout,
+// or than the time left before the deadline of their context when it is earlier
+func WithStatementTimeout(timeout time.Duration) Option {
+	return func(r *Repository) {
+		r.statementTimeout = timeout
+	}
+}
+
+// WithReadAttempts sets how many times a read-only transaction is attempted when it fails on a transient error,
+// such as a serialization failure or a reset connection. 1 disables the retries.
+func WithReadAttempts(attempts int) Option {
+	return func(r *Repository) {
+		r.readRetry.attempts = attempts
+	}
+}
+
+// NewRepository builds a repository on the connection pool. Its queries are traced and timed, and the statistics
+// of the pool are reported through the global meter provider.
+func NewRepository(connection *sql.DB, options ...Option) (*Repository, error) {
+	bunDB, err := newDB(connection, PoolPrimary)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &Repository{
+		db:        bunDB,
+		readRetry: retryPolicy{attempts: 3, initial: 50 * time.Millisecond, max: time.Second},
+	}
+	for _, option := range options {
+		option(repo)
+	}
+
+	if repo.replica != nil {
+		repo.replica.db, err = newDB(repo.replica.connection, PoolReplica)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+// newDB wraps the connection pool, tracing its queries and reporting its statistics under the name of the pool
+func newDB(connection *sql.DB, pool string) (*bun.DB, error) {
 	bunDB := bun.NewDB(connection, pgdialect.New())
 
-	return &Repository{db: bunDB}, nil
+	hook, err := newQueryHook()
+	if err != nil {
+		return nil, err
+	}
+	bunDB.AddQueryHook(hook)
+
+	if err := registerPoolMetrics(connection, pool); err != nil {
+		return nil, err
+	}
+	return bunDB, nil
 }
 
-func NewConnection(databaseURL string) (*sql.DB, error) {
-	dbConfig, err := pgx.ParseConfig(databaseURL)
+// Config is the configuration of the connection pool
+type Config struct {
+	URL string
+	// MaxOpenConns is the maximum number of open connections, 0 meaning unlimited
+	MaxOpenConns int
+	MaxIdleConns int
+	// ConnMaxLifetime and ConnMaxIdleTime recycle the connections, 0 meaning never
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout is the statement_timeout of every connection, 0 meaning none
+	StatementTimeout time.Duration
+	// ConnectAttempts is how many times the database is pinged on startup before giving up
+	ConnectAttempts int
+	// ConnectBackoff is the delay before the second ping, doubled after each failure up to ConnectMaxBackoff
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
+}
+
+// NewConnection opens a connection pool without connecting to the database
+func NewConnection(cfg Config) (*sql.DB, error) {
+	dbConfig, err := pgx.ParseConfig(cfg.URL)
 	if err != nil {
 		return nil, err
 	}
 	dbConfig.PreferSimpleProtocol = true
-	return stdlib.OpenDB(*dbConfig), nil
+	if cfg.StatementTimeout > 0 {
+		dbConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	connection := stdlib.OpenDB(*dbConfig)
+	connection.SetMaxOpenConns(cfg.MaxOpenConns)
+	connection.SetMaxIdleConns(cfg.MaxIdleConns)
+	connection.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	connection.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return connection, nil
 }
 
+// Connect opens a connection pool and waits for the database to answer, retrying with an exponential backoff so that
+// the API can start along with its database. It fails once ConnectAttempts pings failed or ctx is done.
+func Connect(ctx context.Context, cfg Config) (*sql.DB, error) {
+	connection, err := NewConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := retryPolicy{attempts: cfg.ConnectAttempts, initial: cfg.ConnectBackoff, max: cfg.ConnectMaxBackoff}
+	err = policy.run(ctx, func(error) bool { return true }, func(attempt int) error {
+		err := connection.PingContext(ctx)
+		if err != nil && attempt < policy.attempts {
+			log.WithError(err).WithField("attempt", attempt).Warn("Database is not reachable, retrying")
+		}
+		return err
+	})
+	if err != nil {
+		_ = connection.Close()
+		return nil, fmt.Errorf("failed to reach the database: %w", err)
+	}
+	return connection, nil
+}
+
+// Close closes the connection pools of the primary and of the replica
 func (r *Repository) Close() error {
-	return r.db.Close()
+	err := r.db.Close()
+	if r.replica != nil {
+		err = errors.Join(err, r.replica.db.Close())
+	}
+	return err
 }
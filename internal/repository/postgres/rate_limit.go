@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/uptrace/bun"
+	"sync"
+	"time"
+	"traive-engineering-challenge/internal/ratelimit"
+	"traive-engineering-challenge/internal/repository/models"
+)
+
+// rateLimitSweepInterval is how often the buckets of a tenant that refilled completely are deleted. Each bucket expires
+// after the idle time of its own limit, so that sweeping while taking from one class of routes leaves the partly drained
+// buckets of the classes refilling slower.
+const rateLimitSweepInterval = 10 * time.Minute
+
+// RateLimitStore keeps the rate limiting buckets in Postgres, so that every instance of the API shares them.
+// Each take locks the row of its bucket, which serialises the requests of a caller across instances.
+type RateLimitStore struct {
+	repo *Repository
+	now  func() time.Time
+
+	mu        sync.Mutex
+	lastSweep map[string]time.Time
+}
+
+func NewRateLimitStore(repo *Repository) *RateLimitStore {
+	return &RateLimitStore{
+		repo:      repo,
+		now:       time.Now,
+		lastSweep: make(map[string]time.Time),
+	}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	now := s.now()
+
+	var result ratelimit.Result
+	// buckets are not read back by callers, so taking a token does not stick the reads of the caller to the primary
+	err := s.repo.runInTenantTx(ctx, s.repo.db, nil, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		// create the bucket full when it is missing, so that there is always a row to lock
+		_, err := tx.NewInsert().
+			Model(&models.RateLimitBucket{TenantID: tenantID, Key: key, Tokens: float64(limit.Burst), UpdatedAt: now, ExpiresAt: now}).
+			On("CONFLICT DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		bucketModel := &models.RateLimitBucket{TenantID: tenantID, Key: key}
+		if err := tx.NewSelect().Model(bucketModel).WherePK().For("UPDATE").Scan(ctx); err != nil {
+			return err
+		}
+
+		var bucket ratelimit.Bucket
+		bucket, result = ratelimit.Take(ratelimit.Bucket{Tokens: bucketModel.Tokens, UpdatedAt: bucketModel.UpdatedAt}, limit, now)
+		bucketModel.Tokens = bucket.Tokens
+		bucketModel.UpdatedAt = bucket.UpdatedAt
+		bucketModel.ExpiresAt = bucket.UpdatedAt.Add(limit.IdleFor())
+
+		if _, err := tx.NewUpdate().Model(bucketModel).Column("tokens", "updated_at", "expires_at").WherePK().Exec(ctx); err != nil {
+			return err
+		}
+
+		if s.sweepDue(tenantID, now) {
+			_, err = tx.NewDelete().
+				Model((*models.RateLimitBucket)(nil)).
+				Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+				Where("? < ?", bun.Ident("expires_at"), now).
+				Exec(ctx)
+		}
+		return err
+	})
+	if err != nil {
+		return ratelimit.Result{}, errors.New("failed to take rate limit token")
+	}
+
+	return result, nil
+}
+
+// sweepDue tells whether the idle buckets of the tenant should be deleted, at most once per interval and instance
+func (s *RateLimitStore) sweepDue(tenantID string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep[tenantID]) < rateLimitSweepInterval {
+		return false
+	}
+	s.lastSweep[tenantID] = now
+	return true
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/ratelimit"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	InsertRateLimitBucketQuery = `^INSERT INTO "rate_limit_buckets" AS "b" (.+) ON CONFLICT DO NOTHING`
+	SelectRateLimitBucketQuery = `^SELECT (.+) FROM "rate_limit_buckets" AS "b" WHERE \("b"."tenant_id" = 'default' AND "b"."key" = 'read:ip:192.0.2.1'\) FOR UPDATE`
+	UpdateRateLimitBucketQuery = `^UPDATE "rate_limit_buckets" AS "b" SET "tokens" = (.+), "updated_at" = (.+), "expires_at" = '2024-01-01 12:00:10\+00:00' WHERE \("b"."tenant_id" = 'default' AND "b"."key" = 'read:ip:192.0.2.1'\)`
+	DeleteRateLimitBucketQuery = `^DELETE FROM "rate_limit_buckets" AS "b" WHERE \("tenant_id" = 'default'\) AND \("expires_at" < '2024-01-01 12:00:00\+00:00'\)`
+)
+
+var rateLimitBucketSchema = []string{"tenant_id", "key", "tokens", "updated_at", "expires_at"}
+
+func TestRateLimitStore_Take(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limit := ratelimit.PerMinute(60, 10)
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		swept      bool
+		wantResult ratelimit.Result
+		wantErr    bool
+	}{
+		"happy path - takes a token from the locked bucket": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(InsertRateLimitBucketQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(SelectRateLimitBucketQuery).WillReturnRows(sqlmock.NewRows(rateLimitBucketSchema).
+					AddRow("default", "read:ip:192.0.2.1", 4.5, now.Add(-time.Second), now.Add(5500*time.Millisecond)))
+				mock.ExpectExec(UpdateRateLimitBucketQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			swept:      true,
+			wantResult: ratelimit.Result{Allowed: true, Limit: 10, Remaining: 4, ResetAfter: 5500 * time.Millisecond},
+		},
+		"happy path - deletes the idle buckets of the tenant once per interval": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(InsertRateLimitBucketQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(SelectRateLimitBucketQuery).WillReturnRows(sqlmock.NewRows(rateLimitBucketSchema).
+					AddRow("default", "read:ip:192.0.2.1", 10.0, now, now))
+				mock.ExpectExec(UpdateRateLimitBucketQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(DeleteRateLimitBucketQuery).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			wantResult: ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Second},
+		},
+		"failure - the bucket cannot be locked": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(InsertRateLimitBucketQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(SelectRateLimitBucketQuery).WillReturnError(errors.New("lock timeout"))
+				mock.ExpectRollback()
+			},
+			swept:   true,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			store := NewRateLimitStore(repo)
+			store.now = func() time.Time { return now }
+			if tc.swept {
+				store.lastSweep[tenant.Default] = now
+			}
+
+			tc.setupMocks(mock)
+
+			result, err := store.Take(context.Background(), "read:ip:192.0.2.1", limit)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantResult, result)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+	"sync"
+	"sync/atomic"
+	"time"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// replicationLagQuery returns the replication lag of the database in seconds: the age of the last transaction replayed
+// while WAL received from the primary is still waiting to be replayed, and 0 on a primary or a replica that caught up
+const replicationLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// ReplicaConfig configures the routing of the queries to a read replica
+type ReplicaConfig struct {
+	// MaxLag is the replication lag beyond which queries go back to the primary
+	MaxLag time.Duration
+	// CheckInterval is how often MonitorReplica checks the health and the lag of the replica
+	CheckInterval time.Duration
+	// StickyWindow is how long the reads of a caller go to the primary after it wrote, so that it reads its own writes
+	StickyWindow time.Duration
+}
+
+// replica is a read replica serving the list and report queries, as long as it is healthy and does not lag
+type replica struct {
+	connection *sql.DB
+	db         *bun.DB
+	cfg        ReplicaConfig
+	healthy    atomic.Bool
+
+	mu         sync.Mutex
+	lastWrites map[string]time.Time
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+// WithReplica routes the list and report queries to the read replica of the connection pool. The replica is only
+// used once MonitorReplica found it healthy.
+func WithReplica(connection *sql.DB, cfg ReplicaConfig) Option {
+	return func(r *Repository) {
+		r.replica = &replica{
+			connection: connection,
+			cfg:        cfg,
+			lastWrites: make(map[string]time.Time),
+			now:        time.Now,
+		}
+	}
+}
+
+// MonitorReplica checks the health and the replication lag of the replica every check interval until ctx is done.
+// It returns immediately when the repository has no replica.
+func (r *Repository) MonitorReplica(ctx context.Context) {
+	if r.replica == nil {
+		return
+	}
+
+	ticker := time.NewTicker(r.replica.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		r.checkReplica(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkReplica marks the replica as healthy when it answers within the check interval and lags at most by MaxLag
+func (r *Repository) checkReplica(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.replica.cfg.CheckInterval)
+	defer cancel()
+
+	var lagSeconds float64
+	err := r.replica.db.NewRaw(replicationLagQuery).Scan(ctx, &lagSeconds)
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	healthy := err == nil && lag <= r.replica.cfg.MaxLag
+
+	if r.replica.healthy.Swap(healthy) != healthy {
+		entry := log.WithField("lag", lag.String())
+		switch {
+		case healthy:
+			entry.Info("Read replica is healthy, routing reads to it")
+		case err != nil:
+			entry.WithError(err).Warn("Read replica is unavailable, routing reads to the primary")
+		default:
+			entry.Warn("Read replica is lagging, routing reads to the primary")
+		}
+	}
+}
+
+// readReplicaInTenant runs fn like readInTenant, on the replica unless it is unhealthy, lagging, or the caller wrote
+// within the sticky window. A transient failure of the replica sends the next attempts to the primary.
+func (r *Repository) readReplicaInTenant(ctx context.Context, fn func(ctx context.Context, tx bun.Tx, tenantID string) error) error {
+	return r.retryRead(ctx, func() error {
+		db := r.readDB(ctx)
+		err := r.runInTenantTx(ctx, db, &sql.TxOptions{ReadOnly: true}, fn)
+		if err != nil && db != r.db && isTransient(err) {
+			r.replica.healthy.Store(false)
+		}
+		return err
+	})
+}
+
+// readDB is the database the reads of the caller of ctx are routed to
+func (r *Repository) readDB(ctx context.Context) *bun.DB {
+	if r.replica == nil || !r.replica.healthy.Load() || r.replica.isSticky(callerKey(ctx)) {
+		return r.db
+	}
+	return r.replica.db
+}
+
+// markWrite starts the sticky window of the caller of ctx
+func (r *Repository) markWrite(ctx context.Context) {
+	if r.replica == nil {
+		return
+	}
+	if key := callerKey(ctx); key != "" {
+		r.replica.markWrite(key)
+	}
+}
+
+func (s *replica) markWrite(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.lastWrites[key] = now
+
+	// forget the callers whose window is over, at most once per window
+	if now.Sub(s.lastSweep) >= s.cfg.StickyWindow {
+		s.lastSweep = now
+		for k, writtenAt := range s.lastWrites {
+			if now.Sub(writtenAt) >= s.cfg.StickyWindow {
+				delete(s.lastWrites, k)
+			}
+		}
+	}
+}
+
+func (s *replica) isSticky(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writtenAt, ok := s.lastWrites[key]
+	return ok && s.now().Sub(writtenAt) < s.cfg.StickyWindow
+}
+
+// callerKey identifies the caller of ctx within its tenant, or is empty when the request is not authenticated.
+// Stickiness is kept in memory, so a caller whose requests are balanced over several instances of the API may
+// still read from the replica on an instance it did not write through.
+func callerKey(ctx context.Context) string {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Subject == "" {
+		return ""
+	}
+	return tenant.FromContext(ctx) + "/" + principal.Subject
+}
@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const ReplicationLagQuery = `^SELECT CASE\s+WHEN NOT pg_is_in_recovery\(\)`
+
+func newReplicatedRepository(t *testing.T) (*Repository, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	primaryDB, primary, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	replicaDB, replica, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+
+	repo, err := NewRepository(primaryDB, WithReplica(replicaDB, ReplicaConfig{
+		MaxLag:        5 * time.Second,
+		CheckInterval: time.Second,
+		StickyWindow:  10 * time.Second,
+	}))
+	require.NoError(t, err)
+	repo.readRetry.initial = time.Millisecond
+
+	return repo, primary, replica
+}
+
+func expectReplicationLag(mock sqlmock.Sqlmock, lagSeconds float64) {
+	mock.ExpectQuery(ReplicationLagQuery).WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(lagSeconds))
+}
+
+func expectListTransactions(mock sqlmock.Sqlmock) {
+	expectTenantTx(mock, tenant.Default)
+	mock.ExpectQuery(GetTransactionsQuery).WillReturnRows(sqlmock.NewRows(transactionSchema))
+	mock.ExpectCommit()
+}
+
+func TestRepository_ReplicaRouting(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		// setupReplica sets the expectations of the health check of the replica, if it is checked
+		setupReplica func(replica sqlmock.Sqlmock)
+		checked      bool
+		wantReplica  bool
+	}{
+		"happy path - the list is read from a healthy replica": {
+			setupReplica: func(replica sqlmock.Sqlmock) { expectReplicationLag(replica, 0.2) },
+			checked:      true,
+			wantReplica:  true,
+		},
+		"failure - the replica was not checked yet": {
+			setupReplica: func(replica sqlmock.Sqlmock) {},
+		},
+		"failure - the replica lags beyond the threshold": {
+			setupReplica: func(replica sqlmock.Sqlmock) { expectReplicationLag(replica, 30) },
+			checked:      true,
+		},
+		"failure - the replica is unreachable": {
+			setupReplica: func(replica sqlmock.Sqlmock) {
+				replica.ExpectQuery(ReplicationLagQuery).WillReturnError(errors.New("connection refused"))
+			},
+			checked: true,
+		},
+	}
+
+	for name, tc := range testData {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, primary, replica := newReplicatedRepository(t)
+			tc.setupReplica(replica)
+			if tc.checked {
+				repo.checkReplica(context.Background())
+			}
+
+			if tc.wantReplica {
+				expectListTransactions(replica)
+			} else {
+				expectListTransactions(primary)
+			}
+
+			_, err := repo.ListTransactions(context.Background())
+			require.NoError(t, err)
+			expectationMet(t, primary)
+			expectationMet(t, replica)
+		})
+	}
+}
+
+// TestRepository_ReadYourWrites checks that a caller reads from the primary for the sticky window after it wrote,
+// while the other callers keep reading from the replica
+func TestRepository_ReadYourWrites(t *testing.T) {
+	t.Parallel()
+
+	repo, primary, replica := newReplicatedRepository(t)
+	now := time.Now()
+	repo.replica.now = func() time.Time { return now }
+
+	expectReplicationLag(replica, 0)
+	repo.checkReplica(context.Background())
+
+	writer := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "key-1"})
+	other := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "key-2"})
+
+	// the write of the caller
+	expectTenantTx(primary, tenant.Default)
+	primary.ExpectExec(DeleteLimitQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	primary.ExpectCommit()
+	require.NoError(t, repo.DeleteLimit(writer, uuid.New()))
+
+	// within the window, the caller reads its write from the primary, others read from the replica
+	expectListTransactions(primary)
+	_, err := repo.ListTransactions(writer)
+	require.NoError(t, err)
+
+	expectListTransactions(replica)
+	_, err = repo.ListTransactions(other)
+	require.NoError(t, err)
+
+	// the same caller in another tenant is another caller
+	expectTenantTx(replica, "acme")
+	replica.ExpectQuery(GetTransactionsQuery).WillReturnRows(sqlmock.NewRows(transactionSchema))
+	replica.ExpectCommit()
+	_, err = repo.ListTransactions(tenant.WithID(writer, "acme"))
+	require.NoError(t, err)
+
+	// once the window is over, the caller reads from the replica again
+	now = now.Add(10 * time.Second)
+	expectListTransactions(replica)
+	_, err = repo.ListTransactions(writer)
+	require.NoError(t, err)
+
+	expectationMet(t, primary)
+	expectationMet(t, replica)
+}
+
+func TestRepository_ReplicaFallback(t *testing.T) {
+	t.Parallel()
+
+	repo, primary, replica := newReplicatedRepository(t)
+	expectReplicationLag(replica, 0)
+	repo.checkReplica(context.Background())
+
+	// the replica fails on a transient error: the read is retried on the primary, which serves the next reads
+	expectTenantTx(replica, tenant.Default)
+	replica.ExpectQuery(GetTransactionsQuery).WillReturnError(&pgconn.PgError{Code: "57P01", Message: "terminating connection due to administrator command"})
+	replica.ExpectRollback()
+	expectListTransactions(primary)
+	expectListTransactions(primary)
+
+	_, err := repo.ListTransactions(context.Background())
+	require.NoError(t, err)
+	_, err = repo.ListTransactions(context.Background())
+	require.NoError(t, err)
+
+	// the next health check sends the reads back to the replica
+	expectReplicationLag(replica, 0)
+	repo.checkReplica(context.Background())
+	expectListTransactions(replica)
+	_, err = repo.ListTransactions(context.Background())
+	require.NoError(t, err)
+
+	expectationMet(t, primary)
+	expectationMet(t, replica)
+}
+
+// TestRepository_RiskHistoryOnPrimary checks that the history read by the risk rules comes from the primary, even
+// when the replica is healthy
+func TestRepository_RiskHistoryOnPrimary(t *testing.T) {
+	t.Parallel()
+
+	repo, primary, replica := newReplicatedRepository(t)
+	expectReplicationLag(replica, 0)
+	repo.checkReplica(context.Background())
+
+	expectTenantTx(primary, tenant.Default)
+	primary.ExpectQuery(CountUserTransactionsQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	primary.ExpectCommit()
+
+	count, err := repo.CountUserTransactionsSince(context.Background(), uuid.New(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	expectationMet(t, primary)
+	expectationMet(t, replica)
+}
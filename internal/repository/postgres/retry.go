@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/jackc/pgconn"
+	"io"
+	"math/rand"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// retryPolicy retries an operation with an exponential backoff: the delay before the second attempt is initial,
+// doubled after each failure up to max, with a random jitter so that instances do not retry in lockstep
+type retryPolicy struct {
+	attempts int
+	initial  time.Duration
+	max      time.Duration
+}
+
+// run calls fn until it succeeds, fails with an error that is not retryable, the attempts are exhausted or ctx is done.
+// It returns the error of the last attempt.
+func (p retryPolicy) run(ctx context.Context, retryable func(error) bool, fn func(attempt int) error) error {
+	delay := p.initial
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= p.attempts || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(jitter(delay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > p.max {
+			delay = p.max
+		}
+	}
+}
+
+// retryRead runs the read with the read retry policy of the repository, retrying it on transient errors
+func (r *Repository) retryRead(ctx context.Context, read func() error) error {
+	return r.readRetry.run(ctx, isTransient, func(int) error {
+		return read()
+	})
+}
+
+// jitter picks a random delay between half of the delay and the delay
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// isTransient tells whether the error may not happen again when the operation is retried: serialization failures,
+// deadlocks, and connections that were refused, reset or terminated by the server
+func isTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", "40P01", "57P01", "57P02", "57P03":
+			// serialization_failure, deadlock_detected, admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+		// connection exceptions
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		pgconn.SafeToRetry(err)
+}
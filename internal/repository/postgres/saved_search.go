@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+)
+
+const savedSearchNotFoundMessage = "saved search not found"
+
+// CreateSavedSearch stores a saved search. It returns a repository.UniqueIndexError when the tenant already has a
+// search of the same name.
+func (r *Repository) CreateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	searchModel := mappers.ConvertSavedSearchDomainToModel(search)
+
+	now := time.Now()
+	searchModel.CreatedAt = now
+	searchModel.UpdatedAt = now
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		searchModel.TenantID = tenantID
+		_, err := tx.NewInsert().Model(searchModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertSavedSearchModelToDomain(*searchModel)
+	return &created, nil
+}
+
+func (r *Repository) GetSavedSearch(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error) {
+	return r.getSavedSearch(ctx, "id", id)
+}
+
+func (r *Repository) GetSavedSearchByName(ctx context.Context, name string) (*domain.SavedSearch, error) {
+	return r.getSavedSearch(ctx, "name", name)
+}
+
+// getSavedSearch retrieves the saved search of the tenant whose column has the value
+func (r *Repository) getSavedSearch(ctx context.Context, column string, value interface{}) (*domain.SavedSearch, error) {
+	searchModel := new(models.SavedSearch)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(searchModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident(column), value).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(savedSearchNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve saved search")
+	}
+
+	search := mappers.ConvertSavedSearchModelToDomain(*searchModel)
+	return &search, nil
+}
+
+// ListSavedSearches retrieves every saved search of the tenant, by name
+func (r *Repository) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	var searchModels []*models.SavedSearch
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(&searchModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Order("name ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list saved searches")
+	}
+
+	return mappers.ConvertSavedSearchToDomainList(searchModels), nil
+}
+
+// UpdateSavedSearch replaces the definition of an existing saved search, keeping its tenant, owner and creation time
+func (r *Repository) UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	searchModel := mappers.ConvertSavedSearchDomainToModel(search)
+	searchModel.UpdatedAt = time.Now()
+
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewUpdate().
+			Model(searchModel).
+			ExcludeColumn("id", TenantIDColumn, "owner", "created_at").
+			WherePK().
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Returning("owner, created_at").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		searchModel.TenantID = tenantID
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(savedSearchNotFoundMessage)
+		}
+		var unique repository.UniqueIndexError
+		if translated := translateInsertError(err); errors.As(translated, &unique) {
+			return nil, translated
+		}
+		return nil, errors.New("failed to update saved search")
+	}
+	if rows == 0 {
+		return nil, repository.NewNotFoundError(savedSearchNotFoundMessage)
+	}
+
+	updated := mappers.ConvertSavedSearchModelToDomain(*searchModel)
+	return &updated, nil
+}
+
+func (r *Repository) DeleteSavedSearch(ctx context.Context, id uuid.UUID) error {
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewDelete().
+			Model((*models.SavedSearch)(nil)).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return errors.New("failed to delete saved search")
+	}
+	if rows == 0 {
+		return repository.NewNotFoundError(savedSearchNotFoundMessage)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	GetSavedSearchByNameQuery = `^SELECT (.+) FROM "saved_searches" AS "s" WHERE \("tenant_id" = 'default'\) AND \("name" = 'big-debits'\)`
+	InsertSavedSearchQuery    = `^INSERT INTO "saved_searches" (.+) VALUES (.+)'default', 'big-debits', 'analyst'`
+)
+
+var savedSearchSchema = []string{"id", "name", "owner", "origin", "transaction_type", "filter", "sort", "shared", "created_at", "updated_at"}
+
+func TestRepository_GetSavedSearchByName(t *testing.T) {
+	t.Parallel()
+
+	searchID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - returns the saved search": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetSavedSearchByNameQuery).WillReturnRows(sqlmock.NewRows(savedSearchSchema).
+					AddRow(searchID.String(), "big-debits", "analyst", nil, nil, "amount > 1000", "-amount", true, time.Now(), time.Now()))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the saved search does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetSavedSearchByNameQuery).WillReturnRows(sqlmock.NewRows(savedSearchSchema))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			search, err := repo.GetSavedSearchByName(context.Background(), "big-debits")
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, searchID, search.ID)
+				require.Equal(t, "amount > 1000", search.Filter)
+				require.True(t, search.Shared)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_CreateSavedSearch(t *testing.T) {
+	t.Parallel()
+
+	search := domain.SavedSearch{ID: uuid.New(), Name: "big-debits", Owner: "analyst", Filter: "amount > 1000"}
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		wantUnique bool
+		wantErr    bool
+	}{
+		"happy path - stores the saved search in the tenant": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(InsertSavedSearchQuery).WillReturnRows(sqlmock.NewRows([]string{"origin", "transaction_type", "sort"}).AddRow(nil, nil, nil))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the name is taken": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(InsertSavedSearchQuery).WillReturnError(&pgconn.PgError{
+					Code:   "23505",
+					Detail: "Key (tenant_id, name)=(default, big-debits) already exists.",
+				})
+				mock.ExpectRollback()
+			},
+			wantUnique: true,
+			wantErr:    true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			created, err := repo.CreateSavedSearch(context.Background(), search)
+			if tc.wantErr {
+				require.Error(t, err)
+				var unique repository.UniqueIndexError
+				require.Equal(t, tc.wantUnique, errors.As(err, &unique))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, search.ID, created.ID)
+				require.False(t, created.CreatedAt.IsZero())
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"strconv"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+)
+
+const (
+	scheduleNotFoundMessage = "schedule not found"
+	// scheduleRunsPrimaryKey is the constraint the run of an occurrence materialized twice violates
+	scheduleRunsPrimaryKey = "schedule_runs_pkey"
+)
+
+func (r *Repository) CreateSchedule(ctx context.Context, schedule domain.Schedule) (*domain.Schedule, error) {
+	scheduleModel := mappers.ConvertScheduleDomainToModel(schedule)
+
+	now := time.Now()
+	scheduleModel.CreatedAt = now
+	scheduleModel.UpdatedAt = now
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		scheduleModel.TenantID = tenantID
+		_, err := tx.NewInsert().Model(scheduleModel).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, translateInsertError(err)
+	}
+
+	created := mappers.ConvertScheduleModelToDomain(*scheduleModel)
+	return &created, nil
+}
+
+func (r *Repository) GetSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	scheduleModel := new(models.Schedule)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(scheduleModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(scheduleNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve schedule")
+	}
+
+	schedule := mappers.ConvertScheduleModelToDomain(*scheduleModel)
+	return &schedule, nil
+}
+
+// ListSchedules retrieves the schedules of the tenant, only the ones of the user when set, by creation time
+func (r *Repository) ListSchedules(ctx context.Context, userID *uuid.UUID) ([]domain.Schedule, error) {
+	var scheduleModels []*models.Schedule
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		query := tx.NewSelect().
+			Model(&scheduleModels).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID)
+		if userID != nil {
+			query = query.Where("? = ?", bun.Ident("user_id"), *userID)
+		}
+		return query.Order("created_at ASC", "id ASC").Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list schedules")
+	}
+
+	return mappers.ConvertScheduleToDomainList(scheduleModels), nil
+}
+
+// UpdateScheduleStatus moves a schedule in one of the from statuses to the status, setting its next run. It returns
+// an error wrapping domain.ErrScheduleTransition when the schedule is in another status, which it may have just
+// changed to.
+func (r *Repository) UpdateScheduleStatus(ctx context.Context, id uuid.UUID, from []domain.ScheduleStatus, to domain.ScheduleStatus, nextRunAt *time.Time) (*domain.Schedule, error) {
+	scheduleModel := new(models.Schedule)
+
+	var current string
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		err := tx.NewUpdate().
+			Model(scheduleModel).
+			Set("? = ?", bun.Ident("status"), to).
+			Set("? = ?", bun.Ident("next_run_at"), nextRunAt).
+			Set("? = ?", bun.Ident("updated_at"), time.Now()).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Where("? IN (?)", bun.Ident("status"), bun.In(from)).
+			Returning("*").
+			Scan(ctx)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// the schedule does not exist or is in another status
+		return tx.NewSelect().
+			Model((*models.Schedule)(nil)).
+			Column("status").
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx, &current)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(scheduleNotFoundMessage)
+		}
+		return nil, errors.New("failed to update schedule status")
+	}
+	if current != "" {
+		return nil, fmt.Errorf("%w: the schedule is %s", domain.ErrScheduleTransition, current)
+	}
+
+	updated := mappers.ConvertScheduleModelToDomain(*scheduleModel)
+	return &updated, nil
+}
+
+// ClaimDueSchedules leases to the owner, until now plus the lease, up to limit active schedules of any tenant whose next
+// run is due at now, and whose lease is free or expired. Schedules locked by a concurrent claim are skipped, so that
+// every schedule is processed by a single instance at a time.
+func (r *Repository) ClaimDueSchedules(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]domain.Schedule, error) {
+	var scheduleModels []*models.Schedule
+
+	err := r.runAsScheduler(ctx, func(ctx context.Context, tx bun.Tx) error {
+		due := tx.NewSelect().
+			Model((*models.Schedule)(nil)).
+			Column("id").
+			Where("? = ?", bun.Ident("status"), domain.ScheduleStatusActive).
+			Where("? <= ?", bun.Ident("next_run_at"), now).
+			WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("? IS NULL", bun.Ident("lease_expires_at")).
+					WhereOr("? <= ?", bun.Ident("lease_expires_at"), now)
+			}).
+			Order("next_run_at ASC").
+			Limit(limit).
+			For("UPDATE SKIP LOCKED")
+
+		return tx.NewUpdate().
+			Model((*models.Schedule)(nil)).
+			Set("? = ?", bun.Ident("lease_owner"), owner).
+			Set("? = ?", bun.Ident("lease_expires_at"), now.Add(lease)).
+			Where("? IN (?)", bun.Ident("id"), due).
+			Returning("*").
+			Scan(ctx, &scheduleModels)
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("failed to claim due schedules")
+	}
+
+	return mappers.ConvertScheduleToDomainList(scheduleModels), nil
+}
+
+// AdvanceSchedule records that the occurrence of a schedule leased by the owner was processed, failing with the error
+// when not empty, and moves the schedule to its next run; the schedule is completed when there is none. It returns
+// repository.ErrLeaseLost when the owner no longer holds the lease, or when the schedule was paused, cancelled or
+// already advanced past the occurrence meanwhile.
+func (r *Repository) AdvanceSchedule(ctx context.Context, id uuid.UUID, owner string, occurrence time.Time, nextRunAt *time.Time, lastError string) error {
+	status := domain.ScheduleStatusActive
+	if nextRunAt == nil {
+		status = domain.ScheduleStatusCompleted
+	}
+
+	var rows int64
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		result, err := tx.NewUpdate().
+			Model((*models.Schedule)(nil)).
+			Set("? = ?", bun.Ident("status"), status).
+			Set("? = ?", bun.Ident("next_run_at"), nextRunAt).
+			Set("? = ?", bun.Ident("last_run_at"), occurrence).
+			Set("? = ? + 1", bun.Ident("run_count"), bun.Ident("run_count")).
+			Set("? = ?", bun.Ident("last_error"), lastError).
+			Set("? = ?", bun.Ident("updated_at"), time.Now()).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Where("? = ?", bun.Ident("lease_owner"), owner).
+			Where("? = ?", bun.Ident("status"), domain.ScheduleStatusActive).
+			Where("? = ?", bun.Ident("next_run_at"), occurrence).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		rows, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return errors.New("failed to advance schedule")
+	}
+	if rows == 0 {
+		return repository.ErrLeaseLost
+	}
+	return nil
+}
+
+// ReleaseSchedule frees the lease the owner holds on a schedule, if it still does, so that the schedule can be
+// claimed again before the lease expires
+func (r *Repository) ReleaseSchedule(ctx context.Context, id uuid.UUID, owner string) error {
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		_, err := tx.NewUpdate().
+			Model((*models.Schedule)(nil)).
+			Set("? = NULL", bun.Ident("lease_owner")).
+			Set("? = NULL", bun.Ident("lease_expires_at")).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Where("? = ?", bun.Ident("lease_owner"), owner).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return errors.New("failed to release schedule")
+	}
+	return nil
+}
+
+// HasScheduleRun tells whether the occurrence of the schedule was materialized into a transaction
+func (r *Repository) HasScheduleRun(ctx context.Context, scheduleID uuid.UUID, occurrence time.Time) (bool, error) {
+	var exists bool
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		var err error
+		exists, err = tx.NewSelect().
+			Model((*models.ScheduleRun)(nil)).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("schedule_id"), scheduleID).
+			Where("? = ?", bun.Ident("occurrence"), occurrence).
+			Exists(ctx)
+		return err
+	})
+	if err != nil {
+		return false, errors.New("failed to retrieve schedule run")
+	}
+	return exists, nil
+}
+
+// runAsScheduler runs fn in a database transaction bound to no tenant but flagged as the scheduler's, which the row
+// level security policy of the schedules lets see the schedules of every tenant
+func (r *Repository) runAsScheduler(ctx context.Context, fn func(ctx context.Context, tx bun.Tx) error) error {
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var err error
+		if timeout := r.statementTimeoutFor(ctx); timeout == 0 {
+			_, err = tx.ExecContext(ctx, "SELECT set_config('app.scheduler', 'on', true)")
+		} else {
+			_, err = tx.ExecContext(ctx, "SELECT set_config('app.scheduler', 'on', true), set_config('statement_timeout', ?, true)",
+				strconv.FormatInt(timeout.Milliseconds(), 10))
+		}
+		if err != nil {
+			return err
+		}
+		return fn(ctx, tx)
+	})
+}
@@ -0,0 +1,285 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	ClaimDueSchedulesQuery = `^UPDATE "schedules" AS "sc" SET "lease_owner" = 'instance-1', "lease_expires_at" = '2024-02-01 08:01:00\+00:00' ` +
+		`WHERE \("id" IN \(SELECT "sc"."id" FROM "schedules" AS "sc" WHERE \("status" = 'ACTIVE'\) AND \("next_run_at" <= '2024-02-01 08:00:00\+00:00'\) ` +
+		`AND \(\("lease_expires_at" IS NULL\) OR \("lease_expires_at" <= '2024-02-01 08:00:00\+00:00'\)\) ORDER BY "next_run_at" ASC LIMIT 10 FOR UPDATE SKIP LOCKED\)\) RETURNING \*`
+	AdvanceScheduleQuery = `^UPDATE "schedules" AS "sc" SET "status" = 'ACTIVE', "next_run_at" = '2024-03-01 08:00:00\+00:00', ` +
+		`"last_run_at" = '2024-02-01 08:00:00\+00:00', "run_count" = "run_count" \+ 1, "last_error" = '', "updated_at" = (.+) ` +
+		`WHERE \("tenant_id" = 'default'\) AND \("id" = '(.+)'\) AND \("lease_owner" = 'instance-1'\) AND \("status" = 'ACTIVE'\) ` +
+		`AND \("next_run_at" = '2024-02-01 08:00:00\+00:00'\)`
+	PauseScheduleQuery     = `^UPDATE "schedules" AS "sc" SET "status" = 'PAUSED', "next_run_at" = NULL, (.+) AND \("status" IN \('ACTIVE'\)\) RETURNING \*`
+	GetScheduleStatusQuery = `^SELECT "sc"."status" FROM "schedules" AS "sc" WHERE \("tenant_id" = 'default'\)`
+	SchedulerTxQuery       = `^SELECT set_config\('app.scheduler', 'on', true\)`
+	ScheduleRunExistsQuery = `^SELECT EXISTS \(SELECT (.+) FROM "schedule_runs" AS "sr" WHERE \("tenant_id" = 'default'\) AND \("schedule_id" = '(.+)'\) ` +
+		`AND \("occurrence" = '2024-02-01 08:00:00\+00:00'\)\)`
+	InsertScheduleRunQuery = `^INSERT INTO "schedule_runs" (.+) VALUES \('default', '(.+)', '2024-02-01 08:00:00\+00:00'`
+)
+
+var scheduleSchema = []string{"id", "tenant_id", "user_id", "origin", "transaction_type", "amount", "currency", "description", "metadata", "tags",
+	"cron", "rrule", "start_at", "status", "next_run_at", "last_run_at", "run_count", "last_error", "lease_owner", "lease_expires_at",
+	"created_at", "updated_at"}
+
+func TestRepository_ClaimDueSchedules(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+	scheduleID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		wantCount  int
+		wantErr    bool
+	}{
+		"happy path - leases the due schedules of every tenant": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(SchedulerTxQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(ClaimDueSchedulesQuery).WillReturnRows(sqlmock.NewRows(scheduleSchema).
+					AddRow(scheduleID.String(), "acme", uuid.NewString(), domain.OriginDesktopWeb, domain.TransactionTypeDebit.String(), 999, "USD", "Gym",
+						[]byte(`{}`), "{}", nil, "FREQ=MONTHLY", now, "ACTIVE", now, nil, 0, "", "instance-1", now.Add(time.Minute), now, now))
+				mock.ExpectCommit()
+			},
+			wantCount: 1,
+		},
+		"happy path - nothing is due": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(SchedulerTxQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(ClaimDueSchedulesQuery).WillReturnRows(sqlmock.NewRows(scheduleSchema))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the claim fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(SchedulerTxQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(ClaimDueSchedulesQuery).WillReturnError(errors.New("connection reset"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			schedules, err := repo.ClaimDueSchedules(context.Background(), "instance-1", now, time.Minute, 10)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, schedules, tc.wantCount)
+				if tc.wantCount > 0 {
+					require.Equal(t, scheduleID, schedules[0].ID)
+					require.Equal(t, "acme", schedules[0].TenantID)
+					require.Equal(t, domain.TransactionTypeDebit, schedules[0].TransactionType)
+				}
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_AdvanceSchedule(t *testing.T) {
+	t.Parallel()
+
+	occurrence := time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+	nextRun := occurrence.AddDate(0, 1, 0)
+
+	testData := map[string]struct {
+		setupMocks    func(sqlmock.Sqlmock)
+		wantLeaseLost bool
+		wantErr       bool
+	}{
+		"happy path - moves the schedule to its next run": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvanceScheduleQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the lease was taken over": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(AdvanceScheduleQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantLeaseLost: true,
+			wantErr:       true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			err = repo.AdvanceSchedule(context.Background(), uuid.New(), "instance-1", occurrence, &nextRun, "")
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tc.wantLeaseLost, errors.Is(err, repository.ErrLeaseLost))
+			} else {
+				require.NoError(t, err)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_UpdateScheduleStatus(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	scheduleID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks     func(sqlmock.Sqlmock)
+		wantTransition bool
+		wantNotFound   bool
+		wantErr        bool
+	}{
+		"happy path - pauses the schedule": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(PauseScheduleQuery).WillReturnRows(sqlmock.NewRows(scheduleSchema).
+					AddRow(scheduleID.String(), tenant.Default, uuid.NewString(), domain.OriginDesktopWeb, domain.TransactionTypeCredit.String(), 100, "USD", "",
+						[]byte(`{}`), "{}", "0 9 * * *", nil, now, "PAUSED", nil, nil, 3, "", nil, nil, now, now))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the schedule is in another status": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(PauseScheduleQuery).WillReturnRows(sqlmock.NewRows(scheduleSchema))
+				mock.ExpectQuery(GetScheduleStatusQuery).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("CANCELLED"))
+				mock.ExpectCommit()
+			},
+			wantTransition: true,
+			wantErr:        true,
+		},
+		"failure - the schedule does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(PauseScheduleQuery).WillReturnRows(sqlmock.NewRows(scheduleSchema))
+				mock.ExpectQuery(GetScheduleStatusQuery).WillReturnRows(sqlmock.NewRows([]string{"status"}))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			schedule, err := repo.UpdateScheduleStatus(context.Background(), scheduleID,
+				[]domain.ScheduleStatus{domain.ScheduleStatusActive}, domain.ScheduleStatusPaused, nil)
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tc.wantTransition, errors.Is(err, domain.ErrScheduleTransition))
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, domain.ScheduleStatusPaused, schedule.Status)
+				require.Nil(t, schedule.NextRunAt)
+				require.Equal(t, 3, schedule.RunCount)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_HasScheduleRun(t *testing.T) {
+	t.Parallel()
+
+	occurrence := time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		want       bool
+		wantErr    bool
+	}{
+		"happy path - the occurrence was materialized": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ScheduleRunExistsQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectCommit()
+			},
+			want: true,
+		},
+		"happy path - the occurrence was not materialized": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ScheduleRunExistsQuery).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the query fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ScheduleRunExistsQuery).WillReturnError(errors.New("connection reset"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			materialized, err := repo.HasScheduleRun(context.Background(), uuid.New(), occurrence)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, materialized)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/models"
+	"traive-engineering-challenge/internal/repository/models/mappers"
+)
+
+// GetBalance returns the balance of a user in the currency computed from every credit and debit in it created before
+// the given instant
+func (r *Repository) GetBalance(ctx context.Context, userID uuid.UUID, currency string, before time.Time) (int64, error) {
+	var balance int64
+
+	err := r.readReplicaInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		var err error
+		balance, err = userBalance(ctx, tx, tenantID, userID, currency, before)
+		return err
+	})
+	if err != nil {
+		return 0, errors.New("failed to compute balance")
+	}
+
+	return balance, nil
+}
+
+func userBalance(ctx context.Context, db bun.IDB, tenantID string, userID uuid.UUID, currency string, before time.Time) (int64, error) {
+	var balance int64
+
+	err := db.NewSelect().
+		Model((*models.Transaction)(nil)).
+		ColumnExpr(
+			"COALESCE(SUM(CASE WHEN ? = ? THEN ? WHEN ? = ? THEN -? ELSE 0 END), 0)::bigint",
+			bun.Ident("transaction_type"), domain.TransactionTypeCredit.String(), bun.Ident("amount"),
+			bun.Ident("transaction_type"), domain.TransactionTypeDebit.String(), bun.Ident("amount"),
+		).
+		Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+		Where("? = ?", bun.Ident("user_id"), userID).
+		Where("? = ?", bun.Ident("currency"), currency).
+		Where("? < ?", bun.Ident("created_at"), before).
+		Scan(ctx, &balance)
+	return balance, err
+}
+
+// ListUserTransactionsInPeriod retrieves every transaction of a user in the currency created in [from, to), oldest first
+func (r *Repository) ListUserTransactionsInPeriod(ctx context.Context, userID uuid.UUID, currency string, from, to time.Time) ([]domain.Transaction, error) {
+	var transactionModel []*models.Transaction
+
+	err := r.readReplicaInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(&transactionModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("user_id"), userID).
+			Where("? = ?", bun.Ident("currency"), currency).
+			Where("? >= ?", bun.Ident("created_at"), from).
+			Where("? < ?", bun.Ident("created_at"), to).
+			Order("created_at ASC", "id ASC").
+			Scan(ctx)
+	})
+	if err != nil {
+		return nil, errors.New("failed to list transactions in period")
+	}
+
+	if len(transactionModel) == 0 {
+		return []domain.Transaction{}, nil
+	}
+
+	return mappers.ConvertTransactionToDomainList(transactionModel), nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+const (
+	GetBalanceQuery                   = `^SELECT COALESCE\(SUM\(CASE WHEN "transaction_type" = 'CREDIT TRANSACTION' (.+) FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'default'\) AND \("user_id" = (.+)\) AND \("currency" = 'USD'\) AND \("created_at" < (.+)\)`
+	ListUserTransactionsInPeriodQuery = `^SELECT (.+) FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'default'\) (.+) AND \("currency" = 'USD'\) (.+) ORDER BY "created_at" ASC, "id" ASC`
+)
+
+func TestRepository_GetBalance(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks  func(sqlmock.Sqlmock)
+		wantBalance int64
+		wantErr     bool
+	}{
+		"happy path - returns the balance": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetBalanceQuery).
+					WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(1500))
+				mock.ExpectCommit()
+			},
+			wantBalance: 1500,
+		},
+		"failure - query fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetBalanceQuery).
+					WillReturnError(fmt.Errorf("query failed"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			balance, err := repo.GetBalance(context.Background(), uuid.New(), domain.DefaultCurrency, time.Now())
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantBalance, balance)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_ListUserTransactionsInPeriod(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		wantLen    int
+		wantErr    bool
+	}{
+		"happy path - returns the transactions of the period": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ListUserTransactionsInPeriodQuery).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+			wantLen: 2,
+		},
+		"happy path - returns an empty list": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ListUserTransactionsInPeriodQuery).
+					WillReturnRows(sqlmock.NewRows(transactionSchema))
+				mock.ExpectCommit()
+			},
+			wantLen: 0,
+		},
+		"failure - query fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(ListUserTransactionsInPeriodQuery).
+					WillReturnError(fmt.Errorf("query failed"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+			transactions, err := repo.ListUserTransactionsInPeriod(context.Background(), uuid.New(), domain.DefaultCurrency, from, from.AddDate(0, 1, 0))
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, transactions, tc.wantLen)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/uptrace/bun"
+	"strconv"
+	"time"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// TenantIDColumn is the column holding the tenant of every tenant-scoped row
+const TenantIDColumn = "tenant_id"
+
+// runInTenant runs fn in a database transaction bound to the tenant of the context. Every query of fn must
+// still filter on the tenant; binding the transaction additionally enables the row level security policies,
+// which only expose the rows of the tenant set in app.tenant_id.
+// Once it committed, the reads of the caller stick to the primary for the sticky window of the replica.
+func (r *Repository) runInTenant(ctx context.Context, fn func(ctx context.Context, tx bun.Tx, tenantID string) error) error {
+	if err := r.runInTenantTx(ctx, r.db, nil, fn); err != nil {
+		return err
+	}
+	r.markWrite(ctx)
+	return nil
+}
+
+// readInTenant runs fn like runInTenant, in a read-only transaction that is retried when it fails on a transient
+// error. fn must not have side effects outside of the transaction.
+func (r *Repository) readInTenant(ctx context.Context, fn func(ctx context.Context, tx bun.Tx, tenantID string) error) error {
+	return r.retryRead(ctx, func() error {
+		return r.runInTenantTx(ctx, r.db, &sql.TxOptions{ReadOnly: true}, fn)
+	})
+}
+
+func (r *Repository) runInTenantTx(ctx context.Context, db *bun.DB, opts *sql.TxOptions, fn func(ctx context.Context, tx bun.Tx, tenantID string) error) error {
+	tenantID := tenant.FromContext(ctx)
+
+	return db.RunInTx(ctx, opts, func(ctx context.Context, tx bun.Tx) error {
+		if err := bindTenant(ctx, tx, tenantID, r.statementTimeoutFor(ctx)); err != nil {
+			return err
+		}
+		return fn(ctx, tx, tenantID)
+	})
+}
+
+// statementTimeoutFor is the statement timeout of the queries run on behalf of ctx: the statement timeout of the
+// repository, shortened to the time left before the deadline of ctx. It is 0 when neither is set.
+func (r *Repository) statementTimeoutFor(ctx context.Context) time.Duration {
+	timeout := r.statementTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		left := time.Until(deadline)
+		if left < time.Millisecond {
+			left = time.Millisecond
+		}
+		if timeout == 0 || left < timeout {
+			timeout = left
+		}
+	}
+	return timeout
+}
+
+// bindTenant sets the tenant used by the row level security policies for the rest of the transaction, along with
+// its statement timeout when set
+func bindTenant(ctx context.Context, tx bun.Tx, tenantID string, statementTimeout time.Duration) error {
+	if statementTimeout == 0 {
+		_, err := tx.ExecContext(ctx, "SELECT set_config('app.tenant_id', ?, true)", tenantID)
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "SELECT set_config('app.tenant_id', ?, true), set_config('statement_timeout', ?, true)",
+		tenantID, strconv.FormatInt(statementTimeout.Milliseconds(), 10))
+	return err
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/support"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// TestRepository_TenantIsolation checks that every query is bound to the tenant of the context and filters on it,
+// so that the rows of another tenant can neither be read nor written
+func TestRepository_TenantIsolation(t *testing.T) {
+	t.Parallel()
+
+	const tenantID = "acme"
+	transactionID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		run        func(ctx context.Context, repo *Repository) error
+	}{
+		"listing only returns the transactions of the tenant, whatever the filters": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenantID)
+				mock.ExpectQuery(`^SELECT (.+) FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'acme'\) AND \("tenant_id" = 'default'\) ORDER BY "created_at" ASC, "id" ASC LIMIT 10$`).
+					WillReturnRows(sqlmock.NewRows(transactionSchema))
+				mock.ExpectCommit()
+			},
+			run: func(ctx context.Context, repo *Repository) error {
+				_, err := repo.ListTransactions(ctx, filter.WithTenantID(tenant.Default))
+				return err
+			},
+		},
+		"a transaction of another tenant is not found": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenantID)
+				mock.ExpectQuery(`^SELECT (.+) FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'acme'\) AND \("id" = '` + transactionID.String() + `'\)`).
+					WillReturnRows(sqlmock.NewRows(transactionSchema))
+				mock.ExpectRollback()
+			},
+			run: func(ctx context.Context, repo *Repository) error {
+				_, err := repo.GetTransaction(ctx, transactionID)
+				var notFound repository.NotFoundError
+				if !errors.As(err, &notFound) {
+					return errors.New("expected a not found error")
+				}
+				return nil
+			},
+		},
+		"transactions are created in the tenant": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenantID)
+				mock.ExpectExec(`^INSERT INTO "transactions" \("id", "tenant_id", (.+)\) VALUES \('` + transactionID.String() + `', 'acme', `).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			run: func(ctx context.Context, repo *Repository) error {
+				_, err := repo.CreateTransaction(ctx, *support.ValidDomainTransaction(transactionID, uuid.New(), support.DesktopWeb, "", 100))
+				return err
+			},
+		},
+		"the limits of another tenant do not apply": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenantID)
+				mock.ExpectQuery(`^SELECT (.+) FROM "transaction_limits" AS "l" WHERE \("tenant_id" = 'acme'\) AND \(\("scope" IN (.+)\) OR \("user_id" = (.+)\)\)`).
+					WillReturnRows(sqlmock.NewRows(limitSchema))
+				mock.ExpectCommit()
+			},
+			run: func(ctx context.Context, repo *Repository) error {
+				_, err := repo.ListApplicableLimits(ctx, uuid.New())
+				return err
+			},
+		},
+		"debits of another tenant are not summed": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenantID)
+				mock.ExpectQuery(`^SELECT COALESCE\(SUM\("amount"\), 0\)::bigint FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'acme'\)`).
+					WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+				mock.ExpectCommit()
+			},
+			run: func(ctx context.Context, repo *Repository) error {
+				_, err := repo.SumUserDebitsSince(ctx, uuid.New(), "", domain.DefaultCurrency, time.Now().Add(-time.Hour))
+				return err
+			},
+		},
+		"only the API keys of the tenant are listed": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`^SELECT (.+) FROM "api_keys" AS "k" WHERE \("tenant_id" = 'acme'\)`).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			run: func(ctx context.Context, repo *Repository) error {
+				_, err := repo.ListAPIKeys(ctx)
+				return err
+			},
+		},
+		"API keys are created in the tenant": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`^INSERT INTO "api_keys" \("id", "tenant_id", (.+)\) VALUES \((.+), 'acme', `).
+					WillReturnRows(sqlmock.NewRows([]string{"last_used_at", "revoked_at"}).AddRow(nil, nil))
+			},
+			run: func(ctx context.Context, repo *Repository) error {
+				_, err := repo.CreateAPIKey(ctx, domain.APIKey{ID: uuid.New(), Name: "billing", Prefix: "abcdef012345", KeyHash: "hash"})
+				return err
+			},
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			require.NoError(t, tc.run(tenant.WithID(context.Background(), tenantID), repo))
+
+			expectationMet(t, mock)
+		})
+	}
+}
@@ -2,8 +2,14 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/jackc/pgconn"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 	"time"
 	"traive-engineering-challenge/internal/domain"
 	"traive-engineering-challenge/internal/repository"
@@ -12,14 +18,7 @@ import (
 	"traive-engineering-challenge/internal/repository/models/mappers"
 )
 
-const TransactionModelTableExpr = "transactions"
-
-type contextKey string
-
-const (
-	PageKey     contextKey = "page"
-	PageSizeKey contextKey = "pageSize"
-)
+const transactionNotFoundMessage = "transaction not found"
 
 func (r *Repository) CreateTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error) {
 
@@ -31,13 +30,12 @@ func (r *Repository) CreateTransaction(ctx context.Context, transaction domain.T
 	now := time.Now()
 	transactionModel.CreatedAt = now
 
-	_, err = r.db.NewInsert().Model(transactionModel).Exec(ctx)
+	err = r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		transactionModel.TenantID = tenantID
+		return insertTransaction(ctx, tx, transactionModel, transaction.ScheduleRun)
+	})
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return nil, repository.NewUniqueIndexError(pgErr.Detail)
-		}
-		return nil, err
+		return nil, translateInsertError(err)
 	}
 
 	transactionRecordCreated, err := mappers.ConvertTransactionModelToDomain(*transactionModel)
@@ -48,35 +46,94 @@ func (r *Repository) CreateTransaction(ctx context.Context, transaction domain.T
 	return transactionRecordCreated, err
 }
 
-// ListTransactions retrieves a list of transactions from the database
-// It accepts a context and a list of filter options
-// It returns a list of transactions and an error
-func (r *Repository) ListTransactions(ctx context.Context, filters ...filter.Options) ([]domain.Transaction, error) {
-	var transactionModel []*models.Transaction
-
-	transactionsFilter := &filter.TransactionFilter{
-		Query: r.db.NewSelect().
-			Model(&transactionModel).
-			TableExpr("transactions AS t"), // Use TableExpr to introduce an alias and avoid conflicts with transaction keyword
+// insertTransaction inserts the transaction, preceded by the run of the occurrence of a schedule it materializes if
+// any, so that a second transaction of the occurrence fails on the primary key of the runs
+func insertTransaction(ctx context.Context, tx bun.Tx, transactionModel *models.Transaction, run *domain.ScheduleRun) error {
+	if run != nil {
+		_, err := tx.NewInsert().Model(&models.ScheduleRun{
+			TenantID:      transactionModel.TenantID,
+			ScheduleID:    run.ScheduleID,
+			Occurrence:    run.Occurrence,
+			TransactionID: transactionModel.ID,
+			CreatedAt:     transactionModel.CreatedAt,
+		}).Exec(ctx)
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, opt := range filters {
-		opt(transactionsFilter)
-	}
+	_, err := tx.NewInsert().Model(transactionModel).Exec(ctx)
+	return err
+}
 
-	// Retrieve the page and pageSize from context
-	page, ok := ctx.Value(PageKey).(int)
-	if !ok {
-		page = 1
+// translateInsertError converts unique violations into a repository.UniqueIndexError, or repository.ErrScheduleRunExists
+// for the runs of the schedules
+func translateInsertError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if pgErr.ConstraintName == scheduleRunsPrimaryKey {
+			return repository.ErrScheduleRunExists
+		}
+		return repository.NewUniqueIndexError(pgErr.Detail)
 	}
-	pageSize, ok := ctx.Value(PageSizeKey).(int)
-	if !ok {
-		pageSize = 10
+	return err
+}
+
+// GetTransaction retrieves a transaction by ID. It returns a repository.NotFoundError when it does not exist.
+func (r *Repository) GetTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error) {
+	transactionModel := new(models.Transaction)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(transactionModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(transactionNotFoundMessage)
+		}
+		return nil, errors.New("failed to retrieve transaction")
 	}
 
+	return mappers.ConvertTransactionModelToDomain(*transactionModel)
+}
+
+// ListTransactions retrieves a page of the transactions of the tenant from the database, in the order of the sort of
+// the filters, oldest first by default
+// It accepts a context carrying the page and a list of filter options
+// It returns a list of transactions and an error
+func (r *Repository) ListTransactions(ctx context.Context, filters ...filter.Options) ([]domain.Transaction, error) {
+	var transactionModel []*models.Transaction
+
+	page, pageSize := repository.PageFromContext(ctx)
 	offset := (page - 1) * pageSize
+	transactionsFilter := filter.New(filters...)
+
+	err := r.readReplicaInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		// bun aliases the table as "transaction", quoted so that it does not conflict with the keyword
+		query := tx.NewSelect().
+			Model(&transactionModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID)
+		if search := transactionsFilter.Search; search != nil {
+			query = query.ColumnExpr("?TableColumns").
+				ColumnExpr("ts_rank(?, "+searchQuery+") AS search_rank", bun.Ident("search_vector"), *search).
+				ColumnExpr("ts_headline('simple', "+searchDocument+", "+searchQuery+", ?) AS search_snippet", *search, headlineOptions)
+			if transactionsFilter.Sort == nil {
+				query = query.OrderExpr("search_rank DESC")
+			}
+		}
+		query, err := applyTransactionFilter(query, transactionsFilter)
+		if err != nil {
+			return err
+		}
 
-	err := transactionsFilter.Query.Offset(offset).Limit(pageSize).Scan(ctx)
+		return orderTransactions(query, transactionsFilter.Sort).
+			Offset(offset).
+			Limit(pageSize).
+			Scan(ctx)
+	})
 	if err != nil {
 		return nil, errors.New("failed to list transactions")
 	}
@@ -89,3 +146,241 @@ func (r *Repository) ListTransactions(ctx context.Context, filters ...filter.Opt
 
 	return result, nil
 }
+
+const (
+	// searchQuery is the text search query of a search, whose words must all be found
+	searchQuery = "plainto_tsquery('simple', ?)"
+	// searchDocument is the text the search_vector column of migration 012 indexes, from which the snippets are taken
+	searchDocument = `concat_ws(' ', NULLIF(description, ''), NULLIF(metadata ->> 'merchant', ''), NULLIF(metadata ->> 'reference', ''))`
+	// headlineOptions highlights the matching words of a snippet of at most 20 words
+	headlineOptions = "StartSel=<b>, StopSel=</b>, MaxWords=20, MinWords=10"
+)
+
+// categorySubtreeQuery selects the IDs of a category and of every category below it in the tree
+const categorySubtreeQuery = `WITH RECURSIVE subtree AS (` +
+	`SELECT id FROM categories WHERE id = ? ` +
+	`UNION SELECT child.id FROM categories AS child JOIN subtree ON child.parent_id = subtree.id` +
+	`) SELECT id FROM subtree`
+
+// applyTransactionFilter restricts the query to the transactions matching every criterion of the filter
+func applyTransactionFilter(query *bun.SelectQuery, f filter.TransactionFilter) (*bun.SelectQuery, error) {
+	if f.TenantID != nil {
+		query = query.Where("? = ?", bun.Ident(filter.TenantID), *f.TenantID)
+	}
+	if f.Origin != nil {
+		query = query.Where("? = ?", bun.Ident(filter.Origin), *f.Origin)
+	}
+	if f.TransactionType != nil {
+		query = query.Where("? = ?", bun.Ident(filter.TransactionType), *f.TransactionType)
+	}
+	if f.UserID != nil {
+		query = query.Where("? = ?", bun.Ident(filter.UserID), *f.UserID)
+	}
+	if len(f.Metadata) > 0 {
+		// Keys are encoded in order, so that the same filter always compiles to the same query
+		metadata, err := json.Marshal(f.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("? @> ?::jsonb", bun.Ident("metadata"), string(metadata))
+	}
+	if len(f.Tags) > 0 {
+		query = query.Where("? @> ?", bun.Ident("tags"), pgdialect.Array(f.Tags))
+	}
+	if f.Category != nil {
+		query = query.Where("? IN ("+categorySubtreeQuery+")", bun.Ident("category_id"), *f.Category)
+	}
+	if f.Search != nil {
+		query = query.Where("? @@ "+searchQuery, bun.Ident("search_vector"), *f.Search)
+	}
+	for _, expression := range f.Expressions {
+		condition, args, err := compileExpression(expression)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition, args...)
+	}
+	return query, nil
+}
+
+// orderTransactions orders the query like filter.Sort.Less, by creation time then ID without a sort
+func orderTransactions(query *bun.SelectQuery, sort *filter.Sort) *bun.SelectQuery {
+	switch {
+	case sort == nil || (sort.Field == filter.CreatedAt && !sort.Descending):
+		return query.Order("created_at ASC", "id ASC")
+	case sort.Field == filter.CreatedAt:
+		return query.Order("created_at DESC", "id DESC")
+	case sort.Descending:
+		return query.OrderExpr("? DESC", bun.Ident(sort.Field)).Order("created_at ASC", "id ASC")
+	default:
+		return query.OrderExpr("? ASC", bun.Ident(sort.Field)).Order("created_at ASC", "id ASC")
+	}
+}
+
+// sqlOperators maps the operators of the filter expressions to SQL
+var sqlOperators = map[filter.Operator]string{
+	filter.Equal:          "=",
+	filter.NotEqual:       "<>",
+	filter.Greater:        ">",
+	filter.GreaterOrEqual: ">=",
+	filter.Less:           "<",
+	filter.LessOrEqual:    "<=",
+	filter.In:             "IN",
+	filter.NotIn:          "NOT IN",
+}
+
+// compileExpression compiles a filter expression into a condition whose fields and values are all placeholders,
+// with their arguments. The fields were checked against the allow-list of the filter package when it was parsed.
+func compileExpression(expression filter.Expression) (string, []interface{}, error) {
+	switch e := expression.(type) {
+	case filter.Logical:
+		left, leftArgs, err := compileExpression(e.Left)
+		if err != nil {
+			return "", nil, err
+		}
+		right, rightArgs, err := compileExpression(e.Right)
+		if err != nil {
+			return "", nil, err
+		}
+		operator := "AND"
+		if e.Operator == filter.Or {
+			operator = "OR"
+		}
+		return fmt.Sprintf("(%s %s %s)", left, operator, right), append(leftArgs, rightArgs...), nil
+	case filter.Not:
+		operand, args, err := compileExpression(e.Operand)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("NOT (%s)", operand), args, nil
+	case filter.Comparison:
+		operator, ok := sqlOperators[e.Operator]
+		if !ok || len(e.Values) == 0 {
+			return "", nil, fmt.Errorf("invalid comparison %s", e)
+		}
+		if e.Operator == filter.In || e.Operator == filter.NotIn {
+			return fmt.Sprintf("? %s (?)", operator), []interface{}{bun.Ident(e.Field), bun.In(e.Values)}, nil
+		}
+		return fmt.Sprintf("? %s ?", operator), []interface{}{bun.Ident(e.Field), e.Values[0]}, nil
+	default:
+		return "", nil, fmt.Errorf("unexpected filter expression %T", expression)
+	}
+}
+
+// CountUserTransactionsSince counts the transactions of a user created at or after the given instant. The risk rules
+// screen every transaction with it, so it reads from the primary: a lagging replica would miss the latest transactions
+// and let a burst through the velocity rule. The count is not serialised with the insert of the transaction screened.
+func (r *Repository) CountUserTransactionsSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	var count int
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		var err error
+		count, err = tx.NewSelect().
+			Model((*models.Transaction)(nil)).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("user_id"), userID).
+			Where("? >= ?", bun.Ident("created_at"), since).
+			Count(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, errors.New("failed to count transactions")
+	}
+
+	return count, nil
+}
+
+// GetLatestUserTransaction retrieves the most recent transaction of a user. The risk rules screen every transaction
+// with it, so it reads from the primary like CountUserTransactionsSince.
+// It returns a repository.NotFoundError when the user has no transactions.
+func (r *Repository) GetLatestUserTransaction(ctx context.Context, userID uuid.UUID) (*domain.Transaction, error) {
+	transactionModel := new(models.Transaction)
+
+	err := r.readInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewSelect().
+			Model(transactionModel).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("user_id"), userID).
+			Order("created_at DESC").
+			Limit(1).
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError("no transactions found for user")
+		}
+		return nil, errors.New("failed to retrieve latest transaction")
+	}
+
+	return mappers.ConvertTransactionModelToDomain(*transactionModel)
+}
+
+// SetTransactionCategory sets or clears the category of a transaction, recording who changed it and when.
+// It returns a repository.NotFoundError when the transaction does not exist.
+func (r *Repository) SetTransactionCategory(ctx context.Context, id uuid.UUID, categoryID *uuid.UUID, categorizedBy string) (*domain.Transaction, error) {
+	transactionModel := new(models.Transaction)
+
+	err := r.runInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		return tx.NewUpdate().
+			Model(transactionModel).
+			Set("? = ?", bun.Ident("category_id"), categoryID).
+			Set("? = ?", bun.Ident("categorized_by"), categorizedBy).
+			Set("? = ?", bun.Ident("categorized_at"), time.Now()).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID).
+			Where("? = ?", bun.Ident("id"), id).
+			Returning("?TableColumns").
+			Scan(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.NewNotFoundError(transactionNotFoundMessage)
+		}
+		return nil, errors.New("failed to categorize transaction")
+	}
+
+	return mappers.ConvertTransactionModelToDomain(*transactionModel)
+}
+
+// statsColumns are the columns of the dimensions of the transaction stats
+var statsColumns = map[string]string{
+	domain.StatsByCategory:        "category_id",
+	domain.StatsByOrigin:          "origin",
+	domain.StatsByTransactionType: "transaction_type",
+	domain.StatsByStatus:          "status",
+}
+
+// GetTransactionStats counts and sums the transactions of the tenant matching the filters by the value of the
+// dimension, ordered by that value. The sort and the page of the filters are ignored.
+func (r *Repository) GetTransactionStats(ctx context.Context, dimension string, filters ...filter.Options) ([]domain.TransactionStats, error) {
+	column, ok := statsColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unknown stats dimension %q", dimension)
+	}
+
+	var rows []models.TransactionStats
+	err := r.readReplicaInTenant(ctx, func(ctx context.Context, tx bun.Tx, tenantID string) error {
+		query := tx.NewSelect().
+			Model((*models.Transaction)(nil)).
+			ColumnExpr("COALESCE(?::text, '') AS key", bun.Ident(column)).
+			ColumnExpr("count(*) AS count").
+			ColumnExpr("COALESCE(sum(amount) FILTER (WHERE transaction_type = ?), 0) AS credits", domain.TransactionTypeCredit.String()).
+			ColumnExpr("COALESCE(sum(amount) FILTER (WHERE transaction_type = ?), 0) AS debits", domain.TransactionTypeDebit.String()).
+			Where("? = ?", bun.Ident(TenantIDColumn), tenantID)
+		query, err := applyTransactionFilter(query, filter.New(filters...))
+		if err != nil {
+			return err
+		}
+
+		// keys are ordered byte by byte, like the strings of Go
+		return query.GroupExpr("1").OrderExpr(`COALESCE(?::text, '') COLLATE "C" ASC`, bun.Ident(column)).Scan(ctx, &rows)
+	})
+	if err != nil {
+		return nil, errors.New("failed to compute transaction stats")
+	}
+
+	stats := make([]domain.TransactionStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, domain.TransactionStats(row))
+	}
+	return stats, nil
+}
@@ -2,14 +2,20 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/require"
+	"regexp"
 	"testing"
 	"time"
 	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/filter"
 	"traive-engineering-challenge/internal/support"
+	"traive-engineering-challenge/internal/tenant"
 )
 
 var (
@@ -27,8 +33,9 @@ var (
 )
 
 const (
-	InsertTransactionQuery = `^INSERT INTO "transactions"`
-	GetTransactionsQuery   = `^SELECT (.+) FROM "transactions"`
+	InsertTransactionQuery     = `^INSERT INTO "transactions"`
+	GetTransactionsQuery       = `^SELECT (.+) FROM "transactions"`
+	CountUserTransactionsQuery = `^SELECT count\(\*\) FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'default'\) AND \("user_id" = (.+)\) AND \("created_at" >= (.+)\)`
 )
 
 type queryMock func(sqlmock.Sqlmock)
@@ -45,28 +52,54 @@ func TestRepository_CreateTransaction(t *testing.T) {
 		Amount:          1000,
 		CreatedAt:       time.Now(),
 	}
+	scheduled := transaction
+	scheduled.ScheduleRun = &domain.ScheduleRun{ScheduleID: uuid.New(), Occurrence: time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)}
 
 	testData := map[string]struct {
 		setupMocks       func(sqlmock.Sqlmock)
 		wantErr          bool
+		wantErrIs        error
 		inputTransaction domain.Transaction
 	}{
 		"happy path - creates new transaction": {
 			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
 				mock.ExpectExec(InsertTransactionQuery).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 			inputTransaction: transaction,
 			wantErr:          false,
 		},
 		"failure - insert provider fails": {
 			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
 				mock.ExpectExec(InsertTransactionQuery).
 					WillReturnError(fmt.Errorf("insert operation failed"))
+				mock.ExpectRollback()
 			},
 			inputTransaction: transaction,
 			wantErr:          true,
 		},
+		"happy path - stores the run of the occurrence of a schedule with its transaction": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(InsertScheduleRunQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(InsertTransactionQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			inputTransaction: scheduled,
+		},
+		"failure - the occurrence of the schedule was already materialized": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectExec(InsertScheduleRunQuery).WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: scheduleRunsPrimaryKey})
+				mock.ExpectRollback()
+			},
+			inputTransaction: scheduled,
+			wantErr:          true,
+			wantErrIs:        repository.ErrScheduleRunExists,
+		},
 	}
 
 	for name, tc := range testData {
@@ -82,6 +115,9 @@ func TestRepository_CreateTransaction(t *testing.T) {
 			_, err = repo.CreateTransaction(context.Background(), tc.inputTransaction)
 			if tc.wantErr {
 				require.Error(t, err)
+				if tc.wantErrIs != nil {
+					require.ErrorIs(t, err, tc.wantErrIs)
+				}
 			} else {
 				require.NoError(t, err)
 			}
@@ -96,19 +132,86 @@ func TestRepository_ListTransactions(t *testing.T) {
 
 	testData := map[string]struct {
 		setupMocks func(sqlmock.Sqlmock)
+		filter     string
+		sort       string
+		options    []filter.Options
 		wantErr    bool
 	}{
 		"happy path - returns list of transactions": {
 			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
 				mock.ExpectQuery(GetTransactionsQuery).
 					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		"happy path - compiles the filter expression into the query": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(regexp.QuoteMeta(`WHERE ("tenant_id" = 'default') AND ` +
+					`((("amount" > 1000 AND "origin" IN ('mobile-ios', 'mobile-android')) ` +
+					`OR (NOT ("status" = 'COMPLETED') AND "created_at" >= '2026-01-01 00:00:00+00:00'))) ORDER BY`)).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+			filter:  `amount > 1000 and origin in ("mobile-ios", "mobile-android") or not status = "COMPLETED" and created_at >= "2026-01-01"`,
+			wantErr: false,
+		},
+		"happy path - filters the metadata and the tags by containment": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(regexp.QuoteMeta(`WHERE ("tenant_id" = 'default') AND ` +
+					`("metadata" @> '{"campaign":"summer","order_id":"A-1001"}'::jsonb) AND ("tags" @> '{"vip","web"}') ORDER BY`)).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+			options: []filter.Options{
+				filter.WithMetadata("order_id", "A-1001"),
+				filter.WithMetadata("campaign", "summer"),
+				filter.WithTag("vip"),
+				filter.WithTag("web"),
+			},
+			wantErr: false,
+		},
+		"happy path - filters a category with its subcategories": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(regexp.QuoteMeta(`WHERE ("tenant_id" = 'default') AND ("category_id" IN (WITH RECURSIVE subtree AS (` +
+					`SELECT id FROM categories WHERE id = '` + transactionIDOne + `' ` +
+					`UNION SELECT child.id FROM categories AS child JOIN subtree ON child.parent_id = subtree.id) SELECT id FROM subtree)) ORDER BY`)).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+			options: []filter.Options{filter.WithCategory(uuid.MustParse(transactionIDOne))},
+			wantErr: false,
+		},
+		"happy path - sorts by amount, then oldest first": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY "amount" DESC, "created_at" ASC, "id" ASC LIMIT 10`)).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
 			},
+			sort:    "-amount",
+			wantErr: false,
+		},
+		"happy path - sorts newest first": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY "created_at" DESC, "id" DESC LIMIT 10`)).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+			sort:    "-created_at",
 			wantErr: false,
 		},
 		"failure - query fails": {
 			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
 				mock.ExpectQuery(GetTransactionsQuery).
 					WillReturnError(fmt.Errorf("query failed"))
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
@@ -124,7 +227,19 @@ func TestRepository_ListTransactions(t *testing.T) {
 
 			tc.setupMocks(mock)
 
-			_, err = repo.ListTransactions(context.Background())
+			filters := tc.options
+			if tc.filter != "" {
+				expression, err := filter.Parse(tc.filter)
+				require.NoError(t, err)
+				filters = append(filters, filter.WithExpression(expression))
+			}
+			if tc.sort != "" {
+				sort, err := filter.ParseSort(tc.sort)
+				require.NoError(t, err)
+				filters = append(filters, filter.WithSort(sort))
+			}
+
+			_, err = repo.ListTransactions(context.Background(), filters...)
 			if tc.wantErr {
 				require.Error(t, err)
 			} else {
@@ -136,6 +251,124 @@ func TestRepository_ListTransactions(t *testing.T) {
 	}
 }
 
+func TestRepository_ListTransactions_Search(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+
+	repo, err := NewRepository(db)
+	require.NoError(t, err)
+
+	expectTenantTx(mock, tenant.Default)
+	mock.ExpectQuery(regexp.QuoteMeta(`"transaction"."categorized_at", ` +
+		`ts_rank("search_vector", plainto_tsquery('simple', 'corner shop')) AS search_rank, ` +
+		`ts_headline('simple', concat_ws(' ', NULLIF(description, ''), NULLIF(metadata ->> 'merchant', ''), NULLIF(metadata ->> 'reference', '')), ` +
+		`plainto_tsquery('simple', 'corner shop'), 'StartSel=<b>, StopSel=</b>, MaxWords=20, MinWords=10') AS search_snippet ` +
+		`FROM "transactions" AS "transaction" WHERE ("tenant_id" = 'default') AND ("search_vector" @@ plainto_tsquery('simple', 'corner shop')) ` +
+		`ORDER BY search_rank DESC, "created_at" ASC, "id" ASC`)).
+		WillReturnRows(sqlmock.NewRows(append(transactionSchema, "search_rank", "search_snippet")).
+			AddRow(transactionIDOne, uuid.NewString(), support.DesktopWeb, domain.TransactionTypeCredit, 1000, time.Now(), 0.6, "<b>Corner</b> <b>shop</b>"))
+	mock.ExpectCommit()
+
+	transactions, err := repo.ListTransactions(context.Background(), filter.WithSearch("corner shop"))
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.Equal(t, &domain.SearchMatch{Rank: 0.6, Snippet: "<b>Corner</b> <b>shop</b>"}, transactions[0].Match)
+
+	expectationMet(t, mock)
+}
+
+func TestRepository_SetTransactionCategory(t *testing.T) {
+	t.Parallel()
+
+	categoryID := uuid.New()
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - records the category and who set it": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "transactions" AS "transaction" SET "category_id" = '` + categoryID.String() + `', "categorized_by" = 'analyst', "categorized_at" = `)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "origin", "transaction_type", "amount", "category_id", "categorized_by", "categorized_at", "created_at"}).
+						AddRow(transactionIDOne, uuid.NewString(), support.DesktopWeb, domain.TransactionTypeCredit, 1000, categoryID.String(), "analyst", time.Now(), time.Now()))
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the transaction does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(`^UPDATE "transactions"`).WillReturnRows(sqlmock.NewRows(transactionSchema))
+				mock.ExpectRollback()
+			},
+			wantNotFound: true,
+			wantErr:      true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			transaction, err := repo.SetTransactionCategory(context.Background(), uuid.MustParse(transactionIDOne), &categoryID, "analyst")
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, &categoryID, transaction.CategoryID)
+				require.Equal(t, "analyst", transaction.CategorizedBy)
+				require.NotNil(t, transaction.CategorizedAt)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_GetTransactionStats(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+
+	repo, err := NewRepository(db)
+	require.NoError(t, err)
+
+	expectTenantTx(mock, tenant.Default)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE("origin"::text, '') AS key, count(*) AS count, ` +
+		`COALESCE(sum(amount) FILTER (WHERE transaction_type = 'CREDIT TRANSACTION'), 0) AS credits, ` +
+		`COALESCE(sum(amount) FILTER (WHERE transaction_type = 'DEBIT TRANSACTION'), 0) AS debits ` +
+		`FROM "transactions" AS "transaction" WHERE ("tenant_id" = 'default') AND ("transaction_type" = 'DEBIT TRANSACTION') ` +
+		`GROUP BY 1 ORDER BY COALESCE("origin"::text, '') COLLATE "C" ASC`)).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count", "credits", "debits"}).
+			AddRow(support.DesktopWeb, 2, 0, 1500).
+			AddRow(support.MobileIOS, 1, 0, 200))
+	mock.ExpectCommit()
+
+	stats, err := repo.GetTransactionStats(context.Background(), domain.StatsByOrigin, filter.WithTransactionType(domain.TransactionTypeDebit.String()))
+	require.NoError(t, err)
+	require.Equal(t, []domain.TransactionStats{
+		{Key: support.DesktopWeb, Count: 2, Debits: 1500},
+		{Key: support.MobileIOS, Count: 1, Debits: 200},
+	}, stats)
+
+	_, err = repo.GetTransactionStats(context.Background(), "amount")
+	require.Error(t, err)
+
+	expectationMet(t, mock)
+}
+
 // Helper functions
 
 func expectationMet(t *testing.T, mock sqlmock.Sqlmock) {
@@ -143,3 +376,184 @@ func expectationMet(t *testing.T, mock sqlmock.Sqlmock) {
 		t.Fatalf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+// expectTenantTx expects a database transaction to begin and be bound to the tenant
+func expectTenantTx(mock sqlmock.Sqlmock, tenantID string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`^SELECT set_config\('app.tenant_id', '` + tenantID + `', true\)`).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestRepository_CountUserTransactionsSince(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks func(sqlmock.Sqlmock)
+		wantCount  int
+		wantErr    bool
+	}{
+		"happy path - returns the number of transactions": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(`^SELECT count\(\*\) FROM "transactions" AS "transaction" WHERE \("tenant_id" = 'default'\) AND \("user_id" = (.+)\) AND \("created_at" >= (.+)\)`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+			wantCount: 3,
+		},
+		"failure - query fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(`^SELECT count\(\*\)`).
+					WillReturnError(fmt.Errorf("query failed"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			count, err := repo.CountUserTransactionsSince(context.Background(), uuid.New(), time.Now().Add(-time.Minute))
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantCount, count)
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_GetLatestUserTransaction(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - returns the latest transaction": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetTransactionsQuery + ` AS "transaction" WHERE \("tenant_id" = 'default'\) AND (.+) ORDER BY "created_at" DESC LIMIT 1`).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the user has no transactions": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetTransactionsQuery).
+					WillReturnRows(sqlmock.NewRows(transactionSchema))
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		"failure - query fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetTransactionsQuery).
+					WillReturnError(fmt.Errorf("query failed"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			transaction, err := repo.GetLatestUserTransaction(context.Background(), uuid.New())
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, transactionIDOne, transaction.ID.String())
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
+
+func TestRepository_GetTransaction(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		setupMocks   func(sqlmock.Sqlmock)
+		wantNotFound bool
+		wantErr      bool
+	}{
+		"happy path - returns the transaction": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetTransactionsQuery + ` AS "transaction" WHERE \("tenant_id" = 'default'\) AND \("id" = (.+)\)`).
+					WillReturnRows(buildPopulatedTransactions())
+				mock.ExpectCommit()
+			},
+		},
+		"failure - the transaction does not exist": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetTransactionsQuery).
+					WillReturnRows(sqlmock.NewRows(transactionSchema))
+				mock.ExpectRollback()
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		"failure - query fails": {
+			setupMocks: func(mock sqlmock.Sqlmock) {
+				expectTenantTx(mock, tenant.Default)
+				mock.ExpectQuery(GetTransactionsQuery).
+					WillReturnError(fmt.Errorf("query failed"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			require.NoError(t, err)
+
+			repo, err := NewRepository(db)
+			require.NoError(t, err)
+
+			tc.setupMocks(mock)
+
+			transaction, err := repo.GetTransaction(context.Background(), uuid.MustParse(transactionIDOne))
+			if tc.wantErr {
+				require.Error(t, err)
+				var notFound repository.NotFoundError
+				require.Equal(t, tc.wantNotFound, errors.As(err, &notFound))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, transactionIDOne, transaction.ID.String())
+			}
+
+			expectationMet(t, mock)
+		})
+	}
+}
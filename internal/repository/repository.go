@@ -4,11 +4,82 @@ package repository
 
 import (
 	"context"
+	"github.com/google/uuid"
+	"time"
 	"traive-engineering-challenge/internal/domain"
 	"traive-engineering-challenge/internal/repository/filter"
 )
 
 type Repository interface {
 	CreateTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error)
+	GetTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error)
 	ListTransactions(ctx context.Context, filters ...filter.Options) ([]domain.Transaction, error)
+	CountUserTransactionsSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	GetLatestUserTransaction(ctx context.Context, userID uuid.UUID) (*domain.Transaction, error)
+	GetBalance(ctx context.Context, userID uuid.UUID, currency string, before time.Time) (int64, error)
+	ListUserTransactionsInPeriod(ctx context.Context, userID uuid.UUID, currency string, from, to time.Time) ([]domain.Transaction, error)
+	CreateTransactionWithinLimits(ctx context.Context, transaction domain.Transaction, limits []domain.Limit) (*domain.Transaction, error)
+	SumUserDebitsSince(ctx context.Context, userID uuid.UUID, origin, currency string, since time.Time) (int64, error)
+	SetTransactionCategory(ctx context.Context, id uuid.UUID, categoryID *uuid.UUID, categorizedBy string) (*domain.Transaction, error)
+	GetTransactionStats(ctx context.Context, dimension string, filters ...filter.Options) ([]domain.TransactionStats, error)
+
+	CreateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error)
+	GetLimit(ctx context.Context, id uuid.UUID) (*domain.Limit, error)
+	ListLimits(ctx context.Context) ([]domain.Limit, error)
+	UpdateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error)
+	DeleteLimit(ctx context.Context, id uuid.UUID) error
+	ListApplicableLimits(ctx context.Context, userID uuid.UUID) ([]domain.Limit, error)
+
+	CreateCategory(ctx context.Context, category domain.Category) (*domain.Category, error)
+	GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	ListCategories(ctx context.Context) ([]domain.Category, error)
+	UpdateCategory(ctx context.Context, category domain.Category) (*domain.Category, error)
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+
+	CreateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error)
+	GetCategoryRule(ctx context.Context, id uuid.UUID) (*domain.CategoryRule, error)
+	ListCategoryRules(ctx context.Context) ([]domain.CategoryRule, error)
+	UpdateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error)
+	DeleteCategoryRule(ctx context.Context, id uuid.UUID) error
+
+	CreateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error)
+	GetSavedSearchByName(ctx context.Context, name string) (*domain.SavedSearch, error)
+	ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id uuid.UUID) error
+
+	CreateSchedule(ctx context.Context, schedule domain.Schedule) (*domain.Schedule, error)
+	GetSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
+	ListSchedules(ctx context.Context, userID *uuid.UUID) ([]domain.Schedule, error)
+	UpdateScheduleStatus(ctx context.Context, id uuid.UUID, from []domain.ScheduleStatus, to domain.ScheduleStatus, nextRunAt *time.Time) (*domain.Schedule, error)
+	ClaimDueSchedules(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]domain.Schedule, error)
+	AdvanceSchedule(ctx context.Context, id uuid.UUID, owner string, occurrence time.Time, nextRunAt *time.Time, lastError string) error
+	ReleaseSchedule(ctx context.Context, id uuid.UUID, owner string) error
+	HasScheduleRun(ctx context.Context, scheduleID uuid.UUID, occurrence time.Time) (bool, error)
+
+	CreateHold(ctx context.Context, hold domain.Hold) (*domain.Hold, error)
+	GetHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error)
+	ListHolds(ctx context.Context, userID *uuid.UUID) ([]domain.Hold, error)
+	CaptureHold(ctx context.Context, id uuid.UUID, transaction domain.Transaction, limits []domain.Limit) (*domain.Hold, *domain.Transaction, error)
+	ReleaseHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error)
+	GetHeldAmount(ctx context.Context, userID uuid.UUID, currency string) (int64, error)
+	ExpireHolds(ctx context.Context, now time.Time, limit int) (int, error)
+
+	CreateRateSnapshot(ctx context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error)
+	ListRateSnapshots(ctx context.Context) ([]domain.RateSnapshot, error)
+	GetEffectiveRateSnapshot(ctx context.Context, at time.Time) (*domain.RateSnapshot, error)
+	CreateFXQuote(ctx context.Context, quote domain.FXQuote) (*domain.FXQuote, error)
+	GetFXQuote(ctx context.Context, id uuid.UUID) (*domain.FXQuote, error)
+	CreateFXConversion(ctx context.Context, conversion domain.FXConversion, debit, credit domain.Transaction, limits []domain.Limit) (*domain.FXConversion, error)
+	GetFXConversion(ctx context.Context, id uuid.UUID) (*domain.FXConversion, error)
+	ListFXConversions(ctx context.Context, userID *uuid.UUID) ([]domain.FXConversion, error)
+
+	CreateAPIKey(ctx context.Context, key domain.APIKey) (*domain.APIKey, error)
+	GetAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	RotateAPIKey(ctx context.Context, id uuid.UUID, replacement domain.APIKey) (*domain.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error
 }
@@ -0,0 +1,1621 @@
+// Package repositorytest checks that an implementation of repository.Repository behaves like the others: the same
+// filters, pagination, ordering and errors. Every implementation runs it from its own tests.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// Run runs the conformance suite against the repository. Every test works in a tenant of its own, so that the
+// repository can be shared between the tests and keep the data of previous runs.
+func Run(t *testing.T, repo repository.Repository) {
+	t.Run("transactions", func(t *testing.T) { testTransactions(t, repo) })
+	t.Run("list transactions", func(t *testing.T) { testListTransactions(t, repo) })
+	t.Run("statements", func(t *testing.T) { testStatements(t, repo) })
+	t.Run("limits", func(t *testing.T) { testLimits(t, repo) })
+	t.Run("transactions within limits", func(t *testing.T) { testTransactionsWithinLimits(t, repo) })
+	t.Run("api keys", func(t *testing.T) { testAPIKeys(t, repo) })
+	t.Run("saved searches", func(t *testing.T) { testSavedSearches(t, repo) })
+	t.Run("categories", func(t *testing.T) { testCategories(t, repo) })
+	t.Run("categorized transactions", func(t *testing.T) { testCategorizedTransactions(t, repo) })
+	t.Run("search transactions", func(t *testing.T) { testSearchTransactions(t, repo) })
+	t.Run("schedules", func(t *testing.T) { testSchedules(t, repo) })
+	t.Run("holds", func(t *testing.T) { testHolds(t, repo) })
+	t.Run("currencies", func(t *testing.T) { testCurrencies(t, repo) })
+	t.Run("foreign exchange", func(t *testing.T) { testFX(t, repo) })
+}
+
+// newTenant returns a context of a tenant no other test uses
+func newTenant() context.Context {
+	return tenant.WithID(context.Background(), "conformance-"+uuid.NewString()[:8])
+}
+
+func newTransaction(userID uuid.UUID, origin string, transactionType domain.TransactionType, amount int64) domain.Transaction {
+	return domain.Transaction{
+		ID:              uuid.New(),
+		UserID:          userID,
+		Origin:          origin,
+		TransactionType: transactionType,
+		Amount:          amount,
+		Status:          domain.TransactionStatusCompleted,
+	}
+}
+
+func createTransactions(t *testing.T, ctx context.Context, repo repository.Repository, transactions ...domain.Transaction) []domain.Transaction {
+	created := make([]domain.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		c, err := repo.CreateTransaction(ctx, transaction)
+		require.NoError(t, err)
+		created = append(created, *c)
+	}
+	return created
+}
+
+// requireSameTransaction compares the transactions, allowing for the precision of the stored creation times
+func requireSameTransaction(t *testing.T, want, got domain.Transaction) {
+	require.WithinDuration(t, want.CreatedAt, got.CreatedAt, time.Millisecond)
+	want.CreatedAt, got.CreatedAt = time.Time{}, time.Time{}
+	require.Equal(t, want, got)
+}
+
+// requireIDs checks that the transactions are the expected ones, in the expected order
+func requireIDs(t *testing.T, want []domain.Transaction, got []domain.Transaction) {
+	wantIDs := make([]uuid.UUID, 0, len(want))
+	for _, transaction := range want {
+		wantIDs = append(wantIDs, transaction.ID)
+	}
+	gotIDs := make([]uuid.UUID, 0, len(got))
+	for _, transaction := range got {
+		gotIDs = append(gotIDs, transaction.ID)
+	}
+	require.Equal(t, wantIDs, gotIDs)
+}
+
+func requireNotFound(t *testing.T, err error) {
+	var notFound repository.NotFoundError
+	require.True(t, errors.As(err, &notFound), "expected a not found error, got %v", err)
+}
+
+func requireUniqueIndex(t *testing.T, err error) {
+	var unique repository.UniqueIndexError
+	require.True(t, errors.As(err, &unique), "expected a unique index error, got %v", err)
+}
+
+func testTransactions(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	userID := uuid.New()
+
+	transaction := newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 100)
+	transaction.Status = domain.TransactionStatusPendingReview
+	transaction.FlaggedRules = []string{"velocity"}
+	transaction.Metadata = map[string]string{"order_id": "A-1001", "merchant": "Corner shop"}
+	transaction.Tags = []string{"vip", "campaign:summer"}
+	start := time.Now()
+	created, err := repo.CreateTransaction(ctx, transaction)
+	require.NoError(t, err)
+	require.WithinDuration(t, start, created.CreatedAt, time.Second)
+
+	got, err := repo.GetTransaction(ctx, transaction.ID)
+	require.NoError(t, err)
+	requireSameTransaction(t, *created, *got)
+
+	t.Run("an ID cannot be reused within a tenant", func(t *testing.T) {
+		_, err := repo.CreateTransaction(ctx, transaction)
+		requireUniqueIndex(t, err)
+
+		other := newTenant()
+		reused := transaction
+		reused.Amount = 200
+		_, err = repo.CreateTransaction(other, reused)
+		require.NoError(t, err)
+		got, err := repo.GetTransaction(other, transaction.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(200), got.Amount)
+		got, err = repo.GetTransaction(ctx, transaction.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(100), got.Amount)
+	})
+
+	t.Run("an unknown transaction or one of another tenant is not found", func(t *testing.T) {
+		_, err := repo.GetTransaction(ctx, uuid.New())
+		requireNotFound(t, err)
+		_, err = repo.GetTransaction(newTenant(), transaction.ID)
+		requireNotFound(t, err)
+	})
+
+	t.Run("the latest transaction of the user is returned", func(t *testing.T) {
+		later := createTransactions(t, ctx, repo, newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeDebit, 10))
+
+		latest, err := repo.GetLatestUserTransaction(ctx, userID)
+		require.NoError(t, err)
+		requireSameTransaction(t, later[0], *latest)
+
+		_, err = repo.GetLatestUserTransaction(ctx, uuid.New())
+		requireNotFound(t, err)
+	})
+
+	t.Run("the transactions of the user are counted from the instant", func(t *testing.T) {
+		count, err := repo.CountUserTransactionsSince(ctx, userID, start.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		count, err = repo.CountUserTransactionsSince(ctx, userID, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		count, err = repo.CountUserTransactionsSince(newTenant(), userID, start.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
+
+func testListTransactions(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	alice, bob := uuid.New(), uuid.New()
+
+	annotated := newTransaction(alice, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 100)
+	annotated.Metadata = map[string]string{"order_id": "A-1001", "campaign": "summer"}
+	annotated.Tags = []string{"vip", "web"}
+	alsoAnnotated := newTransaction(bob, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 30)
+	alsoAnnotated.Metadata = map[string]string{"campaign": "summer"}
+	alsoAnnotated.Tags = []string{"vip"}
+
+	all := createTransactions(t, ctx, repo,
+		annotated,
+		newTransaction(alice, domain.OriginMobileIOS, domain.TransactionTypeDebit, 20),
+		alsoAnnotated,
+		newTransaction(bob, domain.OriginMobileAndroid, domain.TransactionTypeCredit, 40),
+		newTransaction(alice, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 50),
+	)
+	createTransactions(t, newTenant(), repo, newTransaction(alice, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 60))
+
+	// the transactions are listed oldest first, by ID when they were created at the same time
+	listed, err := repo.ListTransactions(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, len(all))
+	for i := 1; i < len(listed); i++ {
+		previous, current := listed[i-1], listed[i]
+		require.True(t, previous.CreatedAt.Before(current.CreatedAt) ||
+			(previous.CreatedAt.Equal(current.CreatedAt) && previous.ID.String() < current.ID.String()),
+			"transactions are not ordered by creation time and ID")
+	}
+
+	matching := func(match func(domain.Transaction) bool) []domain.Transaction {
+		var want []domain.Transaction
+		for _, transaction := range listed {
+			if match(transaction) {
+				want = append(want, transaction)
+			}
+		}
+		return want
+	}
+
+	filters := map[string]struct {
+		options []filter.Options
+		want    []domain.Transaction
+	}{
+		"it keeps the tenant of the context when another one is asked for": {
+			options: []filter.Options{filter.WithTenantID(tenant.Default)},
+		},
+		"it matches the tenant of the context when it is asked for": {
+			options: []filter.Options{filter.WithTenantID(tenant.FromContext(ctx))},
+			want:    listed,
+		},
+		"it returns an empty list when nothing matches": {
+			options: []filter.Options{filter.WithUserID(uuid.New())},
+		},
+		"it filters on a metadata entry": {
+			options: []filter.Options{filter.WithMetadata("campaign", "summer")},
+			want:    matching(func(tr domain.Transaction) bool { return tr.ID == annotated.ID || tr.ID == alsoAnnotated.ID }),
+		},
+		"it filters on every metadata entry": {
+			options: []filter.Options{filter.WithMetadata("campaign", "summer"), filter.WithMetadata("order_id", "A-1001")},
+			want:    matching(func(tr domain.Transaction) bool { return tr.ID == annotated.ID }),
+		},
+		"it filters on a metadata value": {
+			options: []filter.Options{filter.WithMetadata("campaign", "winter")},
+		},
+		"it filters on a tag": {
+			options: []filter.Options{filter.WithTag("vip")},
+			want:    matching(func(tr domain.Transaction) bool { return tr.ID == annotated.ID || tr.ID == alsoAnnotated.ID }),
+		},
+		"it filters on every tag": {
+			options: []filter.Options{filter.WithTag("vip"), filter.WithTag("web")},
+			want:    matching(func(tr domain.Transaction) bool { return tr.ID == annotated.ID }),
+		},
+	}
+
+	// every combination of the origin, transaction type and user filters
+	criteria := []struct {
+		name   string
+		option filter.Options
+		match  func(domain.Transaction) bool
+	}{
+		{"origin", filter.WithOrigin(domain.OriginDesktopWeb), func(tr domain.Transaction) bool { return tr.Origin == domain.OriginDesktopWeb }},
+		{"type", filter.WithTransactionType(domain.TransactionTypeDebit.String()), func(tr domain.Transaction) bool { return tr.TransactionType == domain.TransactionTypeDebit }},
+		{"user", filter.WithUserID(alice), func(tr domain.Transaction) bool { return tr.UserID == alice }},
+	}
+	for combination := 1; combination < 1<<len(criteria); combination++ {
+		var names []string
+		var options []filter.Options
+		var matches []func(domain.Transaction) bool
+		for i, criterion := range criteria {
+			if combination&(1<<i) != 0 {
+				names = append(names, criterion.name)
+				options = append(options, criterion.option)
+				matches = append(matches, criterion.match)
+			}
+		}
+		filters["it filters on "+strings.Join(names, " and ")] = struct {
+			options []filter.Options
+			want    []domain.Transaction
+		}{
+			options: options,
+			want: matching(func(tr domain.Transaction) bool {
+				for _, match := range matches {
+					if !match(tr) {
+						return false
+					}
+				}
+				return true
+			}),
+		}
+	}
+
+	for name, tc := range filters {
+		t.Run(name, func(t *testing.T) {
+			got, err := repo.ListTransactions(ctx, tc.options...)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			requireIDs(t, tc.want, got)
+		})
+	}
+
+	// the repository lists the transactions for which the expressions evaluate to true
+	expressions := []string{
+		`amount > 30 and origin in ("desktop-web", "mobile-android")`,
+		`user_id = "` + bob.String() + `" or not transaction_type != "CREDIT TRANSACTION"`,
+		`amount >= 20 and amount <= 40 and origin not in ("mobile-ios")`,
+		`status = "COMPLETED" and created_at >= "2020-01-01" and created_at < "` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"`,
+		`id = "` + all[2].ID.String() + `"`,
+		`created_at < "2020-01-01" or status = "PENDING_REVIEW"`,
+	}
+	for _, input := range expressions {
+		t.Run("it filters on "+input, func(t *testing.T) {
+			expression, err := filter.Parse(input)
+			require.NoError(t, err)
+
+			got, err := repo.ListTransactions(ctx, filter.WithExpression(expression), filter.WithUserID(alice))
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			requireIDs(t, matching(func(tr domain.Transaction) bool { return tr.UserID == alice && expression.Matches(tr) }), got)
+
+			got, err = repo.ListTransactions(ctx, filter.WithExpression(expression))
+			require.NoError(t, err)
+			requireIDs(t, matching(expression.Matches), got)
+		})
+	}
+
+	// the amounts of the transactions differ, and the creation times break the ties of the other sorts
+	reversed := make([]domain.Transaction, 0, len(listed))
+	for i := len(listed) - 1; i >= 0; i-- {
+		reversed = append(reversed, listed[i])
+	}
+	byAmount := func(descending bool) []domain.Transaction {
+		sorted := append([]domain.Transaction(nil), listed...)
+		sort.Slice(sorted, func(i, j int) bool { return (sorted[i].Amount < sorted[j].Amount) != descending })
+		return sorted
+	}
+	sorts := map[string][]domain.Transaction{
+		"created_at":  listed,
+		"-created_at": reversed,
+		"amount":      byAmount(false),
+		"-amount":     byAmount(true),
+	}
+	for input, want := range sorts {
+		t.Run("it sorts by "+input, func(t *testing.T) {
+			s, err := filter.ParseSort(input)
+			require.NoError(t, err)
+
+			got, err := repo.ListTransactions(ctx, filter.WithSort(s))
+			require.NoError(t, err)
+			requireIDs(t, want, got)
+
+			var paged []domain.Transaction
+			for page := 1; page <= 3; page++ {
+				got, err := repo.ListTransactions(repository.WithPage(ctx, page, 2), filter.WithSort(s))
+				require.NoError(t, err)
+				paged = append(paged, got...)
+			}
+			requireIDs(t, want, paged)
+		})
+	}
+
+	t.Run("it pages through the transactions", func(t *testing.T) {
+		var paged []domain.Transaction
+		for page := 1; page <= 3; page++ {
+			got, err := repo.ListTransactions(repository.WithPage(ctx, page, 2))
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(got), 2)
+			paged = append(paged, got...)
+		}
+		requireIDs(t, listed, paged)
+	})
+
+	bobs := matching(func(tr domain.Transaction) bool { return tr.UserID == bob })
+	pages := map[string]struct {
+		page, pageSize int
+		options        []filter.Options
+		want           []domain.Transaction
+	}{
+		"it returns an empty page past the last one":       {page: 4, pageSize: 2},
+		"it returns a partial last page":                   {page: 2, pageSize: 3, want: listed[3:]},
+		"it returns every transaction in a larger page":    {page: 1, pageSize: 100, want: listed},
+		"it returns the first page for a page below 1":     {page: 0, pageSize: 2, want: listed[:2]},
+		"it uses the default size for a page size below 1": {page: 1, pageSize: -1, want: listed},
+		"it applies the filters before paging":             {page: 2, pageSize: 1, options: []filter.Options{filter.WithUserID(bob)}, want: bobs[1:]},
+	}
+	for name, tc := range pages {
+		t.Run(name, func(t *testing.T) {
+			got, err := repo.ListTransactions(repository.WithPage(ctx, tc.page, tc.pageSize), tc.options...)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			requireIDs(t, tc.want, got)
+		})
+	}
+
+	t.Run("it returns the first 10 transactions by default", func(t *testing.T) {
+		many := make([]domain.Transaction, 0, 11)
+		for i := 0; i < 11; i++ {
+			many = append(many, newTransaction(bob, domain.OriginMobileIOS, domain.TransactionTypeCredit, int64(i+1)))
+		}
+		manyCtx := newTenant()
+		createTransactions(t, manyCtx, repo, many...)
+
+		got, err := repo.ListTransactions(manyCtx)
+		require.NoError(t, err)
+		require.Len(t, got, repository.DefaultPageSize)
+	})
+}
+
+func testStatements(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	userID := uuid.New()
+	start := time.Now().Add(-time.Second)
+
+	created := createTransactions(t, ctx, repo,
+		newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 500),
+		newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeDebit, 120),
+		newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeUnspecified, 1000),
+		newTransaction(uuid.New(), domain.OriginDesktopWeb, domain.TransactionTypeCredit, 70),
+	)
+	end := time.Now().Add(time.Second)
+
+	balance, err := repo.GetBalance(ctx, userID, domain.DefaultCurrency, end)
+	require.NoError(t, err)
+	require.Equal(t, int64(380), balance)
+
+	balance, err = repo.GetBalance(ctx, userID, domain.DefaultCurrency, start)
+	require.NoError(t, err)
+	require.Zero(t, balance)
+
+	inPeriod, err := repo.ListUserTransactionsInPeriod(ctx, userID, domain.DefaultCurrency, start, end)
+	require.NoError(t, err)
+	require.Len(t, inPeriod, 3)
+	for _, transaction := range inPeriod {
+		require.Equal(t, userID, transaction.UserID)
+	}
+	require.Equal(t, created[0].ID, inPeriod[0].ID)
+
+	inPeriod, err = repo.ListUserTransactionsInPeriod(ctx, userID, domain.DefaultCurrency, end, end.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, inPeriod)
+	require.Empty(t, inPeriod)
+}
+
+func testLimits(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	userID, otherUserID := uuid.New(), uuid.New()
+
+	create := func(limit domain.Limit) domain.Limit {
+		limit.ID = uuid.New()
+		created, err := repo.CreateLimit(ctx, limit)
+		require.NoError(t, err)
+		return *created
+	}
+	global := create(domain.Limit{Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodDaily, MaxAmount: 1000})
+	origin := create(domain.Limit{Scope: domain.LimitScopeOrigin, Origin: domain.OriginMobileIOS, Period: domain.LimitPeriodDaily, MaxAmount: 500})
+	own := create(domain.Limit{Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodMonthly, MaxAmount: 5000})
+	other := create(domain.Limit{Scope: domain.LimitScopeUser, UserID: &otherUserID, Period: domain.LimitPeriodMonthly, MaxAmount: 100})
+
+	got, err := repo.GetLimit(ctx, own.ID)
+	require.NoError(t, err)
+	require.Equal(t, own.UserID, got.UserID)
+	require.Equal(t, own.MaxAmount, got.MaxAmount)
+
+	limitIDs := func(limits []domain.Limit) []uuid.UUID {
+		ids := make([]uuid.UUID, 0, len(limits))
+		for _, limit := range limits {
+			ids = append(ids, limit.ID)
+		}
+		return ids
+	}
+
+	listed, err := repo.ListLimits(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{global.ID, origin.ID, own.ID, other.ID}, limitIDs(listed))
+
+	applicable, err := repo.ListApplicableLimits(ctx, userID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{global.ID, origin.ID, own.ID}, limitIDs(applicable))
+
+	t.Run("an ID cannot be reused", func(t *testing.T) {
+		_, err := repo.CreateLimit(ctx, global)
+		requireUniqueIndex(t, err)
+	})
+
+	t.Run("an update keeps the creation time", func(t *testing.T) {
+		update := own
+		update.MaxAmount = 7000
+		update.CreatedAt = time.Time{}
+		updated, err := repo.UpdateLimit(ctx, update)
+		require.NoError(t, err)
+		require.Equal(t, int64(7000), updated.MaxAmount)
+
+		got, err := repo.GetLimit(ctx, own.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(7000), got.MaxAmount)
+		require.WithinDuration(t, own.CreatedAt, got.CreatedAt, time.Millisecond)
+		require.False(t, got.UpdatedAt.Before(got.CreatedAt))
+	})
+
+	t.Run("the limits of another tenant are not found", func(t *testing.T) {
+		otherTenant := newTenant()
+		_, err := repo.GetLimit(otherTenant, own.ID)
+		requireNotFound(t, err)
+		_, err = repo.UpdateLimit(otherTenant, own)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.DeleteLimit(otherTenant, own.ID))
+
+		listed, err := repo.ListLimits(otherTenant)
+		require.NoError(t, err)
+		require.Empty(t, listed)
+	})
+
+	t.Run("a deleted limit is not found", func(t *testing.T) {
+		require.NoError(t, repo.DeleteLimit(ctx, other.ID))
+		_, err := repo.GetLimit(ctx, other.ID)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.DeleteLimit(ctx, other.ID))
+	})
+}
+
+func testTransactionsWithinLimits(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	userID := uuid.New()
+
+	daily := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodDaily, MaxAmount: 100, Currency: domain.DefaultCurrency}
+	ios := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeOrigin, Origin: domain.OriginMobileIOS, Period: domain.LimitPeriodDaily, MaxAmount: 50, Currency: domain.DefaultCurrency}
+	limits := []domain.Limit{daily, ios}
+
+	// the callers only pass the limits that apply to the debit
+	_, err := repo.CreateTransactionWithinLimits(ctx, newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 60), limits[:1])
+	require.NoError(t, err)
+
+	// the debits of the other origins do not count towards the origin limit
+	_, err = repo.CreateTransactionWithinLimits(ctx, newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeDebit, 40), limits)
+	require.NoError(t, err)
+
+	_, err = repo.CreateTransactionWithinLimits(ctx, newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeDebit, 20), limits)
+	var exceeded domain.LimitExceededError
+	require.True(t, errors.As(err, &exceeded), "expected a limit exceeded error, got %v", err)
+	require.Equal(t, []string{daily.ID.String(), ios.ID.String()}, exceeded.LimitIDs)
+
+	sum, err := repo.SumUserDebitsSince(ctx, userID, "", domain.DefaultCurrency, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(100), sum)
+
+	sum, err = repo.SumUserDebitsSince(ctx, userID, domain.OriginMobileIOS, domain.DefaultCurrency, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(40), sum)
+
+	// the limits of a user do not see the debits made in another tenant
+	_, err = repo.CreateTransactionWithinLimits(newTenant(), newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 90), limits[:1])
+	require.NoError(t, err)
+}
+
+func testAPIKeys(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+
+	newKey := func(name string) domain.APIKey {
+		id := uuid.New()
+		return domain.APIKey{
+			ID:      id,
+			Name:    name,
+			Prefix:  "ct_" + strings.ReplaceAll(id.String(), "-", "")[:12],
+			KeyHash: fmt.Sprintf("hash-%s", id),
+			Scopes:  []string{domain.ScopeTransactionsRead},
+		}
+	}
+
+	key := newKey("reporting")
+	created, err := repo.CreateAPIKey(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, tenant.FromContext(ctx), created.TenantID)
+	require.Equal(t, key.KeyHash, created.KeyHash)
+
+	got, err := repo.GetAPIKey(ctx, key.ID)
+	require.NoError(t, err)
+	require.Equal(t, key.Scopes, got.Scopes)
+
+	t.Run("a key is looked up by its prefix from any tenant", func(t *testing.T) {
+		got, err := repo.GetAPIKeyByPrefix(newTenant(), key.Prefix)
+		require.NoError(t, err)
+		require.Equal(t, key.ID, got.ID)
+		require.Equal(t, tenant.FromContext(ctx), got.TenantID)
+
+		_, err = repo.GetAPIKeyByPrefix(ctx, "ct_unknown")
+		requireNotFound(t, err)
+	})
+
+	t.Run("the ID and the prefix cannot be reused", func(t *testing.T) {
+		_, err := repo.CreateAPIKey(ctx, key)
+		requireUniqueIndex(t, err)
+
+		samePrefix := newKey("copy")
+		samePrefix.Prefix = key.Prefix
+		_, err = repo.CreateAPIKey(newTenant(), samePrefix)
+		requireUniqueIndex(t, err)
+	})
+
+	t.Run("the keys of another tenant are neither found nor listed", func(t *testing.T) {
+		otherTenant := newTenant()
+		_, err := repo.GetAPIKey(otherTenant, key.ID)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.RevokeAPIKey(otherTenant, key.ID))
+
+		listed, err := repo.ListAPIKeys(otherTenant)
+		require.NoError(t, err)
+		require.Empty(t, listed)
+	})
+
+	t.Run("the use of a key is recorded", func(t *testing.T) {
+		usedAt := time.Now().UTC().Truncate(time.Second)
+		require.NoError(t, repo.TouchAPIKey(newTenant(), key.ID, usedAt))
+
+		got, err := repo.GetAPIKey(ctx, key.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.LastUsedAt)
+		require.True(t, usedAt.Equal(*got.LastUsedAt))
+	})
+
+	t.Run("a key is rotated once", func(t *testing.T) {
+		replacement := newKey("reporting")
+		rotated, err := repo.RotateAPIKey(ctx, key.ID, replacement)
+		require.NoError(t, err)
+		require.Equal(t, replacement.ID, rotated.ID)
+		require.Equal(t, tenant.FromContext(ctx), rotated.TenantID)
+
+		old, err := repo.GetAPIKey(ctx, key.ID)
+		require.NoError(t, err)
+		require.True(t, old.IsRevoked())
+
+		_, err = repo.RotateAPIKey(ctx, key.ID, newKey("reporting"))
+		requireNotFound(t, err)
+		requireNotFound(t, repo.RevokeAPIKey(ctx, key.ID))
+
+		listed, err := repo.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		require.Equal(t, key.ID, listed[0].ID)
+		require.Equal(t, replacement.ID, listed[1].ID)
+	})
+
+	t.Run("a failed rotation leaves the key active", func(t *testing.T) {
+		active := newKey("billing")
+		_, err := repo.CreateAPIKey(ctx, active)
+		require.NoError(t, err)
+
+		conflicting := newKey("billing")
+		conflicting.Prefix = key.Prefix
+		_, err = repo.RotateAPIKey(ctx, active.ID, conflicting)
+		requireUniqueIndex(t, err)
+
+		got, err := repo.GetAPIKey(ctx, active.ID)
+		require.NoError(t, err)
+		require.False(t, got.IsRevoked())
+
+		require.NoError(t, repo.RevokeAPIKey(ctx, active.ID))
+		requireNotFound(t, repo.RevokeAPIKey(ctx, active.ID))
+	})
+}
+
+func testSavedSearches(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+
+	search := domain.SavedSearch{
+		ID:              uuid.New(),
+		Name:            "big-debits",
+		Owner:           "analyst",
+		Origin:          domain.OriginMobileIOS,
+		TransactionType: domain.TransactionTypeDebit.String(),
+		Filter:          "amount > 1000",
+		Sort:            "-amount",
+		Shared:          true,
+	}
+	start := time.Now()
+	created, err := repo.CreateSavedSearch(ctx, search)
+	require.NoError(t, err)
+	require.WithinDuration(t, start, created.CreatedAt, time.Second)
+	require.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	got, err := repo.GetSavedSearch(ctx, search.ID)
+	require.NoError(t, err)
+	requireSameSavedSearch(t, *created, *got)
+	got, err = repo.GetSavedSearchByName(ctx, search.Name)
+	require.NoError(t, err)
+	requireSameSavedSearch(t, *created, *got)
+
+	other := domain.SavedSearch{ID: uuid.New(), Name: "all-credits", Owner: "colleague", TransactionType: domain.TransactionTypeCredit.String()}
+	_, err = repo.CreateSavedSearch(ctx, other)
+	require.NoError(t, err)
+
+	t.Run("the searches of the tenant are listed by name", func(t *testing.T) {
+		searches, err := repo.ListSavedSearches(ctx)
+		require.NoError(t, err)
+		require.Len(t, searches, 2)
+		require.Equal(t, []string{other.Name, search.Name}, []string{searches[0].Name, searches[1].Name})
+
+		searches, err = repo.ListSavedSearches(newTenant())
+		require.NoError(t, err)
+		require.NotNil(t, searches)
+		require.Empty(t, searches)
+	})
+
+	t.Run("a name is unique within a tenant only", func(t *testing.T) {
+		duplicate := search
+		duplicate.ID = uuid.New()
+		_, err := repo.CreateSavedSearch(ctx, duplicate)
+		requireUniqueIndex(t, err)
+
+		_, err = repo.CreateSavedSearch(newTenant(), duplicate)
+		require.NoError(t, err)
+	})
+
+	t.Run("the searches of another tenant are not found", func(t *testing.T) {
+		_, err := repo.GetSavedSearch(newTenant(), search.ID)
+		requireNotFound(t, err)
+		_, err = repo.GetSavedSearchByName(newTenant(), search.Name)
+		requireNotFound(t, err)
+		_, err = repo.UpdateSavedSearch(newTenant(), search)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.DeleteSavedSearch(newTenant(), search.ID))
+	})
+
+	t.Run("an update keeps the owner and the creation time", func(t *testing.T) {
+		update := search
+		update.Owner = "someone else"
+		update.Filter = "amount > 5000"
+		update.Shared = false
+		updated, err := repo.UpdateSavedSearch(ctx, update)
+		require.NoError(t, err)
+		require.Equal(t, search.Owner, updated.Owner)
+		require.WithinDuration(t, created.CreatedAt, updated.CreatedAt, time.Millisecond)
+		require.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+
+		got, err := repo.GetSavedSearch(ctx, search.ID)
+		require.NoError(t, err)
+		require.Equal(t, "amount > 5000", got.Filter)
+		require.Equal(t, search.Owner, got.Owner)
+		require.False(t, got.Shared)
+
+		update.Name = other.Name
+		_, err = repo.UpdateSavedSearch(ctx, update)
+		requireUniqueIndex(t, err)
+	})
+
+	t.Run("a deleted search is not found", func(t *testing.T) {
+		require.NoError(t, repo.DeleteSavedSearch(ctx, other.ID))
+		_, err := repo.GetSavedSearch(ctx, other.ID)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.DeleteSavedSearch(ctx, other.ID))
+	})
+}
+
+func testCategories(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+
+	food := domain.Category{ID: uuid.New(), Name: "food"}
+	start := time.Now()
+	created, err := repo.CreateCategory(ctx, food)
+	require.NoError(t, err)
+	require.WithinDuration(t, start, created.CreatedAt, time.Second)
+	require.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	groceries := domain.Category{ID: uuid.New(), Name: "groceries", ParentID: &food.ID}
+	_, err = repo.CreateCategory(ctx, groceries)
+	require.NoError(t, err)
+
+	got, err := repo.GetCategory(ctx, groceries.ID)
+	require.NoError(t, err)
+	require.Equal(t, groceries.Name, got.Name)
+	require.Equal(t, &food.ID, got.ParentID)
+
+	minAmount := int64(1000)
+	first := domain.CategoryRule{ID: uuid.New(), CategoryID: groceries.ID, Priority: 10, DescriptionPattern: "(?i)grocer"}
+	second := domain.CategoryRule{ID: uuid.New(), CategoryID: food.ID, Priority: 20, MinAmount: &minAmount, Metadata: map[string]string{"merchant": "acme"}}
+	urgent := domain.CategoryRule{ID: uuid.New(), CategoryID: food.ID, Origin: domain.OriginMobileIOS}
+	for _, rule := range []domain.CategoryRule{first, second, urgent} {
+		_, err := repo.CreateCategoryRule(ctx, rule)
+		require.NoError(t, err)
+	}
+
+	t.Run("the categories of the tenant are listed by name", func(t *testing.T) {
+		categories, err := repo.ListCategories(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{food.Name, groceries.Name}, []string{categories[0].Name, categories[1].Name})
+
+		categories, err = repo.ListCategories(newTenant())
+		require.NoError(t, err)
+		require.NotNil(t, categories)
+		require.Empty(t, categories)
+	})
+
+	t.Run("a name is unique within a tenant only", func(t *testing.T) {
+		duplicate := domain.Category{ID: uuid.New(), Name: food.Name}
+		_, err := repo.CreateCategory(ctx, duplicate)
+		requireUniqueIndex(t, err)
+
+		_, err = repo.CreateCategory(newTenant(), duplicate)
+		require.NoError(t, err)
+	})
+
+	t.Run("the categories and rules of another tenant are not found", func(t *testing.T) {
+		_, err := repo.GetCategory(newTenant(), food.ID)
+		requireNotFound(t, err)
+		_, err = repo.UpdateCategory(newTenant(), food)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.DeleteCategory(newTenant(), food.ID))
+		_, err = repo.GetCategoryRule(newTenant(), first.ID)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.DeleteCategoryRule(newTenant(), first.ID))
+	})
+
+	t.Run("the rules are listed by priority, then oldest first", func(t *testing.T) {
+		rules, err := repo.ListCategoryRules(ctx)
+		require.NoError(t, err)
+		require.Len(t, rules, 3)
+		require.Equal(t, []uuid.UUID{urgent.ID, first.ID, second.ID}, []uuid.UUID{rules[0].ID, rules[1].ID, rules[2].ID})
+		require.Equal(t, &minAmount, rules[2].MinAmount)
+		require.Equal(t, second.Metadata, rules[2].Metadata)
+		require.Empty(t, rules[0].Metadata)
+	})
+
+	t.Run("an update keeps the creation time", func(t *testing.T) {
+		update := groceries
+		update.Name = "supermarkets"
+		update.ParentID = nil
+		updated, err := repo.UpdateCategory(ctx, update)
+		require.NoError(t, err)
+		require.False(t, updated.CreatedAt.IsZero())
+
+		got, err := repo.GetCategory(ctx, groceries.ID)
+		require.NoError(t, err)
+		require.Equal(t, "supermarkets", got.Name)
+		require.Nil(t, got.ParentID)
+
+		update.Name = food.Name
+		_, err = repo.UpdateCategory(ctx, update)
+		requireUniqueIndex(t, err)
+
+		rule := first
+		rule.Priority = 30
+		_, err = repo.UpdateCategoryRule(ctx, rule)
+		require.NoError(t, err)
+		gotRule, err := repo.GetCategoryRule(ctx, first.ID)
+		require.NoError(t, err)
+		require.Equal(t, 30, gotRule.Priority)
+	})
+
+	t.Run("a category with subcategories or rules cannot be deleted", func(t *testing.T) {
+		parent := domain.Category{ID: uuid.New(), Name: "travel"}
+		child := domain.Category{ID: uuid.New(), Name: "flights", ParentID: &parent.ID}
+		for _, category := range []domain.Category{parent, child} {
+			_, err := repo.CreateCategory(ctx, category)
+			require.NoError(t, err)
+		}
+		require.ErrorIs(t, repo.DeleteCategory(ctx, parent.ID), domain.ErrCategoryInUse)
+		require.ErrorIs(t, repo.DeleteCategory(ctx, food.ID), domain.ErrCategoryInUse)
+
+		require.NoError(t, repo.DeleteCategory(ctx, child.ID))
+		require.NoError(t, repo.DeleteCategory(ctx, parent.ID))
+		_, err := repo.GetCategory(ctx, parent.ID)
+		requireNotFound(t, err)
+	})
+
+	t.Run("a deleted rule is not found", func(t *testing.T) {
+		require.NoError(t, repo.DeleteCategoryRule(ctx, second.ID))
+		_, err := repo.GetCategoryRule(ctx, second.ID)
+		requireNotFound(t, err)
+		requireNotFound(t, repo.DeleteCategoryRule(ctx, second.ID))
+	})
+}
+
+func testCategorizedTransactions(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+
+	food := domain.Category{ID: uuid.New(), Name: "food"}
+	groceries := domain.Category{ID: uuid.New(), Name: "groceries", ParentID: &food.ID}
+	travel := domain.Category{ID: uuid.New(), Name: "travel"}
+	for _, category := range []domain.Category{food, groceries, travel} {
+		_, err := repo.CreateCategory(ctx, category)
+		require.NoError(t, err)
+	}
+
+	userID := uuid.New()
+	lunch := newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 1500)
+	lunch.CategoryID = &food.ID
+	lunch.CategorizedBy = domain.CategorizedByRulePrefix + uuid.NewString()
+	categorizedAt := time.Now().UTC().Truncate(time.Millisecond)
+	lunch.CategorizedAt = &categorizedAt
+	market := newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeDebit, 4200)
+	market.Description = "Weekly groceries"
+	refund := newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeCredit, 700)
+	transactions := createTransactions(t, ctx, repo, lunch, market, refund)
+
+	got, err := repo.GetTransaction(ctx, lunch.ID)
+	require.NoError(t, err)
+	require.Equal(t, &food.ID, got.CategoryID)
+	require.Equal(t, lunch.CategorizedBy, got.CategorizedBy)
+	require.WithinDuration(t, categorizedAt, *got.CategorizedAt, time.Millisecond)
+
+	t.Run("an override records who changed the category", func(t *testing.T) {
+		start := time.Now()
+		categorized, err := repo.SetTransactionCategory(ctx, market.ID, &groceries.ID, "analyst")
+		require.NoError(t, err)
+		require.Equal(t, &groceries.ID, categorized.CategoryID)
+		require.Equal(t, "analyst", categorized.CategorizedBy)
+		require.WithinDuration(t, start, *categorized.CategorizedAt, time.Second)
+		require.Equal(t, market.Description, categorized.Description)
+
+		_, err = repo.SetTransactionCategory(ctx, uuid.New(), &groceries.ID, "analyst")
+		requireNotFound(t, err)
+		_, err = repo.SetTransactionCategory(newTenant(), market.ID, &groceries.ID, "analyst")
+		requireNotFound(t, err)
+	})
+
+	t.Run("a category filter includes the subcategories", func(t *testing.T) {
+		got, err := repo.ListTransactions(ctx, filter.WithCategory(food.ID))
+		require.NoError(t, err)
+		requireIDs(t, transactions[:2], got)
+
+		got, err = repo.ListTransactions(ctx, filter.WithCategory(groceries.ID))
+		require.NoError(t, err)
+		requireIDs(t, transactions[1:2], got)
+
+		got, err = repo.ListTransactions(ctx, filter.WithCategory(travel.ID))
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+
+	t.Run("the transactions are aggregated by dimension", func(t *testing.T) {
+		stats, err := repo.GetTransactionStats(ctx, domain.StatsByCategory)
+		require.NoError(t, err)
+		want := []domain.TransactionStats{
+			{Key: "", Count: 1, Credits: 700},
+			{Key: food.ID.String(), Count: 1, Debits: 1500},
+			{Key: groceries.ID.String(), Count: 1, Debits: 4200},
+		}
+		sort.Slice(want[1:], func(i, j int) bool { return want[1+i].Key < want[1+j].Key })
+		require.Equal(t, want, stats)
+
+		stats, err = repo.GetTransactionStats(ctx, domain.StatsByOrigin, filter.WithCategory(food.ID))
+		require.NoError(t, err)
+		require.Equal(t, []domain.TransactionStats{
+			{Key: domain.OriginDesktopWeb, Count: 1, Debits: 1500},
+			{Key: domain.OriginMobileIOS, Count: 1, Debits: 4200},
+		}, stats)
+
+		stats, err = repo.GetTransactionStats(newTenant(), domain.StatsByStatus)
+		require.NoError(t, err)
+		require.Empty(t, stats)
+	})
+
+	t.Run("the transactions of a deleted category are uncategorized", func(t *testing.T) {
+		require.ErrorIs(t, repo.DeleteCategory(ctx, food.ID), domain.ErrCategoryInUse)
+		require.NoError(t, repo.DeleteCategory(ctx, groceries.ID))
+
+		got, err := repo.GetTransaction(ctx, market.ID)
+		require.NoError(t, err)
+		require.Nil(t, got.CategoryID)
+		require.Equal(t, "analyst", got.CategorizedBy)
+	})
+}
+
+func testSearchTransactions(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+
+	userID := uuid.New()
+	inMetadata := newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeDebit, 4200)
+	inMetadata.Description = "Weekly groceries"
+	inMetadata.Metadata = map[string]string{"merchant": "Corner Shop", "order_id": "market"}
+	inDescription := newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 1500)
+	inDescription.Description = "Corner shop groceries"
+	partial := newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 700)
+	partial.Description = "Refund"
+	partial.Metadata = map[string]string{"reference": "corner-17"}
+	unrelated := newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 300)
+	unrelated.Metadata = map[string]string{"order_id": "market"}
+	transactions := createTransactions(t, ctx, repo, inMetadata, inDescription, partial, unrelated)
+
+	t.Run("the transactions matching every word are listed by relevance", func(t *testing.T) {
+		got, err := repo.ListTransactions(ctx, filter.WithSearch("SHOP corner"))
+		require.NoError(t, err)
+		requireIDs(t, []domain.Transaction{transactions[1], transactions[0]}, got)
+		require.Equal(t, "<b>Corner</b> <b>shop</b> groceries", got[0].Match.Snippet)
+		require.Equal(t, "Weekly groceries <b>Corner</b> <b>Shop</b>", got[1].Match.Snippet)
+		require.Greater(t, got[0].Match.Rank, got[1].Match.Rank)
+	})
+
+	t.Run("the description, the merchant and the reference are searched", func(t *testing.T) {
+		got, err := repo.ListTransactions(ctx, filter.WithSearch("corner"))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uuid.UUID{transactions[0].ID, transactions[1].ID, transactions[2].ID}, []uuid.UUID{got[0].ID, got[1].ID, got[2].ID})
+
+		got, err = repo.ListTransactions(ctx, filter.WithSearch("market"))
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+
+	t.Run("a search composes with the other filters and the sort", func(t *testing.T) {
+		got, err := repo.ListTransactions(ctx, filter.WithSearch("groceries"), filter.WithOrigin(domain.OriginMobileIOS))
+		require.NoError(t, err)
+		requireIDs(t, transactions[:1], got)
+
+		sort, err := filter.ParseSort("amount")
+		require.NoError(t, err)
+		got, err = repo.ListTransactions(ctx, filter.WithSearch("corner"), filter.WithSort(sort))
+		require.NoError(t, err)
+		requireIDs(t, []domain.Transaction{transactions[2], transactions[1], transactions[0]}, got)
+		require.NotNil(t, got[0].Match)
+
+		stats, err := repo.GetTransactionStats(ctx, domain.StatsByTransactionType, filter.WithSearch("corner"))
+		require.NoError(t, err)
+		require.Equal(t, []domain.TransactionStats{
+			{Key: domain.TransactionTypeCredit.String(), Count: 1, Credits: 700},
+			{Key: domain.TransactionTypeDebit.String(), Count: 2, Debits: 5700},
+		}, stats)
+	})
+
+	t.Run("the transactions are not annotated without a search", func(t *testing.T) {
+		got, err := repo.ListTransactions(ctx)
+		require.NoError(t, err)
+		require.Len(t, got, 4)
+		for _, transaction := range got {
+			require.Nil(t, transaction.Match)
+		}
+	})
+}
+
+// requireSameSavedSearch compares the searches, allowing for the precision of the stored times
+func requireSameSavedSearch(t *testing.T, want, got domain.SavedSearch) {
+	require.WithinDuration(t, want.CreatedAt, got.CreatedAt, time.Millisecond)
+	require.WithinDuration(t, want.UpdatedAt, got.UpdatedAt, time.Millisecond)
+	want.CreatedAt, got.CreatedAt = time.Time{}, time.Time{}
+	want.UpdatedAt, got.UpdatedAt = time.Time{}, time.Time{}
+	require.Equal(t, want, got)
+}
+
+func testSchedules(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	tenantID := tenant.FromContext(ctx)
+	userID := uuid.New()
+
+	firstRun := time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)
+	schedule := domain.Schedule{
+		ID:              uuid.New(),
+		UserID:          userID,
+		Origin:          domain.OriginDesktopWeb,
+		TransactionType: domain.TransactionTypeDebit,
+		Amount:          999,
+		Description:     "Gym",
+		Metadata:        map[string]string{"plan": "premium"},
+		Cron:            "0 9 * * *",
+		StartAt:         firstRun,
+		Status:          domain.ScheduleStatusActive,
+		NextRunAt:       &firstRun,
+	}
+	created, err := repo.CreateSchedule(ctx, schedule)
+	require.NoError(t, err)
+	require.Equal(t, tenantID, created.TenantID)
+
+	got, err := repo.GetSchedule(ctx, schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, schedule.Cron, got.Cron)
+	require.Equal(t, schedule.Metadata, got.Metadata)
+	require.Equal(t, domain.DefaultCurrency, got.Currency)
+	require.Equal(t, domain.ScheduleStatusActive, got.Status)
+	require.True(t, firstRun.Equal(*got.NextRunAt))
+
+	other := schedule
+	other.ID = uuid.New()
+	other.UserID = uuid.New()
+	other.Cron, other.RRule = "", "FREQ=MONTHLY"
+	_, err = repo.CreateSchedule(ctx, other)
+	require.NoError(t, err)
+
+	_, err = repo.CreateSchedule(ctx, schedule)
+	requireUniqueIndex(t, err)
+
+	t.Run("the schedules of the tenant are listed by creation time", func(t *testing.T) {
+		schedules, err := repo.ListSchedules(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		require.Equal(t, []uuid.UUID{schedule.ID, other.ID}, []uuid.UUID{schedules[0].ID, schedules[1].ID})
+
+		schedules, err = repo.ListSchedules(ctx, &other.UserID)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		require.Equal(t, other.ID, schedules[0].ID)
+
+		schedules, err = repo.ListSchedules(newTenant(), nil)
+		require.NoError(t, err)
+		require.Empty(t, schedules)
+	})
+
+	t.Run("the schedules of another tenant are not found", func(t *testing.T) {
+		_, err := repo.GetSchedule(newTenant(), schedule.ID)
+		requireNotFound(t, err)
+		_, err = repo.UpdateScheduleStatus(newTenant(), schedule.ID,
+			[]domain.ScheduleStatus{domain.ScheduleStatusActive}, domain.ScheduleStatusPaused, nil)
+		requireNotFound(t, err)
+	})
+
+	t.Run("a due schedule is leased to a single owner at a time", func(t *testing.T) {
+		claimed := claimSchedule(t, repo, "instance-1", firstRun, schedule.ID)
+		require.NotNil(t, claimed)
+		require.Equal(t, tenantID, claimed.TenantID)
+		require.Nil(t, claimSchedule(t, repo, "instance-2", firstRun.Add(time.Second), schedule.ID))
+
+		nextRun := firstRun.AddDate(0, 0, 1)
+		require.ErrorIs(t, repo.AdvanceSchedule(ctx, schedule.ID, "instance-2", firstRun, &nextRun, ""), repository.ErrLeaseLost)
+		require.NoError(t, repo.AdvanceSchedule(ctx, schedule.ID, "instance-1", firstRun, &nextRun, "transaction denied"))
+		require.ErrorIs(t, repo.AdvanceSchedule(ctx, schedule.ID, "instance-1", firstRun, &nextRun, ""), repository.ErrLeaseLost)
+		require.NoError(t, repo.ReleaseSchedule(ctx, schedule.ID, "instance-1"))
+
+		got, err := repo.GetSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		require.True(t, nextRun.Equal(*got.NextRunAt))
+		require.True(t, firstRun.Equal(*got.LastRunAt))
+		require.Equal(t, 1, got.RunCount)
+		require.Equal(t, "transaction denied", got.LastError)
+
+		require.Nil(t, claimSchedule(t, repo, "instance-2", firstRun.Add(time.Hour), schedule.ID))
+		require.NotNil(t, claimSchedule(t, repo, "instance-2", nextRun, schedule.ID))
+	})
+
+	t.Run("an expired lease is claimed again", func(t *testing.T) {
+		nextRun := firstRun.AddDate(0, 0, 1)
+		require.NotNil(t, claimSchedule(t, repo, "instance-3", nextRun.Add(time.Hour), schedule.ID))
+
+		require.ErrorIs(t, repo.AdvanceSchedule(ctx, schedule.ID, "instance-2", nextRun, nil, ""), repository.ErrLeaseLost)
+		require.NoError(t, repo.AdvanceSchedule(ctx, schedule.ID, "instance-3", nextRun, nil, ""))
+
+		got, err := repo.GetSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, domain.ScheduleStatusCompleted, got.Status)
+		require.Nil(t, got.NextRunAt)
+		require.Equal(t, 2, got.RunCount)
+		require.Empty(t, got.LastError)
+	})
+
+	t.Run("a status changes only from the given ones", func(t *testing.T) {
+		paused, err := repo.UpdateScheduleStatus(ctx, other.ID,
+			[]domain.ScheduleStatus{domain.ScheduleStatusActive}, domain.ScheduleStatusPaused, nil)
+		require.NoError(t, err)
+		require.Equal(t, domain.ScheduleStatusPaused, paused.Status)
+		require.Nil(t, paused.NextRunAt)
+		require.Nil(t, claimSchedule(t, repo, "instance-1", firstRun.AddDate(1, 0, 0), other.ID))
+
+		_, err = repo.UpdateScheduleStatus(ctx, other.ID,
+			[]domain.ScheduleStatus{domain.ScheduleStatusActive}, domain.ScheduleStatusPaused, nil)
+		require.ErrorIs(t, err, domain.ErrScheduleTransition)
+
+		nextRun := firstRun.AddDate(0, 1, 0)
+		resumed, err := repo.UpdateScheduleStatus(ctx, other.ID,
+			[]domain.ScheduleStatus{domain.ScheduleStatusPaused}, domain.ScheduleStatusActive, &nextRun)
+		require.NoError(t, err)
+		require.Equal(t, domain.ScheduleStatusActive, resumed.Status)
+		require.True(t, nextRun.Equal(*resumed.NextRunAt))
+	})
+
+	t.Run("the run of an occurrence is stored with its transaction", func(t *testing.T) {
+		requireRun := func(ctx context.Context, want bool) {
+			materialized, err := repo.HasScheduleRun(ctx, schedule.ID, firstRun)
+			require.NoError(t, err)
+			require.Equal(t, want, materialized)
+		}
+		requireRun(ctx, false)
+
+		// a transaction of a client with the metadata of the occurrence is not its run
+		lookalike := schedule.Transaction(firstRun)
+		lookalike.ScheduleRun = nil
+		createTransactions(t, ctx, repo, lookalike)
+		requireRun(ctx, false)
+
+		createTransactions(t, ctx, repo, schedule.Transaction(firstRun))
+		requireRun(ctx, true)
+		requireRun(newTenant(), false)
+
+		again := schedule.Transaction(firstRun)
+		_, err := repo.CreateTransaction(ctx, again)
+		require.ErrorIs(t, err, repository.ErrScheduleRunExists)
+		_, err = repo.GetTransaction(ctx, again.ID)
+		requireNotFound(t, err)
+	})
+}
+
+// claimSchedule claims the due schedules for the owner, releasing the ones of the other runs sharing the repository,
+// and returns the schedule with the ID if it was claimed
+func claimSchedule(t *testing.T, repo repository.Repository, owner string, now time.Time, id uuid.UUID) *domain.Schedule {
+	schedules, err := repo.ClaimDueSchedules(context.Background(), owner, now, time.Minute, 100)
+	require.NoError(t, err)
+
+	var claimed *domain.Schedule
+	for i := range schedules {
+		if schedules[i].ID == id {
+			claimed = &schedules[i]
+			continue
+		}
+		require.NoError(t, repo.ReleaseSchedule(tenant.WithID(context.Background(), schedules[i].TenantID), schedules[i].ID, owner))
+	}
+	return claimed
+}
+
+func testHolds(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	userID := uuid.New()
+
+	createTransactions(t, ctx, repo, newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 100))
+
+	newHold := func(amount int64, expiresAt time.Time) domain.Hold {
+		return domain.Hold{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Origin:      domain.OriginDesktopWeb,
+			Amount:      amount,
+			Description: "Hotel",
+			Metadata:    map[string]string{"booking": "42"},
+			Status:      domain.HoldStatusActive,
+			ExpiresAt:   expiresAt,
+		}
+	}
+	inAnHour := time.Now().Add(time.Hour)
+
+	hold := newHold(60, inAnHour)
+	created, err := repo.CreateHold(ctx, hold)
+	require.NoError(t, err)
+	require.Equal(t, tenant.FromContext(ctx), created.TenantID)
+
+	got, err := repo.GetHold(ctx, hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, hold.Metadata, got.Metadata)
+	require.Equal(t, domain.HoldStatusActive, got.Status)
+	require.WithinDuration(t, inAnHour, got.ExpiresAt, time.Millisecond)
+
+	duplicate := hold
+	duplicate.Amount = 10
+	_, err = repo.CreateHold(ctx, duplicate)
+	requireUniqueIndex(t, err)
+
+	t.Run("the holds cannot reserve more than the available balance", func(t *testing.T) {
+		_, err := repo.CreateHold(ctx, newHold(50, inAnHour))
+		var insufficientFunds domain.InsufficientFundsError
+		require.True(t, errors.As(err, &insufficientFunds), "expected an insufficient funds error, got %v", err)
+		require.Equal(t, domain.NewInsufficientFundsError(40, 50), insufficientFunds)
+
+		held, err := repo.GetHeldAmount(ctx, userID, domain.DefaultCurrency)
+		require.NoError(t, err)
+		require.Equal(t, int64(60), held)
+	})
+
+	t.Run("a debit cannot take the held funds", func(t *testing.T) {
+		_, err := repo.CreateTransactionWithinLimits(ctx, newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 41), nil)
+		var insufficientFunds domain.InsufficientFundsError
+		require.True(t, errors.As(err, &insufficientFunds), "expected an insufficient funds error, got %v", err)
+		require.Equal(t, domain.NewInsufficientFundsError(40, 41), insufficientFunds)
+
+		balance, err := repo.GetBalance(ctx, userID, domain.DefaultCurrency, time.Now().Add(time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(100), balance, "the rejected debit should not be stored")
+	})
+
+	t.Run("the expired holds do not reserve their amount", func(t *testing.T) {
+		expired, err := repo.CreateHold(ctx, newHold(40, time.Now().Add(-time.Minute)))
+		require.NoError(t, err)
+
+		held, err := repo.GetHeldAmount(ctx, userID, domain.DefaultCurrency)
+		require.NoError(t, err)
+		require.Equal(t, int64(60), held)
+
+		_, err = repo.ReleaseHold(ctx, expired.ID)
+		require.ErrorIs(t, err, domain.ErrHoldTransition)
+
+		count, err := repo.ExpireHolds(context.Background(), time.Now(), 1000)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, count, 1)
+
+		got, err := repo.GetHold(ctx, expired.ID)
+		require.NoError(t, err)
+		require.Equal(t, domain.HoldStatusExpired, got.Status)
+	})
+
+	t.Run("the holds of the tenant are listed by creation time", func(t *testing.T) {
+		holds, err := repo.ListHolds(ctx, &userID)
+		require.NoError(t, err)
+		require.Len(t, holds, 2)
+		require.Equal(t, hold.ID, holds[0].ID)
+
+		holds, err = repo.ListHolds(newTenant(), nil)
+		require.NoError(t, err)
+		require.Empty(t, holds)
+	})
+
+	t.Run("the holds of another tenant are not found", func(t *testing.T) {
+		_, err := repo.GetHold(newTenant(), hold.ID)
+		requireNotFound(t, err)
+		_, err = repo.ReleaseHold(newTenant(), hold.ID)
+		requireNotFound(t, err)
+	})
+
+	t.Run("a capture debits the captured amount and releases the hold", func(t *testing.T) {
+		daily := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodDaily, MaxAmount: 40, Currency: domain.DefaultCurrency}
+		debit := newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 50)
+
+		_, _, err := repo.CaptureHold(ctx, hold.ID, debit, []domain.Limit{daily})
+		var exceeded domain.LimitExceededError
+		require.True(t, errors.As(err, &exceeded), "expected a limit exceeded error, got %v", err)
+
+		captured, transaction, err := repo.CaptureHold(ctx, hold.ID, debit, nil)
+		require.NoError(t, err)
+		require.Equal(t, domain.HoldStatusCaptured, captured.Status)
+		require.Equal(t, int64(50), captured.CapturedAmount)
+		require.Equal(t, debit.ID, *captured.TransactionID)
+		require.Equal(t, debit.ID, transaction.ID)
+
+		held, err := repo.GetHeldAmount(ctx, userID, domain.DefaultCurrency)
+		require.NoError(t, err)
+		require.Zero(t, held)
+		balance, err := repo.GetBalance(ctx, userID, domain.DefaultCurrency, time.Now().Add(time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(50), balance)
+
+		_, _, err = repo.CaptureHold(ctx, hold.ID, newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 10), nil)
+		require.ErrorIs(t, err, domain.ErrHoldTransition)
+		_, err = repo.ReleaseHold(ctx, hold.ID)
+		require.ErrorIs(t, err, domain.ErrHoldTransition)
+	})
+
+	t.Run("a release frees the amount of the hold", func(t *testing.T) {
+		released, err := repo.CreateHold(ctx, newHold(50, inAnHour))
+		require.NoError(t, err)
+
+		got, err := repo.ReleaseHold(ctx, released.ID)
+		require.NoError(t, err)
+		require.Equal(t, domain.HoldStatusReleased, got.Status)
+
+		held, err := repo.GetHeldAmount(ctx, userID, domain.DefaultCurrency)
+		require.NoError(t, err)
+		require.Zero(t, held)
+
+		_, err = repo.ReleaseHold(ctx, uuid.New())
+		requireNotFound(t, err)
+	})
+}
+
+func testCurrencies(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	userID := uuid.New()
+	start := time.Now().Add(-time.Second)
+
+	inEUR := func(transaction domain.Transaction) domain.Transaction {
+		transaction.Currency = "EUR"
+		return transaction
+	}
+	createTransactions(t, ctx, repo,
+		newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 100),
+		inEUR(newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 500)),
+		inEUR(newTransaction(userID, domain.OriginMobileIOS, domain.TransactionTypeDebit, 30)),
+	)
+	end := time.Now().Add(time.Second)
+
+	t.Run("the balances and the statements are kept per currency", func(t *testing.T) {
+		balance, err := repo.GetBalance(ctx, userID, domain.DefaultCurrency, end)
+		require.NoError(t, err)
+		require.Equal(t, int64(100), balance)
+
+		balance, err = repo.GetBalance(ctx, userID, "EUR", end)
+		require.NoError(t, err)
+		require.Equal(t, int64(470), balance)
+
+		balance, err = repo.GetBalance(ctx, userID, "JPY", end)
+		require.NoError(t, err)
+		require.Zero(t, balance)
+
+		inPeriod, err := repo.ListUserTransactionsInPeriod(ctx, userID, "EUR", start, end)
+		require.NoError(t, err)
+		require.Len(t, inPeriod, 2)
+		for _, transaction := range inPeriod {
+			require.Equal(t, "EUR", transaction.Currency)
+		}
+	})
+
+	t.Run("a hold reserves the balance in its currency only", func(t *testing.T) {
+		newHold := func(currency string, amount int64) domain.Hold {
+			return domain.Hold{
+				ID:        uuid.New(),
+				UserID:    userID,
+				Origin:    domain.OriginDesktopWeb,
+				Amount:    amount,
+				Currency:  currency,
+				Status:    domain.HoldStatusActive,
+				ExpiresAt: time.Now().Add(time.Hour),
+			}
+		}
+
+		_, err := repo.CreateHold(ctx, newHold(domain.DefaultCurrency, 150))
+		var insufficientFunds domain.InsufficientFundsError
+		require.True(t, errors.As(err, &insufficientFunds), "expected an insufficient funds error, got %v", err)
+		require.Equal(t, domain.NewInsufficientFundsError(100, 150), insufficientFunds)
+
+		hold, err := repo.CreateHold(ctx, newHold("EUR", 400))
+		require.NoError(t, err)
+		got, err := repo.GetHold(ctx, hold.ID)
+		require.NoError(t, err)
+		require.Equal(t, "EUR", got.Currency)
+
+		held, err := repo.GetHeldAmount(ctx, userID, "EUR")
+		require.NoError(t, err)
+		require.Equal(t, int64(400), held)
+		held, err = repo.GetHeldAmount(ctx, userID, domain.DefaultCurrency)
+		require.NoError(t, err)
+		require.Zero(t, held)
+
+		// the EUR hold leaves the USD balance available
+		_, err = repo.CreateHold(ctx, newHold(domain.DefaultCurrency, 100))
+		require.NoError(t, err)
+
+		_, err = repo.CreateHold(ctx, newHold("EUR", 71))
+		require.True(t, errors.As(err, &insufficientFunds), "expected an insufficient funds error, got %v", err)
+		require.Equal(t, domain.NewInsufficientFundsError(70, 71), insufficientFunds)
+	})
+
+	t.Run("a limit caps the debits in its currency only", func(t *testing.T) {
+		usd, err := repo.CreateLimit(ctx, domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodDaily, MaxAmount: 50})
+		require.NoError(t, err)
+		require.Equal(t, domain.DefaultCurrency, usd.Currency)
+		eur, err := repo.CreateLimit(ctx, domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodDaily, MaxAmount: 50, Currency: "EUR"})
+		require.NoError(t, err)
+		got, err := repo.GetLimit(ctx, eur.ID)
+		require.NoError(t, err)
+		require.Equal(t, "EUR", got.Currency)
+
+		// the 30 EUR already debited do not count towards the USD limit; the USD hold takes the whole USD balance, so
+		// that the USD debit needs a credit
+		createTransactions(t, ctx, repo, newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 40))
+		_, err = repo.CreateTransactionWithinLimits(ctx, newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 40), []domain.Limit{*usd})
+		require.NoError(t, err)
+
+		_, err = repo.CreateTransactionWithinLimits(ctx, inEUR(newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeDebit, 30)), []domain.Limit{*eur})
+		var exceeded domain.LimitExceededError
+		require.True(t, errors.As(err, &exceeded), "expected a limit exceeded error, got %v", err)
+		require.Equal(t, []string{eur.ID.String()}, exceeded.LimitIDs)
+
+		sum, err := repo.SumUserDebitsSince(ctx, userID, "", domain.DefaultCurrency, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(40), sum)
+		sum, err = repo.SumUserDebitsSince(ctx, userID, "", "EUR", time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, int64(30), sum)
+	})
+}
+
+func testFX(t *testing.T, repo repository.Repository) {
+	ctx := newTenant()
+	userID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	newSnapshot := func(effectiveAt time.Time, rate string) domain.RateSnapshot {
+		return domain.RateSnapshot{
+			ID:          uuid.New(),
+			Source:      "conformance",
+			EffectiveAt: effectiveAt,
+			Rates:       []domain.FXRate{{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: rate}},
+		}
+	}
+
+	_, err := repo.GetEffectiveRateSnapshot(ctx, now)
+	requireNotFound(t, err)
+
+	past := newSnapshot(now.Add(-time.Hour), "1.08")
+	current := newSnapshot(now.Add(-time.Minute), "1.085")
+	future := newSnapshot(now.Add(time.Hour), "1.09")
+	for _, snapshot := range []domain.RateSnapshot{past, current, future} {
+		_, err := repo.CreateRateSnapshot(ctx, snapshot)
+		require.NoError(t, err)
+	}
+	_, err = repo.CreateRateSnapshot(ctx, past)
+	requireUniqueIndex(t, err)
+
+	t.Run("the effective snapshot is the latest effective one", func(t *testing.T) {
+		effective, err := repo.GetEffectiveRateSnapshot(ctx, now)
+		require.NoError(t, err)
+		require.Equal(t, current.ID, effective.ID)
+		require.Equal(t, current.Rates, effective.Rates)
+
+		effective, err = repo.GetEffectiveRateSnapshot(ctx, now.Add(-30*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, past.ID, effective.ID)
+
+		_, err = repo.GetEffectiveRateSnapshot(newTenant(), now)
+		requireNotFound(t, err)
+	})
+
+	t.Run("the snapshots are listed by effective time, the latest first", func(t *testing.T) {
+		snapshots, err := repo.ListRateSnapshots(ctx)
+		require.NoError(t, err)
+		require.Len(t, snapshots, 3)
+		require.Equal(t, []uuid.UUID{future.ID, current.ID, past.ID}, []uuid.UUID{snapshots[0].ID, snapshots[1].ID, snapshots[2].ID})
+
+		snapshots, err = repo.ListRateSnapshots(newTenant())
+		require.NoError(t, err)
+		require.Empty(t, snapshots)
+	})
+
+	newQuote := func(expiresAt time.Time) domain.FXQuote {
+		return domain.FXQuote{
+			ID:             uuid.New(),
+			UserID:         userID,
+			SourceCurrency: "EUR",
+			TargetCurrency: "USD",
+			SourceAmount:   10000,
+			TargetAmount:   10850,
+			Rate:           "1.085",
+			SnapshotID:     current.ID,
+			ExpiresAt:      expiresAt,
+		}
+	}
+
+	quote := newQuote(now.Add(time.Minute))
+	_, err = repo.CreateFXQuote(ctx, quote)
+	require.NoError(t, err)
+
+	got, err := repo.GetFXQuote(ctx, quote.ID)
+	require.NoError(t, err)
+	require.Equal(t, quote.Rate, got.Rate)
+	require.Equal(t, quote.TargetAmount, got.TargetAmount)
+	_, err = repo.GetFXQuote(newTenant(), quote.ID)
+	requireNotFound(t, err)
+
+	newEURTransaction := func(transactionType domain.TransactionType, amount int64) domain.Transaction {
+		transaction := newTransaction(userID, domain.OriginDesktopWeb, transactionType, amount)
+		transaction.Currency = "EUR"
+		return transaction
+	}
+	createTransactions(t, ctx, repo, newEURTransaction(domain.TransactionTypeCredit, 10000))
+
+	t.Run("a quote is converted once into a debit and a credit", func(t *testing.T) {
+		conversion := domain.NewFXConversion(quote, domain.OriginDesktopWeb)
+		debit, credit := conversion.Transactions()
+
+		created, err := repo.CreateFXConversion(ctx, conversion, debit, credit, nil)
+		require.NoError(t, err)
+		require.Equal(t, conversion.ID, created.ID)
+
+		transaction, err := repo.GetTransaction(ctx, debit.ID)
+		require.NoError(t, err)
+		require.Equal(t, "EUR", transaction.Currency)
+		require.Equal(t, int64(10000), transaction.Amount)
+		transaction, err = repo.GetTransaction(ctx, credit.ID)
+		require.NoError(t, err)
+		require.Equal(t, "USD", transaction.Currency)
+		require.Equal(t, conversion.ID.String(), transaction.Metadata[domain.FXConversionIDMetadataKey])
+
+		again := domain.NewFXConversion(quote, domain.OriginDesktopWeb)
+		debit, credit = again.Transactions()
+		_, err = repo.CreateFXConversion(ctx, again, debit, credit, nil)
+		require.ErrorIs(t, err, domain.ErrQuoteUnavailable)
+		_, err = repo.GetTransaction(ctx, debit.ID)
+		requireNotFound(t, err)
+
+		got, err := repo.GetFXConversion(ctx, conversion.ID)
+		require.NoError(t, err)
+		require.Equal(t, conversion.CreditTransactionID, got.CreditTransactionID)
+		_, err = repo.GetFXConversion(newTenant(), conversion.ID)
+		requireNotFound(t, err)
+	})
+
+	t.Run("an expired quote is not converted", func(t *testing.T) {
+		expired := newQuote(now.Add(-time.Second))
+		_, err := repo.CreateFXQuote(ctx, expired)
+		require.NoError(t, err)
+
+		conversion := domain.NewFXConversion(expired, domain.OriginDesktopWeb)
+		debit, credit := conversion.Transactions()
+		_, err = repo.CreateFXConversion(ctx, conversion, debit, credit, nil)
+		require.ErrorIs(t, err, domain.ErrQuoteUnavailable)
+
+		conversion = domain.NewFXConversion(newQuote(now.Add(time.Minute)), domain.OriginDesktopWeb)
+		debit, credit = conversion.Transactions()
+		_, err = repo.CreateFXConversion(ctx, conversion, debit, credit, nil)
+		requireNotFound(t, err)
+	})
+
+	t.Run("a conversion cannot debit more than the available balance in the source currency", func(t *testing.T) {
+		createTransactions(t, ctx, repo,
+			newEURTransaction(domain.TransactionTypeCredit, 5000),
+			newTransaction(userID, domain.OriginDesktopWeb, domain.TransactionTypeCredit, 50000))
+		hold := domain.Hold{ID: uuid.New(), UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 1000, Currency: "EUR", Status: domain.HoldStatusActive, ExpiresAt: now.Add(time.Hour)}
+		_, err := repo.CreateHold(ctx, hold)
+		require.NoError(t, err)
+
+		quote := newQuote(now.Add(time.Minute))
+		_, err = repo.CreateFXQuote(ctx, quote)
+		require.NoError(t, err)
+
+		conversion := domain.NewFXConversion(quote, domain.OriginDesktopWeb)
+		debit, credit := conversion.Transactions()
+		_, err = repo.CreateFXConversion(ctx, conversion, debit, credit, nil)
+		var insufficientFunds domain.InsufficientFundsError
+		require.True(t, errors.As(err, &insufficientFunds), "expected an insufficient funds error, got %v", err)
+		require.Equal(t, domain.NewInsufficientFundsError(4000, 10000), insufficientFunds)
+		_, err = repo.GetTransaction(ctx, debit.ID)
+		requireNotFound(t, err)
+	})
+
+	t.Run("a conversion cannot debit more than the limits in the source currency allow", func(t *testing.T) {
+		createTransactions(t, ctx, repo, newEURTransaction(domain.TransactionTypeCredit, 10000))
+
+		quote := newQuote(now.Add(time.Minute))
+		_, err := repo.CreateFXQuote(ctx, quote)
+		require.NoError(t, err)
+
+		eur := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodDaily, MaxAmount: 15000, Currency: "EUR"}
+		conversion := domain.NewFXConversion(quote, domain.OriginDesktopWeb)
+		debit, credit := conversion.Transactions()
+		_, err = repo.CreateFXConversion(ctx, conversion, debit, credit, []domain.Limit{eur})
+		var exceeded domain.LimitExceededError
+		require.True(t, errors.As(err, &exceeded), "expected a limit exceeded error, got %v", err)
+		require.Equal(t, []string{eur.ID.String()}, exceeded.LimitIDs)
+		_, err = repo.GetTransaction(ctx, credit.ID)
+		requireNotFound(t, err)
+	})
+
+	t.Run("the conversions of the tenant are listed by creation time", func(t *testing.T) {
+		conversions, err := repo.ListFXConversions(ctx, &userID)
+		require.NoError(t, err)
+		require.Len(t, conversions, 1)
+		require.Equal(t, quote.ID, conversions[0].QuoteID)
+
+		otherUserID := uuid.New()
+		conversions, err = repo.ListFXConversions(ctx, &otherUserID)
+		require.NoError(t, err)
+		require.Empty(t, conversions)
+
+		conversions, err = repo.ListFXConversions(newTenant(), nil)
+		require.NoError(t, err)
+		require.Empty(t, conversions)
+	})
+}
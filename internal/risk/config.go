@@ -0,0 +1,112 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// Rule types accepted in the rules file
+const (
+	RuleTypeVelocity        = "velocity"
+	RuleTypeAmountThreshold = "amount_threshold"
+	RuleTypeOriginChange    = "origin_change"
+)
+
+// RuleConfig is a single entry of the rules file, e.g.
+//
+//	rules:
+//	  - id: velocity-5-per-minute
+//	    type: velocity
+//	    decision: review
+//	    window: 1m
+//	    max_transactions: 5
+type RuleConfig struct {
+	ID              string        `mapstructure:"id"`
+	Type            string        `mapstructure:"type"`
+	Decision        string        `mapstructure:"decision"`
+	Window          time.Duration `mapstructure:"window"`
+	MaxTransactions int           `mapstructure:"max_transactions"`
+	Origin          string        `mapstructure:"origin"`
+	MaxAmount       int64         `mapstructure:"max_amount"`
+}
+
+type RulesConfig struct {
+	Rules []RuleConfig `mapstructure:"rules"`
+}
+
+// LoadEngine builds a rules engine from a YAML rules file. An empty path yields an engine without rules.
+func LoadEngine(path string, history History) (*Engine, error) {
+	if path == "" {
+		return NewEngine(), nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read risk rules file: %w", err)
+	}
+
+	var cfg RulesConfig
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode risk rules file: %w", err)
+	}
+
+	rules, err := BuildRules(cfg, history)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(rules...), nil
+}
+
+// BuildRules validates the configuration and instantiates the built-in rules it describes
+func BuildRules(cfg RulesConfig, history History) ([]Rule, error) {
+	rules := make([]Rule, 0, len(cfg.Rules))
+	seen := make(map[string]bool, len(cfg.Rules))
+
+	for i, rc := range cfg.Rules {
+		if rc.ID == "" {
+			return nil, fmt.Errorf("risk rule #%d: id is required", i+1)
+		}
+		if seen[rc.ID] {
+			return nil, fmt.Errorf("risk rule %s: duplicate id", rc.ID)
+		}
+		seen[rc.ID] = true
+
+		decision, err := ParseDecision(rc.Decision)
+		if err != nil {
+			return nil, fmt.Errorf("risk rule %s: %w", rc.ID, err)
+		}
+
+		rule, err := buildRule(rc, decision, history)
+		if err != nil {
+			return nil, fmt.Errorf("risk rule %s: %w", rc.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func buildRule(rc RuleConfig, decision Decision, history History) (Rule, error) {
+	switch rc.Type {
+	case RuleTypeVelocity:
+		if rc.Window <= 0 || rc.MaxTransactions <= 0 {
+			return nil, errors.New("velocity rules require a positive window and max_transactions")
+		}
+		return NewVelocityRule(rc.ID, decision, history, rc.Window, rc.MaxTransactions), nil
+	case RuleTypeAmountThreshold:
+		if rc.MaxAmount <= 0 {
+			return nil, errors.New("amount_threshold rules require a positive max_amount")
+		}
+		return NewAmountThresholdRule(rc.ID, decision, rc.Origin, rc.MaxAmount), nil
+	case RuleTypeOriginChange:
+		if rc.Window <= 0 {
+			return nil, errors.New("origin_change rules require a positive window")
+		}
+		return NewOriginChangeRule(rc.ID, decision, history, rc.Window), nil
+	default:
+		return nil, fmt.Errorf("unknown rule type %q", rc.Type)
+	}
+}
@@ -0,0 +1,108 @@
+package risk
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEngine(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		content   string
+		wantRules int
+		wantErr   bool
+	}{
+		"happy path - loads every built-in rule": {
+			content: `
+rules:
+  - id: velocity
+    type: velocity
+    decision: review
+    window: 1m
+    max_transactions: 5
+  - id: amount
+    type: amount_threshold
+    decision: deny
+    origin: desktop-web
+    max_amount: 1000
+  - id: origin-change
+    type: origin_change
+    decision: review
+    window: 10m
+`,
+			wantRules: 3,
+		},
+		"failure - unknown rule type": {
+			content: `
+rules:
+  - id: unknown
+    type: geo_fencing
+    decision: deny
+`,
+			wantErr: true,
+		},
+		"failure - unknown decision": {
+			content: `
+rules:
+  - id: amount
+    type: amount_threshold
+    decision: block
+    max_amount: 1000
+`,
+			wantErr: true,
+		},
+		"failure - duplicate ids": {
+			content: `
+rules:
+  - id: amount
+    type: amount_threshold
+    decision: deny
+    max_amount: 1000
+  - id: amount
+    type: amount_threshold
+    decision: review
+    max_amount: 100
+`,
+			wantErr: true,
+		},
+		"failure - velocity rule without window": {
+			content: `
+rules:
+  - id: velocity
+    type: velocity
+    decision: deny
+    max_transactions: 5
+`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			engine, err := LoadEngine(path, nil)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, engine.rules, tc.wantRules)
+		})
+	}
+}
+
+func TestLoadEngine_WithoutFile(t *testing.T) {
+	engine, err := LoadEngine("", nil)
+	require.NoError(t, err)
+	require.Empty(t, engine.rules)
+}
+
+func TestLoadEngine_ShippedRules(t *testing.T) {
+	_, err := LoadEngine(filepath.Join("..", "..", "configs", "risk-rules.yaml"), nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,100 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"traive-engineering-challenge/internal/domain"
+)
+
+// Decision is the outcome of screening a transaction. Decisions are ordered by severity.
+type Decision int
+
+const (
+	DecisionAllow Decision = iota
+	DecisionReview
+	DecisionDeny
+)
+
+func (d Decision) String() string {
+	return [...]string{"allow", "review", "deny"}[d]
+}
+
+// ParseDecision converts the textual form used in the rules file into a Decision
+func ParseDecision(value string) (Decision, error) {
+	switch strings.ToLower(value) {
+	case "allow":
+		return DecisionAllow, nil
+	case "review":
+		return DecisionReview, nil
+	case "deny":
+		return DecisionDeny, nil
+	default:
+		return DecisionAllow, fmt.Errorf("unknown decision %q", value)
+	}
+}
+
+// Rule inspects a transaction before it is persisted.
+// Evaluate returns true when the rule is triggered, in which case the rule's Decision applies.
+type Rule interface {
+	ID() string
+	Decision() Decision
+	Evaluate(ctx context.Context, transaction domain.Transaction) (bool, error)
+}
+
+// Result aggregates the outcome of every rule: the most severe decision wins
+// and the IDs of every triggered rule are reported.
+type Result struct {
+	Decision Decision
+	RuleIDs  []string
+}
+
+// Screener decides whether a transaction can be persisted
+type Screener interface {
+	Screen(ctx context.Context, transaction domain.Transaction) (Result, error)
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine builds a rules engine. An engine without rules allows every transaction.
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Screen evaluates every rule against the transaction. Rules are evaluated in order and
+// an error in any of them aborts the screening, so a transaction is never let through unchecked.
+func (e *Engine) Screen(ctx context.Context, transaction domain.Transaction) (Result, error) {
+	result := Result{Decision: DecisionAllow}
+
+	for _, rule := range e.rules {
+		triggered, err := rule.Evaluate(ctx, transaction)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to evaluate rule %s: %w", rule.ID(), err)
+		}
+		if !triggered {
+			continue
+		}
+
+		result.RuleIDs = append(result.RuleIDs, rule.ID())
+		if rule.Decision() > result.Decision {
+			result.Decision = rule.Decision()
+		}
+	}
+
+	return result, nil
+}
+
+// DeniedError is returned when a transaction is rejected by the screening
+type DeniedError struct {
+	RuleIDs []string
+}
+
+func NewDeniedError(ruleIDs []string) DeniedError {
+	return DeniedError{RuleIDs: ruleIDs}
+}
+
+func (d DeniedError) Error() string {
+	return fmt.Sprintf("transaction denied by rules: %s", strings.Join(d.RuleIDs, ", "))
+}
@@ -0,0 +1,72 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"traive-engineering-challenge/internal/domain"
+)
+
+type stubRule struct {
+	baseRule
+	triggered bool
+	err       error
+}
+
+func (s stubRule) Evaluate(context.Context, domain.Transaction) (bool, error) {
+	return s.triggered, s.err
+}
+
+func TestEngine_Screen(t *testing.T) {
+	t.Parallel()
+
+	transaction := domain.Transaction{ID: uuid.New(), UserID: uuid.New(), Amount: 100}
+
+	testData := map[string]struct {
+		rules        []Rule
+		wantDecision Decision
+		wantRuleIDs  []string
+		wantErr      bool
+	}{
+		"no rules - allows the transaction": {
+			wantDecision: DecisionAllow,
+		},
+		"no rule triggered - allows the transaction": {
+			rules: []Rule{
+				stubRule{baseRule: baseRule{id: "a", decision: DecisionDeny}},
+			},
+			wantDecision: DecisionAllow,
+		},
+		"the most severe decision wins and every triggered rule is reported": {
+			rules: []Rule{
+				stubRule{baseRule: baseRule{id: "review", decision: DecisionReview}, triggered: true},
+				stubRule{baseRule: baseRule{id: "deny", decision: DecisionDeny}, triggered: true},
+				stubRule{baseRule: baseRule{id: "not-triggered", decision: DecisionDeny}},
+			},
+			wantDecision: DecisionDeny,
+			wantRuleIDs:  []string{"review", "deny"},
+		},
+		"failure - a rule fails": {
+			rules: []Rule{
+				stubRule{baseRule: baseRule{id: "broken", decision: DecisionReview}, err: errors.New("boom")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			result, err := NewEngine(tc.rules...).Screen(context.Background(), transaction)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantDecision, result.Decision)
+			require.Equal(t, tc.wantRuleIDs, result.RuleIDs)
+		})
+	}
+}
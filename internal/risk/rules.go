@@ -0,0 +1,114 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+// History gives rules access to the previous transactions of a user. It must read the latest committed transactions,
+// e.g. from the primary database rather than a replica.
+type History interface {
+	CountUserTransactionsSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	GetLatestUserTransaction(ctx context.Context, userID uuid.UUID) (*domain.Transaction, error)
+}
+
+type baseRule struct {
+	id       string
+	decision Decision
+}
+
+func (b baseRule) ID() string {
+	return b.id
+}
+
+func (b baseRule) Decision() Decision {
+	return b.decision
+}
+
+// VelocityRule is triggered when a user already created MaxTransactions or more within Window
+type VelocityRule struct {
+	baseRule
+	history         History
+	window          time.Duration
+	maxTransactions int
+	now             func() time.Time
+}
+
+func NewVelocityRule(id string, decision Decision, history History, window time.Duration, maxTransactions int) *VelocityRule {
+	return &VelocityRule{
+		baseRule:        baseRule{id: id, decision: decision},
+		history:         history,
+		window:          window,
+		maxTransactions: maxTransactions,
+		now:             time.Now,
+	}
+}
+
+func (v *VelocityRule) Evaluate(ctx context.Context, transaction domain.Transaction) (bool, error) {
+	count, err := v.history.CountUserTransactionsSince(ctx, transaction.UserID, v.now().Add(-v.window))
+	if err != nil {
+		return false, err
+	}
+	return count >= v.maxTransactions, nil
+}
+
+// AmountThresholdRule is triggered when the amount exceeds MaxAmount.
+// When an origin is set the rule only applies to transactions from that origin.
+type AmountThresholdRule struct {
+	baseRule
+	origin    string
+	maxAmount int64
+}
+
+func NewAmountThresholdRule(id string, decision Decision, origin string, maxAmount int64) *AmountThresholdRule {
+	return &AmountThresholdRule{
+		baseRule:  baseRule{id: id, decision: decision},
+		origin:    origin,
+		maxAmount: maxAmount,
+	}
+}
+
+func (a *AmountThresholdRule) Evaluate(_ context.Context, transaction domain.Transaction) (bool, error) {
+	if a.origin != "" && a.origin != transaction.Origin {
+		return false, nil
+	}
+	return transaction.Amount > a.maxAmount, nil
+}
+
+// OriginChangeRule is triggered when the previous transaction of the user, created within Window,
+// came from a different origin device
+type OriginChangeRule struct {
+	baseRule
+	history History
+	window  time.Duration
+	now     func() time.Time
+}
+
+func NewOriginChangeRule(id string, decision Decision, history History, window time.Duration) *OriginChangeRule {
+	return &OriginChangeRule{
+		baseRule: baseRule{id: id, decision: decision},
+		history:  history,
+		window:   window,
+		now:      time.Now,
+	}
+}
+
+func (o *OriginChangeRule) Evaluate(ctx context.Context, transaction domain.Transaction) (bool, error) {
+	latest, err := o.history.GetLatestUserTransaction(ctx, transaction.UserID)
+	if err != nil {
+		var notFound repository.NotFoundError
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if latest.CreatedAt.Before(o.now().Add(-o.window)) {
+		return false, nil
+	}
+	return latest.Origin != transaction.Origin, nil
+}
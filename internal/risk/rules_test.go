@@ -0,0 +1,127 @@
+package risk
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestVelocityRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	history := mocks.NewMockRepository(ctrl)
+	now := time.Now()
+	transaction := domain.Transaction{ID: uuid.New(), UserID: uuid.New()}
+
+	rule := NewVelocityRule("velocity", DecisionReview, history, time.Minute, 3)
+	rule.now = func() time.Time { return now }
+
+	history.EXPECT().CountUserTransactionsSince(gomock.Any(), transaction.UserID, now.Add(-time.Minute)).Return(2, nil)
+	triggered, err := rule.Evaluate(context.Background(), transaction)
+	require.NoError(t, err)
+	require.False(t, triggered)
+
+	history.EXPECT().CountUserTransactionsSince(gomock.Any(), transaction.UserID, now.Add(-time.Minute)).Return(3, nil)
+	triggered, err = rule.Evaluate(context.Background(), transaction)
+	require.NoError(t, err)
+	require.True(t, triggered)
+}
+
+func TestAmountThresholdRule(t *testing.T) {
+	t.Parallel()
+
+	testData := map[string]struct {
+		origin        string
+		transaction   domain.Transaction
+		wantTriggered bool
+	}{
+		"amount above the threshold of any origin": {
+			transaction:   domain.Transaction{Origin: support.MobileIOS, Amount: 1001},
+			wantTriggered: true,
+		},
+		"amount equal to the threshold": {
+			transaction:   domain.Transaction{Origin: support.MobileIOS, Amount: 1000},
+			wantTriggered: false,
+		},
+		"amount above the threshold of another origin": {
+			origin:        support.DesktopWeb,
+			transaction:   domain.Transaction{Origin: support.MobileIOS, Amount: 5000},
+			wantTriggered: false,
+		},
+		"amount above the threshold of the same origin": {
+			origin:        support.DesktopWeb,
+			transaction:   domain.Transaction{Origin: support.DesktopWeb, Amount: 5000},
+			wantTriggered: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			rule := NewAmountThresholdRule("amount", DecisionDeny, tc.origin, 1000)
+
+			triggered, err := rule.Evaluate(context.Background(), tc.transaction)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantTriggered, triggered)
+		})
+	}
+}
+
+func TestOriginChangeRule(t *testing.T) {
+	now := time.Now()
+	userID := uuid.New()
+	transaction := domain.Transaction{ID: uuid.New(), UserID: userID, Origin: support.MobileIOS}
+
+	testData := map[string]struct {
+		latest        *domain.Transaction
+		latestErr     error
+		wantTriggered bool
+		wantErr       bool
+	}{
+		"first transaction of the user": {
+			latestErr: repository.NewNotFoundError("not found"),
+		},
+		"recent transaction from the same origin": {
+			latest: &domain.Transaction{Origin: support.MobileIOS, CreatedAt: now.Add(-time.Minute)},
+		},
+		"recent transaction from another origin": {
+			latest:        &domain.Transaction{Origin: support.DesktopWeb, CreatedAt: now.Add(-time.Minute)},
+			wantTriggered: true,
+		},
+		"old transaction from another origin": {
+			latest: &domain.Transaction{Origin: support.DesktopWeb, CreatedAt: now.Add(-time.Hour)},
+		},
+		"failure - history lookup fails": {
+			latestErr: context.DeadlineExceeded,
+			wantErr:   true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			history := mocks.NewMockRepository(ctrl)
+			history.EXPECT().GetLatestUserTransaction(gomock.Any(), userID).Return(tc.latest, tc.latestErr)
+
+			rule := NewOriginChangeRule("origin-change", DecisionReview, history, 10*time.Minute)
+			rule.now = func() time.Time { return now }
+
+			triggered, err := rule.Evaluate(context.Background(), transaction)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantTriggered, triggered)
+		})
+	}
+}
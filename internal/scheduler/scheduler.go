@@ -0,0 +1,183 @@
+// Package scheduler materializes the due occurrences of the recurring schedules into transactions. Every instance of
+// the API may run a scheduler: the schedules are leased to one instance at a time through the repository, and the
+// run of an occurrence is stored along with its transaction, so that an occurrence processed again after an instance
+// died mid-way is not materialized twice.
+//
+// The scheduler also marks expired the active holds past their expiry. The holds stop reserving their amount as soon
+// as they expire, so this only keeps their status up to date.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"strings"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+// maxLastErrorLength is the size of the last_error column of the schedules
+const maxLastErrorLength = 1024
+
+type Config struct {
+	// Interval is how often the due schedules are claimed
+	Interval time.Duration
+	// Lease is how long a claimed schedule is reserved to the instance. An instance stops processing a schedule once
+	// half of its lease elapsed, leaving the rest of its occurrences to the next claim.
+	Lease time.Duration
+	// BatchSize is how many schedules are claimed at once
+	BatchSize int
+}
+
+// Scheduler claims the due schedules of every tenant and materializes their occurrences
+type Scheduler struct {
+	repo         repository.Repository
+	transactions service.TransactionService
+	cfg          Config
+	// owner identifies the instance holding the leases
+	owner string
+	now   func() time.Time
+}
+
+// New builds a scheduler creating the transactions through the transaction service, so that they are screened,
+// categorized and checked against the limits like the ones created through the API
+func New(repo repository.Repository, transactions service.TransactionService, cfg Config) *Scheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "scheduler"
+	}
+
+	return &Scheduler{
+		repo:         repo,
+		transactions: transactions,
+		cfg:          cfg,
+		owner:        fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8]),
+		now:          time.Now,
+	}
+}
+
+// Run materializes the due occurrences and expires the holds every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Failed to claim due schedules")
+		}
+		if _, err := s.ExpireHolds(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Failed to expire holds")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims the due schedules and materializes their due occurrences, returning how many it materialized.
+// A schedule failing on a transient error is left due, and claimed again once its lease is released.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	claimedAt := s.now()
+	schedules, err := s.repo.ClaimDueSchedules(ctx, s.owner, claimedAt, s.cfg.Lease, s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := claimedAt.Add(s.cfg.Lease / 2)
+	created := 0
+	for _, schedule := range schedules {
+		count, err := s.process(tenant.WithID(ctx, schedule.TenantID), schedule, deadline)
+		created += count
+
+		entry := log.WithField("schedule_id", schedule.ID).WithField("tenant_id", schedule.TenantID)
+		switch {
+		case errors.Is(err, repository.ErrLeaseLost):
+			entry.Info("Schedule changed while it was processed, leaving it")
+		case err != nil:
+			entry.WithError(err).Warn("Failed to process schedule, it will be retried")
+		}
+	}
+	return created, nil
+}
+
+// ExpireHolds marks expired a batch of the active holds of every tenant past their expiry, returning how many it marked
+func (s *Scheduler) ExpireHolds(ctx context.Context) (int, error) {
+	return s.repo.ExpireHolds(ctx, s.now(), s.cfg.BatchSize)
+}
+
+// process materializes the occurrences of the schedule due at the time of the claim, until the deadline, then
+// releases its lease
+func (s *Scheduler) process(ctx context.Context, schedule domain.Schedule, deadline time.Time) (int, error) {
+	defer func() {
+		if err := s.repo.ReleaseSchedule(ctx, schedule.ID, s.owner); err != nil {
+			log.WithError(err).WithField("schedule_id", schedule.ID).Warn("Failed to release schedule, it is claimed again once its lease expires")
+		}
+	}()
+
+	created := 0
+	for schedule.NextRunAt != nil && !schedule.NextRunAt.After(s.now()) && s.now().Before(deadline) {
+		occurrence := *schedule.NextRunAt
+		lastError, err := s.materialize(ctx, schedule, occurrence)
+		if err != nil {
+			return created, err
+		}
+
+		nextRunAt, err := schedule.NextRun(occurrence)
+		if err != nil {
+			return created, err
+		}
+		if err := s.repo.AdvanceSchedule(ctx, schedule.ID, s.owner, occurrence, nextRunAt, lastError); err != nil {
+			return created, err
+		}
+
+		if lastError == "" {
+			created++
+		}
+		schedule.NextRunAt = nextRunAt
+	}
+	return created, nil
+}
+
+// materialize creates the transaction of the occurrence, unless its run was already recorded. It returns why the
+// transaction was rejected, which skips the occurrence, or an error when the occurrence must be retried.
+func (s *Scheduler) materialize(ctx context.Context, schedule domain.Schedule, occurrence time.Time) (string, error) {
+	// an instance that lost its lease may have materialized the occurrence without advancing the schedule; looking
+	// its run up first keeps the limits and the risk rules from rejecting it because of itself
+	materialized, err := s.repo.HasScheduleRun(ctx, schedule.ID, occurrence)
+	if err != nil {
+		return "", err
+	}
+	if materialized {
+		return "", nil
+	}
+
+	_, err = s.transactions.CreateTransaction(ctx, schedule.Transaction(occurrence))
+	var (
+		validation        domain.ValidationError
+		denied            risk.DeniedError
+		limitExceeded     domain.LimitExceededError
+		insufficientFunds domain.InsufficientFundsError
+	)
+	switch {
+	case err == nil, errors.Is(err, repository.ErrScheduleRunExists):
+		return "", nil
+	case errors.As(err, &validation), errors.As(err, &denied), errors.As(err, &limitExceeded),
+		errors.As(err, &insufficientFunds):
+		lastError := err.Error()
+		if len(lastError) > maxLastErrorLength {
+			lastError = strings.ToValidUTF8(lastError[:maxLastErrorLength], "")
+		}
+		return lastError, nil
+	default:
+		return "", err
+	}
+}
@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/memory"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/service"
+	"traive-engineering-challenge/internal/tenant"
+)
+
+var testConfig = Config{Interval: time.Second, Lease: time.Minute, BatchSize: 10}
+
+func newScheduler(repo repository.Repository, now time.Time, rules ...risk.Rule) *Scheduler {
+	s := New(repo, service.NewTransactionService(repo, risk.NewEngine(rules...)), testConfig)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func createSchedule(t *testing.T, ctx context.Context, repo repository.Repository, schedule domain.Schedule) domain.Schedule {
+	schedule.ID = uuid.New()
+	schedule.UserID = uuid.New()
+	schedule.Origin = domain.OriginDesktopWeb
+	schedule.TransactionType = domain.TransactionTypeDebit
+	schedule.Status = domain.ScheduleStatusActive
+	nextRunAt, err := schedule.FirstRun()
+	require.NoError(t, err)
+	schedule.NextRunAt = nextRunAt
+
+	created, err := repo.CreateSchedule(ctx, schedule)
+	require.NoError(t, err)
+	return *created
+}
+
+func scheduledTransactions(t *testing.T, ctx context.Context, repo repository.Repository) []domain.Transaction {
+	transactions, err := repo.ListTransactions(repository.WithPage(ctx, 1, 1000))
+	require.NoError(t, err)
+	return transactions
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 2).Add(time.Hour)
+	ctx := tenant.WithID(context.Background(), "acme")
+
+	t.Run("it materializes every due occurrence once", func(t *testing.T) {
+		repo := memory.New()
+		schedule := createSchedule(t, ctx, repo, domain.Schedule{Amount: 999, RRule: "FREQ=DAILY", StartAt: start})
+
+		created, err := newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 3, created)
+
+		created, err = newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, created)
+
+		transactions := scheduledTransactions(t, ctx, repo)
+		require.Len(t, transactions, 3)
+		for _, transaction := range transactions {
+			require.Equal(t, schedule.ID.String(), transaction.Metadata[domain.ScheduleIDMetadataKey])
+			require.Equal(t, int64(999), transaction.Amount)
+		}
+
+		got, err := repo.GetSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, 3, got.RunCount)
+		require.Equal(t, start.AddDate(0, 0, 2), *got.LastRunAt)
+		require.Equal(t, start.AddDate(0, 0, 3), *got.NextRunAt)
+	})
+
+	t.Run("it materializes an occurrence once across concurrent schedulers", func(t *testing.T) {
+		repo := memory.New()
+		for i := 0; i < 5; i++ {
+			createSchedule(t, ctx, repo, domain.Schedule{Amount: 100, Cron: "0 * * * *", StartAt: start})
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := newScheduler(repo, now).RunOnce(context.Background())
+				require.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		// the hourly occurrences from the start to now, included
+		require.Len(t, scheduledTransactions(t, ctx, repo), 5*(2*24+2))
+	})
+
+	t.Run("it does not create again an occurrence created before the schedule advanced", func(t *testing.T) {
+		repo := memory.New()
+		schedule := createSchedule(t, ctx, repo, domain.Schedule{Amount: 100, RRule: "FREQ=DAILY", StartAt: start})
+		_, err := repo.CreateTransaction(ctx, schedule.Transaction(start))
+		require.NoError(t, err)
+
+		_, err = newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Len(t, scheduledTransactions(t, ctx, repo), 3)
+	})
+
+	t.Run("it is not kept from materializing an occurrence by a transaction created like it", func(t *testing.T) {
+		repo := memory.New()
+		schedule := createSchedule(t, ctx, repo, domain.Schedule{Amount: 100, RRule: "FREQ=DAILY", StartAt: start})
+		lookalike := schedule.Transaction(start)
+		lookalike.ScheduleRun, lookalike.TransactionType, lookalike.Amount = nil, domain.TransactionTypeCredit, 1
+		_, err := repo.CreateTransaction(ctx, lookalike)
+		require.NoError(t, err)
+
+		created, err := newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 3, created)
+		require.Len(t, scheduledTransactions(t, ctx, repo), 4)
+	})
+
+	t.Run("it schedules the transactions in the currency of the schedule", func(t *testing.T) {
+		repo := memory.New()
+		createSchedule(t, ctx, repo, domain.Schedule{Amount: 100, Currency: "EUR", RRule: "FREQ=DAILY;COUNT=1", StartAt: start})
+
+		_, err := newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		transactions := scheduledTransactions(t, ctx, repo)
+		require.Len(t, transactions, 1)
+		require.Equal(t, "EUR", transactions[0].Currency)
+	})
+
+	t.Run("it skips a rejected occurrence and records why", func(t *testing.T) {
+		repo := memory.New()
+		schedule := createSchedule(t, ctx, repo, domain.Schedule{Amount: 5000, RRule: "FREQ=DAILY", StartAt: start})
+
+		created, err := newScheduler(repo, now, risk.NewAmountThresholdRule("big-debit", risk.DecisionDeny, "", 1000)).
+			RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, created)
+		require.Empty(t, scheduledTransactions(t, ctx, repo))
+
+		got, err := repo.GetSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, 3, got.RunCount)
+		require.Contains(t, got.LastError, "big-debit")
+		require.Equal(t, start.AddDate(0, 0, 3), *got.NextRunAt)
+	})
+
+	t.Run("it skips an occurrence that would take held funds", func(t *testing.T) {
+		repo := memory.New()
+		schedule := createSchedule(t, ctx, repo, domain.Schedule{Amount: 100, RRule: "FREQ=DAILY", StartAt: start})
+		_, err := repo.CreateTransaction(ctx, domain.Transaction{
+			ID: uuid.New(), UserID: schedule.UserID, Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeCredit, Amount: 150,
+		})
+		require.NoError(t, err)
+		_, err = repo.CreateHold(ctx, domain.Hold{
+			ID: uuid.New(), UserID: schedule.UserID, Origin: domain.OriginDesktopWeb, Amount: 100, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		created, err := newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, created)
+
+		got, err := repo.GetSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, 3, got.RunCount)
+		require.Contains(t, got.LastError, "insufficient funds")
+	})
+
+	t.Run("it completes a schedule whose recurrence ended", func(t *testing.T) {
+		repo := memory.New()
+		schedule := createSchedule(t, ctx, repo, domain.Schedule{Amount: 100, RRule: "FREQ=DAILY;COUNT=2", StartAt: start})
+
+		created, err := newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, created)
+
+		got, err := repo.GetSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+		require.Equal(t, domain.ScheduleStatusCompleted, got.Status)
+		require.Nil(t, got.NextRunAt)
+	})
+
+	t.Run("it skips a paused schedule", func(t *testing.T) {
+		repo := memory.New()
+		schedule := createSchedule(t, ctx, repo, domain.Schedule{Amount: 100, RRule: "FREQ=DAILY", StartAt: start})
+		_, err := repo.UpdateScheduleStatus(ctx, schedule.ID,
+			[]domain.ScheduleStatus{domain.ScheduleStatusActive}, domain.ScheduleStatusPaused, nil)
+		require.NoError(t, err)
+
+		created, err := newScheduler(repo, now).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, created)
+		require.Empty(t, scheduledTransactions(t, ctx, repo))
+	})
+}
+
+func TestScheduler_ExpireHolds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	ctx := tenant.WithID(context.Background(), "acme")
+	repo := memory.New()
+	userID := uuid.New()
+
+	_, err := repo.CreateTransaction(ctx, domain.Transaction{ID: uuid.New(), UserID: userID, Origin: domain.OriginDesktopWeb,
+		TransactionType: domain.TransactionTypeCredit, Amount: 100, Status: domain.TransactionStatusCompleted})
+	require.NoError(t, err)
+
+	createHold := func(expiresAt time.Time) domain.Hold {
+		created, err := repo.CreateHold(ctx, domain.Hold{ID: uuid.New(), UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 10,
+			Status: domain.HoldStatusActive, ExpiresAt: expiresAt})
+		require.NoError(t, err)
+		return *created
+	}
+	expired := createHold(now.Add(-time.Minute))
+	active := createHold(now.Add(time.Hour))
+
+	count, err := newScheduler(repo, now).ExpireHolds(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	for hold, want := range map[uuid.UUID]domain.HoldStatus{expired.ID: domain.HoldStatusExpired, active.ID: domain.HoldStatusActive} {
+		got, err := repo.GetHold(ctx, hold)
+		require.NoError(t, err)
+		require.Equal(t, want, got.Status)
+	}
+}
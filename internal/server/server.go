@@ -0,0 +1,107 @@
+// Package server runs the HTTP servers of the API and stops them gracefully
+package server
+
+import (
+	"context"
+	"errors"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Addr string
+	// ReadTimeout bounds the reading of a whole request, body included
+	ReadTimeout time.Duration
+	// ReadHeaderTimeout bounds the reading of the headers of a request, protecting against slow clients
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout bounds the handling of a request, from the end of the reading of its headers to the end of the response
+	WriteTimeout time.Duration
+	// IdleTimeout is how long keep-alive connections are kept open between requests
+	IdleTimeout time.Duration
+}
+
+// New builds a server of the handler listening on the address of the config
+func New(cfg Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// Serve listens on the address of the server in a goroutine. The error that stops the server is sent to errs,
+// unless the server was shut down.
+func Serve(srv *http.Server, errs chan<- error) {
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+}
+
+// Step is a stage of the shutdown of the application
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Shutdown runs the steps in order, all of them within the deadline of ctx. A step that fails is logged and does not
+// prevent the next ones from running, so that telemetry is flushed and connections are closed whatever happened before.
+func Shutdown(ctx context.Context, steps ...Step) error {
+	var errs []error
+	for _, step := range steps {
+		start := time.Now()
+		if err := step.Run(ctx); err != nil {
+			log.WithError(err).WithField("step", step.Name).Error("Shutdown step failed")
+			errs = append(errs, err)
+			continue
+		}
+		log.WithField("step", step.Name).WithField("duration", time.Since(start).String()).Info("Shutdown step completed")
+	}
+	return errors.Join(errs...)
+}
+
+// Workers runs background workers until they are stopped
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{ctx: ctx, cancel: cancel}
+}
+
+// Go runs the worker in a goroutine. The context passed to the worker is cancelled when the workers are stopped,
+// and the worker must then return.
+func (w *Workers) Go(run func(ctx context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		run(w.ctx)
+	}()
+}
+
+// Stop cancels the workers and waits for them to return, giving up when ctx is done
+func (w *Workers) Stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) Step {
+		return Step{Name: name, Run: func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	err := Shutdown(context.Background(),
+		step("server", nil),
+		step("telemetry", errors.New("collector unavailable")),
+		step("database", nil),
+	)
+	require.EqualError(t, err, "collector unavailable")
+	require.Equal(t, []string{"server", "telemetry", "database"}, ran, "every step should run, in order")
+}
+
+// TestShutdown_DrainsRequests checks that requests in flight when the shutdown starts are completed
+func TestShutdown_DrainsRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := New(Config{ReadHeaderTimeout: time.Second}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(listener) }()
+
+	type response struct {
+		body string
+		err  error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			responses <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- response{body: string(body), err: err}
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- Shutdown(context.Background(), Step{Name: "server", Run: srv.Shutdown})
+	}()
+
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown should wait for the request in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-shutdown)
+	resp := <-responses
+	require.NoError(t, resp.err)
+	require.Equal(t, "done", resp.body)
+
+	_, err = http.Get("http://" + listener.Addr().String())
+	require.Error(t, err, "new connections should be refused once shut down")
+}
+
+func TestWorkers_Stop(t *testing.T) {
+	tests := map[string]struct {
+		worker  func(ctx context.Context)
+		wantErr error
+	}{
+		"it waits for the workers to return": {
+			worker: func(ctx context.Context) {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+			},
+		},
+		"it gives up on workers that do not return in time": {
+			worker: func(ctx context.Context) {
+				<-ctx.Done()
+				time.Sleep(time.Second)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			workers := NewWorkers()
+			workers.Go(tc.worker)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			require.ErrorIs(t, workers.Stop(ctx), tc.wantErr)
+		})
+	}
+}
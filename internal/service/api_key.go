@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+// API keys look like tk_<prefix>_<secret>, where both parts are hex encoded random bytes
+const (
+	apiKeyMarker      = "tk"
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+)
+
+func (a apiKeyService) CreateAPIKey(ctx context.Context, request domain.APIKeyRequest) (*domain.IssuedAPIKey, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, plaintext, err := newAPIKey(request.Name, request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := a.repo.CreateAPIKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.IssuedAPIKey{APIKey: *created, Key: plaintext}, nil
+}
+
+func (a apiKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return a.repo.ListAPIKeys(ctx)
+}
+
+// RotateAPIKey revokes a key and issues a new one with the same name and scopes
+func (a apiKeyService) RotateAPIKey(ctx context.Context, id uuid.UUID) (*domain.IssuedAPIKey, error) {
+	current, err := a.repo.GetAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.IsRevoked() {
+		return nil, repository.NewNotFoundError("api key not found")
+	}
+
+	replacement, plaintext, err := newAPIKey(current.Name, current.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := a.repo.RotateAPIKey(ctx, id, replacement)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.IssuedAPIKey{APIKey: *rotated, Key: plaintext}, nil
+}
+
+func (a apiKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	return a.repo.RevokeAPIKey(ctx, id)
+}
+
+// Authenticate resolves a plaintext key into the stored key and records its use.
+// It returns domain.ErrInvalidAPIKey for malformed, unknown and revoked keys.
+func (a apiKeyService) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	if a.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(a.bootstrapKey)) == 1 {
+		return &domain.APIKey{Name: "bootstrap", Scopes: []string{domain.ScopeAdmin}}, nil
+	}
+
+	prefix, ok := parseAPIKeyPrefix(key)
+	if !ok {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	stored, err := a.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		var notFound repository.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(stored.KeyHash)) != 1 || stored.IsRevoked() {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if err := a.repo.TouchAPIKey(ctx, stored.ID, now); err != nil {
+		// failing to record the last use must not deny access
+		log.WithError(err).WithField("api_key_id", stored.ID).Warn("Failed to record api key usage")
+	}
+	stored.LastUsedAt = &now
+
+	return stored, nil
+}
+
+func newAPIKey(name string, scopes []string) (domain.APIKey, string, error) {
+	prefix, err := randomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	plaintext := fmt.Sprintf("%s_%s_%s", apiKeyMarker, prefix, secret)
+	return domain.APIKey{
+		ID:      uuid.New(),
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: hashAPIKey(plaintext),
+		Scopes:  scopes,
+	}, plaintext, nil
+}
+
+func parseAPIKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyMarker || len(parts[1]) != 2*apiKeyPrefixBytes || len(parts[2]) != 2*apiKeySecretBytes {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// hashAPIKey hashes a key with SHA-256. Keys carry 256 bits of randomness, so a fast unsalted hash is sufficient.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
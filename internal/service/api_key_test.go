@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/mocks"
+)
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	svc := NewAPIKeyService(repo, "")
+
+	var stored domain.APIKey
+	repo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, key domain.APIKey) (*domain.APIKey, error) {
+			stored = key
+			return &key, nil
+		},
+	)
+
+	issued, err := svc.CreateAPIKey(context.Background(), domain.APIKeyRequest{Name: "billing", Scopes: []string{domain.ScopeTransactionsRead}})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(issued.Key, "tk_"+stored.Prefix+"_"))
+	require.NotContains(t, stored.KeyHash, issued.Key)
+
+	repo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), stored.Prefix).Return(&stored, nil).Times(2)
+	repo.EXPECT().TouchAPIKey(gomock.Any(), stored.ID, gomock.Any()).Return(nil)
+
+	authenticated, err := svc.Authenticate(context.Background(), issued.Key)
+	require.NoError(t, err)
+	require.Equal(t, stored.ID, authenticated.ID)
+	require.NotNil(t, authenticated.LastUsedAt)
+
+	tampered := issued.Key[:len(issued.Key)-1] + "0"
+	if tampered == issued.Key {
+		tampered = issued.Key[:len(issued.Key)-1] + "1"
+	}
+	_, err = svc.Authenticate(context.Background(), tampered)
+	require.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	revokedAt := time.Now()
+
+	testData := map[string]struct {
+		bootstrapKey string
+		key          string
+		prepareRepo  func(repo *mocks.MockRepository)
+		wantScopes   []string
+		wantErr      error
+	}{
+		"the bootstrap key is an admin key": {
+			bootstrapKey: "bootstrap-secret",
+			key:          "bootstrap-secret",
+			prepareRepo:  func(repo *mocks.MockRepository) {},
+			wantScopes:   []string{domain.ScopeAdmin},
+		},
+		"malformed keys are rejected without a lookup": {
+			key:         "not-a-key",
+			prepareRepo: func(repo *mocks.MockRepository) {},
+			wantErr:     domain.ErrInvalidAPIKey,
+		},
+		"unknown keys are rejected": {
+			key: "tk_" + strings.Repeat("a", 12) + "_" + strings.Repeat("b", 64),
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), strings.Repeat("a", 12)).Return(nil, repository.NewNotFoundError("not found"))
+			},
+			wantErr: domain.ErrInvalidAPIKey,
+		},
+		"revoked keys are rejected": {
+			key: "tk_" + strings.Repeat("a", 12) + "_" + strings.Repeat("b", 64),
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().GetAPIKeyByPrefix(gomock.Any(), strings.Repeat("a", 12)).Return(&domain.APIKey{
+					KeyHash:   hashAPIKey("tk_" + strings.Repeat("a", 12) + "_" + strings.Repeat("b", 64)),
+					RevokedAt: &revokedAt,
+				}, nil)
+			},
+			wantErr: domain.ErrInvalidAPIKey,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			tc.prepareRepo(repo)
+
+			key, err := NewAPIKeyService(repo, tc.bootstrapKey).Authenticate(context.Background(), tc.key)
+			if tc.wantErr != nil {
+				require.True(t, errors.Is(err, tc.wantErr))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantScopes, key.Scopes)
+		})
+	}
+}
+
+func TestAPIKeyService_RotateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	current := domain.APIKey{Name: "billing", Scopes: []string{domain.ScopeTransactionsWrite}}
+
+	repo.EXPECT().GetAPIKey(gomock.Any(), current.ID).Return(&current, nil)
+	repo.EXPECT().RotateAPIKey(gomock.Any(), current.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, replacement domain.APIKey) (*domain.APIKey, error) {
+			return &replacement, nil
+		},
+	)
+
+	rotated, err := NewAPIKeyService(repo, "").RotateAPIKey(context.Background(), current.ID)
+	require.NoError(t, err)
+	require.Equal(t, current.Name, rotated.Name)
+	require.Equal(t, current.Scopes, rotated.Scopes)
+	require.NotEqual(t, current.ID, rotated.ID)
+	require.NotEmpty(t, rotated.Key)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+func (c categoryService) CreateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	category.ID = uuid.New()
+	if err := c.validateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return c.repo.CreateCategory(ctx, category)
+}
+
+func (c categoryService) GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	return c.repo.GetCategory(ctx, id)
+}
+
+func (c categoryService) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	return c.repo.ListCategories(ctx)
+}
+
+// UpdateCategory renames a category or moves it to another parent, which cannot be one of its own subcategories
+func (c categoryService) UpdateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	if err := c.validateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return c.repo.UpdateCategory(ctx, category)
+}
+
+// DeleteCategory deletes a category without subcategories nor rules; its transactions are left uncategorized
+func (c categoryService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	return c.repo.DeleteCategory(ctx, id)
+}
+
+func (c categoryService) CreateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	if err := c.validateCategoryRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = uuid.New()
+	return c.repo.CreateCategoryRule(ctx, rule)
+}
+
+func (c categoryService) GetCategoryRule(ctx context.Context, id uuid.UUID) (*domain.CategoryRule, error) {
+	return c.repo.GetCategoryRule(ctx, id)
+}
+
+// ListCategoryRules retrieves the rules in the order they are evaluated
+func (c categoryService) ListCategoryRules(ctx context.Context) ([]domain.CategoryRule, error) {
+	return c.repo.ListCategoryRules(ctx)
+}
+
+// UpdateCategoryRule replaces the conditions of a rule; the transactions it already categorized keep their category
+func (c categoryService) UpdateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	if err := c.validateCategoryRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return c.repo.UpdateCategoryRule(ctx, rule)
+}
+
+func (c categoryService) DeleteCategoryRule(ctx context.Context, id uuid.UUID) error {
+	return c.repo.DeleteCategoryRule(ctx, id)
+}
+
+// validateCategory checks the category and that its parent exists outside of its subtree, so that the categories
+// remain a tree
+func (c categoryService) validateCategory(ctx context.Context, category domain.Category) error {
+	var problems []string
+	var validationErr domain.ValidationError
+	if err := category.Validate(); errors.As(err, &validationErr) {
+		problems = append(problems, validationErr.Problems...)
+	}
+
+	if category.ParentID != nil && *category.ParentID != category.ID {
+		categories, err := c.repo.ListCategories(ctx)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !containsCategory(categories, *category.ParentID):
+			problems = append(problems, "parent_id does not exist")
+		case containsID(domain.CategorySubtree(categories, category.ID), *category.ParentID):
+			problems = append(problems, "parent_id cannot be one of the subcategories of the category")
+		}
+	}
+
+	if len(problems) > 0 {
+		return domain.NewValidationError(problems)
+	}
+	return nil
+}
+
+// validateCategoryRule checks the rule and that its category exists
+func (c categoryService) validateCategoryRule(ctx context.Context, rule domain.CategoryRule) error {
+	var problems []string
+	var validationErr domain.ValidationError
+	if err := rule.Validate(); errors.As(err, &validationErr) {
+		problems = append(problems, validationErr.Problems...)
+	}
+
+	if rule.CategoryID != uuid.Nil {
+		var notFound repository.NotFoundError
+		if _, err := c.repo.GetCategory(ctx, rule.CategoryID); errors.As(err, &notFound) {
+			problems = append(problems, "category_id does not exist")
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(problems) > 0 {
+		return domain.NewValidationError(problems)
+	}
+	return nil
+}
+
+func containsCategory(categories []domain.Category, id uuid.UUID) bool {
+	for _, category := range categories {
+		if category.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
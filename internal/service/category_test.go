@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/mocks"
+)
+
+func TestCategoryService_UpdateCategory(t *testing.T) {
+	food := domain.Category{ID: uuid.New(), Name: "food"}
+	groceries := domain.Category{ID: uuid.New(), Name: "groceries", ParentID: &food.ID}
+	organic := domain.Category{ID: uuid.New(), Name: "organic", ParentID: &groceries.ID}
+	travel := domain.Category{ID: uuid.New(), Name: "travel"}
+	unknown := uuid.New()
+
+	testData := map[string]struct {
+		category     domain.Category
+		wantProblems []string
+	}{
+		"categories can be moved to another parent": {
+			category: domain.Category{ID: groceries.ID, Name: "groceries", ParentID: &travel.ID},
+		},
+		"categories can become roots": {
+			category: domain.Category{ID: groceries.ID, Name: "groceries"},
+		},
+		"failure - the parent does not exist": {
+			category:     domain.Category{ID: groceries.ID, Name: "groceries", ParentID: &unknown},
+			wantProblems: []string{"parent_id does not exist"},
+		},
+		"failure - the parent is a subcategory of the category": {
+			category:     domain.Category{ID: food.ID, Name: "food", ParentID: &organic.ID},
+			wantProblems: []string{"parent_id cannot be one of the subcategories of the category"},
+		},
+		"failure - the parent is the category itself": {
+			category:     domain.Category{ID: food.ID, Name: "food", ParentID: &food.ID},
+			wantProblems: []string{"parent_id cannot be the category itself"},
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			repo.EXPECT().ListCategories(gomock.Any()).Return([]domain.Category{food, groceries, organic, travel}, nil).AnyTimes()
+			if tc.wantProblems == nil {
+				repo.EXPECT().UpdateCategory(gomock.Any(), tc.category).Return(&tc.category, nil)
+			}
+
+			_, err := NewCategoryService(repo).UpdateCategory(context.Background(), tc.category)
+			if tc.wantProblems != nil {
+				var validationErr domain.ValidationError
+				require.True(t, errors.As(err, &validationErr))
+				require.Equal(t, tc.wantProblems, validationErr.Problems)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCategoryService_CreateCategoryRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	categoryID := uuid.New()
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().GetCategory(gomock.Any(), categoryID).Return(nil, repository.NewNotFoundError("category not found"))
+	svc := NewCategoryService(repo)
+
+	_, err := svc.CreateCategoryRule(context.Background(), domain.CategoryRule{CategoryID: categoryID, DescriptionPattern: "("})
+	var validationErr domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Problems, 2)
+	require.Equal(t, "category_id does not exist", validationErr.Problems[1])
+
+	repo.EXPECT().GetCategory(gomock.Any(), categoryID).Return(&domain.Category{ID: categoryID, Name: "food"}, nil)
+	repo.EXPECT().CreateCategoryRule(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) { return &rule, nil },
+	)
+	created, err := svc.CreateCategoryRule(context.Background(), domain.CategoryRule{CategoryID: categoryID, DescriptionPattern: "(?i)grocer"})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, created.ID)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+// LoadRates validates and stores a snapshot of rates, effective from now when its effective_at is not set
+func (s fxService) LoadRates(ctx context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error) {
+	if err := snapshot.Validate(); err != nil {
+		return nil, err
+	}
+
+	snapshot.ID = uuid.New()
+	if snapshot.EffectiveAt.IsZero() {
+		snapshot.EffectiveAt = s.now()
+	}
+	snapshot.EffectiveAt = snapshot.EffectiveAt.UTC()
+	return s.repo.CreateRateSnapshot(ctx, snapshot)
+}
+
+// ListRateSnapshots retrieves the snapshots of the tenant, the latest effective first
+func (s fxService) ListRateSnapshots(ctx context.Context) ([]domain.RateSnapshot, error) {
+	return s.repo.ListRateSnapshots(ctx)
+}
+
+// GetRates retrieves the snapshot in effect now. It returns a repository.NotFoundError when no rates are loaded.
+func (s fxService) GetRates(ctx context.Context) (*domain.RateSnapshot, error) {
+	return s.repo.GetEffectiveRateSnapshot(ctx, s.now())
+}
+
+// CreateQuote converts the source amount at the rate of the snapshot in effect now, and locks that rate for the quote
+// TTL. Callers restricted to their own data can only quote conversions of their own balance.
+func (s fxService) CreateQuote(ctx context.Context, request domain.FXQuoteRequest) (*domain.FXQuote, error) {
+	if userID, restricted := restrictedUserID(ctx); restricted && request.UserID != userID {
+		return nil, domain.NewValidationError([]string{"user_id must be the user of the caller"})
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	snapshot, err := s.repo.GetEffectiveRateSnapshot(ctx, now)
+	if err != nil {
+		var notFound repository.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, domain.NewValidationError([]string{"no exchange rates are loaded"})
+		}
+		return nil, err
+	}
+
+	rate, ok := snapshot.Rate(request.SourceCurrency, request.TargetCurrency)
+	if !ok {
+		return nil, domain.NewValidationError([]string{fmt.Sprintf("no rate from %s to %s", request.SourceCurrency, request.TargetCurrency)})
+	}
+	value, err := domain.ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+	targetAmount, err := domain.ConvertAmount(request.SourceAmount, request.SourceCurrency, request.TargetCurrency, value)
+	if err != nil {
+		return nil, err
+	}
+	if targetAmount <= 0 {
+		return nil, domain.NewValidationError([]string{"source_amount is too small to convert"})
+	}
+
+	return s.repo.CreateFXQuote(ctx, domain.FXQuote{
+		ID:             uuid.New(),
+		UserID:         request.UserID,
+		SourceCurrency: request.SourceCurrency,
+		TargetCurrency: request.TargetCurrency,
+		SourceAmount:   request.SourceAmount,
+		TargetAmount:   targetAmount,
+		Rate:           rate,
+		SnapshotID:     snapshot.ID,
+		ExpiresAt:      now.UTC().Add(s.quoteTTL),
+	})
+}
+
+// Convert converts a quote that has not expired into a debit of its source amount and a credit of its target amount,
+// at its rate. It returns an error wrapping domain.ErrQuoteUnavailable when the quote expired or was already
+// converted, a domain.InsufficientFundsError when the available balance of the user in the source currency does not
+// cover the debit, and a domain.LimitExceededError when the debit would exceed a limit. The debit is screened and
+// categorized like the transactions created through the transaction service: a denied conversion is rejected with a
+// risk.DeniedError, and the debit of a conversion that needs a review is stored as pending review.
+func (s fxService) Convert(ctx context.Context, request domain.FXConversionRequest) (*domain.FXConversion, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	quote, err := s.repo.GetFXQuote(ctx, request.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if userID, restricted := restrictedUserID(ctx); restricted && quote.UserID != userID {
+		return nil, repository.NewNotFoundError("quote not found")
+	}
+	if quote.Expired(s.now()) {
+		return nil, fmt.Errorf("%w: the quote expired", domain.ErrQuoteUnavailable)
+	}
+
+	conversion := domain.NewFXConversion(*quote, request.Origin)
+	debit, credit := conversion.Transactions()
+	if err := s.transactions.prepare(ctx, &debit); err != nil {
+		return nil, err
+	}
+	limits, err := applicableLimits(ctx, s.repo, debit.UserID, debit.Origin, debit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := s.repo.CreateFXConversion(ctx, conversion, debit, credit, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	s.transactions.metrics.recordCreated(ctx, &debit)
+	s.transactions.metrics.recordCreated(ctx, &credit)
+	return converted, nil
+}
+
+// GetConversion retrieves a conversion. Callers restricted to their own data get a repository.NotFoundError for the
+// conversions of other users.
+func (s fxService) GetConversion(ctx context.Context, id uuid.UUID) (*domain.FXConversion, error) {
+	conversion, err := s.repo.GetFXConversion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID, restricted := restrictedUserID(ctx); restricted && conversion.UserID != userID {
+		return nil, repository.NewNotFoundError("conversion not found")
+	}
+	return conversion, nil
+}
+
+// ListConversions retrieves the conversions of the tenant, only their own ones for the callers restricted to their
+// own data
+func (s fxService) ListConversions(ctx context.Context) ([]domain.FXConversion, error) {
+	var user *uuid.UUID
+	if userID, restricted := restrictedUserID(ctx); restricted {
+		user = &userID
+	}
+	return s.repo.ListFXConversions(ctx, user)
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/mocks"
+	"traive-engineering-challenge/internal/risk"
+)
+
+func TestFXService_CreateQuote(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	restricted := auth.WithPrincipal(context.Background(), auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, UserID: &userID})
+	snapshot := domain.RateSnapshot{
+		ID:          uuid.New(),
+		EffectiveAt: now.Add(-time.Hour),
+		Rates: []domain.FXRate{
+			{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.085"},
+			{BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: "149.5"},
+			{BaseCurrency: "KRW", QuoteCurrency: "USD", Rate: "0.00075"},
+		},
+	}
+
+	testData := map[string]struct {
+		ctx              context.Context
+		request          domain.FXQuoteRequest
+		snapshotErr      error
+		wantRate         string
+		wantTargetAmount int64
+		wantProblem      string
+	}{
+		"it converts at the rate of the pair": {
+			ctx:              context.Background(),
+			request:          domain.FXQuoteRequest{UserID: userID, SourceCurrency: "EUR", TargetCurrency: "USD", SourceAmount: 10000},
+			wantRate:         "1.085",
+			wantTargetAmount: 10850,
+		},
+		"it converts to a currency without minor unit": {
+			ctx:              restricted,
+			request:          domain.FXQuoteRequest{UserID: userID, SourceCurrency: "USD", TargetCurrency: "JPY", SourceAmount: 1001},
+			wantRate:         "149.5",
+			wantTargetAmount: 1496,
+		},
+		"it converts at the inverse rate of the reverse pair": {
+			ctx:              context.Background(),
+			request:          domain.FXQuoteRequest{UserID: userID, SourceCurrency: "JPY", TargetCurrency: "USD", SourceAmount: 14950},
+			wantRate:         "0.006688963211",
+			wantTargetAmount: 10000,
+		},
+		"it rejects a pair without rate": {
+			ctx:         context.Background(),
+			request:     domain.FXQuoteRequest{UserID: userID, SourceCurrency: "GBP", TargetCurrency: "USD", SourceAmount: 100},
+			wantProblem: "no rate from GBP to USD",
+		},
+		"it rejects an amount converting to nothing": {
+			ctx:         context.Background(),
+			request:     domain.FXQuoteRequest{UserID: userID, SourceCurrency: "KRW", TargetCurrency: "USD", SourceAmount: 6},
+			wantProblem: "source_amount is too small to convert",
+		},
+		"it rejects a quote when no rates are loaded": {
+			ctx:         context.Background(),
+			request:     domain.FXQuoteRequest{UserID: userID, SourceCurrency: "EUR", TargetCurrency: "USD", SourceAmount: 100},
+			snapshotErr: repository.NewNotFoundError("rate snapshot not found"),
+			wantProblem: "no exchange rates are loaded",
+		},
+		"it rejects the quotes of another user for a restricted caller": {
+			ctx:         restricted,
+			request:     domain.FXQuoteRequest{UserID: uuid.New(), SourceCurrency: "EUR", TargetCurrency: "USD", SourceAmount: 100},
+			wantProblem: "user_id must be the user of the caller",
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			if tc.request.UserID == userID {
+				if tc.snapshotErr != nil {
+					repo.EXPECT().GetEffectiveRateSnapshot(gomock.Any(), now).Return(nil, tc.snapshotErr)
+				} else {
+					repo.EXPECT().GetEffectiveRateSnapshot(gomock.Any(), now).Return(&snapshot, nil)
+				}
+			}
+			if tc.wantProblem == "" {
+				repo.EXPECT().CreateFXQuote(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, quote domain.FXQuote) (*domain.FXQuote, error) { return &quote, nil },
+				)
+			}
+			svc := fxService{repo: repo, quoteTTL: 30 * time.Second, now: func() time.Time { return now }}
+
+			quote, err := svc.CreateQuote(tc.ctx, tc.request)
+			if tc.wantProblem != "" {
+				var validationErr domain.ValidationError
+				require.True(t, errors.As(err, &validationErr))
+				require.Equal(t, []string{tc.wantProblem}, validationErr.Problems)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRate, quote.Rate)
+			require.Equal(t, tc.wantTargetAmount, quote.TargetAmount)
+			require.Equal(t, snapshot.ID, quote.SnapshotID)
+			require.Equal(t, now.Add(30*time.Second), quote.ExpiresAt)
+		})
+	}
+}
+
+func TestFXService_Convert(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	quote := domain.FXQuote{
+		ID:             uuid.New(),
+		UserID:         userID,
+		SourceCurrency: "EUR",
+		TargetCurrency: "USD",
+		SourceAmount:   10000,
+		TargetAmount:   10850,
+		Rate:           "1.085",
+		ExpiresAt:      now.Add(10 * time.Second),
+	}
+	otherUserID := uuid.New()
+	eur := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodDaily, MaxAmount: 50000, Currency: "EUR"}
+	usd := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeUser, UserID: &userID, Period: domain.LimitPeriodDaily, MaxAmount: 50000, Currency: "USD"}
+
+	allow := stubScreener{result: risk.Result{Decision: risk.DecisionAllow}}
+
+	testData := map[string]struct {
+		ctx             context.Context
+		expiresAt       time.Time
+		screener        stubScreener
+		wantStatus      domain.TransactionStatus
+		wantUnavailable bool
+		wantNotFound    bool
+		wantDenied      bool
+	}{
+		"it converts the quote into a debit and a credit": {
+			ctx:        context.Background(),
+			expiresAt:  quote.ExpiresAt,
+			screener:   allow,
+			wantStatus: domain.TransactionStatusCompleted,
+		},
+		"it stores the debit of a flagged conversion as pending review": {
+			ctx:        context.Background(),
+			expiresAt:  quote.ExpiresAt,
+			screener:   stubScreener{result: risk.Result{Decision: risk.DecisionReview, RuleIDs: []string{"big-amount"}}},
+			wantStatus: domain.TransactionStatusPendingReview,
+		},
+		"it rejects a denied conversion": {
+			ctx:        context.Background(),
+			expiresAt:  quote.ExpiresAt,
+			screener:   stubScreener{result: risk.Result{Decision: risk.DecisionDeny, RuleIDs: []string{"velocity"}}},
+			wantDenied: true,
+		},
+		"it does not convert an expired quote": {
+			ctx:             context.Background(),
+			expiresAt:       now,
+			wantUnavailable: true,
+		},
+		"it hides the quotes of another user from a restricted caller": {
+			ctx:          auth.WithPrincipal(context.Background(), auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, UserID: &otherUserID}),
+			expiresAt:    quote.ExpiresAt,
+			wantNotFound: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			stored := quote
+			stored.ExpiresAt = tc.expiresAt
+
+			repo := mocks.NewMockRepository(ctrl)
+			repo.EXPECT().GetFXQuote(gomock.Any(), quote.ID).Return(&stored, nil)
+			if !tc.wantUnavailable && !tc.wantNotFound && !tc.wantDenied {
+				repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, nil)
+				repo.EXPECT().ListApplicableLimits(gomock.Any(), userID).Return([]domain.Limit{eur, usd}, nil)
+				repo.EXPECT().CreateFXConversion(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), []domain.Limit{eur}).DoAndReturn(
+					func(_ context.Context, conversion domain.FXConversion, debit, credit domain.Transaction, _ []domain.Limit) (*domain.FXConversion, error) {
+						require.Equal(t, domain.TransactionTypeDebit, debit.TransactionType)
+						require.Equal(t, "EUR", debit.Currency)
+						require.Equal(t, int64(10000), debit.Amount)
+						require.Equal(t, tc.wantStatus, debit.Status)
+						require.Equal(t, tc.screener.result.RuleIDs, debit.FlaggedRules)
+						require.Equal(t, domain.TransactionTypeCredit, credit.TransactionType)
+						require.Equal(t, "USD", credit.Currency)
+						require.Equal(t, int64(10850), credit.Amount)
+						require.Equal(t, conversion.ID.String(), credit.Metadata[domain.FXConversionIDMetadataKey])
+						return &conversion, nil
+					},
+				)
+			}
+			svc := fxService{
+				repo:         repo,
+				transactions: transactionService{repo: repo, screener: tc.screener, metrics: newTransactionMetrics()},
+				quoteTTL:     30 * time.Second,
+				now:          func() time.Time { return now },
+			}
+
+			conversion, err := svc.Convert(tc.ctx, domain.FXConversionRequest{QuoteID: quote.ID, Origin: domain.OriginDesktopWeb})
+			switch {
+			case tc.wantUnavailable:
+				require.ErrorIs(t, err, domain.ErrQuoteUnavailable)
+			case tc.wantNotFound:
+				var notFound repository.NotFoundError
+				require.True(t, errors.As(err, &notFound))
+			case tc.wantDenied:
+				var denied risk.DeniedError
+				require.True(t, errors.As(err, &denied))
+				require.Equal(t, []string{"velocity"}, denied.RuleIDs)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, quote.ID, conversion.QuoteID)
+				require.Equal(t, "1.085", conversion.Rate)
+			}
+		})
+	}
+}
+
+func TestFXService_LoadRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().CreateRateSnapshot(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error) {
+			return &snapshot, nil
+		},
+	)
+	svc := fxService{repo: repo, now: func() time.Time { return now }}
+
+	created, err := svc.LoadRates(context.Background(), domain.RateSnapshot{Rates: []domain.FXRate{{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.085"}}})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, created.ID)
+	require.Equal(t, now, created.EffectiveAt)
+
+	_, err = svc.LoadRates(context.Background(), domain.RateSnapshot{Rates: []domain.FXRate{{BaseCurrency: "EUR", QuoteCurrency: "XXX", Rate: "-1"}}})
+	var validationErr domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Problems, 2)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+// CreateHold validates and places an active hold expiring after its TTL, in DefaultCurrency when it has no currency. It
+// returns a domain.InsufficientFundsError when the available balance of the user in its currency does not cover it.
+// Callers restricted to their own data can only place holds
+// on their own balance.
+func (s holdService) CreateHold(ctx context.Context, hold domain.Hold) (*domain.Hold, error) {
+	if userID, restricted := restrictedUserID(ctx); restricted && hold.UserID != userID {
+		return nil, domain.NewValidationError([]string{"user_id must be the user of the caller"})
+	}
+	if hold.Currency == "" {
+		hold.Currency = domain.DefaultCurrency
+	}
+	if err := hold.Validate(); err != nil {
+		return nil, err
+	}
+
+	hold.ID = uuid.New()
+	hold.Status = domain.HoldStatusActive
+	hold.ExpiresAt = s.now().UTC().Add(hold.TTL())
+	hold.TTLSeconds = 0
+	hold.CapturedAmount, hold.TransactionID = 0, nil
+	return s.repo.CreateHold(ctx, hold)
+}
+
+// GetHold retrieves a hold, reported expired once past its expiry even before the expiry sweep updates it. Callers
+// restricted to their own data get a repository.NotFoundError for the holds of other users.
+func (s holdService) GetHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	hold, err := s.repo.GetHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID, restricted := restrictedUserID(ctx); restricted && hold.UserID != userID {
+		return nil, repository.NewNotFoundError("hold not found")
+	}
+	return s.withExpiry(hold), nil
+}
+
+// ListHolds retrieves the holds of the tenant, only their own ones for the callers restricted to their own data
+func (s holdService) ListHolds(ctx context.Context) ([]domain.Hold, error) {
+	var user *uuid.UUID
+	if userID, restricted := restrictedUserID(ctx); restricted {
+		user = &userID
+	}
+
+	holds, err := s.repo.ListHolds(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	for i := range holds {
+		holds[i] = *s.withExpiry(&holds[i])
+	}
+	return holds, nil
+}
+
+// CaptureHold settles an active hold with a debit of the amount, the whole amount of the hold when zero; what it does
+// not capture is released. The debit is screened, categorized and checked against the limits like any other debit.
+func (s holdService) CaptureHold(ctx context.Context, id uuid.UUID, amount int64) (*domain.Hold, error) {
+	hold, err := s.GetHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if err := hold.ValidateCapture(amount); err != nil {
+		return nil, err
+	}
+
+	transaction := hold.Capture(amount)
+	if err := s.transactions.prepare(ctx, &transaction); err != nil {
+		return nil, err
+	}
+	limits, err := applicableLimits(ctx, s.repo, transaction.UserID, transaction.Origin, transaction.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	captured, created, err := s.repo.CaptureHold(ctx, id, transaction, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	s.transactions.metrics.recordCreated(ctx, created)
+	return captured, nil
+}
+
+// ReleaseHold frees the amount of an active hold
+func (s holdService) ReleaseHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	if _, err := s.GetHold(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ReleaseHold(ctx, id)
+}
+
+// GetUserBalance returns the balance of a user in the currency together with the amount its active holds in it reserve
+func (s holdService) GetUserBalance(ctx context.Context, userID uuid.UUID, currency string) (*domain.Balance, error) {
+	balance, err := s.repo.GetBalance(ctx, userID, currency, s.now())
+	if err != nil {
+		return nil, err
+	}
+	held, err := s.repo.GetHeldAmount(ctx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Balance{UserID: userID, Currency: currency, Balance: balance, Held: held, Available: balance - held}, nil
+}
+
+// withExpiry reports an active hold past its expiry as expired
+func (s holdService) withExpiry(hold *domain.Hold) *domain.Hold {
+	if hold.Expired(s.now()) {
+		hold.Status = domain.HoldStatusExpired
+	}
+	return hold
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/mocks"
+	"traive-engineering-challenge/internal/risk"
+)
+
+func TestHoldService_CreateHold(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	restricted := auth.WithPrincipal(context.Background(), auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, UserID: &userID})
+
+	testData := map[string]struct {
+		ctx           context.Context
+		hold          domain.Hold
+		wantExpiresAt time.Time
+		wantCurrency  string
+		wantErr       bool
+	}{
+		"it expires the hold after the default TTL": {
+			ctx:           context.Background(),
+			hold:          domain.Hold{UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 5000},
+			wantExpiresAt: now.Add(domain.DefaultHoldTTL),
+			wantCurrency:  domain.DefaultCurrency,
+		},
+		"it expires the hold after its TTL": {
+			ctx:           restricted,
+			hold:          domain.Hold{UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 5000, TTLSeconds: 3600},
+			wantExpiresAt: now.Add(time.Hour),
+			wantCurrency:  domain.DefaultCurrency,
+		},
+		"it places the hold in its currency": {
+			ctx:           context.Background(),
+			hold:          domain.Hold{UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 5000, Currency: "EUR"},
+			wantExpiresAt: now.Add(domain.DefaultHoldTTL),
+			wantCurrency:  "EUR",
+		},
+		"it rejects an unsupported currency": {
+			ctx:     context.Background(),
+			hold:    domain.Hold{UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 5000, Currency: "XXX"},
+			wantErr: true,
+		},
+		"it rejects the holds of another user for a restricted caller": {
+			ctx:     restricted,
+			hold:    domain.Hold{UserID: uuid.New(), Origin: domain.OriginDesktopWeb, Amount: 5000},
+			wantErr: true,
+		},
+		"it rejects a TTL over the maximum": {
+			ctx:     context.Background(),
+			hold:    domain.Hold{UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 5000, TTLSeconds: int64(domain.MaxHoldTTL/time.Second) + 1},
+			wantErr: true,
+		},
+		"it rejects the reserved metadata key": {
+			ctx:     context.Background(),
+			hold:    domain.Hold{UserID: userID, Origin: domain.OriginDesktopWeb, Amount: 5000, Metadata: map[string]string{domain.HoldIDMetadataKey: "x"}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			if !tc.wantErr {
+				repo.EXPECT().CreateHold(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, hold domain.Hold) (*domain.Hold, error) { return &hold, nil },
+				)
+			}
+			svc := holdService{repo: repo, now: func() time.Time { return now }}
+
+			created, err := svc.CreateHold(tc.ctx, tc.hold)
+			if tc.wantErr {
+				var validationErr domain.ValidationError
+				require.True(t, errors.As(err, &validationErr))
+				return
+			}
+			require.NoError(t, err)
+			require.NotEqual(t, uuid.Nil, created.ID)
+			require.Equal(t, domain.HoldStatusActive, created.Status)
+			require.Equal(t, tc.wantExpiresAt, created.ExpiresAt)
+			require.Equal(t, tc.wantCurrency, created.Currency)
+			require.Zero(t, created.TTLSeconds)
+		})
+	}
+}
+
+func TestHoldService_CaptureHold(t *testing.T) {
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	hold := domain.Hold{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Origin:      domain.OriginDesktopWeb,
+		Amount:      5000,
+		Currency:    "EUR",
+		Description: "Hotel",
+		Metadata:    map[string]string{"booking": "42"},
+		Status:      domain.HoldStatusActive,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	testData := map[string]struct {
+		amount        int64
+		screener      risk.Screener
+		wantAmount    int64
+		wantStatus    domain.TransactionStatus
+		wantDenied    bool
+		wantInvalid   bool
+		wantNoCapture bool
+	}{
+		"it captures the whole amount by default": {
+			screener:   stubScreener{result: risk.Result{Decision: risk.DecisionAllow}},
+			wantAmount: 5000,
+			wantStatus: domain.TransactionStatusCompleted,
+		},
+		"it captures part of the amount": {
+			amount:     4200,
+			screener:   stubScreener{result: risk.Result{Decision: risk.DecisionReview}},
+			wantAmount: 4200,
+			wantStatus: domain.TransactionStatusPendingReview,
+		},
+		"it rejects an amount over the amount of the hold": {
+			amount:        5001,
+			screener:      stubScreener{result: risk.Result{Decision: risk.DecisionAllow}},
+			wantInvalid:   true,
+			wantNoCapture: true,
+		},
+		"it rejects a denied debit": {
+			screener:      stubScreener{result: risk.Result{Decision: risk.DecisionDeny}},
+			wantDenied:    true,
+			wantNoCapture: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			repo.EXPECT().GetHold(gomock.Any(), hold.ID).Return(&hold, nil)
+			if !tc.wantNoCapture {
+				repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, nil)
+				repo.EXPECT().ListApplicableLimits(gomock.Any(), hold.UserID).Return(nil, nil)
+				repo.EXPECT().CaptureHold(gomock.Any(), hold.ID, gomock.Any(), gomock.Nil()).DoAndReturn(
+					func(_ context.Context, _ uuid.UUID, transaction domain.Transaction, _ []domain.Limit) (*domain.Hold, *domain.Transaction, error) {
+						require.Equal(t, domain.TransactionTypeDebit, transaction.TransactionType)
+						require.Equal(t, tc.wantAmount, transaction.Amount)
+						require.Equal(t, "EUR", transaction.Currency)
+						require.Equal(t, tc.wantStatus, transaction.Status)
+						require.Equal(t, hold.ID.String(), transaction.Metadata[domain.HoldIDMetadataKey])
+						require.Equal(t, "42", transaction.Metadata["booking"])
+
+						captured := hold
+						captured.Status, captured.CapturedAmount, captured.TransactionID = domain.HoldStatusCaptured, transaction.Amount, &transaction.ID
+						return &captured, &transaction, nil
+					})
+			}
+			svc := holdService{
+				repo:         repo,
+				transactions: transactionService{repo: repo, screener: tc.screener, metrics: newTransactionMetrics()},
+				now:          func() time.Time { return now },
+			}
+
+			captured, err := svc.CaptureHold(context.Background(), hold.ID, tc.amount)
+			switch {
+			case tc.wantInvalid:
+				var validationErr domain.ValidationError
+				require.True(t, errors.As(err, &validationErr))
+			case tc.wantDenied:
+				var denied risk.DeniedError
+				require.True(t, errors.As(err, &denied))
+			default:
+				require.NoError(t, err)
+				require.Equal(t, domain.HoldStatusCaptured, captured.Status)
+				require.Equal(t, tc.wantAmount, captured.CapturedAmount)
+			}
+		})
+	}
+}
+
+func TestHoldService_GetHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	userID := uuid.New()
+	hold := domain.Hold{ID: uuid.New(), UserID: uuid.New(), Status: domain.HoldStatusActive, ExpiresAt: now}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().GetHold(gomock.Any(), hold.ID).DoAndReturn(func(context.Context, uuid.UUID) (*domain.Hold, error) {
+		stored := hold
+		return &stored, nil
+	}).Times(2)
+	svc := holdService{repo: repo, now: func() time.Time { return now }}
+
+	got, err := svc.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.HoldStatusExpired, got.Status, "an active hold past its expiry is reported expired")
+
+	restricted := auth.WithPrincipal(context.Background(), auth.Principal{Scopes: []string{domain.ScopeTransactionsRead}, UserID: &userID})
+	_, err = svc.GetHold(restricted, hold.ID)
+	var notFound repository.NotFoundError
+	require.True(t, errors.As(err, &notFound))
+}
+
+func TestHoldService_GetUserBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	userID := uuid.New()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().GetBalance(gomock.Any(), userID, "EUR", now).Return(int64(10000), nil)
+	repo.EXPECT().GetHeldAmount(gomock.Any(), userID, "EUR").Return(int64(3000), nil)
+	svc := holdService{repo: repo, now: func() time.Time { return now }}
+
+	balance, err := svc.GetUserBalance(context.Background(), userID, "EUR")
+	require.NoError(t, err)
+	require.Equal(t, domain.Balance{UserID: userID, Currency: "EUR", Balance: 10000, Held: 3000, Available: 7000}, *balance)
+}
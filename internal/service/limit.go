@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+// CreateLimit validates and stores a limit, in DefaultCurrency when it has no currency
+func (l limitService) CreateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	if limit.Currency == "" {
+		limit.Currency = domain.DefaultCurrency
+	}
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit.ID = uuid.New()
+	return l.repo.CreateLimit(ctx, limit)
+}
+
+func (l limitService) GetLimit(ctx context.Context, id uuid.UUID) (*domain.Limit, error) {
+	return l.repo.GetLimit(ctx, id)
+}
+
+func (l limitService) ListLimits(ctx context.Context) ([]domain.Limit, error) {
+	return l.repo.ListLimits(ctx)
+}
+
+// UpdateLimit validates and replaces the definition of a limit, in DefaultCurrency when it has no currency
+func (l limitService) UpdateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	if limit.Currency == "" {
+		limit.Currency = domain.DefaultCurrency
+	}
+	if err := limit.Validate(); err != nil {
+		return nil, err
+	}
+
+	return l.repo.UpdateLimit(ctx, limit)
+}
+
+func (l limitService) DeleteLimit(ctx context.Context, id uuid.UUID) error {
+	return l.repo.DeleteLimit(ctx, id)
+}
+
+// GetUserLimits reports the headroom left on every limit that applies to the user.
+// Origin limits are reported for every origin, each one against the debits made through it, and every limit against
+// the debits in its currency.
+func (l limitService) GetUserLimits(ctx context.Context, userID uuid.UUID) ([]domain.LimitUsage, error) {
+	limits, err := l.repo.ListApplicableLimits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]domain.LimitUsage, 0, len(limits))
+	for _, limit := range limits {
+		used, err := l.repo.SumUserDebitsSince(ctx, userID, limit.Origin, limit.Currency, time.Now().Add(-limit.Period.Window()))
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, domain.NewLimitUsage(limit, used))
+	}
+
+	return usages, nil
+}
+
+// applicableLimits retrieves the limits that cap a debit in the currency made by the user through the origin
+func applicableLimits(ctx context.Context, repo repository.Repository, userID uuid.UUID, origin, currency string) ([]domain.Limit, error) {
+	limits, err := repo.ListApplicableLimits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var applicable []domain.Limit
+	for _, limit := range limits {
+		if limit.AppliesTo(userID, origin, currency) {
+			applicable = append(applicable, limit)
+		}
+	}
+	return applicable, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/mocks"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestLimitService_CreateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	svc := NewLimitService(repo)
+
+	_, err := svc.CreateLimit(context.Background(), domain.Limit{Scope: domain.LimitScopeGlobal})
+	var validationErr domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+
+	repo.EXPECT().CreateLimit(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, limit domain.Limit) (*domain.Limit, error) { return &limit, nil },
+	)
+	created, err := svc.CreateLimit(context.Background(), domain.Limit{Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodDaily, MaxAmount: 10})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, created.ID)
+	require.Equal(t, domain.DefaultCurrency, created.Currency)
+}
+
+func TestLimitService_GetUserLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := uuid.New()
+	globalLimit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodMonthly, MaxAmount: 1000, Currency: domain.DefaultCurrency}
+	originLimit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeOrigin, Origin: support.MobileAndroid, Period: domain.LimitPeriodDaily, MaxAmount: 100, Currency: "EUR"}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().ListApplicableLimits(gomock.Any(), userID).Return([]domain.Limit{globalLimit, originLimit}, nil)
+	repo.EXPECT().SumUserDebitsSince(gomock.Any(), userID, "", domain.DefaultCurrency, gomock.Any()).Return(int64(400), nil)
+	repo.EXPECT().SumUserDebitsSince(gomock.Any(), userID, support.MobileAndroid, "EUR", gomock.Any()).Return(int64(150), nil)
+
+	usages, err := NewLimitService(repo).GetUserLimits(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, []domain.LimitUsage{
+		{Limit: globalLimit, Used: 400, Remaining: 600},
+		{Limit: originLimit, Used: 150, Remaining: 0},
+	}, usages)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"traive-engineering-challenge/internal/domain"
+)
+
+const instrumentationName = "traive-engineering-challenge/internal/service"
+
+// transactionMetrics counts the created transactions and sums their amounts by type, origin and status
+type transactionMetrics struct {
+	created metric.Int64Counter
+	amount  metric.Int64Counter
+}
+
+func newTransactionMetrics() transactionMetrics {
+	meter := otel.Meter(instrumentationName)
+
+	created, err := meter.Int64Counter("transactions.created",
+		metric.WithDescription("Number of transactions created"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	amount, err := meter.Int64Counter("transactions.amount",
+		metric.WithDescription("Sum of the amounts of the transactions created"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return transactionMetrics{created: created, amount: amount}
+}
+
+func (m transactionMetrics) recordCreated(ctx context.Context, transaction *domain.Transaction) {
+	attrs := metric.WithAttributes(
+		attribute.String("type", transaction.TransactionType.String()),
+		attribute.String("origin", transaction.Origin),
+		attribute.String("status", string(transaction.Status)),
+	)
+	m.created.Add(ctx, 1, attrs)
+	m.amount.Add(ctx, transaction.Amount, attrs)
+}
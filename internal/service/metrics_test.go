@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"testing"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository/mocks"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/support"
+)
+
+func TestTransactionService_CreateTransaction_Metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(meterProvider) })
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().ListCategoryRules(gomock.Any()).Times(2).Return(nil, nil)
+	repo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, tx domain.Transaction) (*domain.Transaction, error) { return &tx, nil },
+	)
+	svc := NewTransactionService(repo, stubScreener{result: risk.Result{Decision: risk.DecisionAllow}})
+
+	for _, amount := range []int64{1000, 250} {
+		_, err := svc.CreateTransaction(context.Background(), *support.ValidDomainTransaction(uuid.New(), uuid.New(), support.DesktopWeb, "", amount))
+		require.NoError(t, err)
+	}
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+
+	sums := map[string]metricdata.DataPoint[int64]{}
+	for _, scope := range collected.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			points := m.Data.(metricdata.Sum[int64]).DataPoints
+			require.Len(t, points, 1)
+			sums[m.Name] = points[0]
+		}
+	}
+
+	require.EqualValues(t, 2, sums["transactions.created"].Value)
+	require.EqualValues(t, 1250, sums["transactions.amount"].Value)
+
+	created := sums["transactions.created"]
+	origin, ok := created.Attributes.Value(attribute.Key("origin"))
+	require.True(t, ok)
+	require.Equal(t, support.DesktopWeb, origin.AsString())
+}
@@ -7,10 +7,12 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	domain "traive-engineering-challenge/internal/domain"
 	filter "traive-engineering-challenge/internal/repository/filter"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockTransactionService is a mock of TransactionService interface.
@@ -36,6 +38,21 @@ func (m *MockTransactionService) EXPECT() *MockTransactionServiceMockRecorder {
 	return m.recorder
 }
 
+// CategorizeTransaction mocks base method.
+func (m *MockTransactionService) CategorizeTransaction(ctx context.Context, id uuid.UUID, categoryID *uuid.UUID) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CategorizeTransaction", ctx, id, categoryID)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CategorizeTransaction indicates an expected call of CategorizeTransaction.
+func (mr *MockTransactionServiceMockRecorder) CategorizeTransaction(ctx, id, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CategorizeTransaction", reflect.TypeOf((*MockTransactionService)(nil).CategorizeTransaction), ctx, id, categoryID)
+}
+
 // CreateTransaction mocks base method.
 func (m *MockTransactionService) CreateTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +68,41 @@ func (mr *MockTransactionServiceMockRecorder) CreateTransaction(ctx, transaction
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockTransactionService)(nil).CreateTransaction), ctx, transaction)
 }
 
+// GetTransaction mocks base method.
+func (m *MockTransactionService) GetTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransaction", ctx, id)
+	ret0, _ := ret[0].(*domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransaction indicates an expected call of GetTransaction.
+func (mr *MockTransactionServiceMockRecorder) GetTransaction(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockTransactionService)(nil).GetTransaction), ctx, id)
+}
+
+// GetTransactionStats mocks base method.
+func (m *MockTransactionService) GetTransactionStats(ctx context.Context, dimension string, options ...filter.Options) ([]domain.TransactionStats, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, dimension}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetTransactionStats", varargs...)
+	ret0, _ := ret[0].([]domain.TransactionStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionStats indicates an expected call of GetTransactionStats.
+func (mr *MockTransactionServiceMockRecorder) GetTransactionStats(ctx, dimension interface{}, options ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, dimension}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionStats", reflect.TypeOf((*MockTransactionService)(nil).GetTransactionStats), varargs...)
+}
+
 // ListTransactions mocks base method.
 func (m *MockTransactionService) ListTransactions(ctx context.Context, options ...filter.Options) ([]domain.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -70,3 +122,902 @@ func (mr *MockTransactionServiceMockRecorder) ListTransactions(ctx interface{},
 	varargs := append([]interface{}{ctx}, options...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockTransactionService)(nil).ListTransactions), varargs...)
 }
+
+// MockLimitService is a mock of LimitService interface.
+type MockLimitService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLimitServiceMockRecorder
+}
+
+// MockLimitServiceMockRecorder is the mock recorder for MockLimitService.
+type MockLimitServiceMockRecorder struct {
+	mock *MockLimitService
+}
+
+// NewMockLimitService creates a new mock instance.
+func NewMockLimitService(ctrl *gomock.Controller) *MockLimitService {
+	mock := &MockLimitService{ctrl: ctrl}
+	mock.recorder = &MockLimitServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLimitService) EXPECT() *MockLimitServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateLimit mocks base method.
+func (m *MockLimitService) CreateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLimit", ctx, limit)
+	ret0, _ := ret[0].(*domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLimit indicates an expected call of CreateLimit.
+func (mr *MockLimitServiceMockRecorder) CreateLimit(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLimit", reflect.TypeOf((*MockLimitService)(nil).CreateLimit), ctx, limit)
+}
+
+// DeleteLimit mocks base method.
+func (m *MockLimitService) DeleteLimit(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLimit", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLimit indicates an expected call of DeleteLimit.
+func (mr *MockLimitServiceMockRecorder) DeleteLimit(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLimit", reflect.TypeOf((*MockLimitService)(nil).DeleteLimit), ctx, id)
+}
+
+// GetLimit mocks base method.
+func (m *MockLimitService) GetLimit(ctx context.Context, id uuid.UUID) (*domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimit", ctx, id)
+	ret0, _ := ret[0].(*domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimit indicates an expected call of GetLimit.
+func (mr *MockLimitServiceMockRecorder) GetLimit(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimit", reflect.TypeOf((*MockLimitService)(nil).GetLimit), ctx, id)
+}
+
+// GetUserLimits mocks base method.
+func (m *MockLimitService) GetUserLimits(ctx context.Context, userID uuid.UUID) ([]domain.LimitUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLimits", ctx, userID)
+	ret0, _ := ret[0].([]domain.LimitUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLimits indicates an expected call of GetUserLimits.
+func (mr *MockLimitServiceMockRecorder) GetUserLimits(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLimits", reflect.TypeOf((*MockLimitService)(nil).GetUserLimits), ctx, userID)
+}
+
+// ListLimits mocks base method.
+func (m *MockLimitService) ListLimits(ctx context.Context) ([]domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLimits", ctx)
+	ret0, _ := ret[0].([]domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLimits indicates an expected call of ListLimits.
+func (mr *MockLimitServiceMockRecorder) ListLimits(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLimits", reflect.TypeOf((*MockLimitService)(nil).ListLimits), ctx)
+}
+
+// UpdateLimit mocks base method.
+func (m *MockLimitService) UpdateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLimit", ctx, limit)
+	ret0, _ := ret[0].(*domain.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLimit indicates an expected call of UpdateLimit.
+func (mr *MockLimitServiceMockRecorder) UpdateLimit(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLimit", reflect.TypeOf((*MockLimitService)(nil).UpdateLimit), ctx, limit)
+}
+
+// MockSavedSearchService is a mock of SavedSearchService interface.
+type MockSavedSearchService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSavedSearchServiceMockRecorder
+}
+
+// MockSavedSearchServiceMockRecorder is the mock recorder for MockSavedSearchService.
+type MockSavedSearchServiceMockRecorder struct {
+	mock *MockSavedSearchService
+}
+
+// NewMockSavedSearchService creates a new mock instance.
+func NewMockSavedSearchService(ctrl *gomock.Controller) *MockSavedSearchService {
+	mock := &MockSavedSearchService{ctrl: ctrl}
+	mock.recorder = &MockSavedSearchServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSavedSearchService) EXPECT() *MockSavedSearchServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateSavedSearch mocks base method.
+func (m *MockSavedSearchService) CreateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavedSearch", ctx, search)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavedSearch indicates an expected call of CreateSavedSearch.
+func (mr *MockSavedSearchServiceMockRecorder) CreateSavedSearch(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavedSearch", reflect.TypeOf((*MockSavedSearchService)(nil).CreateSavedSearch), ctx, search)
+}
+
+// DeleteSavedSearch mocks base method.
+func (m *MockSavedSearchService) DeleteSavedSearch(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSavedSearch", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSavedSearch indicates an expected call of DeleteSavedSearch.
+func (mr *MockSavedSearchServiceMockRecorder) DeleteSavedSearch(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSavedSearch", reflect.TypeOf((*MockSavedSearchService)(nil).DeleteSavedSearch), ctx, id)
+}
+
+// GetSavedSearch mocks base method.
+func (m *MockSavedSearchService) GetSavedSearch(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedSearch", ctx, id)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedSearch indicates an expected call of GetSavedSearch.
+func (mr *MockSavedSearchServiceMockRecorder) GetSavedSearch(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedSearch", reflect.TypeOf((*MockSavedSearchService)(nil).GetSavedSearch), ctx, id)
+}
+
+// GetSavedSearchByName mocks base method.
+func (m *MockSavedSearchService) GetSavedSearchByName(ctx context.Context, name string) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedSearchByName", ctx, name)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedSearchByName indicates an expected call of GetSavedSearchByName.
+func (mr *MockSavedSearchServiceMockRecorder) GetSavedSearchByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedSearchByName", reflect.TypeOf((*MockSavedSearchService)(nil).GetSavedSearchByName), ctx, name)
+}
+
+// ListSavedSearches mocks base method.
+func (m *MockSavedSearchService) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSavedSearches", ctx)
+	ret0, _ := ret[0].([]domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSavedSearches indicates an expected call of ListSavedSearches.
+func (mr *MockSavedSearchServiceMockRecorder) ListSavedSearches(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSavedSearches", reflect.TypeOf((*MockSavedSearchService)(nil).ListSavedSearches), ctx)
+}
+
+// UpdateSavedSearch mocks base method.
+func (m *MockSavedSearchService) UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSavedSearch", ctx, search)
+	ret0, _ := ret[0].(*domain.SavedSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSavedSearch indicates an expected call of UpdateSavedSearch.
+func (mr *MockSavedSearchServiceMockRecorder) UpdateSavedSearch(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSavedSearch", reflect.TypeOf((*MockSavedSearchService)(nil).UpdateSavedSearch), ctx, search)
+}
+
+// MockCategoryService is a mock of CategoryService interface.
+type MockCategoryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoryServiceMockRecorder
+}
+
+// MockCategoryServiceMockRecorder is the mock recorder for MockCategoryService.
+type MockCategoryServiceMockRecorder struct {
+	mock *MockCategoryService
+}
+
+// NewMockCategoryService creates a new mock instance.
+func NewMockCategoryService(ctrl *gomock.Controller) *MockCategoryService {
+	mock := &MockCategoryService{ctrl: ctrl}
+	mock.recorder = &MockCategoryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategoryService) EXPECT() *MockCategoryServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateCategory mocks base method.
+func (m *MockCategoryService) CreateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, category)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockCategoryServiceMockRecorder) CreateCategory(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockCategoryService)(nil).CreateCategory), ctx, category)
+}
+
+// CreateCategoryRule mocks base method.
+func (m *MockCategoryService) CreateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategoryRule", ctx, rule)
+	ret0, _ := ret[0].(*domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategoryRule indicates an expected call of CreateCategoryRule.
+func (mr *MockCategoryServiceMockRecorder) CreateCategoryRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryRule", reflect.TypeOf((*MockCategoryService)(nil).CreateCategoryRule), ctx, rule)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockCategoryService) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockCategoryServiceMockRecorder) DeleteCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockCategoryService)(nil).DeleteCategory), ctx, id)
+}
+
+// DeleteCategoryRule mocks base method.
+func (m *MockCategoryService) DeleteCategoryRule(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategoryRule indicates an expected call of DeleteCategoryRule.
+func (mr *MockCategoryServiceMockRecorder) DeleteCategoryRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryRule", reflect.TypeOf((*MockCategoryService)(nil).DeleteCategoryRule), ctx, id)
+}
+
+// GetCategory mocks base method.
+func (m *MockCategoryService) GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", ctx, id)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockCategoryServiceMockRecorder) GetCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockCategoryService)(nil).GetCategory), ctx, id)
+}
+
+// GetCategoryRule mocks base method.
+func (m *MockCategoryService) GetCategoryRule(ctx context.Context, id uuid.UUID) (*domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryRule", ctx, id)
+	ret0, _ := ret[0].(*domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryRule indicates an expected call of GetCategoryRule.
+func (mr *MockCategoryServiceMockRecorder) GetCategoryRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryRule", reflect.TypeOf((*MockCategoryService)(nil).GetCategoryRule), ctx, id)
+}
+
+// ListCategories mocks base method.
+func (m *MockCategoryService) ListCategories(ctx context.Context) ([]domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", ctx)
+	ret0, _ := ret[0].([]domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockCategoryServiceMockRecorder) ListCategories(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockCategoryService)(nil).ListCategories), ctx)
+}
+
+// ListCategoryRules mocks base method.
+func (m *MockCategoryService) ListCategoryRules(ctx context.Context) ([]domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategoryRules", ctx)
+	ret0, _ := ret[0].([]domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategoryRules indicates an expected call of ListCategoryRules.
+func (mr *MockCategoryServiceMockRecorder) ListCategoryRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategoryRules", reflect.TypeOf((*MockCategoryService)(nil).ListCategoryRules), ctx)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockCategoryService) UpdateCategory(ctx context.Context, category domain.Category) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", ctx, category)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockCategoryServiceMockRecorder) UpdateCategory(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockCategoryService)(nil).UpdateCategory), ctx, category)
+}
+
+// UpdateCategoryRule mocks base method.
+func (m *MockCategoryService) UpdateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategoryRule", ctx, rule)
+	ret0, _ := ret[0].(*domain.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategoryRule indicates an expected call of UpdateCategoryRule.
+func (mr *MockCategoryServiceMockRecorder) UpdateCategoryRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategoryRule", reflect.TypeOf((*MockCategoryService)(nil).UpdateCategoryRule), ctx, rule)
+}
+
+// MockScheduleService is a mock of ScheduleService interface.
+type MockScheduleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduleServiceMockRecorder
+}
+
+// MockScheduleServiceMockRecorder is the mock recorder for MockScheduleService.
+type MockScheduleServiceMockRecorder struct {
+	mock *MockScheduleService
+}
+
+// NewMockScheduleService creates a new mock instance.
+func NewMockScheduleService(ctrl *gomock.Controller) *MockScheduleService {
+	mock := &MockScheduleService{ctrl: ctrl}
+	mock.recorder = &MockScheduleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduleService) EXPECT() *MockScheduleServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelSchedule mocks base method.
+func (m *MockScheduleService) CancelSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, id)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockScheduleServiceMockRecorder) CancelSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockScheduleService)(nil).CancelSchedule), ctx, id)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockScheduleService) CreateSchedule(ctx context.Context, schedule domain.Schedule) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", ctx, schedule)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockScheduleServiceMockRecorder) CreateSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockScheduleService)(nil).CreateSchedule), ctx, schedule)
+}
+
+// GetSchedule mocks base method.
+func (m *MockScheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", ctx, id)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockScheduleServiceMockRecorder) GetSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockScheduleService)(nil).GetSchedule), ctx, id)
+}
+
+// ListSchedules mocks base method.
+func (m *MockScheduleService) ListSchedules(ctx context.Context) ([]domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", ctx)
+	ret0, _ := ret[0].([]domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockScheduleServiceMockRecorder) ListSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockScheduleService)(nil).ListSchedules), ctx)
+}
+
+// PauseSchedule mocks base method.
+func (m *MockScheduleService) PauseSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseSchedule", ctx, id)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseSchedule indicates an expected call of PauseSchedule.
+func (mr *MockScheduleServiceMockRecorder) PauseSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSchedule", reflect.TypeOf((*MockScheduleService)(nil).PauseSchedule), ctx, id)
+}
+
+// PreviewSchedule mocks base method.
+func (m *MockScheduleService) PreviewSchedule(ctx context.Context, id uuid.UUID, count int) ([]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewSchedule", ctx, id, count)
+	ret0, _ := ret[0].([]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewSchedule indicates an expected call of PreviewSchedule.
+func (mr *MockScheduleServiceMockRecorder) PreviewSchedule(ctx, id, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewSchedule", reflect.TypeOf((*MockScheduleService)(nil).PreviewSchedule), ctx, id, count)
+}
+
+// ResumeSchedule mocks base method.
+func (m *MockScheduleService) ResumeSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSchedule", ctx, id)
+	ret0, _ := ret[0].(*domain.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeSchedule indicates an expected call of ResumeSchedule.
+func (mr *MockScheduleServiceMockRecorder) ResumeSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSchedule", reflect.TypeOf((*MockScheduleService)(nil).ResumeSchedule), ctx, id)
+}
+
+// MockHoldService is a mock of HoldService interface.
+type MockHoldService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldServiceMockRecorder
+}
+
+// MockHoldServiceMockRecorder is the mock recorder for MockHoldService.
+type MockHoldServiceMockRecorder struct {
+	mock *MockHoldService
+}
+
+// NewMockHoldService creates a new mock instance.
+func NewMockHoldService(ctrl *gomock.Controller) *MockHoldService {
+	mock := &MockHoldService{ctrl: ctrl}
+	mock.recorder = &MockHoldServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHoldService) EXPECT() *MockHoldServiceMockRecorder {
+	return m.recorder
+}
+
+// CaptureHold mocks base method.
+func (m *MockHoldService) CaptureHold(ctx context.Context, id uuid.UUID, amount int64) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, id, amount)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockHoldServiceMockRecorder) CaptureHold(ctx, id, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockHoldService)(nil).CaptureHold), ctx, id, amount)
+}
+
+// CreateHold mocks base method.
+func (m *MockHoldService) CreateHold(ctx context.Context, hold domain.Hold) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, hold)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockHoldServiceMockRecorder) CreateHold(ctx, hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockHoldService)(nil).CreateHold), ctx, hold)
+}
+
+// GetHold mocks base method.
+func (m *MockHoldService) GetHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, id)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockHoldServiceMockRecorder) GetHold(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockHoldService)(nil).GetHold), ctx, id)
+}
+
+// GetUserBalance mocks base method.
+func (m *MockHoldService) GetUserBalance(ctx context.Context, userID uuid.UUID, currency string) (*domain.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserBalance", ctx, userID, currency)
+	ret0, _ := ret[0].(*domain.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserBalance indicates an expected call of GetUserBalance.
+func (mr *MockHoldServiceMockRecorder) GetUserBalance(ctx, userID, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalance", reflect.TypeOf((*MockHoldService)(nil).GetUserBalance), ctx, userID, currency)
+}
+
+// ListHolds mocks base method.
+func (m *MockHoldService) ListHolds(ctx context.Context) ([]domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHolds", ctx)
+	ret0, _ := ret[0].([]domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHolds indicates an expected call of ListHolds.
+func (mr *MockHoldServiceMockRecorder) ListHolds(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHolds", reflect.TypeOf((*MockHoldService)(nil).ListHolds), ctx)
+}
+
+// ReleaseHold mocks base method.
+func (m *MockHoldService) ReleaseHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", ctx, id)
+	ret0, _ := ret[0].(*domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockHoldServiceMockRecorder) ReleaseHold(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockHoldService)(nil).ReleaseHold), ctx, id)
+}
+
+// MockFXService is a mock of FXService interface.
+type MockFXService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFXServiceMockRecorder
+}
+
+// MockFXServiceMockRecorder is the mock recorder for MockFXService.
+type MockFXServiceMockRecorder struct {
+	mock *MockFXService
+}
+
+// NewMockFXService creates a new mock instance.
+func NewMockFXService(ctrl *gomock.Controller) *MockFXService {
+	mock := &MockFXService{ctrl: ctrl}
+	mock.recorder = &MockFXServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFXService) EXPECT() *MockFXServiceMockRecorder {
+	return m.recorder
+}
+
+// Convert mocks base method.
+func (m *MockFXService) Convert(ctx context.Context, request domain.FXConversionRequest) (*domain.FXConversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Convert", ctx, request)
+	ret0, _ := ret[0].(*domain.FXConversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockFXServiceMockRecorder) Convert(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockFXService)(nil).Convert), ctx, request)
+}
+
+// CreateQuote mocks base method.
+func (m *MockFXService) CreateQuote(ctx context.Context, request domain.FXQuoteRequest) (*domain.FXQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", ctx, request)
+	ret0, _ := ret[0].(*domain.FXQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockFXServiceMockRecorder) CreateQuote(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockFXService)(nil).CreateQuote), ctx, request)
+}
+
+// GetConversion mocks base method.
+func (m *MockFXService) GetConversion(ctx context.Context, id uuid.UUID) (*domain.FXConversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversion", ctx, id)
+	ret0, _ := ret[0].(*domain.FXConversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversion indicates an expected call of GetConversion.
+func (mr *MockFXServiceMockRecorder) GetConversion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversion", reflect.TypeOf((*MockFXService)(nil).GetConversion), ctx, id)
+}
+
+// GetRates mocks base method.
+func (m *MockFXService) GetRates(ctx context.Context) (*domain.RateSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRates", ctx)
+	ret0, _ := ret[0].(*domain.RateSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRates indicates an expected call of GetRates.
+func (mr *MockFXServiceMockRecorder) GetRates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRates", reflect.TypeOf((*MockFXService)(nil).GetRates), ctx)
+}
+
+// ListConversions mocks base method.
+func (m *MockFXService) ListConversions(ctx context.Context) ([]domain.FXConversion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConversions", ctx)
+	ret0, _ := ret[0].([]domain.FXConversion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConversions indicates an expected call of ListConversions.
+func (mr *MockFXServiceMockRecorder) ListConversions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConversions", reflect.TypeOf((*MockFXService)(nil).ListConversions), ctx)
+}
+
+// ListRateSnapshots mocks base method.
+func (m *MockFXService) ListRateSnapshots(ctx context.Context) ([]domain.RateSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRateSnapshots", ctx)
+	ret0, _ := ret[0].([]domain.RateSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRateSnapshots indicates an expected call of ListRateSnapshots.
+func (mr *MockFXServiceMockRecorder) ListRateSnapshots(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRateSnapshots", reflect.TypeOf((*MockFXService)(nil).ListRateSnapshots), ctx)
+}
+
+// LoadRates mocks base method.
+func (m *MockFXService) LoadRates(ctx context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadRates", ctx, snapshot)
+	ret0, _ := ret[0].(*domain.RateSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadRates indicates an expected call of LoadRates.
+func (mr *MockFXServiceMockRecorder) LoadRates(ctx, snapshot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadRates", reflect.TypeOf((*MockFXService)(nil).LoadRates), ctx, snapshot)
+}
+
+// MockAPIKeyService is a mock of APIKeyService interface.
+type MockAPIKeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyServiceMockRecorder
+}
+
+// MockAPIKeyServiceMockRecorder is the mock recorder for MockAPIKeyService.
+type MockAPIKeyServiceMockRecorder struct {
+	mock *MockAPIKeyService
+}
+
+// NewMockAPIKeyService creates a new mock instance.
+func NewMockAPIKeyService(ctrl *gomock.Controller) *MockAPIKeyService {
+	mock := &MockAPIKeyService{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyService) EXPECT() *MockAPIKeyServiceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, key)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyServiceMockRecorder) Authenticate(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKeyService)(nil).Authenticate), ctx, key)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, request domain.APIKeyRequest) (*domain.IssuedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, request)
+	ret0, _ := ret[0].(*domain.IssuedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyServiceMockRecorder) CreateAPIKey(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyService)(nil).CreateAPIKey), ctx, request)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyServiceMockRecorder) ListAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKeyService)(nil).ListAPIKeys), ctx)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyServiceMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyService)(nil).RevokeAPIKey), ctx, id)
+}
+
+// RotateAPIKey mocks base method.
+func (m *MockAPIKeyService) RotateAPIKey(ctx context.Context, id uuid.UUID) (*domain.IssuedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateAPIKey", ctx, id)
+	ret0, _ := ret[0].(*domain.IssuedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateAPIKey indicates an expected call of RotateAPIKey.
+func (mr *MockAPIKeyServiceMockRecorder) RotateAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateAPIKey", reflect.TypeOf((*MockAPIKeyService)(nil).RotateAPIKey), ctx, id)
+}
+
+// MockStatementService is a mock of StatementService interface.
+type MockStatementService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatementServiceMockRecorder
+}
+
+// MockStatementServiceMockRecorder is the mock recorder for MockStatementService.
+type MockStatementServiceMockRecorder struct {
+	mock *MockStatementService
+}
+
+// NewMockStatementService creates a new mock instance.
+func NewMockStatementService(ctrl *gomock.Controller) *MockStatementService {
+	mock := &MockStatementService{ctrl: ctrl}
+	mock.recorder = &MockStatementServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatementService) EXPECT() *MockStatementServiceMockRecorder {
+	return m.recorder
+}
+
+// GetMonthlyStatement mocks base method.
+func (m *MockStatementService) GetMonthlyStatement(ctx context.Context, userID uuid.UUID, currency, period string) (*domain.Statement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMonthlyStatement", ctx, userID, currency, period)
+	ret0, _ := ret[0].(*domain.Statement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMonthlyStatement indicates an expected call of GetMonthlyStatement.
+func (mr *MockStatementServiceMockRecorder) GetMonthlyStatement(ctx, userID, currency, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMonthlyStatement", reflect.TypeOf((*MockStatementService)(nil).GetMonthlyStatement), ctx, userID, currency, period)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/filter"
+)
+
+// CreateSavedSearch stores a saved search owned by the authenticated caller
+func (s savedSearchService) CreateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	if err := validateSavedSearch(search); err != nil {
+		return nil, err
+	}
+
+	search.ID = uuid.New()
+	search.Owner = callerSubject(ctx)
+	return s.repo.CreateSavedSearch(ctx, search)
+}
+
+// GetSavedSearch retrieves a saved search. Searches the caller cannot see are reported as not found.
+func (s savedSearchService) GetSavedSearch(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error) {
+	search, err := s.repo.GetSavedSearch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return visibleSavedSearch(ctx, search)
+}
+
+// GetSavedSearchByName retrieves the saved search applied by GET /v1/transactions?view={name}. Searches the caller
+// cannot see are reported as not found.
+func (s savedSearchService) GetSavedSearchByName(ctx context.Context, name string) (*domain.SavedSearch, error) {
+	search, err := s.repo.GetSavedSearchByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return visibleSavedSearch(ctx, search)
+}
+
+// ListSavedSearches retrieves the searches of the caller and the ones shared with the tenant; admins get every
+// search of the tenant
+func (s savedSearchService) ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error) {
+	searches, err := s.repo.ListSavedSearches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]domain.SavedSearch, 0, len(searches))
+	for _, search := range searches {
+		if canSeeSavedSearch(ctx, search) {
+			visible = append(visible, search)
+		}
+	}
+	return visible, nil
+}
+
+// UpdateSavedSearch replaces the definition of a saved search. Only its owner and admins may change it; other
+// callers get domain.ErrNotSavedSearchOwner, or a repository.NotFoundError when they cannot see it.
+func (s savedSearchService) UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) {
+	if err := validateSavedSearch(search); err != nil {
+		return nil, err
+	}
+	if _, err := s.owned(ctx, search.ID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateSavedSearch(ctx, search)
+}
+
+// DeleteSavedSearch deletes a saved search, with the same restrictions as UpdateSavedSearch
+func (s savedSearchService) DeleteSavedSearch(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.owned(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteSavedSearch(ctx, id)
+}
+
+// visibleSavedSearch hides the saved search from callers who cannot see it
+func visibleSavedSearch(ctx context.Context, search *domain.SavedSearch) (*domain.SavedSearch, error) {
+	if !canSeeSavedSearch(ctx, *search) {
+		return nil, repository.NewNotFoundError("saved search not found")
+	}
+	return search, nil
+}
+
+// owned retrieves a saved search the caller may change
+func (s savedSearchService) owned(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error) {
+	search, err := s.GetSavedSearch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if search.Owner != callerSubject(ctx) && !isAdmin(ctx) {
+		return nil, domain.ErrNotSavedSearchOwner
+	}
+	return search, nil
+}
+
+func canSeeSavedSearch(ctx context.Context, search domain.SavedSearch) bool {
+	return search.IsVisibleTo(callerSubject(ctx)) || isAdmin(ctx)
+}
+
+// validateSavedSearch checks the search, parsing its filter expression and its sort
+func validateSavedSearch(search domain.SavedSearch) error {
+	var problems []string
+	var validationErr domain.ValidationError
+	if err := search.Validate(); errors.As(err, &validationErr) {
+		problems = append(problems, validationErr.Problems...)
+	}
+	if search.Filter != "" {
+		if _, err := filter.Parse(search.Filter); err != nil {
+			problems = append(problems, "filter: "+err.Error())
+		}
+	}
+	if search.Sort != "" {
+		if _, err := filter.ParseSort(search.Sort); err != nil {
+			problems = append(problems, "sort: "+err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return domain.NewValidationError(problems)
+	}
+	return nil
+}
+
+// callerSubject returns the subject of the authenticated caller, empty when the request was not authenticated
+func callerSubject(ctx context.Context) string {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	return principal.Subject
+}
+
+func isAdmin(ctx context.Context) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	return ok && principal.IsAdmin()
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/mocks"
+)
+
+func TestSavedSearchService_CreateSavedSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	svc := NewSavedSearchService(repo)
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "analyst"})
+
+	_, err := svc.CreateSavedSearch(ctx, domain.SavedSearch{Name: "big debits", Filter: "amount >", Sort: "origin"})
+	var validationErr domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Problems, 3)
+
+	repo.EXPECT().CreateSavedSearch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, search domain.SavedSearch) (*domain.SavedSearch, error) { return &search, nil },
+	)
+	created, err := svc.CreateSavedSearch(ctx, domain.SavedSearch{Name: "big-debits", Owner: "someone else", Filter: "amount > 1000", Sort: "-amount"})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, created.ID)
+	require.Equal(t, "analyst", created.Owner)
+}
+
+func TestSavedSearchService_Visibility(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	private := domain.SavedSearch{ID: uuid.New(), Name: "mine", Owner: "analyst"}
+	shared := domain.SavedSearch{ID: uuid.New(), Name: "ours", Owner: "analyst", Shared: true}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().ListSavedSearches(gomock.Any()).Return([]domain.SavedSearch{private, shared}, nil).AnyTimes()
+	repo.EXPECT().GetSavedSearch(gomock.Any(), private.ID).Return(&private, nil).AnyTimes()
+	repo.EXPECT().GetSavedSearch(gomock.Any(), shared.ID).Return(&shared, nil).AnyTimes()
+	svc := NewSavedSearchService(repo)
+
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "analyst"})
+	colleague := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "colleague"})
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "admin", Scopes: []string{domain.ScopeAdmin}})
+
+	searches, err := svc.ListSavedSearches(owner)
+	require.NoError(t, err)
+	require.Equal(t, []domain.SavedSearch{private, shared}, searches)
+
+	searches, err = svc.ListSavedSearches(colleague)
+	require.NoError(t, err)
+	require.Equal(t, []domain.SavedSearch{shared}, searches)
+
+	// a private search is not disclosed, a shared one can only be changed by its owner and admins
+	_, err = svc.GetSavedSearch(colleague, private.ID)
+	var notFoundErr repository.NotFoundError
+	require.True(t, errors.As(err, &notFoundErr))
+	require.True(t, errors.As(svc.DeleteSavedSearch(colleague, private.ID), &notFoundErr))
+	require.ErrorIs(t, svc.DeleteSavedSearch(colleague, shared.ID), domain.ErrNotSavedSearchOwner)
+
+	_, err = svc.UpdateSavedSearch(colleague, domain.SavedSearch{ID: shared.ID, Name: "ours", Filter: "amount > 1"})
+	require.ErrorIs(t, err, domain.ErrNotSavedSearchOwner)
+
+	repo.EXPECT().DeleteSavedSearch(gomock.Any(), shared.ID).Return(nil).Times(2)
+	require.NoError(t, svc.DeleteSavedSearch(owner, shared.ID))
+	require.NoError(t, svc.DeleteSavedSearch(admin, shared.ID))
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+)
+
+// CreateSchedule validates and stores an active schedule, in DefaultCurrency when it has no currency, whose first run
+// is its first occurrence from its start on.
+// The start cannot be more than domain.ScheduleStartGracePeriod in the past, which would backfill the occurrences since.
+// Callers restricted to their own data can only schedule transactions of their own.
+func (s scheduleService) CreateSchedule(ctx context.Context, schedule domain.Schedule) (*domain.Schedule, error) {
+	if userID, restricted := restrictedUserID(ctx); restricted && schedule.UserID != userID {
+		return nil, domain.NewValidationError([]string{"user_id must be the user of the caller"})
+	}
+	now := s.now()
+	if schedule.StartAt.IsZero() {
+		schedule.StartAt = now
+	}
+	schedule.StartAt = schedule.StartAt.UTC()
+	if schedule.Currency == "" {
+		schedule.Currency = domain.DefaultCurrency
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	if err := schedule.ValidateStart(now); err != nil {
+		return nil, err
+	}
+
+	nextRunAt, err := schedule.FirstRun()
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.ID = uuid.New()
+	schedule.Status = domain.ScheduleStatusActive
+	schedule.NextRunAt = nextRunAt
+	schedule.LastRunAt, schedule.RunCount, schedule.LastError = nil, 0, ""
+	return s.repo.CreateSchedule(ctx, schedule)
+}
+
+// GetSchedule retrieves a schedule. Callers restricted to their own data get a repository.NotFoundError for the
+// schedules of other users.
+func (s scheduleService) GetSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	schedule, err := s.repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID, restricted := restrictedUserID(ctx); restricted && schedule.UserID != userID {
+		return nil, repository.NewNotFoundError("schedule not found")
+	}
+	return schedule, nil
+}
+
+// ListSchedules retrieves the schedules of the tenant, only their own ones for the callers restricted to their own data
+func (s scheduleService) ListSchedules(ctx context.Context) ([]domain.Schedule, error) {
+	var user *uuid.UUID
+	if userID, restricted := restrictedUserID(ctx); restricted {
+		user = &userID
+	}
+	return s.repo.ListSchedules(ctx, user)
+}
+
+// PauseSchedule stops materializing the occurrences of an active schedule until it is resumed
+func (s scheduleService) PauseSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	if _, err := s.GetSchedule(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateScheduleStatus(ctx, id, []domain.ScheduleStatus{domain.ScheduleStatusActive}, domain.ScheduleStatusPaused, nil)
+}
+
+// ResumeSchedule reactivates a paused schedule from its first occurrence after now: the occurrences of the pause are
+// skipped. The schedule is completed instead when its recurrence ended meanwhile.
+func (s scheduleService) ResumeSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	schedule, err := s.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != domain.ScheduleStatusPaused {
+		return nil, fmt.Errorf("%w: the schedule is %s", domain.ErrScheduleTransition, schedule.Status)
+	}
+
+	nextRunAt, err := schedule.NextRun(s.now())
+	if err != nil {
+		return nil, err
+	}
+	status := domain.ScheduleStatusActive
+	if nextRunAt == nil {
+		status = domain.ScheduleStatusCompleted
+	}
+	return s.repo.UpdateScheduleStatus(ctx, id, []domain.ScheduleStatus{domain.ScheduleStatusPaused}, status, nextRunAt)
+}
+
+// CancelSchedule ends an active or paused schedule for good
+func (s scheduleService) CancelSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	if _, err := s.GetSchedule(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateScheduleStatus(ctx, id,
+		[]domain.ScheduleStatus{domain.ScheduleStatusActive, domain.ScheduleStatusPaused}, domain.ScheduleStatusCancelled, nil)
+}
+
+// PreviewSchedule lists the next occurrences of a schedule, at most count, which must be between 1 and
+// domain.MaxScheduleOccurrencesPreview
+func (s scheduleService) PreviewSchedule(ctx context.Context, id uuid.UUID, count int) ([]time.Time, error) {
+	if count < 1 || count > domain.MaxScheduleOccurrencesPreview {
+		return nil, domain.NewValidationError([]string{
+			fmt.Sprintf("count must be between 1 and %d", domain.MaxScheduleOccurrencesPreview)})
+	}
+
+	schedule, err := s.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Preview(s.now(), count)
+}
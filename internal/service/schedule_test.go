@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/mocks"
+)
+
+func TestScheduleService_CreateSchedule(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2024, time.February, 1, 8, 30, 0, 0, time.UTC)
+	restricted := auth.WithPrincipal(context.Background(), auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, UserID: &userID})
+
+	testData := map[string]struct {
+		ctx           context.Context
+		schedule      domain.Schedule
+		wantNextRunAt time.Time
+		wantCurrency  string
+		wantErr       bool
+	}{
+		"it starts the schedule now by default": {
+			ctx:           context.Background(),
+			schedule:      domain.Schedule{UserID: userID, Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeDebit, Amount: 999, Cron: "0 9 * * *"},
+			wantNextRunAt: time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+			wantCurrency:  domain.DefaultCurrency,
+		},
+		"it schedules the transactions in the currency of the schedule": {
+			ctx:           context.Background(),
+			schedule:      domain.Schedule{UserID: userID, Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeDebit, Amount: 999, Currency: "EUR", Cron: "0 9 * * *"},
+			wantNextRunAt: time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+			wantCurrency:  "EUR",
+		},
+		"it schedules the transactions of the restricted caller": {
+			ctx:           restricted,
+			schedule:      domain.Schedule{UserID: userID, Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeCredit, Amount: 100, RRule: "FREQ=MONTHLY;BYMONTHDAY=1", StartAt: now.AddDate(0, 0, 1)},
+			wantNextRunAt: time.Date(2024, time.March, 1, 8, 30, 0, 0, time.UTC),
+			wantCurrency:  domain.DefaultCurrency,
+		},
+		"it rejects the transactions of another user for a restricted caller": {
+			ctx:      restricted,
+			schedule: domain.Schedule{UserID: uuid.New(), Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeDebit, Amount: 999, Cron: "0 9 * * *"},
+			wantErr:  true,
+		},
+		"it starts a schedule slightly in the past": {
+			ctx:           context.Background(),
+			schedule:      domain.Schedule{UserID: userID, Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeDebit, Amount: 999, RRule: "FREQ=DAILY", StartAt: now.Add(-time.Minute)},
+			wantNextRunAt: now.Add(-time.Minute),
+			wantCurrency:  domain.DefaultCurrency,
+		},
+		"it rejects a start that would backfill past occurrences": {
+			ctx:      context.Background(),
+			schedule: domain.Schedule{UserID: userID, Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeDebit, Amount: 999, RRule: "FREQ=DAILY", StartAt: now.AddDate(-1, 0, 0)},
+			wantErr:  true,
+		},
+		"it rejects an invalid recurrence": {
+			ctx:      context.Background(),
+			schedule: domain.Schedule{UserID: userID, Origin: domain.OriginDesktopWeb, TransactionType: domain.TransactionTypeDebit, Amount: 999, Cron: "0 25 * * *"},
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			if !tc.wantErr {
+				repo.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, schedule domain.Schedule) (*domain.Schedule, error) { return &schedule, nil },
+				)
+			}
+			svc := scheduleService{repo: repo, now: func() time.Time { return now }}
+
+			created, err := svc.CreateSchedule(tc.ctx, tc.schedule)
+			if tc.wantErr {
+				var validationErr domain.ValidationError
+				require.True(t, errors.As(err, &validationErr))
+				return
+			}
+			require.NoError(t, err)
+			require.NotEqual(t, uuid.Nil, created.ID)
+			require.Equal(t, domain.ScheduleStatusActive, created.Status)
+			require.Equal(t, tc.wantNextRunAt, *created.NextRunAt)
+			require.Equal(t, tc.wantCurrency, created.Currency)
+		})
+	}
+}
+
+func TestScheduleService_ResumeSchedule(t *testing.T) {
+	now := time.Date(2024, time.February, 10, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)
+	schedule := domain.Schedule{
+		ID:              uuid.New(),
+		UserID:          uuid.New(),
+		Origin:          domain.OriginDesktopWeb,
+		TransactionType: domain.TransactionTypeDebit,
+		Amount:          999,
+		StartAt:         start,
+		Status:          domain.ScheduleStatusPaused,
+	}
+
+	testData := map[string]struct {
+		rrule          string
+		status         domain.ScheduleStatus
+		wantStatus     domain.ScheduleStatus
+		wantNextRunAt  *time.Time
+		wantTransition bool
+	}{
+		"it resumes from the first occurrence after now": {
+			rrule:         "FREQ=DAILY",
+			status:        domain.ScheduleStatusPaused,
+			wantStatus:    domain.ScheduleStatusActive,
+			wantNextRunAt: func() *time.Time { t := time.Date(2024, time.February, 11, 9, 0, 0, 0, time.UTC); return &t }(),
+		},
+		"it completes a schedule whose recurrence ended during the pause": {
+			rrule:      "FREQ=DAILY;COUNT=3",
+			status:     domain.ScheduleStatusPaused,
+			wantStatus: domain.ScheduleStatusCompleted,
+		},
+		"it does not resume a cancelled schedule": {
+			rrule:          "FREQ=DAILY",
+			status:         domain.ScheduleStatusCancelled,
+			wantTransition: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			stored := schedule
+			stored.RRule = tc.rrule
+			stored.Status = tc.status
+
+			repo := mocks.NewMockRepository(ctrl)
+			repo.EXPECT().GetSchedule(gomock.Any(), schedule.ID).Return(&stored, nil)
+			if !tc.wantTransition {
+				repo.EXPECT().UpdateScheduleStatus(gomock.Any(), schedule.ID, []domain.ScheduleStatus{domain.ScheduleStatusPaused}, tc.wantStatus, tc.wantNextRunAt).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, _ []domain.ScheduleStatus, to domain.ScheduleStatus, nextRunAt *time.Time) (*domain.Schedule, error) {
+						updated := stored
+						updated.Status, updated.NextRunAt = to, nextRunAt
+						return &updated, nil
+					})
+			}
+			svc := scheduleService{repo: repo, now: func() time.Time { return now }}
+
+			resumed, err := svc.ResumeSchedule(context.Background(), schedule.ID)
+			if tc.wantTransition {
+				require.ErrorIs(t, err, domain.ErrScheduleTransition)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, resumed.Status)
+		})
+	}
+}
+
+func TestScheduleService_GetSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := uuid.New()
+	schedule := domain.Schedule{ID: uuid.New(), UserID: uuid.New()}
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().GetSchedule(gomock.Any(), schedule.ID).Return(&schedule, nil).Times(2)
+	svc := NewScheduleService(repo)
+
+	got, err := svc.GetSchedule(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, schedule.ID, got.ID)
+
+	restricted := auth.WithPrincipal(context.Background(), auth.Principal{Scopes: []string{domain.ScopeTransactionsRead}, UserID: &userID})
+	_, err = svc.GetSchedule(restricted, schedule.ID)
+	var notFound repository.NotFoundError
+	require.True(t, errors.As(err, &notFound))
+}
+
+func TestScheduleService_PreviewSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	svc := NewScheduleService(repo)
+
+	for _, count := range []int{0, domain.MaxScheduleOccurrencesPreview + 1} {
+		_, err := svc.PreviewSchedule(context.Background(), uuid.New(), count)
+		var validationErr domain.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+	}
+}
@@ -3,22 +3,204 @@ package service
 
 import (
 	"context"
+	"github.com/google/uuid"
+	"time"
 	"traive-engineering-challenge/internal/domain"
 	"traive-engineering-challenge/internal/repository"
 	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/risk"
 )
 
 type transactionService struct {
-	repo repository.Repository
+	repo     repository.Repository
+	screener risk.Screener
+	metrics  transactionMetrics
 }
 
 type TransactionService interface {
 	CreateTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error)
+	GetTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error)
 	ListTransactions(ctx context.Context, options ...filter.Options) ([]domain.Transaction, error)
+	CategorizeTransaction(ctx context.Context, id uuid.UUID, categoryID *uuid.UUID) (*domain.Transaction, error)
+	GetTransactionStats(ctx context.Context, dimension string, options ...filter.Options) ([]domain.TransactionStats, error)
+}
+
+type limitService struct {
+	repo repository.Repository
+}
+
+type LimitService interface {
+	CreateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error)
+	GetLimit(ctx context.Context, id uuid.UUID) (*domain.Limit, error)
+	ListLimits(ctx context.Context) ([]domain.Limit, error)
+	UpdateLimit(ctx context.Context, limit domain.Limit) (*domain.Limit, error)
+	DeleteLimit(ctx context.Context, id uuid.UUID) error
+	GetUserLimits(ctx context.Context, userID uuid.UUID) ([]domain.LimitUsage, error)
+}
+
+type savedSearchService struct {
+	repo repository.Repository
+}
+
+type SavedSearchService interface {
+	CreateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id uuid.UUID) (*domain.SavedSearch, error)
+	GetSavedSearchByName(ctx context.Context, name string) (*domain.SavedSearch, error)
+	ListSavedSearches(ctx context.Context) ([]domain.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, search domain.SavedSearch) (*domain.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id uuid.UUID) error
+}
+
+type categoryService struct {
+	repo repository.Repository
+}
+
+type CategoryService interface {
+	CreateCategory(ctx context.Context, category domain.Category) (*domain.Category, error)
+	GetCategory(ctx context.Context, id uuid.UUID) (*domain.Category, error)
+	ListCategories(ctx context.Context) ([]domain.Category, error)
+	UpdateCategory(ctx context.Context, category domain.Category) (*domain.Category, error)
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+	CreateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error)
+	GetCategoryRule(ctx context.Context, id uuid.UUID) (*domain.CategoryRule, error)
+	ListCategoryRules(ctx context.Context) ([]domain.CategoryRule, error)
+	UpdateCategoryRule(ctx context.Context, rule domain.CategoryRule) (*domain.CategoryRule, error)
+	DeleteCategoryRule(ctx context.Context, id uuid.UUID) error
+}
+
+type scheduleService struct {
+	repo repository.Repository
+	now  func() time.Time
+}
+
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, schedule domain.Schedule) (*domain.Schedule, error)
+	GetSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
+	ListSchedules(ctx context.Context) ([]domain.Schedule, error)
+	PauseSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
+	ResumeSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
+	CancelSchedule(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
+	PreviewSchedule(ctx context.Context, id uuid.UUID, count int) ([]time.Time, error)
+}
+
+type holdService struct {
+	repo         repository.Repository
+	transactions transactionService
+	now          func() time.Time
+}
+
+type HoldService interface {
+	CreateHold(ctx context.Context, hold domain.Hold) (*domain.Hold, error)
+	GetHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error)
+	ListHolds(ctx context.Context) ([]domain.Hold, error)
+	CaptureHold(ctx context.Context, id uuid.UUID, amount int64) (*domain.Hold, error)
+	ReleaseHold(ctx context.Context, id uuid.UUID) (*domain.Hold, error)
+	GetUserBalance(ctx context.Context, userID uuid.UUID, currency string) (*domain.Balance, error)
+}
+
+type fxService struct {
+	repo         repository.Repository
+	transactions transactionService
+	quoteTTL     time.Duration
+	now          func() time.Time
+}
+
+type FXService interface {
+	LoadRates(ctx context.Context, snapshot domain.RateSnapshot) (*domain.RateSnapshot, error)
+	ListRateSnapshots(ctx context.Context) ([]domain.RateSnapshot, error)
+	GetRates(ctx context.Context) (*domain.RateSnapshot, error)
+	CreateQuote(ctx context.Context, request domain.FXQuoteRequest) (*domain.FXQuote, error)
+	Convert(ctx context.Context, request domain.FXConversionRequest) (*domain.FXConversion, error)
+	GetConversion(ctx context.Context, id uuid.UUID) (*domain.FXConversion, error)
+	ListConversions(ctx context.Context) ([]domain.FXConversion, error)
+}
+
+type apiKeyService struct {
+	repo         repository.Repository
+	bootstrapKey string
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, request domain.APIKeyRequest) (*domain.IssuedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID) (*domain.IssuedAPIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
 }
 
-func NewTransactionService(repo repository.Repository) TransactionService {
+type statementService struct {
+	repo repository.Repository
+}
+
+type StatementService interface {
+	GetMonthlyStatement(ctx context.Context, userID uuid.UUID, currency, period string) (*domain.Statement, error)
+}
+
+func NewTransactionService(repo repository.Repository, screener risk.Screener) TransactionService {
 	return transactionService{
+		repo:     repo,
+		screener: screener,
+		metrics:  newTransactionMetrics(),
+	}
+}
+
+func NewLimitService(repo repository.Repository) LimitService {
+	return limitService{
+		repo: repo,
+	}
+}
+
+func NewSavedSearchService(repo repository.Repository) SavedSearchService {
+	return savedSearchService{
+		repo: repo,
+	}
+}
+
+func NewCategoryService(repo repository.Repository) CategoryService {
+	return categoryService{
+		repo: repo,
+	}
+}
+
+func NewScheduleService(repo repository.Repository) ScheduleService {
+	return scheduleService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// NewHoldService builds the hold service, whose captures are screened, categorized and checked against the limits
+// like the transactions created through the transaction service
+func NewHoldService(repo repository.Repository, screener risk.Screener) HoldService {
+	return holdService{
+		repo:         repo,
+		transactions: transactionService{repo: repo, screener: screener, metrics: newTransactionMetrics()},
+		now:          time.Now,
+	}
+}
+
+// NewFXService builds the foreign exchange service, whose quotes lock their rate for quoteTTL and whose conversion
+// debits are screened like the transactions created through the transaction service
+func NewFXService(repo repository.Repository, screener risk.Screener, quoteTTL time.Duration) FXService {
+	return fxService{
+		repo:         repo,
+		transactions: transactionService{repo: repo, screener: screener, metrics: newTransactionMetrics()},
+		quoteTTL:     quoteTTL,
+		now:          time.Now,
+	}
+}
+
+// NewAPIKeyService builds the API key service. When set, the bootstrap key is accepted as an admin key
+// without being stored, so that the first keys can be created on a fresh deployment.
+func NewAPIKeyService(repo repository.Repository, bootstrapKey string) APIKeyService {
+	return apiKeyService{
+		repo:         repo,
+		bootstrapKey: bootstrapKey,
+	}
+}
+
+func NewStatementService(repo repository.Repository) StatementService {
+	return statementService{
 		repo: repo,
 	}
 }
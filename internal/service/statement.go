@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"traive-engineering-challenge/internal/domain"
+)
+
+// GetMonthlyStatement builds the statement of a user in the currency for a yyyy-mm period.
+// The opening balance accounts for every transaction in the currency created before the period starts.
+func (s statementService) GetMonthlyStatement(ctx context.Context, userID uuid.UUID, currency, period string) (*domain.Statement, error) {
+	from, to, err := domain.ParseStatementPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	openingBalance, err := s.repo.GetBalance(ctx, userID, currency, from)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.repo.ListUserTransactionsInPeriod(ctx, userID, currency, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewStatement(userID, currency, period, openingBalance, transactions)
+}
@@ -2,11 +2,53 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"traive-engineering-challenge/internal/auth"
 	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
 	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/risk"
 )
 
+// CreateTransaction checks the metadata and the tags of the transaction, which are rejected with a
+// domain.ValidationError when over their limits, then screens it against the risk rules before persisting it.
+// The transaction is categorized by the first categorization rule of the tenant that matches it.
+// Denied transactions are rejected with a risk.DeniedError, while transactions that need a review
+// are persisted as pending review together with the rules that flagged them.
+// Debits are then checked against the limits that apply to the user and origin, and must leave the funds held for the
+// user, or they are rejected with a domain.LimitExceededError or a domain.InsufficientFundsError.
+// Callers restricted to their own data can only create their own transactions.
 func (t transactionService) CreateTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error) {
+	if userID, restricted := restrictedUserID(ctx); restricted && transaction.UserID != userID {
+		return nil, domain.NewValidationError([]string{"user_id must be the user of the caller"})
+	}
+
+	result, err := t.createTransaction(ctx, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	t.metrics.recordCreated(ctx, result)
+	return result, nil
+}
+
+func (t transactionService) createTransaction(ctx context.Context, transaction domain.Transaction) (*domain.Transaction, error) {
+	if err := t.prepare(ctx, &transaction); err != nil {
+		return nil, err
+	}
+
+	if transaction.TransactionType == domain.TransactionTypeDebit {
+		limits, err := applicableLimits(ctx, t.repo, transaction.UserID, transaction.Origin, transaction.Currency)
+		if err != nil {
+			return nil, err
+		}
+		return t.repo.CreateTransactionWithinLimits(ctx, transaction, limits)
+	}
+
 	result, err := t.repo.CreateTransaction(ctx, transaction)
 	if err != nil {
 		return nil, err // Return the error if there's an issue
@@ -14,10 +56,122 @@ func (t transactionService) CreateTransaction(ctx context.Context, transaction d
 	return result, nil
 }
 
+// prepare checks the currency and the metadata of the transaction, screens it and categorizes it, setting its status
+// from the screening
+func (t transactionService) prepare(ctx context.Context, transaction *domain.Transaction) error {
+	if transaction.Currency == "" {
+		transaction.Currency = domain.DefaultCurrency
+	}
+	if err := transaction.ValidateCurrency(); err != nil {
+		return err
+	}
+	if err := transaction.ValidateMetadata(); err != nil {
+		return err
+	}
+
+	screening, err := t.screener.Screen(ctx, *transaction)
+	if err != nil {
+		return err
+	}
+
+	switch screening.Decision {
+	case risk.DecisionDeny:
+		return risk.NewDeniedError(screening.RuleIDs)
+	case risk.DecisionReview:
+		transaction.Status = domain.TransactionStatusPendingReview
+	default:
+		transaction.Status = domain.TransactionStatusCompleted
+	}
+	transaction.FlaggedRules = screening.RuleIDs
+
+	return t.categorize(ctx, transaction)
+}
+
+// GetTransaction retrieves a transaction. Callers restricted to their own data get a repository.NotFoundError
+// for transactions of other users, so that their existence is not disclosed.
+func (t transactionService) GetTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error) {
+	transaction, err := t.repo.GetTransaction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID, restricted := restrictedUserID(ctx); restricted && transaction.UserID != userID {
+		return nil, repository.NewNotFoundError("transaction not found")
+	}
+	return transaction, nil
+}
+
+// ListTransactions retrieves the transactions matching the options. Callers restricted to their own data
+// only see their own transactions, whatever the options.
 func (t transactionService) ListTransactions(ctx context.Context, options ...filter.Options) ([]domain.Transaction, error) {
-	result, err := t.repo.ListTransactions(ctx)
+	if userID, restricted := restrictedUserID(ctx); restricted {
+		options = append(options, filter.WithUserID(userID))
+	}
+
+	result, err := t.repo.ListTransactions(ctx, options...)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// categorize sets the category of the first rule matching the transaction; the category sent by the client, if any,
+// is discarded
+func (t transactionService) categorize(ctx context.Context, transaction *domain.Transaction) error {
+	transaction.CategoryID, transaction.CategorizedBy, transaction.CategorizedAt = nil, "", nil
+
+	rules, err := t.repo.ListCategoryRules(ctx)
+	if err != nil {
+		return err
+	}
+	if rule, ok := domain.Categorize(rules, *transaction); ok {
+		now := time.Now()
+		categoryID := rule.CategoryID
+		transaction.CategoryID = &categoryID
+		transaction.CategorizedBy = domain.CategorizedByRulePrefix + rule.ID.String()
+		transaction.CategorizedAt = &now
+	}
+	return nil
+}
+
+// CategorizeTransaction overrides the category of a transaction, recording the caller that changed it. A nil category
+// leaves the transaction uncategorized. It returns a domain.ValidationError when the category does not exist, and a
+// repository.NotFoundError when the caller cannot see the transaction.
+func (t transactionService) CategorizeTransaction(ctx context.Context, id uuid.UUID, categoryID *uuid.UUID) (*domain.Transaction, error) {
+	if categoryID != nil {
+		if _, err := t.repo.GetCategory(ctx, *categoryID); err != nil {
+			var notFound repository.NotFoundError
+			if errors.As(err, &notFound) {
+				return nil, domain.NewValidationError([]string{"category_id does not exist"})
+			}
+			return nil, err
+		}
+	}
+	if _, err := t.GetTransaction(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return t.repo.SetTransactionCategory(ctx, id, categoryID, callerSubject(ctx))
+}
+
+// GetTransactionStats aggregates the transactions matching the options by the dimension, with the same restrictions
+// as ListTransactions. It returns a domain.ValidationError for an unknown dimension.
+func (t transactionService) GetTransactionStats(ctx context.Context, dimension string, options ...filter.Options) ([]domain.TransactionStats, error) {
+	if !domain.IsValidStatsDimension(dimension) {
+		return nil, domain.NewValidationError([]string{fmt.Sprintf("by must be one of %s", strings.Join(domain.StatsDimensions, ", "))})
+	}
+	if userID, restricted := restrictedUserID(ctx); restricted {
+		options = append(options, filter.WithUserID(userID))
+	}
+
+	return t.repo.GetTransactionStats(ctx, dimension, options...)
+}
+
+// restrictedUserID returns the user the authenticated caller is restricted to, if any
+func restrictedUserID(ctx context.Context) (uuid.UUID, bool) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return principal.RestrictedUserID()
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"traive-engineering-challenge/internal/auth"
+	"traive-engineering-challenge/internal/domain"
+	"traive-engineering-challenge/internal/repository"
+	"traive-engineering-challenge/internal/repository/filter"
+	"traive-engineering-challenge/internal/repository/mocks"
+	"traive-engineering-challenge/internal/risk"
+	"traive-engineering-challenge/internal/support"
+)
+
+type stubScreener struct {
+	result risk.Result
+	err    error
+}
+
+func (s stubScreener) Screen(context.Context, domain.Transaction) (risk.Result, error) {
+	return s.result, s.err
+}
+
+func TestTransactionService_CreateTransaction(t *testing.T) {
+	transaction := support.ValidDomainTransaction(uuid.New(), uuid.New(), support.DesktopWeb, "", 1000)
+	transaction.Description = "Weekly groceries"
+	// a category sent by the client is replaced by the one of the rules
+	transaction.CategoryID = &[]uuid.UUID{uuid.New()}[0]
+
+	groceries := uuid.New()
+	rules := []domain.CategoryRule{
+		{ID: uuid.New(), CategoryID: uuid.New(), Origin: support.MobileIOS},
+		{ID: uuid.New(), CategoryID: groceries, DescriptionPattern: `(?i)grocer`},
+	}
+
+	testData := map[string]struct {
+		screener     stubScreener
+		prepareRepo  func(repo *mocks.MockRepository)
+		wantStatus   domain.TransactionStatus
+		wantRules    []string
+		wantCategory *uuid.UUID
+		wantDenied   bool
+		wantErr      bool
+	}{
+		"allowed transactions are persisted as completed": {
+			screener: stubScreener{result: risk.Result{Decision: risk.DecisionAllow}},
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, nil)
+				repo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, tx domain.Transaction) (*domain.Transaction, error) { return &tx, nil },
+				)
+			},
+			wantStatus: domain.TransactionStatusCompleted,
+		},
+		"flagged transactions are persisted as pending review": {
+			screener: stubScreener{result: risk.Result{Decision: risk.DecisionReview, RuleIDs: []string{"origin-change"}}},
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, nil)
+				repo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, tx domain.Transaction) (*domain.Transaction, error) { return &tx, nil },
+				)
+			},
+			wantStatus: domain.TransactionStatusPendingReview,
+			wantRules:  []string{"origin-change"},
+		},
+		"transactions are categorized by the first matching rule": {
+			screener: stubScreener{result: risk.Result{Decision: risk.DecisionAllow}},
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().ListCategoryRules(gomock.Any()).Return(rules, nil)
+				repo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, tx domain.Transaction) (*domain.Transaction, error) { return &tx, nil },
+				)
+			},
+			wantStatus:   domain.TransactionStatusCompleted,
+			wantCategory: &groceries,
+		},
+		"failure - listing the categorization rules fails": {
+			screener: stubScreener{result: risk.Result{Decision: risk.DecisionAllow}},
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, errors.New("boom"))
+			},
+			wantErr: true,
+		},
+		"denied transactions are not persisted": {
+			screener:    stubScreener{result: risk.Result{Decision: risk.DecisionDeny, RuleIDs: []string{"velocity"}}},
+			prepareRepo: func(repo *mocks.MockRepository) {},
+			wantRules:   []string{"velocity"},
+			wantDenied:  true,
+			wantErr:     true,
+		},
+		"failure - screening fails": {
+			screener:    stubScreener{err: errors.New("boom")},
+			prepareRepo: func(repo *mocks.MockRepository) {},
+			wantErr:     true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			tc.prepareRepo(repo)
+
+			created, err := NewTransactionService(repo, tc.screener).CreateTransaction(context.Background(), *transaction)
+			if tc.wantErr {
+				require.Error(t, err)
+				var denied risk.DeniedError
+				require.Equal(t, tc.wantDenied, errors.As(err, &denied))
+				if tc.wantDenied {
+					require.Equal(t, tc.wantRules, denied.RuleIDs)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, created.Status)
+			require.Equal(t, tc.wantRules, created.FlaggedRules)
+			require.Equal(t, tc.wantCategory, created.CategoryID)
+			if tc.wantCategory != nil {
+				require.Equal(t, domain.CategorizedByRulePrefix+rules[1].ID.String(), created.CategorizedBy)
+				require.NotNil(t, created.CategorizedAt)
+			} else {
+				require.Empty(t, created.CategorizedBy)
+			}
+		})
+	}
+}
+
+func TestTransactionService_CreateTransaction_Limits(t *testing.T) {
+	userID := uuid.New()
+	debit := domain.Transaction{ID: uuid.New(), UserID: userID, Origin: support.MobileIOS, TransactionType: domain.TransactionTypeDebit, Amount: 100}
+
+	globalLimit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodDaily, MaxAmount: 1000, Currency: domain.DefaultCurrency}
+	otherOriginLimit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeOrigin, Origin: support.DesktopWeb, Period: domain.LimitPeriodDaily, MaxAmount: 10, Currency: domain.DefaultCurrency}
+	otherCurrencyLimit := domain.Limit{ID: uuid.New(), Scope: domain.LimitScopeGlobal, Period: domain.LimitPeriodDaily, MaxAmount: 10, Currency: "EUR"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, nil)
+	repo.EXPECT().ListApplicableLimits(gomock.Any(), userID).Return([]domain.Limit{globalLimit, otherOriginLimit, otherCurrencyLimit}, nil)
+	repo.EXPECT().CreateTransactionWithinLimits(gomock.Any(), gomock.Any(), []domain.Limit{globalLimit}).
+		Return(nil, domain.NewLimitExceededError([]string{globalLimit.ID.String()}))
+
+	_, err := NewTransactionService(repo, risk.NewEngine()).CreateTransaction(context.Background(), debit)
+
+	var exceeded domain.LimitExceededError
+	require.True(t, errors.As(err, &exceeded))
+	require.Equal(t, []string{globalLimit.ID.String()}, exceeded.LimitIDs)
+}
+
+func TestTransactionService_CreateTransaction_HeldFunds(t *testing.T) {
+	userID := uuid.New()
+	debit := domain.Transaction{ID: uuid.New(), UserID: userID, Origin: support.MobileIOS, TransactionType: domain.TransactionTypeDebit, Amount: 100}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// without any limit, the debit is still checked against the held funds
+	repo := mocks.NewMockRepository(ctrl)
+	repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, nil)
+	repo.EXPECT().ListApplicableLimits(gomock.Any(), userID).Return(nil, nil)
+	repo.EXPECT().CreateTransactionWithinLimits(gomock.Any(), gomock.Any(), gomock.Len(0)).
+		Return(nil, domain.NewInsufficientFundsError(40, 100))
+
+	_, err := NewTransactionService(repo, risk.NewEngine()).CreateTransaction(context.Background(), debit)
+
+	var insufficientFunds domain.InsufficientFundsError
+	require.True(t, errors.As(err, &insufficientFunds))
+	require.Equal(t, domain.NewInsufficientFundsError(40, 100), insufficientFunds)
+}
+
+func TestTransactionService_CreateTransaction_InvalidMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	transaction := support.ValidDomainTransaction(uuid.New(), uuid.New(), support.DesktopWeb, "", 1000)
+	transaction.Metadata = map[string]string{"order id": "A-1001"}
+	transaction.Tags = []string{"vip", "vip"}
+
+	// Neither screened nor persisted
+	screener := stubScreener{err: errors.New("unexpected screening")}
+	_, err := NewTransactionService(mocks.NewMockRepository(ctrl), screener).CreateTransaction(context.Background(), *transaction)
+
+	var validationErr domain.ValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+	require.Len(t, validationErr.Problems, 2)
+}
+
+func TestTransactionService_CreateTransaction_Isolation(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	testData := map[string]struct {
+		principal   auth.Principal
+		userID      uuid.UUID
+		wantCreated bool
+	}{
+		"end users create their own transactions": {
+			principal:   auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, UserID: &userID},
+			userID:      userID,
+			wantCreated: true,
+		},
+		"end users do not create the transactions of other users": {
+			principal: auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, UserID: &userID},
+			userID:    otherUserID,
+		},
+		"admins create the transactions of every user": {
+			principal:   auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}, Roles: []string{auth.RoleAdmin}, UserID: &userID},
+			userID:      otherUserID,
+			wantCreated: true,
+		},
+		"service clients create the transactions of every user": {
+			principal:   auth.Principal{Scopes: []string{domain.ScopeTransactionsWrite}},
+			userID:      otherUserID,
+			wantCreated: true,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			transaction := support.ValidDomainTransaction(uuid.New(), tc.userID, support.DesktopWeb, "", 1000)
+
+			repo := mocks.NewMockRepository(ctrl)
+			if tc.wantCreated {
+				repo.EXPECT().ListCategoryRules(gomock.Any()).Return(nil, nil).AnyTimes()
+				repo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, transaction domain.Transaction) (*domain.Transaction, error) {
+						return &transaction, nil
+					},
+				)
+			}
+
+			ctx := auth.WithPrincipal(context.Background(), tc.principal)
+			_, err := NewTransactionService(repo, stubScreener{result: risk.Result{Decision: risk.DecisionAllow}}).CreateTransaction(ctx, *transaction)
+			if !tc.wantCreated {
+				var validationErr domain.ValidationError
+				require.True(t, errors.As(err, &validationErr), "expected a validation error, got %v", err)
+				require.Equal(t, []string{"user_id must be the user of the caller"}, validationErr.Problems)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTransactionService_ListTransactions_Isolation(t *testing.T) {
+	userID := uuid.New()
+
+	testData := map[string]struct {
+		principal   *auth.Principal
+		wantOptions int
+	}{
+		"end users only see their own transactions": {
+			principal:   &auth.Principal{Scopes: []string{domain.ScopeTransactionsRead}, UserID: &userID},
+			wantOptions: 2,
+		},
+		"admins see the transactions of every user": {
+			principal:   &auth.Principal{Scopes: []string{domain.ScopeTransactionsRead}, Roles: []string{auth.RoleAdmin}, UserID: &userID},
+			wantOptions: 1,
+		},
+		"service clients see the transactions of every user": {
+			principal:   &auth.Principal{Scopes: []string{domain.ScopeTransactionsRead}},
+			wantOptions: 1,
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			repo.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, options ...filter.Options) ([]domain.Transaction, error) {
+					require.Len(t, options, tc.wantOptions)
+					return []domain.Transaction{}, nil
+				},
+			)
+
+			ctx := auth.WithPrincipal(context.Background(), *tc.principal)
+			_, err := NewTransactionService(repo, risk.NewEngine()).ListTransactions(ctx, filter.WithOrigin(support.MobileIOS))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTransactionService_GetTransaction(t *testing.T) {
+	userID := uuid.New()
+	transaction := support.ValidDomainTransaction(uuid.New(), userID, support.DesktopWeb, "", 1000)
+	otherUserID := uuid.New()
+
+	testData := map[string]struct {
+		principal    auth.Principal
+		wantNotFound bool
+	}{
+		"end users get their own transactions": {
+			principal: auth.Principal{UserID: &userID},
+		},
+		"end users do not get the transactions of other users": {
+			principal:    auth.Principal{UserID: &otherUserID},
+			wantNotFound: true,
+		},
+		"admins get the transactions of every user": {
+			principal: auth.Principal{Roles: []string{auth.RoleAdmin}, UserID: &otherUserID},
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			repo.EXPECT().GetTransaction(gomock.Any(), transaction.ID).Return(transaction, nil)
+
+			ctx := auth.WithPrincipal(context.Background(), tc.principal)
+			got, err := NewTransactionService(repo, risk.NewEngine()).GetTransaction(ctx, transaction.ID)
+			if tc.wantNotFound {
+				var notFound repository.NotFoundError
+				require.True(t, errors.As(err, &notFound))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, transaction, got)
+		})
+	}
+}
+
+func TestTransactionService_CategorizeTransaction(t *testing.T) {
+	userID := uuid.New()
+	transaction := support.ValidDomainTransaction(uuid.New(), userID, support.DesktopWeb, "", 1000)
+	categoryID := uuid.New()
+	otherUserID := uuid.New()
+
+	testData := map[string]struct {
+		principal   auth.Principal
+		categoryID  *uuid.UUID
+		prepareRepo func(repo *mocks.MockRepository)
+		wantErr     func(t *testing.T, err error)
+	}{
+		"overrides record the caller": {
+			principal:  auth.Principal{Subject: "analyst"},
+			categoryID: &categoryID,
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().GetCategory(gomock.Any(), categoryID).Return(&domain.Category{ID: categoryID, Name: "food"}, nil)
+				repo.EXPECT().GetTransaction(gomock.Any(), transaction.ID).Return(transaction, nil)
+				repo.EXPECT().SetTransactionCategory(gomock.Any(), transaction.ID, &categoryID, "analyst").Return(transaction, nil)
+			},
+		},
+		"the category can be cleared": {
+			principal: auth.Principal{Subject: "analyst"},
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().GetTransaction(gomock.Any(), transaction.ID).Return(transaction, nil)
+				repo.EXPECT().SetTransactionCategory(gomock.Any(), transaction.ID, nil, "analyst").Return(transaction, nil)
+			},
+		},
+		"failure - the category does not exist": {
+			principal:  auth.Principal{Subject: "analyst"},
+			categoryID: &categoryID,
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().GetCategory(gomock.Any(), categoryID).Return(nil, repository.NewNotFoundError("category not found"))
+			},
+			wantErr: func(t *testing.T, err error) {
+				var validationErr domain.ValidationError
+				require.True(t, errors.As(err, &validationErr))
+			},
+		},
+		"failure - end users cannot categorize the transactions of other users": {
+			principal:  auth.Principal{Subject: "someone", UserID: &otherUserID},
+			categoryID: &categoryID,
+			prepareRepo: func(repo *mocks.MockRepository) {
+				repo.EXPECT().GetCategory(gomock.Any(), categoryID).Return(&domain.Category{ID: categoryID, Name: "food"}, nil)
+				repo.EXPECT().GetTransaction(gomock.Any(), transaction.ID).Return(transaction, nil)
+			},
+			wantErr: func(t *testing.T, err error) {
+				var notFound repository.NotFoundError
+				require.True(t, errors.As(err, &notFound))
+			},
+		},
+	}
+
+	for name, tc := range testData {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockRepository(ctrl)
+			tc.prepareRepo(repo)
+
+			ctx := auth.WithPrincipal(context.Background(), tc.principal)
+			_, err := NewTransactionService(repo, risk.NewEngine()).CategorizeTransaction(ctx, transaction.ID, tc.categoryID)
+			if tc.wantErr != nil {
+				tc.wantErr(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTransactionService_GetTransactionStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userID := uuid.New()
+	repo := mocks.NewMockRepository(ctrl)
+	svc := NewTransactionService(repo, risk.NewEngine())
+
+	_, err := svc.GetTransactionStats(context.Background(), "amount")
+	var validationErr domain.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+
+	// end users only aggregate their own transactions
+	repo.EXPECT().GetTransactionStats(gomock.Any(), domain.StatsByCategory, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, options ...filter.Options) ([]domain.TransactionStats, error) {
+			require.Len(t, options, 1)
+			return []domain.TransactionStats{}, nil
+		},
+	)
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{UserID: &userID})
+	_, err = svc.GetTransactionStats(ctx, domain.StatsByCategory)
+	require.NoError(t, err)
+}
@@ -3,14 +3,113 @@ package support
 const (
 	MsgTransactionCreatedSuccessfully    = "Transaction created successfully"
 	ErrFailedToRetrieveTransactions      = "Failed to retrieve transactions"
+	ErrFailedToRetrieveTransaction       = "Failed to retrieve transaction"
+	ErrInvalidTransactionID              = "Invalid transaction ID"
+	ErrTransactionNotFound               = "Transaction not found"
+	ErrInvalidFilter                     = "Invalid filter expression"
+	ErrInvalidMetadataFilter             = "Invalid metadata filter"
+	ErrInvalidTagFilter                  = "Invalid tag filter"
+	ErrInvalidTransaction                = "Invalid transaction"
 	ErrFailedToEncodeResponse            = "Failed to encode response"
 	ErrFailedToDecodeRequest             = "Failed to decode request body"
 	ErrFailedToCreateTransaction         = "Failed to create transaction"
+	ErrTransactionDenied                 = "Transaction denied by risk screening"
+	ErrTransactionExceedsLimits          = "Transaction exceeds limits"
+	ErrTransactionInsufficientFunds      = "The available balance does not cover the debit"
+	ErrInvalidLimit                      = "Invalid limit"
+	ErrInvalidLimitID                    = "Invalid limit ID"
+	ErrLimitNotFound                     = "Limit not found"
+	ErrFailedToCreateLimit               = "Failed to create limit"
+	ErrFailedToUpdateLimit               = "Failed to update limit"
+	ErrFailedToDeleteLimit               = "Failed to delete limit"
+	ErrFailedToRetrieveLimits            = "Failed to retrieve limits"
+	ErrMissingCredentials                = "Missing credentials"
+	ErrInvalidCredentials                = "Invalid credentials"
+	ErrFailedToAuthenticate              = "Failed to authenticate request"
+	ErrInsufficientScope                 = "Insufficient scope"
+	ErrAccessDenied                      = "Access denied"
+	ErrInvalidTenantID                   = "Invalid tenant ID"
+	ErrTenantMismatch                    = "Credentials are bound to another tenant"
+	ErrRateLimitExceeded                 = "Rate limit exceeded"
+	ErrInvalidAPIKeyRequest              = "Invalid API key request"
+	ErrInvalidAPIKeyID                   = "Invalid API key ID"
+	ErrAPIKeyNotFound                    = "API key not found"
+	ErrFailedToCreateAPIKey              = "Failed to create API key"
+	ErrFailedToRetrieveAPIKeys           = "Failed to retrieve API keys"
+	ErrFailedToRotateAPIKey              = "Failed to rotate API key"
+	ErrFailedToRevokeAPIKey              = "Failed to revoke API key"
+	ErrInvalidSavedSearch                = "Invalid saved search"
+	ErrInvalidSavedSearchID              = "Invalid saved search ID"
+	ErrSavedSearchNotFound               = "Saved search not found"
+	ErrSavedSearchNameTaken              = "A saved search with this name already exists"
+	ErrNotSavedSearchOwner               = "Only the owner of a saved search can change it"
+	ErrFailedToCreateSavedSearch         = "Failed to create saved search"
+	ErrFailedToUpdateSavedSearch         = "Failed to update saved search"
+	ErrFailedToDeleteSavedSearch         = "Failed to delete saved search"
+	ErrFailedToRetrieveSavedSearches     = "Failed to retrieve saved searches"
+	ErrInvalidSort                       = "Invalid sort"
+	ErrInvalidCategoryFilter             = "Invalid category filter"
+	ErrInvalidSearch                     = "Invalid search"
+	ErrInvalidCategory                   = "Invalid category"
+	ErrInvalidCategoryID                 = "Invalid category ID"
+	ErrCategoryNotFound                  = "Category not found"
+	ErrCategoryNameTaken                 = "A category with this name already exists"
+	ErrCategoryInUse                     = "The category has subcategories or categorization rules"
+	ErrFailedToCreateCategory            = "Failed to create category"
+	ErrFailedToUpdateCategory            = "Failed to update category"
+	ErrFailedToDeleteCategory            = "Failed to delete category"
+	ErrFailedToRetrieveCategories        = "Failed to retrieve categories"
+	ErrInvalidCategoryRule               = "Invalid categorization rule"
+	ErrInvalidCategoryRuleID             = "Invalid categorization rule ID"
+	ErrCategoryRuleNotFound              = "Categorization rule not found"
+	ErrFailedToCreateCategoryRule        = "Failed to create categorization rule"
+	ErrFailedToUpdateCategoryRule        = "Failed to update categorization rule"
+	ErrFailedToDeleteCategoryRule        = "Failed to delete categorization rule"
+	ErrFailedToRetrieveCategoryRules     = "Failed to retrieve categorization rules"
+	ErrFailedToCategorizeTransaction     = "Failed to categorize transaction"
+	ErrInvalidSchedule                   = "Invalid schedule"
+	ErrInvalidScheduleID                 = "Invalid schedule ID"
+	ErrScheduleNotFound                  = "Schedule not found"
+	ErrScheduleTransition                = "The schedule cannot move to this status"
+	ErrFailedToCreateSchedule            = "Failed to create schedule"
+	ErrFailedToUpdateSchedule            = "Failed to update schedule"
+	ErrFailedToRetrieveSchedules         = "Failed to retrieve schedules"
+	ErrInvalidHold                       = "Invalid hold"
+	ErrInvalidHoldID                     = "Invalid hold ID"
+	ErrHoldNotFound                      = "Hold not found"
+	ErrHoldTransition                    = "The hold is no longer active"
+	ErrInsufficientFunds                 = "The available balance does not cover the hold"
+	ErrFailedToCreateHold                = "Failed to create hold"
+	ErrFailedToCaptureHold               = "Failed to capture hold"
+	ErrFailedToReleaseHold               = "Failed to release hold"
+	ErrFailedToRetrieveHolds             = "Failed to retrieve holds"
+	ErrFailedToRetrieveBalance           = "Failed to retrieve balance"
+	ErrInvalidFXRates                    = "Invalid exchange rates"
+	ErrInvalidFXQuote                    = "Invalid quote"
+	ErrInvalidFXConversion               = "Invalid conversion"
+	ErrInvalidConversionID               = "Invalid conversion ID"
+	ErrFXRatesNotFound                   = "No exchange rates are loaded"
+	ErrQuoteNotFound                     = "Quote not found"
+	ErrQuoteUnavailable                  = "The quote is no longer available"
+	ErrConversionInsufficientFunds       = "The available balance does not cover the conversion"
+	ErrConversionNotFound                = "Conversion not found"
+	ErrFailedToLoadFXRates               = "Failed to load exchange rates"
+	ErrFailedToRetrieveFXRates           = "Failed to retrieve exchange rates"
+	ErrFailedToCreateQuote               = "Failed to create quote"
+	ErrFailedToConvert                   = "Failed to convert"
+	ErrFailedToRetrieveConversions       = "Failed to retrieve conversions"
+	ErrInvalidStatsDimension             = "Invalid stats dimension"
+	ErrFailedToRetrieveTransactionStats  = "Failed to retrieve transaction stats"
+	ErrInvalidUserID                     = "Invalid user ID"
+	ErrInvalidCurrency                   = "Invalid currency"
+	ErrInvalidStatementPeriod            = "Invalid statement period, expected yyyy-mm"
+	ErrUnsupportedStatementFormat        = "Unsupported statement format, accepted formats are application/json, text/csv and application/pdf"
+	ErrFailedToRetrieveStatement         = "Failed to retrieve statement"
 	ErrFailedToMarshalRequestBody        = "Failed to marshal request body: %v"
 	ErrFailedToMarshalExpectedResponse   = "Failed to marshal expected response for %s: %v"
 	ErrFailedToUnmarshalExpectedResponse = "Failed to unmarshal expected response JSON for %s: %v"
 	DesktopWeb                           = "desktop-web"
 	MobileAndroid                        = "mobile-android"
 	MobileIOS                            = "mobile-ios"
-	DatabaseURL                          = "DATABASE_URL"
+	ConfigFile                           = "CONFIG_FILE"
 )
@@ -0,0 +1,195 @@
+// Package telemetry installs the OpenTelemetry tracer and meter providers used by the instrumentation of the API
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Exporters of the telemetry
+const (
+	ExporterNone     = "none"
+	ExporterStdout   = "stdout"
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+)
+
+// stdout is where the stdout exporters write, replaced in tests
+var stdout io.Writer = os.Stdout
+
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Exporter is one of the Exporter* constants
+	Exporter string
+	// OTLPEndpoint is the host:port of the collector; the default endpoint of the protocol is used when empty
+	OTLPEndpoint string
+	// OTLPInsecure disables TLS, e.g. to reach a collector running next to the API
+	OTLPInsecure bool
+	// SampleRatio is the share of the traces started by the API that are sampled, from 0 to 1.
+	// Traces started by a caller keep the sampling decision of the caller.
+	SampleRatio float64
+	// MetricInterval is how often the metrics are exported
+	MetricInterval time.Duration
+}
+
+// Telemetry holds the providers installed by Setup
+type Telemetry struct {
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	registry       *prometheus.Registry
+}
+
+// Setup installs the global tracer and meter providers and the W3C trace context and baggage propagators.
+// Spans are recorded and propagated even with the none exporter, so that trace IDs reach downstream services.
+// Whatever the exporter, metrics can also be scraped in the Prometheus format from MetricsHandler.
+// Shutdown must be called on exit to flush the telemetry that was not exported yet.
+func Setup(ctx context.Context, cfg Config) (*Telemetry, error) {
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(cfg.ServiceVersion),
+			semconv.DeploymentEnvironment(cfg.Environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build telemetry resource: %w", err)
+	}
+
+	spanExporter, metricExporter, err := newExporters(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tracerOptions := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+	if spanExporter != nil {
+		tracerOptions = append(tracerOptions, sdktrace.WithBatcher(spanExporter))
+	}
+	tracerProvider := sdktrace.NewTracerProvider(tracerOptions...)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	prometheusReader, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus exporter: %w", err)
+	}
+
+	meterOptions := []sdkmetric.Option{sdkmetric.WithResource(res), sdkmetric.WithReader(prometheusReader)}
+	if metricExporter != nil {
+		meterOptions = append(meterOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(cfg.MetricInterval))))
+	}
+	meterProvider := sdkmetric.NewMeterProvider(meterOptions...)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.WithError(err).Warn("Telemetry error")
+	}))
+
+	return &Telemetry{
+		tracerProvider: tracerProvider,
+		meterProvider:  meterProvider,
+		registry:       registry,
+	}, nil
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format
+func (t *Telemetry) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(t.registry, promhttp.HandlerOpts{})
+}
+
+// Shutdown flushes the pending telemetry and stops the providers
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	return errors.Join(t.tracerProvider.Shutdown(ctx), t.meterProvider.Shutdown(ctx))
+}
+
+func newExporters(ctx context.Context, cfg Config) (sdktrace.SpanExporter, sdkmetric.Exporter, error) {
+	switch cfg.Exporter {
+	case ExporterNone:
+		return nil, nil, nil
+	case ExporterStdout:
+		spanExporter, err := stdouttrace.New(stdouttrace.WithWriter(stdout))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create span exporter: %w", err)
+		}
+		metricExporter, err := stdoutmetric.New(stdoutmetric.WithWriter(stdout))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create metric exporter: %w", err)
+		}
+		return spanExporter, metricExporter, nil
+	case ExporterOTLPGRPC:
+		var traceOptions []otlptracegrpc.Option
+		var metricOptions []otlpmetricgrpc.Option
+		if cfg.OTLPEndpoint != "" {
+			traceOptions = append(traceOptions, otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint))
+			metricOptions = append(metricOptions, otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			traceOptions = append(traceOptions, otlptracegrpc.WithInsecure())
+			metricOptions = append(metricOptions, otlpmetricgrpc.WithInsecure())
+		}
+
+		spanExporter, err := otlptracegrpc.New(ctx, traceOptions...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create span exporter: %w", err)
+		}
+		metricExporter, err := otlpmetricgrpc.New(ctx, metricOptions...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create metric exporter: %w", err)
+		}
+		return spanExporter, metricExporter, nil
+	case ExporterOTLPHTTP:
+		var traceOptions []otlptracehttp.Option
+		var metricOptions []otlpmetrichttp.Option
+		if cfg.OTLPEndpoint != "" {
+			traceOptions = append(traceOptions, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+			metricOptions = append(metricOptions, otlpmetrichttp.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			traceOptions = append(traceOptions, otlptracehttp.WithInsecure())
+			metricOptions = append(metricOptions, otlpmetrichttp.WithInsecure())
+		}
+
+		spanExporter, err := otlptracehttp.New(ctx, traceOptions...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create span exporter: %w", err)
+		}
+		metricExporter, err := otlpmetrichttp.New(ctx, metricOptions...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create metric exporter: %w", err)
+		}
+		return spanExporter, metricExporter, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown telemetry exporter %q", cfg.Exporter)
+	}
+}
@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetup(t *testing.T) {
+	var buf bytes.Buffer
+	stdout = &buf
+
+	telemetry, err := Setup(context.Background(), Config{
+		ServiceName:    "transactions-api",
+		ServiceVersion: "1.2.3",
+		Environment:    "test",
+		Exporter:       ExporterStdout,
+		SampleRatio:    1,
+		MetricInterval: time.Minute,
+	})
+	require.NoError(t, err)
+
+	ctx, span := otel.Tracer("telemetry-test").Start(context.Background(), "operation")
+	require.True(t, span.SpanContext().IsSampled())
+	span.End()
+
+	counter, err := otel.Meter("telemetry-test").Int64Counter("operations")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+
+	// the trace context of the span is propagated to downstream requests
+	header := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	require.Contains(t, header.Get("traceparent"), span.SpanContext().TraceID().String())
+
+	// the metrics can be scraped in the Prometheus format
+	rec := httptest.NewRecorder()
+	telemetry.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `operations_total{otel_scope_name="telemetry-test",otel_scope_version=""} 1`)
+	require.Contains(t, rec.Body.String(), "go_goroutines")
+
+	// shutting down flushes the span and the metric that were not exported yet
+	require.NoError(t, telemetry.Shutdown(context.Background()))
+	require.Contains(t, buf.String(), `"Name":"operation"`)
+	require.Contains(t, buf.String(), `"Name":"operations"`)
+	require.Contains(t, buf.String(), `"Value":"transactions-api"`)
+	require.Contains(t, buf.String(), `"Value":"test"`)
+}
+
+func TestSetup_SampleRatio(t *testing.T) {
+	telemetry, err := Setup(context.Background(), Config{Exporter: ExporterNone, SampleRatio: 0})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, telemetry.Shutdown(context.Background())) }()
+
+	_, span := otel.Tracer("telemetry-test").Start(context.Background(), "operation")
+	defer span.End()
+
+	require.True(t, span.SpanContext().IsValid(), "spans should still carry a trace context")
+	require.False(t, span.SpanContext().IsSampled())
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "zipkin"})
+	require.Error(t, err)
+}
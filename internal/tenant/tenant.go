@@ -0,0 +1,40 @@
+// Package tenant carries the tenant of a request, i.e. the business unit whose data it may access
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Default is the tenant of requests that do not name one, and of the data created before tenants existed
+const Default = "default"
+
+// ErrInvalidID is returned for tenant IDs that are not 1 to 63 lowercase letters, digits, dashes or underscores
+var ErrInvalidID = errors.New("invalid tenant id")
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+type contextKey string
+
+const tenantKey contextKey = "tenant"
+
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+// WithID returns a copy of the context carrying the tenant
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey, id)
+}
+
+// FromContext returns the tenant of the context, or the default tenant when none was set
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantKey).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
@@ -0,0 +1,36 @@
+package tenant
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidateID(t *testing.T) {
+	tests := map[string]bool{
+		"default":          true,
+		"acme":             true,
+		"business-unit_42": true,
+		"":                 false,
+		"Acme":             false,
+		"-acme":            false,
+		"acme corp":        false,
+		"acme'; --":        false,
+	}
+
+	for id, valid := range tests {
+		t.Run(id, func(t *testing.T) {
+			err := ValidateID(id)
+			if valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidID)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	require.Equal(t, Default, FromContext(context.Background()))
+	require.Equal(t, "acme", FromContext(WithID(context.Background(), "acme")))
+}
@@ -0,0 +1,7 @@
+SET search_path TO public;
+
+-- Outcome of the risk screening performed when a transaction is created
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS status VARCHAR(32) NOT NULL DEFAULT 'COMPLETED';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS flagged_rules TEXT[];
+
+CREATE INDEX IF NOT EXISTS transactions_user_id_created_at_idx ON transactions (user_id, created_at);
@@ -0,0 +1,19 @@
+SET search_path TO public;
+
+-- Caps on the sum of the debits of a user over a rolling window.
+-- user limits apply to a single user, origin limits to the debits made through one origin
+-- and global limits to every debit.
+CREATE TABLE IF NOT EXISTS transaction_limits (
+    id         uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    scope      VARCHAR(16) NOT NULL CHECK (scope IN ('user', 'origin', 'global')),
+    user_id    uuid,
+    origin     VARCHAR(255),
+    period     VARCHAR(16) NOT NULL CHECK (period IN ('daily', 'monthly')),
+    max_amount BIGINT NOT NULL CHECK (max_amount > 0),
+    created_at timestamp(6) without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp(6) without time zone NOT NULL DEFAULT now(),
+    CHECK ((scope = 'user') = (user_id IS NOT NULL)),
+    CHECK ((scope = 'origin') = (origin IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS transaction_limits_user_id_idx ON transaction_limits (user_id);
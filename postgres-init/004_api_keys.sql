@@ -0,0 +1,14 @@
+SET search_path TO public;
+
+-- API keys authenticating the clients of the API. Only the SHA-256 of the key is stored,
+-- the prefix is the public part of the key used to look it up.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    prefix       VARCHAR(32) NOT NULL UNIQUE,
+    key_hash     VARCHAR(64) NOT NULL,
+    scopes       TEXT[] NOT NULL,
+    created_at   timestamp(6) without time zone NOT NULL DEFAULT now(),
+    last_used_at timestamp(6) without time zone,
+    revoked_at   timestamp(6) without time zone
+);
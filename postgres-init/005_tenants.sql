@@ -0,0 +1,36 @@
+SET search_path TO public;
+
+-- Every table holds the data of several tenants. The rows created before tenants existed belong to the default tenant.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE transaction_limits ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+
+ALTER TABLE transactions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE transaction_limits ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+
+DROP INDEX IF EXISTS transactions_user_id_created_at_idx;
+CREATE INDEX IF NOT EXISTS transactions_tenant_id_user_id_created_at_idx ON transactions (tenant_id, user_id, created_at);
+DROP INDEX IF EXISTS transaction_limits_user_id_idx;
+CREATE INDEX IF NOT EXISTS transaction_limits_tenant_id_user_id_idx ON transaction_limits (tenant_id, user_id);
+CREATE INDEX IF NOT EXISTS api_keys_tenant_id_idx ON api_keys (tenant_id);
+
+-- Row level security as defence in depth: besides the tenant predicate of every query, the repository binds each
+-- database transaction to a tenant with set_config('app.tenant_id', ..., true), and only the rows of that tenant
+-- are visible or writable. Without a bound tenant no row is visible.
+-- Superusers and roles with BYPASSRLS are not subject to these policies, so the application must connect with
+-- a regular role for them to apply.
+-- API keys are not protected: they are looked up by prefix to learn the tenant of a request.
+ALTER TABLE transactions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE transactions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS transactions_tenant_isolation ON transactions;
+CREATE POLICY transactions_tenant_isolation ON transactions
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE transaction_limits ENABLE ROW LEVEL SECURITY;
+ALTER TABLE transaction_limits FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS transaction_limits_tenant_isolation ON transaction_limits;
+CREATE POLICY transaction_limits_tenant_isolation ON transaction_limits
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
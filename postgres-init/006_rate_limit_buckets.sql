@@ -0,0 +1,22 @@
+SET search_path TO public;
+
+-- Token buckets of the rate limiter when it is configured to share them between instances.
+-- The key identifies the caller (API key, user or IP address) and the class of route (read or write).
+-- A bucket expires once it refilled completely under the limit of its class, after which it is swept.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    tenant_id  VARCHAR(63) NOT NULL,
+    key        VARCHAR(255) NOT NULL,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at timestamp(6) with time zone NOT NULL,
+    expires_at timestamp(6) with time zone NOT NULL,
+    PRIMARY KEY (tenant_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS rate_limit_buckets_tenant_id_expires_at_idx ON rate_limit_buckets (tenant_id, expires_at);
+
+ALTER TABLE rate_limit_buckets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE rate_limit_buckets FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS rate_limit_buckets_tenant_isolation ON rate_limit_buckets;
+CREATE POLICY rate_limit_buckets_tenant_isolation ON rate_limit_buckets
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
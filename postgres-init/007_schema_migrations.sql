@@ -0,0 +1,11 @@
+SET search_path TO public;
+
+-- Versions of the migrations applied to the database. The readiness check of the API compares the latest version
+-- with the version it expects, so every new migration must record its version here.
+-- The table describes the schema rather than the data of a tenant, hence it has no tenant_id.
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INT PRIMARY KEY,
+    applied_at timestamp(6) with time zone NOT NULL DEFAULT now()
+);
+
+INSERT INTO schema_migrations (version) VALUES (1), (2), (3), (4), (5), (6), (7) ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+SET search_path TO public;
+
+-- Amounts are whole minor units read into 64 bit integers, like the max_amount of the limits: NUMERIC(10, 2) values
+-- such as 100.00 could not be scanned into them.
+ALTER TABLE transactions ALTER COLUMN amount TYPE BIGINT USING amount::bigint;
+
+-- Transaction IDs are chosen by the clients; without a primary key a retried request could store a transaction twice.
+-- The key is scoped to the tenant, so that tenants do not collide on the IDs, and a conflict does not tell a client that
+-- an ID exists in another tenant.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'transactions_pkey') THEN
+        ALTER TABLE transactions ADD CONSTRAINT transactions_pkey PRIMARY KEY (tenant_id, id);
+    END IF;
+END $$;
+
+INSERT INTO schema_migrations (version) VALUES (8) ON CONFLICT DO NOTHING;
@@ -0,0 +1,27 @@
+SET search_path TO public;
+
+-- Named combinations of the filters and the sort of the transaction list. Names are unique within a tenant, as
+-- searches shared with the tenant are applied by name. owner is the subject of the caller that created the search.
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id               uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id        VARCHAR(63) NOT NULL,
+    name             VARCHAR(63) NOT NULL,
+    owner            VARCHAR(255) NOT NULL,
+    origin           VARCHAR(255),
+    transaction_type VARCHAR(255),
+    filter           VARCHAR(1024),
+    sort             VARCHAR(32),
+    shared           BOOLEAN NOT NULL DEFAULT false,
+    created_at       timestamp(6) without time zone NOT NULL DEFAULT now(),
+    updated_at       timestamp(6) without time zone NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE saved_searches ENABLE ROW LEVEL SECURITY;
+ALTER TABLE saved_searches FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS saved_searches_tenant_isolation ON saved_searches;
+CREATE POLICY saved_searches_tenant_isolation ON saved_searches
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+INSERT INTO schema_migrations (version) VALUES (9) ON CONFLICT DO NOTHING;
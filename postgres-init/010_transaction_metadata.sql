@@ -0,0 +1,11 @@
+SET search_path TO public;
+
+-- Context attached by the clients, such as order IDs, merchant names and campaign codes. The list endpoint filters
+-- them with containment (metadata @> '{"order_id": "A-1"}', tags @> '{vip}'), which the GIN indexes serve.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS transactions_metadata_idx ON transactions USING GIN (metadata jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS transactions_tags_idx ON transactions USING GIN (tags);
+
+INSERT INTO schema_migrations (version) VALUES (10) ON CONFLICT DO NOTHING;
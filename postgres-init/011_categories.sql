@@ -0,0 +1,58 @@
+SET search_path TO public;
+
+-- The tree of the spending categories of each tenant. Names are unique within a tenant; a category cannot be deleted
+-- while it has subcategories or rules.
+CREATE TABLE IF NOT EXISTS categories (
+    id         uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id  VARCHAR(63) NOT NULL,
+    name       VARCHAR(63) NOT NULL,
+    parent_id  uuid REFERENCES categories (id) ON DELETE RESTRICT,
+    created_at timestamp(6) without time zone NOT NULL DEFAULT now(),
+    updated_at timestamp(6) without time zone NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_idx ON categories (parent_id);
+
+-- Rules categorizing the transactions when they are created, evaluated by priority then creation time
+CREATE TABLE IF NOT EXISTS category_rules (
+    id                  uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id           VARCHAR(63) NOT NULL,
+    category_id         uuid NOT NULL REFERENCES categories (id) ON DELETE RESTRICT,
+    priority            INTEGER NOT NULL DEFAULT 0,
+    origin              VARCHAR(255),
+    min_amount          BIGINT,
+    max_amount          BIGINT,
+    metadata            JSONB,
+    description_pattern VARCHAR(256),
+    created_at          timestamp(6) without time zone NOT NULL DEFAULT now(),
+    updated_at          timestamp(6) without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS category_rules_tenant_idx ON category_rules (tenant_id, priority, created_at, id);
+
+-- categorized_by is rule:{id} for the transactions categorized by a rule, or the subject of the caller that set the
+-- category. Deleting a category is only possible without subcategories and rules, and leaves its transactions
+-- uncategorized.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS description VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS category_id uuid REFERENCES categories (id) ON DELETE SET NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS categorized_by VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS categorized_at timestamp(6) without time zone;
+
+CREATE INDEX IF NOT EXISTS transactions_category_idx ON transactions (tenant_id, category_id);
+
+ALTER TABLE categories ENABLE ROW LEVEL SECURITY;
+ALTER TABLE categories FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS categories_tenant_isolation ON categories;
+CREATE POLICY categories_tenant_isolation ON categories
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE category_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE category_rules FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS category_rules_tenant_isolation ON category_rules;
+CREATE POLICY category_rules_tenant_isolation ON category_rules
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+INSERT INTO schema_migrations (version) VALUES (11) ON CONFLICT DO NOTHING;
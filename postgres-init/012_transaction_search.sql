@@ -0,0 +1,14 @@
+SET search_path TO public;
+
+-- Full-text search of the description and of the merchant and reference metadata, the keys of
+-- domain.SearchableMetadataKeys. The simple configuration neither stems nor drops stop words, so that merchant names
+-- and references are matched as they are written; the words of the description weigh more in the rank.
+-- Adding the generated column rewrites the table.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', description), 'A') ||
+    setweight(to_tsvector('simple', coalesce(metadata ->> 'merchant', '') || ' ' || coalesce(metadata ->> 'reference', '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS transactions_search_idx ON transactions USING GIN (search_vector);
+
+INSERT INTO schema_migrations (version) VALUES (12) ON CONFLICT DO NOTHING;
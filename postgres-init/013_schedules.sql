@@ -0,0 +1,72 @@
+SET search_path TO public;
+
+-- Recurring transactions, described by exactly one of a cron expression and an RFC 5545 recurrence rule. The scheduler
+-- materializes the occurrence at next_run_at of the active schedules into a transaction, then moves next_run_at to the
+-- following occurrence; next_run_at is NULL once the recurrence has no more occurrences.
+-- A schedule is processed by the instance holding its lease. The lease of an instance that died expires after
+-- lease_expires_at and the schedule is claimed again, its occurrence being materialized at most once thanks to the
+-- primary key of the schedule runs.
+CREATE TABLE IF NOT EXISTS schedules (
+    id               uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id        VARCHAR(63) NOT NULL,
+    user_id          uuid NOT NULL,
+    origin           VARCHAR(255) NOT NULL,
+    transaction_type VARCHAR(255) NOT NULL,
+    amount           BIGINT NOT NULL,
+    currency         CHAR(3) NOT NULL DEFAULT 'USD',
+    description      VARCHAR(255) NOT NULL DEFAULT '',
+    metadata         JSONB NOT NULL DEFAULT '{}',
+    tags             TEXT[] NOT NULL DEFAULT '{}',
+    cron             VARCHAR(255),
+    rrule            VARCHAR(1024),
+    start_at         timestamp(6) without time zone NOT NULL,
+    status           VARCHAR(16) NOT NULL,
+    next_run_at      timestamp(6) without time zone,
+    last_run_at      timestamp(6) without time zone,
+    run_count        INTEGER NOT NULL DEFAULT 0,
+    last_error       VARCHAR(1024) NOT NULL DEFAULT '',
+    lease_owner      VARCHAR(255),
+    lease_expires_at timestamp(6) without time zone,
+    created_at       timestamp(6) without time zone NOT NULL DEFAULT now(),
+    updated_at       timestamp(6) without time zone NOT NULL DEFAULT now(),
+    CHECK ((cron IS NULL) <> (rrule IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS schedules_tenant_idx ON schedules (tenant_id, created_at, id);
+-- serves the claims of the scheduler, which only look at the due active schedules
+CREATE INDEX IF NOT EXISTS schedules_due_idx ON schedules (next_run_at) WHERE status = 'ACTIVE';
+
+ALTER TABLE schedules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE schedules FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS schedules_tenant_isolation ON schedules;
+CREATE POLICY schedules_tenant_isolation ON schedules
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+-- The scheduler claims the due schedules of every tenant in transactions setting app.scheduler rather than a tenant;
+-- each schedule is then materialized in a transaction bound to its own tenant.
+DROP POLICY IF EXISTS schedules_scheduler ON schedules;
+CREATE POLICY schedules_scheduler ON schedules
+    USING (current_setting('app.scheduler', true) = 'on')
+    WITH CHECK (current_setting('app.scheduler', true) = 'on');
+
+-- The occurrences materialized by the scheduler, inserted in the database transaction of their transaction. Unlike the
+-- IDs of the transactions, which the clients choose, the runs cannot be written through the API, so that no client can
+-- keep an occurrence from being materialized.
+CREATE TABLE IF NOT EXISTS schedule_runs (
+    tenant_id      VARCHAR(63) NOT NULL,
+    schedule_id    uuid NOT NULL,
+    occurrence     timestamp(6) without time zone NOT NULL,
+    transaction_id uuid NOT NULL,
+    created_at     timestamp(6) without time zone NOT NULL DEFAULT now(),
+    CONSTRAINT schedule_runs_pkey PRIMARY KEY (tenant_id, schedule_id, occurrence)
+);
+
+ALTER TABLE schedule_runs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE schedule_runs FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS schedule_runs_tenant_isolation ON schedule_runs;
+CREATE POLICY schedule_runs_tenant_isolation ON schedule_runs
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+INSERT INTO schema_migrations (version) VALUES (13) ON CONFLICT DO NOTHING;
@@ -0,0 +1,42 @@
+SET search_path TO public;
+
+-- Amounts reserved from the available balance of a user until they are captured by a debit, released, or expire.
+-- The available balance is the balance minus the amounts of the active holds that have not expired yet: a hold past
+-- expires_at no longer reserves its amount, even before the expiry sets its status to EXPIRED.
+CREATE TABLE IF NOT EXISTS holds (
+    id              uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id       VARCHAR(63) NOT NULL,
+    user_id         uuid NOT NULL,
+    origin          VARCHAR(255) NOT NULL,
+    amount          BIGINT NOT NULL CHECK (amount > 0),
+    description     VARCHAR(255) NOT NULL DEFAULT '',
+    metadata        JSONB NOT NULL DEFAULT '{}',
+    tags            TEXT[] NOT NULL DEFAULT '{}',
+    status          VARCHAR(16) NOT NULL,
+    expires_at      timestamp(6) without time zone NOT NULL,
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    transaction_id  uuid,
+    created_at      timestamp(6) without time zone NOT NULL DEFAULT now(),
+    updated_at      timestamp(6) without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS holds_tenant_idx ON holds (tenant_id, created_at, id);
+-- serves the sums of the held amounts of a user
+CREATE INDEX IF NOT EXISTS holds_user_active_idx ON holds (tenant_id, user_id) WHERE status = 'ACTIVE';
+-- serves the expiry, which only looks at the active holds
+CREATE INDEX IF NOT EXISTS holds_expiry_idx ON holds (expires_at) WHERE status = 'ACTIVE';
+
+ALTER TABLE holds ENABLE ROW LEVEL SECURITY;
+ALTER TABLE holds FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS holds_tenant_isolation ON holds;
+CREATE POLICY holds_tenant_isolation ON holds
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+-- The scheduler expires the holds of every tenant in transactions setting app.scheduler rather than a tenant
+DROP POLICY IF EXISTS holds_scheduler ON holds;
+CREATE POLICY holds_scheduler ON holds
+    USING (current_setting('app.scheduler', true) = 'on')
+    WITH CHECK (current_setting('app.scheduler', true) = 'on');
+
+INSERT INTO schema_migrations (version) VALUES (14) ON CONFLICT DO NOTHING;
@@ -0,0 +1,82 @@
+SET search_path TO public;
+
+-- The amounts of the transactions are in minor units of their ISO 4217 currency. The transactions created before
+-- currencies were introduced are in USD.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- The balances are kept per currency: a hold reserves the balance in its currency and a limit caps the debits in its
+-- currency. The holds and the limits created before currencies were introduced are in USD.
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE transaction_limits ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Tables of exchange rates, effective from effective_at until a snapshot with a later effective_at replaces them.
+-- The rates are kept as decimal strings, {"base_currency", "quote_currency", "rate"}, so that they are used exactly
+-- as they were loaded.
+CREATE TABLE IF NOT EXISTS fx_rate_snapshots (
+    id           uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id    VARCHAR(63) NOT NULL,
+    source       VARCHAR(255) NOT NULL DEFAULT '',
+    effective_at timestamp(6) without time zone NOT NULL,
+    rates        JSONB NOT NULL,
+    created_at   timestamp(6) without time zone NOT NULL DEFAULT now()
+);
+
+-- serves the lookup of the snapshot in effect at a given time
+CREATE INDEX IF NOT EXISTS fx_rate_snapshots_effective_idx ON fx_rate_snapshots (tenant_id, effective_at DESC, created_at DESC);
+
+-- Rates locked for a conversion until expires_at
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id              uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id       VARCHAR(63) NOT NULL,
+    user_id         uuid NOT NULL,
+    source_currency CHAR(3) NOT NULL,
+    target_currency CHAR(3) NOT NULL,
+    source_amount   BIGINT NOT NULL CHECK (source_amount > 0),
+    target_amount   BIGINT NOT NULL CHECK (target_amount > 0),
+    rate            NUMERIC NOT NULL CHECK (rate > 0),
+    snapshot_id     uuid NOT NULL REFERENCES fx_rate_snapshots (id),
+    expires_at      timestamp(6) without time zone NOT NULL,
+    created_at      timestamp(6) without time zone NOT NULL DEFAULT now()
+);
+
+-- The executed conversions, at most one per quote, with the debit and the credit they created
+CREATE TABLE IF NOT EXISTS fx_conversions (
+    id                    uuid DEFAULT public.gen_random_uuid() PRIMARY KEY,
+    tenant_id             VARCHAR(63) NOT NULL,
+    quote_id              uuid NOT NULL UNIQUE REFERENCES fx_quotes (id),
+    user_id               uuid NOT NULL,
+    origin                VARCHAR(255) NOT NULL,
+    source_currency       CHAR(3) NOT NULL,
+    target_currency       CHAR(3) NOT NULL,
+    source_amount         BIGINT NOT NULL,
+    target_amount         BIGINT NOT NULL,
+    rate                  NUMERIC NOT NULL,
+    debit_transaction_id  uuid NOT NULL,
+    credit_transaction_id uuid NOT NULL,
+    created_at            timestamp(6) without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS fx_conversions_tenant_idx ON fx_conversions (tenant_id, created_at, id);
+
+ALTER TABLE fx_rate_snapshots ENABLE ROW LEVEL SECURITY;
+ALTER TABLE fx_rate_snapshots FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS fx_rate_snapshots_tenant_isolation ON fx_rate_snapshots;
+CREATE POLICY fx_rate_snapshots_tenant_isolation ON fx_rate_snapshots
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE fx_quotes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE fx_quotes FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS fx_quotes_tenant_isolation ON fx_quotes;
+CREATE POLICY fx_quotes_tenant_isolation ON fx_quotes
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE fx_conversions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE fx_conversions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS fx_conversions_tenant_isolation ON fx_conversions;
+CREATE POLICY fx_conversions_tenant_isolation ON fx_conversions
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+INSERT INTO schema_migrations (version) VALUES (15) ON CONFLICT DO NOTHING;